@@ -0,0 +1,139 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/bridgecrewio/yor/src/common/clioptions"
+	"github.com/bridgecrewio/yor/src/common/tagging/utils"
+	"github.com/urfave/cli/v2"
+)
+
+// the flags which several commands share, so they are named and defaulted the same way in all of them
+const (
+	directoryArg     = "directory"
+	tagArg           = "tags"
+	skipTagsArg      = "skip-tags"
+	customTaggingArg = "custom-tagging"
+	skipDirsArg      = "skip-dirs"
+	outputArg        = "output"
+	tagGroupArg      = "tag-groups"
+	externalConfPath = "config-file"
+	parsersArgs      = "parsers"
+	tagPrefix        = "tag-prefix"
+	tagKeyNamesArg   = "tag-key-names"
+)
+
+const (
+	renameTagKeysUsage  = "rename the built-in tags, comma delimited key=name pairs (e.g. yor_trace=corp:trace-id). --tags and --skip-tags match the renamed keys"
+	renamedTagKeysUsage = "the names the built-in tags are renamed to by yor tag --tag-key-names, comma delimited key=name pairs (e.g. yor_trace=corp:trace-id)"
+)
+
+// directoryFlag is the directory the command works on, which has no default unless value is set
+func directoryFlag(usage string, value string) *cli.StringFlag {
+	defaultText := value
+	if defaultText == "" {
+		defaultText = "path/to/iac/root"
+	}
+	return &cli.StringFlag{
+		Name:        directoryArg,
+		Aliases:     []string{"d"},
+		Usage:       usage,
+		Value:       value,
+		DefaultText: defaultText,
+	}
+}
+
+func tagsFlag(usage string) *cli.StringSliceFlag {
+	return &cli.StringSliceFlag{
+		Name:        tagArg,
+		Aliases:     []string{"t"},
+		Usage:       usage,
+		DefaultText: "yor_trace,git_repository",
+	}
+}
+
+func skipTagsFlag(usage string) *cli.StringSliceFlag {
+	return &cli.StringSliceFlag{
+		Name:        skipTagsArg,
+		Aliases:     []string{"s"},
+		Usage:       usage,
+		Value:       cli.NewStringSlice(),
+		DefaultText: "yor_trace",
+	}
+}
+
+func customTaggingFlag() *cli.StringSliceFlag {
+	return &cli.StringSliceFlag{
+		Name:        customTaggingArg,
+		Aliases:     []string{"c"},
+		Usage:       "paths to custom tag groups and tags plugins",
+		Value:       cli.NewStringSlice(),
+		DefaultText: "path/to/custom/yor/tagging",
+	}
+}
+
+func skipDirsFlag() *cli.StringSliceFlag {
+	return &cli.StringSliceFlag{
+		Name:        skipDirsArg,
+		Usage:       "configuration paths to skip",
+		Value:       cli.NewStringSlice(),
+		DefaultText: "path/to/skip,another/path/to/skip",
+	}
+}
+
+func tagGroupsFlag() *cli.StringSliceFlag {
+	return &cli.StringSliceFlag{
+		Name:        tagGroupArg,
+		Aliases:     []string{"g"},
+		Usage:       "Narrow down results to the matching tag groups",
+		Value:       cli.NewStringSlice(utils.GetAllTagGroupsNames()...),
+		DefaultText: "git,code2cloud",
+	}
+}
+
+func configFileFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:        externalConfPath,
+		Usage:       "external tag group configuration file path",
+		DefaultText: "/path/to/conf/file/ (.yml/.yaml extension)",
+	}
+}
+
+// parsersFlag defaults to all the parsers of clioptions.AllowedParsers, so a new parser is run by all the commands
+func parsersFlag(usage string) *cli.StringSliceFlag {
+	return &cli.StringSliceFlag{
+		Name:        parsersArgs,
+		Aliases:     []string{"i", "framework"},
+		Usage:       usage,
+		Value:       cli.NewStringSlice(clioptions.AllowedParsers...),
+		DefaultText: strings.Join(clioptions.AllowedParsers, ","),
+	}
+}
+
+func tagPrefixFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:        tagPrefix,
+		Usage:       "Add prefix to all the tags",
+		DefaultText: "",
+	}
+}
+
+func tagKeyNamesFlag(usage string) *cli.StringSliceFlag {
+	return &cli.StringSliceFlag{
+		Name:        tagKeyNamesArg,
+		Usage:       usage,
+		Value:       cli.NewStringSlice(),
+		DefaultText: "",
+	}
+}
+
+// outputFormatFlag is the output of the commands which print either a summary or JSON
+func outputFormatFlag() *cli.StringFlag {
+	return &cli.StringFlag{
+		Name:        outputArg,
+		Aliases:     []string{"o"},
+		Usage:       "cli, json",
+		Value:       "cli",
+		DefaultText: "cli",
+	}
+}
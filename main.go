@@ -1,19 +1,39 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/bridgecrewio/yor/src/common"
 	"github.com/bridgecrewio/yor/src/common/clioptions"
+	"github.com/bridgecrewio/yor/src/common/gitservice"
+	"github.com/bridgecrewio/yor/src/common/grpcapi"
+	"github.com/bridgecrewio/yor/src/common/i18n"
 	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/bridgecrewio/yor/src/common/lsp"
+	"github.com/bridgecrewio/yor/src/common/manifest"
+	"github.com/bridgecrewio/yor/src/common/notify"
+	"github.com/bridgecrewio/yor/src/common/pullrequest"
 	"github.com/bridgecrewio/yor/src/common/reports"
+	"github.com/bridgecrewio/yor/src/common/reportstore"
+	"github.com/bridgecrewio/yor/src/common/runlock"
 	"github.com/bridgecrewio/yor/src/common/runner"
 	"github.com/bridgecrewio/yor/src/common/tagging"
+	"github.com/bridgecrewio/yor/src/common/tagging/gittag"
 	"github.com/bridgecrewio/yor/src/common/tagging/tags"
 	"github.com/bridgecrewio/yor/src/common/tagging/utils"
+	"github.com/bridgecrewio/yor/src/common/tagpolicy"
+	commonUtils "github.com/bridgecrewio/yor/src/common/utils"
+	tfPlan "github.com/bridgecrewio/yor/src/terraform/plan"
 	"github.com/urfave/cli/v2"
 )
 
@@ -31,10 +51,24 @@ func main() {
 			listTagsCommand(),
 			listTagGroupsCommand(),
 			tagCommand(),
+			lspCommand(),
+			grpcCommand(),
+			importTagPolicyCommand(),
+			trendCommand(),
+			benchmarkCommand(),
+			checkPlanCommand(),
+			doctorCommand(),
+			migrateTagsCommand(),
+			reportCommand(),
 		},
 	}
+	// the output of all the commands is in the language of the environment, unless yor tag sets another with --lang
+	_ = i18n.SetLocale("")
 	err := app.Run(os.Args)
-	if err != nil {
+	var codedErr *common.CodedError
+	if errors.As(err, &codedErr) {
+		logger.ErrorWithExitCode(codedErr.Code.ExitCode(), err.Error())
+	} else if err != nil {
 		logger.Error(err.Error())
 	}
 }
@@ -78,21 +112,59 @@ func listTagsCommand() *cli.Command {
 }
 
 func tagCommand() *cli.Command {
-	directoryArg := "directory"
-	tagArg := "tags"
-	skipTagsArg := "skip-tags"
-	customTaggingArg := "custom-tagging"
-	skipDirsArg := "skip-dirs"
-	outputArg := "output"
-	tagGroupArg := "tag-groups"
+	onlyKeysArg := "only-keys"
+	onlyGroupsArg := "only-groups"
 	outputJSONFileArg := "output-json-file"
-	externalConfPath := "config-file"
+	outputTagsFileArg := "output-tags-file"
+	outputGraphFileArg := "output-graph-file"
+	outputEnrichmentFileArg := "output-enrichment-file"
+	signReportArg := "sign-report"
+	reportSchemaArg := "report-schema"
 	skipResourceTypesArg := "skip-resource-types"
 	skipResourcesArg := "skip-resources"
-	parsersArgs := "parsers"
 	dryRunArgs := "dry-run"
+	backupArg := "backup"
 	tagLocalModules := "tag-local-modules"
-	tagPrefix := "tag-prefix"
+	remoteArg := "remote"
+	refArg := "ref"
+	remoteDepthArg := "remote-depth"
+	createPRArg := "create-pr"
+	prBranchArg := "pr-branch"
+	prBaseArg := "pr-base"
+	prTitleArg := "pr-title"
+	prProviderArg := "pr-provider"
+	runManifestArg := "run-manifest"
+	verifyLastRunArg := "verify-last-run"
+	timeoutArg := "timeout"
+	checkpointArg := "checkpoint"
+	lockFileArg := "lock-file"
+	waitArg := "wait"
+	progressArg := "progress"
+	pathStyleArg := "path-style"
+	pathPrefixStripArg := "path-prefix-strip"
+	pathPrefixMapArg := "path-prefix-map"
+	followSymlinksArg := "follow-symlinks"
+	skipSubmodulesArg := "skip-submodules"
+	maxFileSizeArg := "max-file-size"
+	maxResourcesPerFileArg := "max-resources-per-file"
+	anonymizeGitIdentitiesArg := "anonymize-git-identities"
+	anonymizationSaltArg := "anonymization-salt"
+	identitiesMappingFileArg := "identities-mapping-file"
+	freezeGitTagsArg := "freeze-git-tags"
+	provenanceFormatArg := "provenance-format"
+	provenanceDelimiterArg := "provenance-delimiter"
+	gitRemoteRuleArg := "git-remote-rule"
+	ignoreValueChangesArg := "ignore-value-changes"
+	requiredTagsArg := "required-tags"
+	baselineArg := "baseline"
+	preScanHookArg := "pre-scan-hook"
+	postFileWriteHookArg := "post-file-write-hook"
+	postRunHookArg := "post-run-hook"
+	notifyArg := "notify"
+	notifyReportURLArg := "notify-report-url"
+	reportStoreArg := "report-store"
+	langArg := "lang"
+	colorArg := "color"
 	return &cli.Command{
 		Name:                   "tag",
 		Usage:                  "apply tagging across your directory",
@@ -100,48 +172,86 @@ func tagCommand() *cli.Command {
 		UseShortOptionHandling: true,
 		Action: func(c *cli.Context) error {
 			options := clioptions.TagOptions{
-				Directory:         c.String(directoryArg),
-				Tag:               c.StringSlice(tagArg),
-				SkipTags:          c.StringSlice(skipTagsArg),
-				CustomTagging:     c.StringSlice(customTaggingArg),
-				SkipDirs:          c.StringSlice(skipDirsArg),
-				Output:            c.String(outputArg),
-				OutputJSONFile:    c.String(outputJSONFileArg),
-				TagGroups:         c.StringSlice(tagGroupArg),
-				ConfigFile:        c.String(externalConfPath),
-				SkipResourceTypes: c.StringSlice(skipResourceTypesArg),
-				SkipResources:     c.StringSlice(skipResourcesArg),
-				Parsers:           c.StringSlice(parsersArgs),
-				DryRun:            c.Bool(dryRunArgs),
-				TagLocalModules:   c.Bool(tagLocalModules),
-				TagPrefix:         c.String(tagPrefix),
+				Directory:              c.String(directoryArg),
+				Tag:                    c.StringSlice(tagArg),
+				SkipTags:               c.StringSlice(skipTagsArg),
+				CustomTagging:          c.StringSlice(customTaggingArg),
+				SkipDirs:               c.StringSlice(skipDirsArg),
+				Output:                 c.String(outputArg),
+				OutputJSONFile:         c.String(outputJSONFileArg),
+				OutputTagsFile:         c.String(outputTagsFileArg),
+				OutputGraphFile:        c.String(outputGraphFileArg),
+				OutputEnrichmentFile:   c.String(outputEnrichmentFileArg),
+				SignReport:             c.Bool(signReportArg),
+				ReportSchema:           c.String(reportSchemaArg),
+				TagGroups:              c.StringSlice(tagGroupArg),
+				OnlyKeys:               c.StringSlice(onlyKeysArg),
+				OnlyGroups:             c.StringSlice(onlyGroupsArg),
+				ConfigFile:             c.String(externalConfPath),
+				SkipResourceTypes:      c.StringSlice(skipResourceTypesArg),
+				SkipResources:          c.StringSlice(skipResourcesArg),
+				Parsers:                c.StringSlice(parsersArgs),
+				DryRun:                 c.Bool(dryRunArgs),
+				Backup:                 c.Bool(backupArg),
+				TagLocalModules:        c.Bool(tagLocalModules),
+				TagPrefix:              c.String(tagPrefix),
+				TagKeyNames:            c.StringSlice(tagKeyNamesArg),
+				Remote:                 c.String(remoteArg),
+				Ref:                    c.String(refArg),
+				RemoteDepth:            c.Int(remoteDepthArg),
+				CreatePR:               c.Bool(createPRArg),
+				PRBranch:               c.String(prBranchArg),
+				PRBase:                 c.String(prBaseArg),
+				PRTitle:                c.String(prTitleArg),
+				PRProvider:             c.String(prProviderArg),
+				RunManifest:            c.String(runManifestArg),
+				VerifyLastRun:          c.Bool(verifyLastRunArg),
+				Timeout:                c.Duration(timeoutArg),
+				Checkpoint:             c.String(checkpointArg),
+				LockFile:               c.String(lockFileArg),
+				Wait:                   c.Duration(waitArg),
+				Progress:               c.String(progressArg),
+				PathStyle:              c.String(pathStyleArg),
+				PathPrefixStrip:        c.StringSlice(pathPrefixStripArg),
+				PathPrefixMap:          c.StringSlice(pathPrefixMapArg),
+				FollowSymlinks:         c.Bool(followSymlinksArg),
+				SkipSubmodules:         c.Bool(skipSubmodulesArg),
+				MaxFileSizeMB:          c.Int(maxFileSizeArg),
+				MaxResourcesPerFile:    c.Int(maxResourcesPerFileArg),
+				AnonymizeGitIdentities: c.String(anonymizeGitIdentitiesArg),
+				AnonymizationSalt:      c.String(anonymizationSaltArg),
+				IdentitiesMappingFile:  c.String(identitiesMappingFileArg),
+				FreezeGitTags:          c.Bool(freezeGitTagsArg),
+				ProvenanceFormat:       c.String(provenanceFormatArg),
+				ProvenanceDelimiter:    c.String(provenanceDelimiterArg),
+				GitRemoteRules:         c.StringSlice(gitRemoteRuleArg),
+				IgnoreValueChanges:     c.StringSlice(ignoreValueChangesArg),
+				RequiredTagsFile:       c.String(requiredTagsArg),
+				BaselineFile:           c.String(baselineArg),
+				PreScanHook:            c.String(preScanHookArg),
+				PostFileWriteHook:      c.String(postFileWriteHookArg),
+				PostRunHook:            c.String(postRunHookArg),
+				Notify:                 append(c.StringSlice(notifyArg), notify.TargetsFromEnv()...),
+				NotifyReportURL:        c.String(notifyReportURLArg),
+				ReportStore:            c.String(reportStoreArg),
+				Lang:                   c.String(langArg),
+				Color:                  c.String(colorArg),
 			}
 
 			options.Validate()
+			reports.SetColorMode(options.Color)
+			if options.Lang != "" {
+				if err := i18n.SetLocale(options.Lang); err != nil {
+					return err
+				}
+			}
 
 			return tag(&options)
 		},
 		Flags: []cli.Flag{ // When adding flags, make sure they are supported in the GitHub action as well via entrypoint.sh
-			&cli.StringFlag{
-				Name:        directoryArg,
-				Aliases:     []string{"d"},
-				Usage:       "directory to tag",
-				Required:    true,
-				DefaultText: "path/to/iac/root",
-			},
-			&cli.StringSliceFlag{
-				Name:        tagArg,
-				Aliases:     []string{"t"},
-				Usage:       "run yor only with the specified tags",
-				DefaultText: "yor_trace,git_repository",
-			},
-			&cli.StringSliceFlag{
-				Name:        skipTagsArg,
-				Aliases:     []string{"s"},
-				Usage:       "run yor skipping the specified tags",
-				Value:       cli.NewStringSlice(),
-				DefaultText: "yor_trace",
-			},
+			directoryFlag("directory to tag, relative to the repository root when used with --remote", ""),
+			tagsFlag("run yor only with the specified tags"),
+			skipTagsFlag("run yor skipping the specified tags, glob patterns of whole keys (e.g. git_last_modified_*)"),
 			&cli.StringFlag{
 				Name:        outputArg,
 				Aliases:     []string{"o"},
@@ -151,35 +261,52 @@ func tagCommand() *cli.Command {
 			},
 			&cli.StringFlag{
 				Name:        outputJSONFileArg,
-				Usage:       "json file path for output",
+				Usage:       "json file path for output, or an object url (s3://bucket/key, gs://bucket/key or az://account/container/blob) to upload it to",
 				DefaultText: "result.json",
 			},
-			&cli.StringSliceFlag{
-				Name:        customTaggingArg,
-				Aliases:     []string{"c"},
-				Usage:       "paths to custom tag groups and tags plugins",
-				Value:       cli.NewStringSlice(),
-				DefaultText: "path/to/custom/yor/tagging",
+			&cli.StringFlag{
+				Name:        outputTagsFileArg,
+				Usage:       "json file path (or object url) for the final tags of each resource, as the input of Conftest or Sentinel policies",
+				DefaultText: "tags.json",
+			},
+			&cli.StringFlag{
+				Name:        outputGraphFileArg,
+				Usage:       "file path (or object url) for the graph of the resources, their files, modules and tags, in DOT if it ends with .dot or .gv and in JSON otherwise",
+				DefaultText: "graph.dot",
+			},
+			&cli.StringFlag{
+				Name:        outputEnrichmentFileArg,
+				Usage:       "json file path (or object url) for the location and the git owners of each resource by its yor_trace, with the fields of the resources of Checkov results, to link Checkov or Prisma Cloud findings to their owners",
+				DefaultText: "yor-enrichment.json",
+			},
+			&cli.StringFlag{
+				Name:        reportSchemaArg,
+				Usage:       "schema of the JSON report (v1, v2). The fields of a schema are never renamed or removed, v1 is the report of the first releases of yor and v2 the full report with its schemaVersion and the versions of the tag groups",
+				Value:       reports.ReportSchemaV2,
+				DefaultText: reports.ReportSchemaV2,
 			},
+			&cli.BoolFlag{
+				Name:        signReportArg,
+				Usage:       "sign the report of --output-json-file with the HMAC-SHA256 key of the YOR_REPORT_SIGNING_KEY environment variable, and write the signature next to it (<file>.sig), which yor report verify checks",
+				Value:       false,
+				DefaultText: "false",
+			},
+			customTaggingFlag(),
+			skipDirsFlag(),
+			tagGroupsFlag(),
 			&cli.StringSliceFlag{
-				Name:        skipDirsArg,
-				Aliases:     nil,
-				Usage:       "configuration paths to skip",
+				Name:        onlyKeysArg,
+				Usage:       "refresh only the tags of the keys, glob patterns of whole keys (e.g. git_last_modified_*). The other tags are left untouched, and the tag groups without any of the tags aren't run. The tag groups of --custom-tagging plugins which can't filter their tags are skipped with a warning",
 				Value:       cli.NewStringSlice(),
-				DefaultText: "path/to/skip,another/path/to/skip",
+				DefaultText: "git_commit,yor_trace",
 			},
 			&cli.StringSliceFlag{
-				Name:        tagGroupArg,
-				Aliases:     []string{"g"},
-				Usage:       "Narrow down results to the matching tag groups",
-				Value:       cli.NewStringSlice(utils.GetAllTagGroupsNames()...),
-				DefaultText: "git,code2cloud",
-			},
-			&cli.StringFlag{
-				Name:        externalConfPath,
-				Usage:       "external tag group configuration file path",
-				DefaultText: "/path/to/conf/file/ (.yml/.yaml extension)",
+				Name:        onlyGroupsArg,
+				Usage:       "refresh only the tags of these tag groups of --tag-groups, leaving the tags of the others untouched. The tag groups of --custom-tagging plugins have no name to select, so they are skipped with a warning",
+				Value:       cli.NewStringSlice(),
+				DefaultText: "git",
 			},
+			configFileFlag(),
 			&cli.StringSliceFlag{
 				Name:        skipResourceTypesArg,
 				Usage:       "skip resource types for tagging",
@@ -192,36 +319,633 @@ func tagCommand() *cli.Command {
 				Value:       cli.NewStringSlice(),
 				DefaultText: "aws_s3_bucket.test-bucket,EC2InstanceResource0",
 			},
-			&cli.StringSliceFlag{
-				Name:        parsersArgs,
-				Aliases:     []string{"i"},
-				Usage:       "IAC types to tag",
-				Value:       cli.NewStringSlice("Terraform", "CloudFormation", "Serverless"),
-				DefaultText: "Terraform,CloudFormation,Serverless",
-			},
+			parsersFlag("IAC types (frameworks) to tag, comma delimited. Files are matched to a framework by their content"),
 			&cli.BoolFlag{
 				Name:        dryRunArgs,
 				Usage:       "skip resource tagging",
 				Value:       false,
 				DefaultText: "false",
 			},
+			&cli.BoolFlag{
+				Name:        backupArg,
+				Usage:       "keep a copy of each file yor writes, as <file>.orig",
+				Value:       false,
+				DefaultText: "false",
+			},
 			&cli.BoolFlag{
 				Name:        tagLocalModules,
 				Usage:       "Always tag local modules",
 				Value:       false,
 				DefaultText: "false",
 			},
+			tagPrefixFlag(),
+			tagKeyNamesFlag(renameTagKeysUsage),
+			&cli.StringFlag{
+				Name:        remoteArg,
+				Usage:       "clone the given git repository to a temporary directory and tag it",
+				DefaultText: "https://github.com/org/repo",
+			},
+			&cli.StringFlag{
+				Name:        refArg,
+				Usage:       "branch or tag of the remote repository to tag. Without --remote, the branch, tag or commit of the repository of the directory to scan, whose files and blame are read from the git object database without checking it out, in a dry run",
+				DefaultText: "main",
+			},
+			&cli.IntFlag{
+				Name:        remoteDepthArg,
+				Usage:       "shallow clone the remote repository with the given number of commits, 0 clones the full history. The history isn't deepened for blame, so lines older than the fetched commits get the git tags of the oldest one",
+				Value:       0,
+				DefaultText: "0",
+			},
+			&cli.BoolFlag{
+				Name:        createPRArg,
+				Usage:       "push the tagging changes to a new branch and open a pull request with the report as its description",
+				Value:       false,
+				DefaultText: "false",
+			},
+			&cli.StringFlag{
+				Name:        prBranchArg,
+				Usage:       "name of the branch the changes are pushed to",
+				DefaultText: "yor/tags-<timestamp>",
+			},
+			&cli.StringFlag{
+				Name:        prBaseArg,
+				Usage:       "base branch of the pull request, defaults to the current branch",
+				DefaultText: "main",
+			},
+			&cli.StringFlag{
+				Name:        prTitleArg,
+				Usage:       "title of the pull request",
+				Value:       pullrequest.DefaultTitle,
+				DefaultText: pullrequest.DefaultTitle,
+			},
+			&cli.StringFlag{
+				Name:        prProviderArg,
+				Usage:       "pull request provider (github, gitlab), inferred from the origin remote by default",
+				DefaultText: "github",
+			},
+			&cli.StringFlag{
+				Name:        runManifestArg,
+				Usage:       "file to write the run manifest to, or to read it from with --verify-last-run",
+				DefaultText: "",
+			},
+			&cli.BoolFlag{
+				Name:        verifyLastRunArg,
+				Usage:       "verify the directory is still fully tagged according to the manifest given by --run-manifest, without tagging it",
+				Value:       false,
+				DefaultText: "false",
+			},
+			&cli.DurationFlag{
+				Name:        timeoutArg,
+				Usage:       "stop tagging further files after the given duration (e.g. 10m) and report the partial results",
+				DefaultText: "no timeout",
+			},
+			&cli.StringFlag{
+				Name:        checkpointArg,
+				Usage:       "file to save the directories tagged so far to, so an interrupted run resumes from them when it is run again with the same file. It is removed once the run is completed",
+				DefaultText: "",
+			},
+			&cli.StringFlag{
+				Name:        lockFileArg,
+				Usage:       "lock file which keeps concurrent yor runs from tagging the same directory",
+				DefaultText: "<directory>/" + runlock.DefaultLockFileName,
+			},
+			&cli.DurationFlag{
+				Name:        waitArg,
+				Usage:       "wait up to the given duration (e.g. 1m) for a concurrent run to finish, instead of failing",
+				DefaultText: "0",
+			},
+			&cli.StringFlag{
+				Name:        progressArg,
+				Usage:       "report the scan progress to stderr, as a status line (cli) or as a stream of JSON events, one per line (json)",
+				DefaultText: "disabled",
+			},
+			&cli.StringFlag{
+				Name:        pathStyleArg,
+				Usage:       "style of the file paths in the report and the yor_file tag: native (OS separator) or posix (forward slashes)",
+				Value:       reports.NativePathStyle,
+				DefaultText: reports.NativePathStyle,
+			},
+			&cli.StringSliceFlag{
+				Name:        pathPrefixStripArg,
+				Usage:       "prefixes to strip from the file paths of the report and all the output files, e.g. the mount point of the repository when yor runs in a container (/tf)",
+				DefaultText: "",
+			},
+			&cli.StringSliceFlag{
+				Name:        pathPrefixMapArg,
+				Usage:       "from=to pairs of prefixes to replace in the file paths of the report and all the output files, e.g. /tf=/home/runner/work/repo to link the files of a container run to the CI workspace",
+				DefaultText: "",
+			},
+			&cli.BoolFlag{
+				Name:        followSymlinksArg,
+				Usage:       "walk the directories symlinks point to, which are skipped by default (symlinks to files are always tagged through the file they point to)",
+				Value:       false,
+				DefaultText: "false",
+			},
+			&cli.BoolFlag{
+				Name:        skipSubmodulesArg,
+				Usage:       "skip git submodules, which are tagged with git data from their own repository by default",
+				Value:       false,
+				DefaultText: "false",
+			},
+			&cli.IntFlag{
+				Name:        maxFileSizeArg,
+				Usage:       "skip and report IaC files larger than the given size in MB, 0 for no limit",
+				Value:       0,
+				DefaultText: "0",
+			},
+			&cli.IntFlag{
+				Name:        maxResourcesPerFileArg,
+				Usage:       "skip and report IaC files with more resources than the given number, 0 for no limit",
+				Value:       0,
+				DefaultText: "0",
+			},
+			&cli.StringFlag{
+				Name:        anonymizeGitIdentitiesArg,
+				Usage:       "replace the emails and user names in the git tags: hash (stable keyed hashes) or redact",
+				DefaultText: "",
+			},
+			&cli.StringFlag{
+				Name:        anonymizationSaltArg,
+				Usage:       "salt of the hashed git identities, read from the YOR_ANONYMIZATION_SALT environment variable by default",
+				DefaultText: "",
+			},
+			&cli.StringFlag{
+				Name:        identitiesMappingFileArg,
+				Usage:       "json file to write the git identities replaced by each anonymized value to",
+				DefaultText: "",
+			},
+			&cli.BoolFlag{
+				Name:        freezeGitTagsArg,
+				Usage:       "keep the git tags of resources whose lines weren't changed since the commit of their git_commit tag, instead of refreshing them",
+				Value:       false,
+				DefaultText: "false",
+			},
+			&cli.StringFlag{
+				Name:        provenanceFormatArg,
+				Usage:       "format of the yor_provenance tag of the provenance tag group: key=value pairs (kv) or a JSON object (json)",
+				Value:       gittag.ProvenanceKeyValueFormat,
+				DefaultText: gittag.ProvenanceKeyValueFormat,
+			},
+			&cli.StringFlag{
+				Name:        provenanceDelimiterArg,
+				Usage:       "delimiter of the key=value pairs of the yor_provenance tag",
+				Value:       gittag.DefaultProvenanceDelimiter,
+				DefaultText: gittag.DefaultProvenanceDelimiter,
+			},
+			&cli.StringSliceFlag{
+				Name:        gitRemoteRuleArg,
+				Usage:       "regular expression with the named groups org and repo, which parses git_org and git_repo of the git_remote tag group from the host and path of the origin remote, e.g. git\\.example\\.com/scm/(?P<org>[^/]+)/(?P<repo>[^/]+). Can be repeated, the first matching rule is used",
+				DefaultText: "",
+			},
+			&cli.StringSliceFlag{
+				Name:        ignoreValueChangesArg,
+				Usage:       "keep the existing values of tags whose new values differ from them only in case and/or whitespace, so files aren't rewritten for them. Values: case, whitespace",
+				DefaultText: "",
+			},
+			&cli.StringFlag{
+				Name:        requiredTagsArg,
+				Usage:       "required tags rules file (e.g. written by import-tag-policy). Resources which miss a required tag after tagging are reported, and fail the run",
+				DefaultText: "",
+			},
+			&cli.StringFlag{
+				Name:        baselineArg,
+				Usage:       "JSON report of a prior run (--output-json-file). The tags and violations it already has are left out of the report, so only new findings are reported",
+				DefaultText: "",
+			},
+			&cli.StringFlag{
+				Name:        preScanHookArg,
+				Usage:       "shell command to run before the scan, with the listed files as JSON on its stdin. The run is aborted if it fails",
+				DefaultText: "",
+			},
+			&cli.StringFlag{
+				Name:        postFileWriteHookArg,
+				Usage:       "shell command to run after each file is tagged (e.g. terraform fmt), with the file and its resources as JSON on its stdin",
+				DefaultText: "",
+			},
+			&cli.StringFlag{
+				Name:        postRunHookArg,
+				Usage:       "shell command to run once the run finished, with the report as JSON on its stdin",
+				DefaultText: "",
+			},
+			&cli.StringSliceFlag{
+				Name:        notifyArg,
+				Usage:       "post a summary of the run to incoming webhooks of Slack or Microsoft Teams, given as slack://<webhook host and path> or teams://<webhook host and path>. Targets may also be given comma separated in the YOR_NOTIFY environment variable, which keeps the webhook URLs out of the process list",
+				DefaultText: "",
+			},
+			&cli.StringFlag{
+				Name:        notifyReportURLArg,
+				Usage:       "link to the report of the run (e.g. the CI artifact of --output-json-file) to add to the notifications",
+				DefaultText: "",
+			},
+			&cli.StringFlag{
+				Name:        reportStoreArg,
+				Usage:       "directory to keep the report of each run in, which yor trend charts",
+				DefaultText: "",
+			},
+			&cli.StringFlag{
+				Name:        langArg,
+				Usage:       "language of the summary and the tables of the cli and markdown outputs (en, de, es, fr), the language of the environment (YOR_LANG, LC_ALL, LC_MESSAGES or LANG) by default",
+				DefaultText: "",
+			},
+			&cli.StringFlag{
+				Name:        colorArg,
+				Usage:       "color the banner, the summary and the tables of the cli output (auto, always, never). auto colors them when stdout is a terminal, unless NO_COLOR is set, or when CLICOLOR_FORCE is set",
+				Value:       reports.ColorAuto,
+				DefaultText: reports.ColorAuto,
+			},
+		},
+	}
+}
+
+func importTagPolicyCommand() *cli.Command {
+	policyFileArg := "policy-file"
+	outputFileArg := "output-file"
+	return &cli.Command{
+		Name:  "import-tag-policy",
+		Usage: "translate an AWS Organizations tag policy to the required tags rules of yor tag --required-tags",
+		Action: func(c *cli.Context) error {
+			options := clioptions.ImportTagPolicyOptions{
+				PolicyFile: c.String(policyFileArg),
+				OutputFile: c.String(outputFileArg),
+			}
+
+			options.Validate()
+			return importTagPolicy(&options)
+		},
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        policyFileArg,
+				Aliases:     []string{"p"},
+				Usage:       "tag policy JSON document, or the output of aws organizations describe-effective-policy / describe-policy",
+				DefaultText: "path/to/tag-policy.json",
+			},
+			&cli.StringFlag{
+				Name:        outputFileArg,
+				Aliases:     []string{"o"},
+				Usage:       "file to write the required tags rules to",
+				Value:       "required-tags.yml",
+				DefaultText: "required-tags.yml",
+			},
+		},
+		HideHelpCommand:        true,
+		UseShortOptionHandling: true,
+	}
+}
+
+func trendCommand() *cli.Command {
+	reportStoreArg := "report-store"
+	return &cli.Command{
+		Name:  "trend",
+		Usage: "chart the tag coverage and the new and updated resources of the runs kept in a report store",
+		Action: func(c *cli.Context) error {
+			options := clioptions.TrendOptions{
+				ReportStore: c.String(reportStoreArg),
+				Directory:   c.String(directoryArg),
+				Output:      c.String(outputArg),
+			}
+
+			options.Validate()
+			return printTrend(&options)
+		},
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        reportStoreArg,
+				Aliases:     []string{"s"},
+				Usage:       "directory of the reports kept by yor tag --report-store",
+				DefaultText: "path/to/report-store",
+			},
 			&cli.StringFlag{
-				Name:        tagPrefix,
-				Usage:       "Add prefix to all the tags",
+				Name:        directoryArg,
+				Aliases:     []string{"d"},
+				Usage:       "chart only the runs which tagged the directory",
+				DefaultText: "all directories",
+			},
+			outputFormatFlag(),
+		},
+		HideHelpCommand:        true,
+		UseShortOptionHandling: true,
+	}
+}
+
+func benchmarkCommand() *cli.Command {
+	return &cli.Command{
+		Name:                   "benchmark",
+		Usage:                  "time the phases of tagging the directory (walk, parse, tags, git, write, report) by framework, without changing its files",
+		HideHelpCommand:        true,
+		UseShortOptionHandling: true,
+		Action: func(c *cli.Context) error {
+			options := clioptions.TagOptions{
+				Directory: c.String(directoryArg),
+				SkipDirs:  c.StringSlice(skipDirsArg),
+				TagGroups: c.StringSlice(tagGroupArg),
+				Parsers:   c.StringSlice(parsersArgs),
+				Output:    c.String(outputArg),
+				DryRun:    true,
+			}
+
+			options.Validate()
+			return benchmark(&options)
+		},
+		Flags: []cli.Flag{
+			directoryFlag("directory to benchmark", ""),
+			skipDirsFlag(),
+			tagGroupsFlag(),
+			parsersFlag("IAC types (frameworks) to benchmark, comma delimited"),
+			outputFormatFlag(),
+		},
+	}
+}
+
+func checkPlanCommand() *cli.Command {
+	planArg := "plan"
+	requiredTagsArg := "required-tags"
+	return &cli.Command{
+		Name:                   "check-plan",
+		Usage:                  "check the tags which the resources created by a terraform plan would have at apply time, i.e. with default tags and computed values, against yor_trace and the required tags rules",
+		HideHelpCommand:        true,
+		UseShortOptionHandling: true,
+		Action: func(c *cli.Context) error {
+			options := clioptions.CheckPlanOptions{
+				PlanFile:         c.String(planArg),
+				RequiredTagsFile: c.String(requiredTagsArg),
+				TagKeyNames:      c.StringSlice(tagKeyNamesArg),
+				Output:           c.String(outputArg),
+			}
+
+			options.Validate()
+			return checkPlan(&options)
+		},
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        planArg,
+				Aliases:     []string{"p"},
+				Usage:       "output of terraform show -json of the plan file, or - to read it from stdin",
+				DefaultText: "path/to/plan.json",
+			},
+			&cli.StringFlag{
+				Name:        requiredTagsArg,
+				Usage:       "YAML file of required tags rules, checked in addition to yor_trace. Only the violations of rules with the error severity fail the check",
 				DefaultText: "",
 			},
+			tagKeyNamesFlag(renamedTagKeysUsage),
+			outputFormatFlag(),
+		},
+	}
+}
+
+func doctorCommand() *cli.Command {
+	fixArg := "fix"
+	return &cli.Command{
+		Name:                   "doctor",
+		Usage:                  "check the tags of the directory for duplicate yor_trace values, e.g. of copy-pasted blocks, and give the duplicates new traces with --fix",
+		HideHelpCommand:        true,
+		UseShortOptionHandling: true,
+		Action: func(c *cli.Context) error {
+			options := clioptions.TagOptions{
+				Directory:   c.String(directoryArg),
+				SkipDirs:    c.StringSlice(skipDirsArg),
+				Parsers:     c.StringSlice(parsersArgs),
+				TagKeyNames: c.StringSlice(tagKeyNamesArg),
+				Output:      c.String(outputArg),
+			}
+
+			options.Validate()
+			return doctor(&options, c.Bool(fixArg))
+		},
+		Flags: []cli.Flag{
+			directoryFlag("directory to check", ""),
+			skipDirsFlag(),
+			parsersFlag("IAC types (frameworks) to check, comma delimited"),
+			tagKeyNamesFlag(renamedTagKeysUsage),
+			&cli.BoolFlag{
+				Name:        fixArg,
+				Usage:       "give new traces to the resources which duplicate the yor_trace of another resource, which keeps it (the first one by file and line)",
+				Value:       false,
+				DefaultText: "false",
+			},
+			outputFormatFlag(),
+		},
+	}
+}
+
+func migrateTagsCommand() *cli.Command {
+	fromArg := "from"
+	toArg := "to"
+	mappingFileArg := "mapping-file"
+	dryRunArgs := "dry-run"
+	return &cli.Command{
+		Name:                   "migrate-tags",
+		Usage:                  "rename tag keys in the tags of the resources of all the supported IaC files, when the tag standards change, and report every rename",
+		HideHelpCommand:        true,
+		UseShortOptionHandling: true,
+		Action: func(c *cli.Context) error {
+			options := clioptions.MigrateTagsOptions{
+				Directory:   c.String(directoryArg),
+				SkipDirs:    c.StringSlice(skipDirsArg),
+				Parsers:     c.StringSlice(parsersArgs),
+				From:        c.String(fromArg),
+				To:          c.String(toArg),
+				MappingFile: c.String(mappingFileArg),
+				DryRun:      c.Bool(dryRunArgs),
+				Output:      c.String(outputArg),
+			}
+
+			options.Validate()
+			return migrateTags(&options)
+		},
+		Flags: []cli.Flag{
+			directoryFlag("directory to migrate", ""),
+			skipDirsFlag(),
+			parsersFlag("IAC types (frameworks) to migrate, comma delimited"),
+			&cli.StringFlag{
+				Name:        fromArg,
+				Usage:       "tag key to rename",
+				DefaultText: "old_key",
+			},
+			&cli.StringFlag{
+				Name:        toArg,
+				Usage:       "new name of the tag key",
+				DefaultText: "new_key",
+			},
+			&cli.StringFlag{
+				Name:        mappingFileArg,
+				Usage:       "YAML or JSON file of the tag keys to rename, as old_key: new_key pairs",
+				DefaultText: "path/to/mapping.yml",
+			},
+			&cli.BoolFlag{
+				Name:        dryRunArgs,
+				Usage:       "report the renames without changing the files",
+				Value:       false,
+				DefaultText: "false",
+			},
+			outputFormatFlag(),
+		},
+	}
+}
+
+func reportCommand() *cli.Command {
+	reportFileArg := "report"
+	signatureFileArg := "signature"
+	return &cli.Command{
+		Name:            "report",
+		Usage:           "work with the JSON reports of yor tag",
+		HideHelpCommand: true,
+		Subcommands: []*cli.Command{
+			{
+				Name:  "verify",
+				Usage: "verify the JSON report wasn't altered since yor tag --sign-report signed it, with the key of the YOR_REPORT_SIGNING_KEY environment variable",
+				Action: func(c *cli.Context) error {
+					options := clioptions.VerifyReportOptions{
+						ReportFile:    c.String(reportFileArg),
+						SignatureFile: c.String(signatureFileArg),
+					}
+
+					options.Validate()
+					return verifyReport(&options)
+				},
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:        reportFileArg,
+						Aliases:     []string{"r"},
+						Usage:       "JSON report written by yor tag --output-json-file",
+						DefaultText: "result.json",
+					},
+					&cli.StringFlag{
+						Name:        signatureFileArg,
+						Aliases:     []string{"s"},
+						Usage:       "signature of the report, the report file with the .sig suffix by default",
+						DefaultText: "result.json.sig",
+					},
+				},
+				HideHelpCommand:        true,
+				UseShortOptionHandling: true,
+			},
+		},
+	}
+}
+
+func lspCommand() *cli.Command {
+	return &cli.Command{
+		Name:                   "lsp",
+		Usage:                  "serve the tags yor would apply to editors, over JSON-RPC on stdin/stdout",
+		HideHelpCommand:        true,
+		UseShortOptionHandling: true,
+		Action: func(c *cli.Context) error {
+			options := clioptions.TagOptions{
+				Directory:     c.String(directoryArg),
+				Tag:           c.StringSlice(tagArg),
+				SkipTags:      c.StringSlice(skipTagsArg),
+				CustomTagging: c.StringSlice(customTaggingArg),
+				TagGroups:     c.StringSlice(tagGroupArg),
+				ConfigFile:    c.String(externalConfPath),
+				Parsers:       c.StringSlice(parsersArgs),
+				TagPrefix:     c.String(tagPrefix),
+				TagKeyNames:   c.StringSlice(tagKeyNamesArg),
+				DryRun:        true,
+			}
+
+			options.Validate()
+
+			return serveLsp(&options)
+		},
+		Flags: []cli.Flag{
+			directoryFlag("root directory of the edited IaC files", "."),
+			tagsFlag("compute only the specified tags"),
+			skipTagsFlag("skip the specified tags"),
+			customTaggingFlag(),
+			tagGroupsFlag(),
+			configFileFlag(),
+			parsersFlag("IAC types (frameworks) to tag, comma delimited. Files are matched to a framework by their content"),
+			tagPrefixFlag(),
+			tagKeyNamesFlag(renameTagKeysUsage),
+		},
+	}
+}
+
+func grpcCommand() *cli.Command {
+	listenArg := "listen"
+	tlsCertArg := "tls-cert"
+	tlsKeyArg := "tls-key"
+	tlsClientCAArg := "tls-client-ca"
+	tenantsFileArg := "tenants-file"
+	return &cli.Command{
+		Name:                   "grpc",
+		Usage:                  "serve the TagDirectory and ValidateDirectory methods of yor.proto over gRPC, for the directories under the given directory",
+		HideHelpCommand:        true,
+		UseShortOptionHandling: true,
+		Action: func(c *cli.Context) error {
+			options := clioptions.TagOptions{
+				Directory:     c.String(directoryArg),
+				Tag:           c.StringSlice(tagArg),
+				SkipTags:      c.StringSlice(skipTagsArg),
+				CustomTagging: c.StringSlice(customTaggingArg),
+				TagGroups:     c.StringSlice(tagGroupArg),
+				ConfigFile:    c.String(externalConfPath),
+				Parsers:       c.StringSlice(parsersArgs),
+				TagPrefix:     c.String(tagPrefix),
+				TagKeyNames:   c.StringSlice(tagKeyNamesArg),
+			}
+
+			options.Validate()
+
+			serverOptions := grpcapi.ServerOptions{
+				Token:        os.Getenv(grpcapi.TokenEnvKey),
+				TLSCertFile:  c.String(tlsCertArg),
+				TLSKeyFile:   c.String(tlsKeyArg),
+				ClientCAFile: c.String(tlsClientCAArg),
+			}
+			if tenantsFile := c.String(tenantsFileArg); tenantsFile != "" {
+				tenants, err := grpcapi.LoadTenants(tenantsFile)
+				if err != nil {
+					return err
+				}
+				serverOptions.Tenants = tenants
+			}
+			return serveGrpc(c.String(listenArg), &options, serverOptions)
+		},
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        listenArg,
+				Aliases:     []string{"l"},
+				Usage:       "address to listen on. Addresses other than loopback ones require TLS, and the clients to authenticate with the token of the YOR_GRPC_TOKEN environment variable or with a certificate of --tls-client-ca",
+				Value:       "localhost:50051",
+				DefaultText: "localhost:50051",
+			},
+			&cli.StringFlag{
+				Name:        tlsCertArg,
+				Usage:       "PEM certificate to serve over TLS with",
+				DefaultText: "path/to/server.crt",
+			},
+			&cli.StringFlag{
+				Name:        tlsKeyArg,
+				Usage:       "PEM private key of --tls-cert",
+				DefaultText: "path/to/server.key",
+			},
+			&cli.StringFlag{
+				Name:        tlsClientCAArg,
+				Usage:       "PEM CA certificates which sign the certificates the clients must present (mutual TLS)",
+				DefaultText: "path/to/clients-ca.crt",
+			},
+			&cli.StringFlag{
+				Name:        tenantsFileArg,
+				Usage:       "YAML file of the tenants of a shared server, each authenticated by its own token and confined to its directory under the root directory, with its own defaults",
+				DefaultText: "path/to/tenants.yaml",
+			},
+			directoryFlag("root directory of the directories to tag, which requests can't get out of", "."),
+			tagsFlag("compute only the specified tags"),
+			skipTagsFlag("skip the specified tags"),
+			customTaggingFlag(),
+			tagGroupsFlag(),
+			configFileFlag(),
+			parsersFlag("IAC types (frameworks) to tag, comma delimited. Files are matched to a framework by their content"),
+			tagPrefixFlag(),
+			tagKeyNamesFlag(renameTagKeysUsage),
 		},
 	}
 }
 
 func listTagGroups() error {
-	for _, tagGroup := range utils.GetAllTagGroupsNames() {
+	for _, tagGroup := range utils.GetSupportedTagGroupsNames() {
 		fmt.Println(tagGroup)
 	}
 	return nil
@@ -238,38 +962,381 @@ func listTags(options *clioptions.ListTagsOptions) error {
 		tagGroup.InitTagGroup("", nil, nil)
 		tagsByGroup[group] = tagGroup.GetTags()
 	}
-	reports.ReportServiceInst.PrintTagGroupTags(tagsByGroup)
+	reports.NewReportService(reports.NewTagChangeAccumulator()).PrintTagGroupTags(tagsByGroup)
 	return nil
 }
 
 func tag(options *clioptions.TagOptions) error {
+	// a terminated run still writes the files which were already tagged and reports them
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+	if options.Remote == "" && options.Ref != "" {
+		return tagRef(ctx, options)
+	}
+	if options.Remote == "" {
+		return tagDirectory(ctx, options)
+	}
+	cloneDir, err := gitservice.CloneRepository(ctx, options.Remote, options.Ref, options.RemoteDepth)
+	if err != nil {
+		return err
+	}
+	// the clone is removed explicitly rather than deferred, so it is removed before the error is logged and yor exits
+	options.Directory, err = gitservice.GetClonedPath(cloneDir, options.Directory)
+	if err == nil {
+		err = tagDirectory(ctx, options)
+	}
+	if removeErr := os.RemoveAll(cloneDir); removeErr != nil {
+		logger.Warning(fmt.Sprintf("Failed to remove the clone of %s at %s: %s", options.Remote, cloneDir, removeErr))
+	}
+	return err
+}
+
+// tagRef scans the files of the directory at the ref, which are read from the git object database, so the working tree
+// may be checked out at any other ref while it is scanned
+func tagRef(ctx context.Context, options *clioptions.TagOptions) error {
+	refFileSystem, err := gitservice.NewRefFileSystem(options.Directory, options.Ref)
+	if err != nil {
+		return err
+	}
+	logger.Info(fmt.Sprintf("Scanning %v at commit %v of %v", options.Directory, refFileSystem.GetCommitHash(), options.Ref))
+	fileSystem := commonUtils.GetFileSystem()
+	commonUtils.SetFileSystem(refFileSystem)
+	defer commonUtils.SetFileSystem(fileSystem)
+	return tagDirectory(ctx, options)
+}
+
+func tagDirectory(ctx context.Context, options *clioptions.TagOptions) error {
+	if options.VerifyLastRun {
+		return verifyLastRun(options)
+	}
+	// dry runs don't write the files, so they aren't locked out by other runs
+	if !options.DryRun {
+		lockFile := options.LockFile
+		if lockFile == "" {
+			lockFile = filepath.Join(options.Directory, runlock.DefaultLockFileName)
+		}
+		lock, err := runlock.Acquire(ctx, lockFile, options.Wait)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := lock.Release(); err != nil {
+				logger.Warning(err.Error())
+			}
+		}()
+	}
 	yorRunner := new(runner.Runner)
 	logger.Info(fmt.Sprintf("Setting up to tag the directory %v\n", options.Directory))
-	err := yorRunner.Init(options)
+	err := yorRunner.InitWithContext(ctx, options)
 	if err != nil {
-		logger.Error(err.Error())
+		return err
 	}
 	reportService, err := yorRunner.TagDirectory()
+	if reportService == nil {
+		return err
+	}
+	if printErr := printReport(reportService, options); printErr != nil {
+		return printErr
+	}
+	// the mapping is written for partial runs as well, since the files which were tagged hold the anonymized values
+	if options.IdentitiesMappingFile != "" {
+		if mappingErr := yorRunner.WriteIdentitiesMapping(options.IdentitiesMappingFile); mappingErr != nil {
+			return mappingErr
+		}
+	}
 	if err != nil {
-		logger.Error(err.Error())
+		return err
+	}
+
+	if options.RunManifest != "" {
+		if err = manifest.Write(options.RunManifest, options, reportService.GetReport()); err != nil {
+			return err
+		}
 	}
-	printReport(reportService, options)
 
+	if options.CreatePR {
+		prURL, err := pullrequest.Create(options.Directory, pullrequest.Options{
+			Branch:   options.PRBranch,
+			Base:     options.PRBase,
+			Title:    options.PRTitle,
+			Provider: options.PRProvider,
+		}, reportService.GetReport().AsMarkdown(), reportService.GetChangedFiles())
+		if err != nil {
+			return err
+		}
+		if prURL != "" {
+			fmt.Printf("Opened pull request %s\n", prURL)
+		}
+	}
+
+	if options.ReportStore != "" {
+		if _, err = reportstore.Save(options.ReportStore, options.Directory, options.DryRun, reportService.GetReport()); err != nil {
+			return err
+		}
+	}
+
+	yorRunner.RunPostRunHook(reportService.GetReport())
+	notifyRun(reportService.GetReport(), options)
+
+	if code, ok := reportService.GetReport().GetErrorCode(); ok {
+		return &common.CodedError{Code: code, Err: fmt.Errorf("the run failed with %d errors, see the errors of the report", len(reportService.GetReport().Errors))}
+	}
+	// the violations fail the run once the tags are written, so they can be fixed on top of them. The violations of
+	// warning and info rules are only reported
+	if violations := reportService.GetReport().CountRequiredTagViolations(tagpolicy.SeverityError); violations > 0 {
+		return fmt.Errorf("found %d required tag violations with the error severity, see the report for the resources which violate them", violations)
+	}
+	return nil
+}
+
+// notifyRun posts the summary of the run to the notification targets. Failed notifications are only warned about,
+// since the files were already tagged.
+func notifyRun(report *reports.Report, options *clioptions.TagOptions) {
+	if len(options.Notify) == 0 {
+		return
+	}
+	summary := notify.NewSummary(report, options.Directory, options.DryRun, options.NotifyReportURL)
+	for _, notifyURL := range options.Notify {
+		target, err := notify.ParseTarget(notifyURL)
+		if err == nil {
+			err = target.Send(summary)
+		}
+		if err != nil {
+			logger.Warning(err.Error())
+		}
+	}
+}
+
+func verifyLastRun(options *clioptions.TagOptions) error {
+	runManifest, err := manifest.Verify(options.RunManifest, options.Directory, options.SkipDirs)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Directory %s is fully tagged, as of the run at %v\n", options.Directory, runManifest.Timestamp)
+	return nil
+}
+
+func printTrend(options *clioptions.TrendOptions) error {
+	storedReports, err := reportstore.Load(options.ReportStore, options.Directory)
+	if err != nil {
+		return err
+	}
+	trend := reportstore.GetTrend(storedReports)
+	if strings.ToLower(options.Output) == "json" {
+		trendBytes, err := json.MarshalIndent(trend, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(trendBytes))
+		return nil
+	}
+	reportstore.PrintTrend(os.Stdout, trend)
+	return nil
+}
+
+// benchmark times a dry run of the directory. The number of workers is set by YOR_WORKER_NUM, like in yor tag.
+func benchmark(options *clioptions.TagOptions) error {
+	if strings.ToLower(options.Output) == "markdown" {
+		return errors.New("the benchmark can be printed as cli or json")
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	yorRunner := new(runner.Runner)
+	if err := yorRunner.InitWithContext(ctx, options); err != nil {
+		return err
+	}
+	result, err := yorRunner.Benchmark()
+	if err != nil {
+		return err
+	}
+	if strings.ToLower(options.Output) == "json" {
+		benchmarkBytes, err := json.MarshalIndent(result, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(benchmarkBytes))
+		return nil
+	}
+	result.Print(os.Stdout)
+	return nil
+}
+
+func checkPlan(options *clioptions.CheckPlanOptions) error {
+	tagKeyNames, err := tags.ParseKeyNames(options.TagKeyNames)
+	if err != nil {
+		return err
+	}
+	plan, err := tfPlan.Load(options.PlanFile)
+	if err != nil {
+		return err
+	}
+	var requiredTags *tagpolicy.RequiredTags
+	if options.RequiredTagsFile != "" {
+		if requiredTags, err = tagpolicy.LoadRequiredTags(options.RequiredTagsFile); err != nil {
+			return err
+		}
+	}
+	result := tfPlan.Check(plan, requiredTags, tagKeyNames)
+	if strings.ToLower(options.Output) == "json" {
+		resultBytes, err := json.MarshalIndent(result, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(resultBytes))
+	} else {
+		result.Print(os.Stdout)
+	}
+	if violations := result.CountViolations(tagpolicy.SeverityError); violations > 0 {
+		return fmt.Errorf("found %d required tag violations with the error severity in the resources to create", violations)
+	}
+	return nil
+}
+
+// doctor checks the tags of the directory, and fails if it found problems which weren't repaired
+func doctor(options *clioptions.TagOptions, fix bool) error {
+	if strings.ToLower(options.Output) == "markdown" {
+		return errors.New("the diagnosis can be printed as cli or json")
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	yorRunner := new(runner.Runner)
+	if err := yorRunner.InitWithContext(ctx, options); err != nil {
+		return err
+	}
+	diagnosis, err := yorRunner.Doctor(fix)
+	if err != nil {
+		return err
+	}
+	if strings.ToLower(options.Output) == "json" {
+		diagnosisBytes, err := json.MarshalIndent(diagnosis, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(diagnosisBytes))
+	} else {
+		diagnosis.Print(os.Stdout)
+	}
+	if !fix && len(diagnosis.DuplicateTraces) > 0 {
+		return fmt.Errorf("found %d yor_trace values on several resources, run yor doctor --fix to give the duplicates new traces", len(diagnosis.DuplicateTraces))
+	}
 	return nil
 }
 
-func printReport(reportService *reports.ReportService, options *clioptions.TagOptions) {
+func migrateTags(options *clioptions.MigrateTagsOptions) error {
+	mapping := map[string]string{}
+	if options.MappingFile != "" {
+		var err error
+		if mapping, err = runner.LoadTagKeyMapping(options.MappingFile); err != nil {
+			return err
+		}
+	}
+	if options.From != "" {
+		mapping[options.From] = options.To
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	yorRunner := new(runner.Runner)
+	err := yorRunner.InitWithContext(ctx, &clioptions.TagOptions{Directory: options.Directory, SkipDirs: options.SkipDirs, Parsers: options.Parsers})
+	if err != nil {
+		return err
+	}
+	migration, err := yorRunner.MigrateTags(mapping, options.DryRun)
+	if err != nil {
+		return err
+	}
+	if strings.ToLower(options.Output) == "json" {
+		migrationBytes, err := json.MarshalIndent(migration, "", "    ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(migrationBytes))
+		return nil
+	}
+	migration.Print(os.Stdout)
+	return nil
+}
+
+func verifyReport(options *clioptions.VerifyReportOptions) error {
+	// #nosec G304 - file is from user
+	report, err := os.ReadFile(options.ReportFile)
+	if err != nil {
+		return fmt.Errorf("failed to read the report %s: %s", options.ReportFile, err)
+	}
+	signature, err := reports.LoadReportSignature(options.SignatureFile)
+	if err != nil {
+		return err
+	}
+	if err = reports.VerifyReport(report, signature, []byte(os.Getenv(reports.SigningKeyEnvKey))); err != nil {
+		return fmt.Errorf("the verification of the report %s failed: %w", options.ReportFile, err)
+	}
+	fmt.Printf("The report %s is verified\n", options.ReportFile)
+	return nil
+}
+
+func importTagPolicy(options *clioptions.ImportTagPolicyOptions) error {
+	requiredTags, err := tagpolicy.ImportAWSTagPolicy(options.PolicyFile)
+	if err != nil {
+		return err
+	}
+	if err = requiredTags.Write(options.OutputFile); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %d required tags to %s\n", len(requiredTags.Tags), options.OutputFile)
+	return nil
+}
+
+func serveLsp(options *clioptions.TagOptions) error {
+	yorRunner := new(runner.Runner)
+	err := yorRunner.Init(options)
+	if err != nil {
+		return err
+	}
+	return lsp.NewServer(yorRunner, os.Stdin, os.Stdout).Serve()
+}
+
+func serveGrpc(listen string, options *clioptions.TagOptions, serverOptions grpcapi.ServerOptions) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	lis, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+	return grpcapi.NewServer(options, serverOptions).Serve(ctx, lis)
+}
+
+func printReport(reportService *reports.ReportService, options *clioptions.TagOptions) error {
 	reportService.CreateReport()
 
 	if options.OutputJSONFile != "" {
-		reportService.PrintJSONToFile(options.OutputJSONFile)
+		if options.SignReport {
+			if err := reportService.PrintSignedJSONToFile(options.OutputJSONFile, []byte(os.Getenv(reports.SigningKeyEnvKey))); err != nil {
+				return err
+			}
+		} else {
+			reportService.PrintJSONToFile(options.OutputJSONFile)
+		}
+	}
+	if options.OutputTagsFile != "" {
+		reportService.PrintTagsExportToFile(options.OutputTagsFile)
+	}
+	if options.OutputGraphFile != "" {
+		reportService.PrintGraphToFile(options.OutputGraphFile)
+	}
+	if options.OutputEnrichmentFile != "" {
+		reportService.PrintEnrichmentToFile(options.OutputEnrichmentFile)
 	}
 	switch strings.ToLower(options.Output) {
 	case "cli":
 		reportService.PrintToStdout()
 	case "json":
 		reportService.PrintJSONToStdout()
-	default:
-		return
+	case "markdown":
+		reportService.PrintMarkdownToStdout()
 	}
+	return nil
 }
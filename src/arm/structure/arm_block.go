@@ -0,0 +1,33 @@
+package structure
+
+import (
+	"github.com/bridgecrewio/yor/src/common/structure"
+)
+
+type ArmBlock struct {
+	structure.Block
+	// path is the location of the resource in the template, i.e. resources[0].resources[1]
+	path string
+}
+
+func (b *ArmBlock) UpdateTags() {
+	if !b.IsTaggable {
+		return
+	}
+
+	mergedTags := make(map[string]string)
+	for _, t := range b.MergeTags() {
+		mergedTags[t.GetKey()] = t.GetValue()
+	}
+	if rawBlock, ok := b.RawBlock.(map[string]interface{}); ok {
+		rawBlock[TagsAttributeName] = mergedTags
+	}
+}
+
+func (b *ArmBlock) GetTagsLines() structure.Lines {
+	return b.TagLines
+}
+
+func (b *ArmBlock) GetSeparator() string {
+	return "/n"
+}
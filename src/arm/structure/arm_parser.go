@@ -0,0 +1,252 @@
+package structure
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bridgecrewio/yor/src/common"
+	"github.com/bridgecrewio/yor/src/common/json"
+	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/bridgecrewio/yor/src/common/structure"
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+	"github.com/bridgecrewio/yor/src/common/utils"
+)
+
+const TagsAttributeName = "tags"
+const ResourcesStartToken = "resources"
+
+// nonTaggableResourceTypes are (prefixes of) top level resource types which don't support tags
+var nonTaggableResourceTypes = []string{"microsoft.authorization/", "microsoft.insights/diagnosticsettings", "microsoft.resources/tags"}
+
+// taggableChildResourceTypes are the child resource types which support tags, most child resources don't
+var taggableChildResourceTypes = []string{
+	"microsoft.compute/virtualmachines/extensions",
+	"microsoft.sql/servers/databases",
+	"microsoft.sql/servers/elasticpools",
+	"microsoft.web/sites/slots",
+}
+
+// ArmParser tags Azure Resource Manager JSON templates. The templates are edited in place, so the formatting and the
+// order of the keys of the original file are kept.
+type ArmParser struct {
+	rootDir string
+}
+
+type armResource struct {
+	path         string
+	name         string
+	resourceType string
+	node         *json.Node
+}
+
+func (p *ArmParser) Name() string {
+	return "ARM"
+}
+
+func (p *ArmParser) Init(rootDir string, _ map[string]string) {
+	p.rootDir = rootDir
+}
+
+func (p *ArmParser) Close() {
+}
+
+func (p *ArmParser) GetSkippedDirs() []string {
+	return []string{}
+}
+
+func (p *ArmParser) GetSupportedFileExtensions() []string {
+	return []string{common.JSONFileType.Extension}
+}
+
+// ValidFile Validate file is an ARM deployment template, by its $schema. Parameter files are not templates.
+func (p *ArmParser) ValidFile(filePath string) bool {
+	src, err := utils.ReadFile(filePath)
+	if err != nil {
+		logger.Warning(fmt.Sprintf("Error reading file %s, skipping: %v", filePath, err))
+		return false
+	}
+	var template map[string]interface{}
+	if err = stdjson.Unmarshal(bytes.TrimPrefix(src, []byte("\ufeff")), &template); err != nil {
+		logger.Debug(fmt.Sprintf("File %s is not an ARM template: %v", filePath, err))
+		return false
+	}
+	schema, _ := template["$schema"].(string)
+	_, hasResources := template[ResourcesStartToken]
+	return hasResources && strings.Contains(strings.ToLower(schema), "deploymenttemplate.json")
+}
+
+func (p *ArmParser) ParseFile(filePath string) ([]structure.IBlock, error) {
+	src, err := utils.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s because %s", filePath, err)
+	}
+	root, err := json.ParseJSONNodes(string(src))
+	if err != nil {
+		logger.Warning(fmt.Sprintf("There was an error processing the ARM template %v: %s", filePath, err))
+		return nil, err
+	}
+
+	parsedBlocks := make([]structure.IBlock, 0)
+	for _, resource := range collectResources(root) {
+		var rawBlock map[string]interface{}
+		if err = stdjson.Unmarshal(src[resource.node.Start:resource.node.End], &rawBlock); err != nil {
+			return nil, err
+		}
+		isTaggable := isTaggableResourceType(resource.resourceType)
+		tagsLines := structure.Lines{Start: -1, End: -1}
+		existingTags := make([]tags.ITag, 0)
+		if tagsNode := resource.node.Get(TagsAttributeName); tagsNode != nil {
+			tagsLines = structure.Lines{Start: json.LineAt(string(src), tagsNode.Start), End: json.LineAt(string(src), tagsNode.End-1)}
+			existingTags, isTaggable = getExistingTags(tagsNode)
+			if !isTaggable {
+				logger.Debug(fmt.Sprintf("Skipping resource %s in %s, its tags are set by an expression", resource.name, filePath))
+			}
+		}
+		parsedBlocks = append(parsedBlocks, &ArmBlock{
+			Block: structure.Block{
+				FilePath:          filePath,
+				ExitingTags:       existingTags,
+				RawBlock:          rawBlock,
+				IsTaggable:        isTaggable,
+				TagsAttributeName: TagsAttributeName,
+				Lines:             structure.Lines{Start: json.LineAt(string(src), resource.node.Start), End: json.LineAt(string(src), resource.node.End-1)},
+				TagLines:          tagsLines,
+				Name:              resource.name,
+				Type:              resource.resourceType,
+			},
+			path: resource.path,
+		})
+	}
+	return parsedBlocks, nil
+}
+
+func (p *ArmParser) WriteFile(readFilePath string, blocks []structure.IBlock, writeFilePath string) error {
+	originFileSrc, err := utils.ReadFile(readFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s because %s", readFilePath, err)
+	}
+	src := string(originFileSrc)
+	root, err := json.ParseJSONNodes(src)
+	if err != nil {
+		return err
+	}
+	resourcesByPath := make(map[string]*json.Node)
+	for _, resource := range collectResources(root) {
+		resourcesByPath[resource.path] = resource.node
+	}
+
+	edits := make([]utils.TextEdit, 0)
+	for _, block := range blocks {
+		armBlock, ok := block.(*ArmBlock)
+		if !ok || !armBlock.IsBlockTaggable() {
+			continue
+		}
+		diff := armBlock.CalculateTagsDiff()
+		if len(diff.Added) == 0 && len(diff.Updated) == 0 {
+			continue
+		}
+		armBlock.UpdateTags()
+		resourceNode, ok := resourcesByPath[armBlock.path]
+		if !ok {
+			logger.Warning(fmt.Sprintf("failed to find resource %s in %s", armBlock.GetResourceID(), readFilePath))
+			continue
+		}
+		edits = append(edits, json.GetTagsEdits(src, resourceNode, TagsAttributeName, diff, escapeTagValue)...)
+	}
+
+	textToWrite := utils.ApplyTextEdits(src, edits)
+	if !stdjson.Valid([]byte(strings.TrimPrefix(textToWrite, "\ufeff"))) {
+		return fmt.Errorf("editing file %v resulted in a malformed template, please open a github issue with the relevant details", readFilePath)
+	}
+	return utils.WriteFile(writeFilePath, utils.MatchLineEndings(originFileSrc, []byte(textToWrite)))
+}
+
+// collectResources returns the resources of the template in the order they appear in it, including the child
+// resources declared inside their parents. The resources are either an array or, in templates using symbolic names,
+// an object keyed by the symbolic name of each resource.
+func collectResources(root *json.Node) []armResource {
+	resources := make([]armResource, 0)
+	var visit func(node *json.Node, path string, symbolicName string, parentType string)
+	visit = func(node *json.Node, path string, symbolicName string, parentType string) {
+		if node.Kind != json.ObjectNode {
+			return
+		}
+		resourceType := node.Get("type").GetString()
+		if parentType != "" && !strings.Contains(resourceType, "/") {
+			// child resources may declare their type relatively to their parent's
+			resourceType = parentType + "/" + resourceType
+		}
+		name := symbolicName
+		if name == "" {
+			name = node.Get("name").GetString()
+		}
+		if name == "" {
+			name = path
+		}
+		resources = append(resources, armResource{path: path, name: name, resourceType: resourceType, node: node})
+		if children := node.Get(ResourcesStartToken); children != nil && children.Kind == json.ArrayNode {
+			for i, child := range children.Elements {
+				visit(child, fmt.Sprintf("%s.%s[%d]", path, ResourcesStartToken, i), "", resourceType)
+			}
+		}
+	}
+
+	resourcesNode := root.Get(ResourcesStartToken)
+	if resourcesNode == nil {
+		return resources
+	}
+	switch resourcesNode.Kind {
+	case json.ArrayNode:
+		for i, element := range resourcesNode.Elements {
+			visit(element, fmt.Sprintf("%s[%d]", ResourcesStartToken, i), "", "")
+		}
+	case json.ObjectNode:
+		for _, member := range resourcesNode.Members {
+			visit(member.Value, fmt.Sprintf("%s.%s", ResourcesStartToken, member.Key), member.Key, "")
+		}
+	}
+	return resources
+}
+
+func isTaggableResourceType(resourceType string) bool {
+	resourceType = strings.ToLower(resourceType)
+	for _, prefix := range nonTaggableResourceTypes {
+		if strings.HasPrefix(resourceType, prefix) {
+			return false
+		}
+	}
+	if strings.Count(resourceType, "/") == 1 {
+		return true
+	}
+	return utils.InSlice(taggableChildResourceTypes, resourceType)
+}
+
+// getExistingTags returns the tags of a resource, which can only be tagged if they are a literal object
+func getExistingTags(tagsNode *json.Node) ([]tags.ITag, bool) {
+	existingTags := make([]tags.ITag, 0)
+	if tagsNode.Kind != json.ObjectNode {
+		return existingTags, false
+	}
+	for _, member := range tagsNode.Members {
+		if member.Value.Kind != json.StringNode {
+			return existingTags, false
+		}
+		// a leading "[[" escapes a literal value starting with "[", which is otherwise evaluated as an expression
+		value := member.Value.GetString()
+		if strings.HasPrefix(value, "[[") {
+			value = value[1:]
+		}
+		existingTags = append(existingTags, &tags.Tag{Key: member.Key, Value: value})
+	}
+	return existingTags, true
+}
+
+// escapeTagValue escapes values starting with "[", which ARM evaluates as expressions
+func escapeTagValue(value string) string {
+	if strings.HasPrefix(value, "[") {
+		return "[" + value
+	}
+	return value
+}
@@ -0,0 +1,124 @@
+package structure
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bridgecrewio/yor/src/common/structure"
+	"github.com/bridgecrewio/yor/src/common/tagging/simple"
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArmParser_ValidFile(t *testing.T) {
+	armParser := ArmParser{}
+	armParser.Init("../../../tests/arm/resources", nil)
+	t.Run("ARM template", func(t *testing.T) {
+		assert.True(t, armParser.ValidFile("../../../tests/arm/resources/storage/azuredeploy.json"))
+	})
+	t.Run("ARM parameters file", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "azuredeploy.parameters.json")
+		content := `{"$schema": "https://schema.management.azure.com/schemas/2019-04-01/deploymentParameters.json#", "parameters": {}}`
+		assert.Nil(t, os.WriteFile(filePath, []byte(content), 0600))
+		assert.False(t, armParser.ValidFile(filePath))
+	})
+	t.Run("CloudFormation template", func(t *testing.T) {
+		assert.False(t, armParser.ValidFile("../../../tests/cloudformation/resources/ebs/ebs.json"))
+	})
+}
+
+func TestArmParser_ParseFile(t *testing.T) {
+	armParser := ArmParser{}
+	armParser.Init("../../../tests/arm/resources/storage", nil)
+	blocks, err := armParser.ParseFile("../../../tests/arm/resources/storage/azuredeploy.json")
+	assert.Nil(t, err)
+	assert.Equal(t, 6, len(blocks))
+
+	expected := []struct {
+		name         string
+		resourceType string
+		taggable     bool
+		lines        structure.Lines
+		tagsLines    structure.Lines
+		existingTags int
+	}{
+		{"yorstorage", "Microsoft.Storage/storageAccounts", true, structure.Lines{Start: 15, End: 28}, structure.Lines{Start: 20, End: 23}, 2},
+		{"yor-vnet", "Microsoft.Network/virtualNetworks", true, structure.Lines{Start: 29, End: 50}, structure.Lines{Start: -1, End: -1}, 0},
+		{"default", "Microsoft.Network/virtualNetworks/subnets", false, structure.Lines{Start: 40, End: 48}, structure.Lines{Start: -1, End: -1}, 0},
+		{"yor-plan", "Microsoft.Web/serverfarms", false, structure.Lines{Start: 51, End: 57}, structure.Lines{Start: 56, End: 56}, 0},
+		{"yor-vault", "Microsoft.KeyVault/vaults", true, structure.Lines{Start: 58, End: 69}, structure.Lines{Start: 63, End: 63}, 0},
+		{"yor-lock", "Microsoft.Authorization/locks", false, structure.Lines{Start: 70, End: 77}, structure.Lines{Start: -1, End: -1}, 0},
+	}
+	for i, block := range blocks {
+		assert.Equal(t, expected[i].name, block.GetResourceID())
+		assert.Equal(t, expected[i].resourceType, block.GetResourceType())
+		assert.Equal(t, expected[i].taggable, block.IsBlockTaggable(), block.GetResourceID())
+		assert.Equal(t, expected[i].lines, block.GetLines())
+		assert.Equal(t, expected[i].tagsLines, block.GetTagsLines())
+		assert.Equal(t, expected[i].existingTags, len(block.GetExistingTags()))
+	}
+}
+
+func TestArmParser_WriteFile(t *testing.T) {
+	t.Run("Keep the formatting of the template", func(t *testing.T) {
+		directory := "../../../tests/arm/resources/storage"
+		writeArmTestHelper(t, directory, directory+"/azuredeploy.json", directory+"/azuredeploy_tagged.json")
+	})
+
+	t.Run("Tag inline resources declared by symbolic names", func(t *testing.T) {
+		directory := t.TempDir()
+		template := "{\r\n" +
+			"  \"$schema\": \"https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#\",\r\n" +
+			"  \"languageVersion\": \"2.0\",\r\n" +
+			"  \"resources\": {\r\n" +
+			"    \"storage\": {\"type\": \"Microsoft.Storage/storageAccounts\", \"name\": \"s\"},\r\n" +
+			"    \"plan\": {\"type\": \"Microsoft.Web/serverfarms\", \"name\": \"p\", \"tags\": {\"a\": \"b\"}}\r\n" +
+			"  }\r\n" +
+			"}\r\n"
+		expected := "{\r\n" +
+			"  \"$schema\": \"https://schema.management.azure.com/schemas/2019-04-01/deploymentTemplate.json#\",\r\n" +
+			"  \"languageVersion\": \"2.0\",\r\n" +
+			"  \"resources\": {\r\n" +
+			"    \"storage\": {\"type\": \"Microsoft.Storage/storageAccounts\", \"name\": \"s\", \"tags\": {\"new_tag\": \"new_value\", \"team\": \"[[platform]\"}},\r\n" +
+			"    \"plan\": {\"type\": \"Microsoft.Web/serverfarms\", \"name\": \"p\", \"tags\": {\"a\": \"b\", \"new_tag\": \"new_value\", \"team\": \"[[platform]\"}}\r\n" +
+			"  }\r\n" +
+			"}\r\n"
+		readFilePath := filepath.Join(directory, "main.json")
+		expectedFilePath := filepath.Join(directory, "expected.json")
+		assert.Nil(t, os.WriteFile(readFilePath, []byte(template), 0600))
+		assert.Nil(t, os.WriteFile(expectedFilePath, []byte(expected), 0600))
+		writeArmTestHelper(t, directory, readFilePath, expectedFilePath)
+	})
+}
+
+func writeArmTestHelper(t *testing.T, directory string, readFilePath string, expectedFilePath string) {
+	armParser := ArmParser{}
+	armParser.Init(directory, nil)
+	tagGroup := simple.TagGroup{}
+	tagGroup.SetTags([]tags.ITag{
+		&tags.Tag{Key: "new_tag", Value: "new_value"},
+		// values starting with "[" are expressions unless escaped
+		&tags.Tag{Key: "team", Value: "[platform]"},
+	})
+	tagGroup.InitTagGroup("", []string{}, []string{})
+	blocks, err := armParser.ParseFile(readFilePath)
+	assert.Nil(t, err)
+	for _, block := range blocks {
+		assert.Nil(t, tagGroup.CreateTagsForBlock(block))
+	}
+	writeFilePath := filepath.Join(t.TempDir(), "tagged.json")
+	assert.Nil(t, armParser.WriteFile(readFilePath, blocks, writeFilePath))
+
+	expectedContent, _ := os.ReadFile(expectedFilePath)
+	actualContent, _ := os.ReadFile(writeFilePath)
+	assert.Equal(t, string(expectedContent), string(actualContent))
+
+	taggedBlocks, err := armParser.ParseFile(writeFilePath)
+	assert.Nil(t, err)
+	for _, block := range taggedBlocks {
+		if block.IsBlockTaggable() {
+			assert.Contains(t, block.GetExistingTags(), &tags.Tag{Key: "team", Value: "[platform]"})
+		}
+	}
+}
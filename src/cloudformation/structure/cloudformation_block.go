@@ -11,6 +11,21 @@ import (
 
 type CloudformationBlock struct {
 	structure.Block
+	// NestedTemplateURL is the template of an AWS::CloudFormation::Stack resource, and NestedTemplateFile its path when
+	// it's a local file, which is uploaded by `aws cloudformation package`
+	NestedTemplateURL  string
+	NestedTemplateFile string
+	// APIDefinitionFile is the local OpenAPI definition of an AWS::Serverless::Api or AWS::Serverless::HttpApi
+	// resource, which is uploaded by `aws cloudformation package`
+	APIDefinitionFile string
+}
+
+func (b *CloudformationBlock) GetNestedTemplate() (string, string) {
+	return b.NestedTemplateURL, b.NestedTemplateFile
+}
+
+func (b *CloudformationBlock) GetAPIDefinitionFile() string {
+	return b.APIDefinitionFile
 }
 
 func (b *CloudformationBlock) UpdateTags() {
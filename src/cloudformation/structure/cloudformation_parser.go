@@ -3,9 +3,7 @@ package structure
 import (
 	stdjson "encoding/json"
 	"fmt"
-	"io"
 	"math"
-	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
@@ -26,15 +24,24 @@ import (
 	sanathyaml "github.com/sanathkr/yaml"
 )
 
+var cfnResourceTypePrefixes = []string{"AWS::", "Alexa::", "Custom::"}
+
 type CloudformationParser struct {
 	*types.YamlParser
 	*types.JSONParser
 }
 
 const TagsAttributeName = "Tags"
+const PropertiesAttributeName = "Properties"
+const TemplateURLAttributeName = "TemplateURL"
+const NestedStackResourceType = "AWS::CloudFormation::Stack"
+const DefinitionURIAttributeName = "DefinitionUri"
 const ResourcesStartToken = "Resources"
 const EnvVarsPath = "Resources/*/Properties/Environment/Variables/*"
 
+// APIResourceTypes are the resource types whose OpenAPI definition can be a local file
+var APIResourceTypes = []string{"AWS::Serverless::Api", "AWS::Serverless::HttpApi"}
+
 var goformationLock sync.Mutex
 
 func (p *CloudformationParser) Name() string {
@@ -61,23 +68,13 @@ func (p *CloudformationParser) GetSupportedFileExtensions() []string {
 	return []string{common.YamlFileType.Extension, common.YmlFileType.Extension, common.CFTFileType.Extension, common.JSONFileType.Extension}
 }
 
-// ValidFile Validate file has AWSTemplateFormatVersion
+// ValidFile Validate file is a CloudFormation template, by its content rather than its extension
 func (p *CloudformationParser) ValidFile(filePath string) bool {
-	// #nosec G304
-	file, err := os.Open(filePath)
-	if err != nil {
-		logger.Warning(fmt.Sprintf("Error opening file %s, skipping: %v", filePath, err))
-		return false
-	}
-	bytes, err := io.ReadAll(file)
+	bytes, err := utils.ReadFile(filePath)
 	if err != nil {
 		logger.Warning(fmt.Sprintf("Error reading file %s, skipping: %v", filePath, err))
 		return false
 	}
-	if err = file.Close(); err != nil {
-		logger.Warning(fmt.Sprintf("Error closing file %s, skipping: %v", filePath, err))
-		return false
-	}
 
 	if !strings.HasSuffix(filePath, ".json") {
 		bytes, err = sanathyaml.YAMLToJSON(bytes)
@@ -92,13 +89,44 @@ func (p *CloudformationParser) ValidFile(filePath string) bool {
 		logger.Warning(fmt.Sprintf("Error unmarshalling JSON for file %s, skipping: %v", filePath, err))
 		return false
 	}
-	_, hasHeader := result["AWSTemplateFormatVersion"]
-	return hasHeader
+	return isCloudFormationTemplate(result)
 }
 
-func goformationParse(file string) (*cloudformation.Template, error) {
-	var template *cloudformation.Template
-	var err error
+// isCloudFormationTemplate checks the template has the AWSTemplateFormatVersion header, which is optional, or declares
+// CloudFormation resource types. Other YAML documents with a Resources section, such as Kubernetes manifests, are not
+// templates and must not be tagged as such.
+func isCloudFormationTemplate(template map[string]interface{}) bool {
+	if _, hasHeader := template["AWSTemplateFormatVersion"]; hasHeader {
+		return true
+	}
+	_, hasAPIVersion := template["apiVersion"]
+	_, hasKind := template["kind"]
+	if hasAPIVersion && hasKind {
+		return false
+	}
+	resources, ok := template["Resources"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	for _, resource := range resources {
+		resourceMap, ok := resource.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		resourceType, ok := resourceMap["Type"].(string)
+		if !ok {
+			continue
+		}
+		for _, prefix := range cfnResourceTypePrefixes {
+			if strings.HasPrefix(resourceType, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func goformationParse(file string) (template *cloudformation.Template, err error) {
 	defer func() {
 		if e := recover(); e != nil {
 			logger.Warning(fmt.Sprintf("Failed to parser cfn file at %v due to: %v", file, e))
@@ -106,10 +134,18 @@ func goformationParse(file string) (*cloudformation.Template, error) {
 		}
 	}()
 
-	template, err = goformation.OpenWithOptions(file, &intrinsics.ProcessorOptions{
+	data, err := utils.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	options := &intrinsics.ProcessorOptions{
 		StringifyPaths: []string{EnvVarsPath},
-	})
-	return template, err
+	}
+	// like goformation.OpenWithOptions, which only reads the file system of the OS
+	if strings.HasSuffix(file, ".json") {
+		return goformation.ParseJSONWithOptions(data, options)
+	}
+	return goformation.ParseYAMLWithOptions(data, options)
 }
 
 func (p *CloudformationParser) ParseFile(filePath string) ([]structure.IBlock, error) {
@@ -136,9 +172,15 @@ func (p *CloudformationParser) ParseFile(filePath string) ([]structure.IBlock, e
 		}
 
 		var resourceNamesToLines map[string]*structure.Lines
+		var yamlLines []string
 		switch utils.GetFileFormat(filePath) {
 		case common.YmlFileType.FileFormat, common.YamlFileType.FileFormat:
 			resourceNamesToLines = yaml.MapResourcesLineYAML(filePath, resourceNames, ResourcesStartToken)
+			src, err := utils.ReadFile(filePath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read file %s because %s", filePath, err)
+			}
+			yamlLines = utils.GetLinesFromBytes(src)
 		case common.JSONFileType.FileFormat:
 			var fileBracketsMapping map[int]json.BracketPair
 			resourceNamesToLines, fileBracketsMapping = json.MapResourcesLineJSON(filePath, resourceNames)
@@ -155,6 +197,10 @@ func (p *CloudformationParser) ParseFile(filePath string) ([]structure.IBlock, e
 			resourceType := resource.AWSCloudFormationType()
 			lines := resourceNamesToLines[resourceName]
 			isTaggable, tagsValue := utils.StructContainsProperty(resource, TagsAttributeName)
+			if isTaggable && yamlLines != nil && lines.Start >= 0 && !yaml.AreTagsEditable(yamlLines[lines.Start:lines.End+1], PropertiesAttributeName, TagsAttributeName) {
+				logger.Info(fmt.Sprintf("Skipping resource %s in %s, its tags are set by a YAML alias, anchor or flow collection", resourceName, filePath))
+				isTaggable = false
+			}
 			tagsLines := structure.Lines{Start: -1, End: -1}
 			var existingTags []tags.ITag
 			if isTaggable {
@@ -176,6 +222,12 @@ func (p *CloudformationParser) ParseFile(filePath string) ([]structure.IBlock, e
 					Type:              resourceType,
 				},
 			}
+			if resourceType == NestedStackResourceType {
+				cfnBlock.NestedTemplateURL, cfnBlock.NestedTemplateFile = getNestedTemplate(filePath, resource)
+			}
+			if utils.InSlice(APIResourceTypes, resourceType) {
+				cfnBlock.APIDefinitionFile = getAPIDefinitionFile(filePath, resource)
+			}
 			parsedBlocks = append(parsedBlocks, cfnBlock)
 		}
 
@@ -186,6 +238,46 @@ func (p *CloudformationParser) ParseFile(filePath string) ([]structure.IBlock, e
 	return nil, err
 }
 
+// getNestedTemplate returns the template URL of a nested stack, and the path of the template when the URL is a path
+// relative to the parent template, as `aws cloudformation package` expects before uploading it
+func getNestedTemplate(filePath string, resource interface{}) (string, string) {
+	hasTemplateURL, templateURLValue := utils.StructContainsProperty(resource, TemplateURLAttributeName)
+	if !hasTemplateURL || templateURLValue.Kind() != reflect.String {
+		return "", ""
+	}
+	templateURL := templateURLValue.String()
+	if templateURL == "" || strings.Contains(templateURL, "://") {
+		return templateURL, ""
+	}
+	templateFile := filepath.Join(filepath.Dir(filePath), filepath.FromSlash(templateURL))
+	if info, err := utils.Stat(templateFile); err != nil || info.IsDir() {
+		return templateURL, ""
+	}
+	return templateURL, templateFile
+}
+
+// getAPIDefinitionFile returns the path of the OpenAPI definition of a serverless API when its DefinitionUri is a path
+// relative to the template, as `aws cloudformation package` expects before uploading it
+func getAPIDefinitionFile(filePath string, resource interface{}) string {
+	hasDefinitionURI, definitionURIValue := utils.StructContainsProperty(resource, DefinitionURIAttributeName)
+	if !hasDefinitionURI || definitionURIValue.Kind() != reflect.Ptr || definitionURIValue.IsNil() {
+		return ""
+	}
+	hasString, stringValue := utils.StructContainsProperty(definitionURIValue.Interface(), "String")
+	if !hasString || stringValue.Kind() != reflect.Ptr || stringValue.IsNil() {
+		return ""
+	}
+	definitionURI := stringValue.Elem().String()
+	if definitionURI == "" || strings.Contains(definitionURI, "://") {
+		return ""
+	}
+	definitionFile := filepath.Join(filepath.Dir(filePath), filepath.FromSlash(definitionURI))
+	if info, err := utils.Stat(definitionFile); err != nil || info.IsDir() {
+		return ""
+	}
+	return definitionFile
+}
+
 func (p *CloudformationParser) extractTagsAndLines(filePath string, lines *structure.Lines, tagsValue reflect.Value) (structure.Lines, []tags.ITag) {
 	tagsLines := p.getTagsLines(filePath, lines)
 	existingTags := p.GetExistingTags(tagsValue)
@@ -225,19 +317,19 @@ func (p *CloudformationParser) WriteFile(readFilePath string, blocks []structure
 		block := block.(*CloudformationBlock)
 		block.UpdateTags()
 	}
-	tempFile, err := os.CreateTemp(filepath.Dir(readFilePath), "temp.*.template")
-	defer func() {
-		_ = os.Remove(tempFile.Name())
-	}()
+	tempFile, err := utils.CreateTemp(filepath.Dir(readFilePath), "temp.*.template")
 	if err != nil {
 		return err
 	}
-	err = p.writeToFile(readFilePath, blocks, tempFile.Name())
+	defer func() {
+		_ = utils.Remove(tempFile)
+	}()
+	err = p.writeToFile(readFilePath, blocks, tempFile)
 	if err != nil {
 		return err
 	}
 
-	_, err = p.ParseFile(tempFile.Name())
+	_, err = p.ParseFile(tempFile)
 	if err != nil {
 		return fmt.Errorf("editing file %v resulted in a malformed template, please open a github issue with the relevant details", readFilePath)
 	}
@@ -279,8 +371,7 @@ func (p *CloudformationParser) getTagsLines(filePath string, resourceLinesRange
 		}
 		return structure.Lines{Start: -1, End: -1}
 	case common.JSONFileType.FileFormat:
-		// #nosec G304
-		file, err := os.ReadFile(filePath)
+		file, err := utils.ReadFile(filePath)
 		if err != nil {
 			logger.Warning(fmt.Sprintf("failed to read file %s", filePath))
 			return structure.Lines{Start: -1, End: -1}
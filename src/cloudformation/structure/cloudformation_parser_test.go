@@ -105,6 +105,79 @@ func TestCloudformationParser_ParseFile(t *testing.T) {
 	})
 }
 
+func TestCloudformationParser_ParseMultiDocumentFile(t *testing.T) {
+	directory := "../../../tests/cloudformation/resources/multi_document"
+	cfnParser := CloudformationParser{}
+	cfnParser.Init(directory, nil)
+	cfnBlocks, err := cfnParser.ParseFile(directory + "/template.yaml")
+	assert.Nil(t, err)
+	// the resources of the second document aren't parsed
+	assert.Equal(t, 4, len(cfnBlocks))
+	expected := map[string]struct {
+		isTaggable bool
+		lines      structure.Lines
+		tagsLines  structure.Lines
+	}{
+		"Bucket": {true, structure.Lines{Start: 4, End: 7}, structure.Lines{Start: -1, End: -1}},
+		// the lines of the user data look like tags, but are a block scalar
+		"Instance": {true, structure.Lines{Start: 9, End: 24}, structure.Lines{Start: 22, End: 24}},
+		// tagging the anchored tags would change the tags of the resource using the alias too
+		"Volume":      {false, structure.Lines{Start: 25, End: 31}, structure.Lines{Start: -1, End: -1}},
+		"OtherVolume": {false, structure.Lines{Start: 32, End: 36}, structure.Lines{Start: -1, End: -1}},
+	}
+	for _, block := range cfnBlocks {
+		expectedBlock := expected[block.GetResourceID()]
+		assert.Equal(t, expectedBlock.isTaggable, block.IsBlockTaggable(), block.GetResourceID())
+		assert.Equal(t, expectedBlock.lines, block.GetLines(), block.GetResourceID())
+		assert.Equal(t, expectedBlock.tagsLines, block.GetTagsLines(), block.GetResourceID())
+	}
+}
+
+func TestCloudformationParser_ParseNestedStacks(t *testing.T) {
+	directory := "../../../tests/cloudformation/resources/nested_stack"
+	cfnParser := CloudformationParser{}
+	cfnParser.Init(directory, nil)
+	cfnBlocks, err := cfnParser.ParseFile(directory + "/parent.yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(cfnBlocks))
+	expected := map[string]struct {
+		templateURL  string
+		templateFile string
+		existingTags int
+	}{
+		"NetworkStack": {"network.yaml", filepath.Join(directory, "network.yaml"), 0},
+		// remote templates can't be related to a local file
+		"RemoteStack": {"https://s3.amazonaws.com/my-bucket/remote.yaml", "", 1},
+		"Bucket":      {"", "", 0},
+	}
+	for _, block := range cfnBlocks {
+		expectedBlock := expected[block.GetResourceID()]
+		assert.True(t, block.IsBlockTaggable(), block.GetResourceID())
+		templateURL, templateFile := block.(*CloudformationBlock).GetNestedTemplate()
+		assert.Equal(t, expectedBlock.templateURL, templateURL, block.GetResourceID())
+		assert.Equal(t, expectedBlock.templateFile, templateFile, block.GetResourceID())
+		assert.Equal(t, expectedBlock.existingTags, len(block.GetExistingTags()), block.GetResourceID())
+	}
+}
+
+func TestCloudformationParser_ParseAPIDefinitions(t *testing.T) {
+	directory := "../../../tests/cloudformation/resources/api_definition"
+	cfnParser := CloudformationParser{}
+	cfnParser.Init(directory, nil)
+	cfnBlocks, err := cfnParser.ParseFile(directory + "/template.yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(cfnBlocks))
+	expected := map[string]string{
+		"PetsApi": filepath.Join(directory, "openapi.yaml"),
+		// definitions in S3 can't be related to a local file
+		"RemoteApi": "",
+		"Bucket":    "",
+	}
+	for _, block := range cfnBlocks {
+		assert.Equal(t, expected[block.GetResourceID()], block.(*CloudformationBlock).GetAPIDefinitionFile(), block.GetResourceID())
+	}
+}
+
 func compareLines(t *testing.T, expected map[string]*structure.Lines, actual map[string]*structure.Lines) {
 	for resourceName := range expected {
 		actualLines := actual[resourceName]
@@ -238,4 +311,31 @@ func TestWriteCFN(t *testing.T) {
 		writeCFNTestHelper(t, directory, "cfn", "yaml")
 	})
 
+	t.Run("test multi document yaml writing", func(t *testing.T) {
+		directory := "../../../tests/cloudformation/resources/multi_document"
+		writeCFNTestHelper(t, directory, "template", "yaml")
+	})
+
+}
+
+func TestCloudformationParser_ValidFile(t *testing.T) {
+	dir := t.TempDir()
+	cfnParser := CloudformationParser{}
+	cfnParser.Init(dir, nil)
+	files := map[string]struct {
+		content string
+		valid   bool
+	}{
+		"header.yaml":        {"AWSTemplateFormatVersion: '2010-09-09'\nResources: {}\n", true},
+		"no_header.yaml":     {"Resources:\n  Bucket:\n    Type: AWS::S3::Bucket\n", true},
+		"custom.json":        {`{"Resources": {"Res": {"Type": "Custom::Resource"}}}`, true},
+		"deployment.yaml":    {"apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\nspec:\n  replicas: 1\n", false},
+		"k8s_resources.yaml": {"apiVersion: v1\nkind: ConfigMap\nResources:\n  Bucket:\n    Type: AWS::S3::Bucket\n", false},
+		"other.yaml":         {"Resources:\n  cpu: 100m\n", false},
+	}
+	for name, file := range files {
+		filePath := filepath.Join(dir, name)
+		assert.Nil(t, os.WriteFile(filePath, []byte(file.content), 0600))
+		assert.Equal(t, file.valid, cfnParser.ValidFile(filePath), name)
+	}
 }
@@ -0,0 +1,282 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/bridgecrewio/yor/src/common"
+	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/bridgecrewio/yor/src/common/reports"
+	"github.com/bridgecrewio/yor/src/common/structure"
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+	"github.com/bridgecrewio/yor/src/common/utils"
+)
+
+// Checkpoint records the directories whose files a run finished tagging, with the results of their files, so an
+// interrupted run can be resumed without tagging them again. The paths are kept relative to the tagged directory.
+type Checkpoint struct {
+	Version       string                `json:"version"`
+	Directory     string                `json:"directory"`
+	DryRun        bool                  `json:"dryRun"`
+	UpdatedAt     time.Time             `json:"updatedAt"`
+	CompletedDirs []string              `json:"completedDirs"`
+	Blocks        []Block               `json:"blocks"`
+	SkippedFiles  []reports.SkippedFile `json:"skippedFiles,omitempty"`
+	Errors        []reports.RunError    `json:"errors,omitempty"`
+
+	dir       string
+	completed map[string]bool
+}
+
+// Block holds what the report needs of a scanned block, since the blocks of the parsers can't be serialized
+type Block struct {
+	File                 string `json:"file"`
+	ResourceID           string `json:"resourceId"`
+	ResourceType         string `json:"resourceType"`
+	Taggable             bool   `json:"taggable"`
+	ExistingTags         []Tag  `json:"existingTags,omitempty"`
+	NewTags              []Tag  `json:"newTags,omitempty"`
+	Imported             bool   `json:"imported,omitempty"`
+	UneditableTagsReason string `json:"uneditableTagsReason,omitempty"`
+	ModuleSource         string `json:"moduleSource,omitempty"`
+	NestedTemplateURL    string `json:"nestedTemplateUrl,omitempty"`
+	NestedTemplateFile   string `json:"nestedTemplateFile,omitempty"`
+	APIDefinitionFile    string `json:"apiDefinitionFile,omitempty"`
+}
+
+type Tag struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source,omitempty"`
+}
+
+// restoredBlock is a block of a checkpoint, which is only reported. It keeps the optional interfaces of the block it
+// was saved from.
+type restoredBlock struct {
+	structure.Block
+	imported             bool
+	uneditableTagsReason string
+	moduleSource         string
+	nestedTemplateURL    string
+	nestedTemplateFile   string
+	apiDefinitionFile    string
+}
+
+func (b *restoredBlock) IsImported() bool {
+	return b.imported
+}
+
+func (b *restoredBlock) GetUneditableTagsReason() string {
+	return b.uneditableTagsReason
+}
+
+func (b *restoredBlock) GetModuleSource() string {
+	return b.moduleSource
+}
+
+func (b *restoredBlock) GetNestedTemplate() (string, string) {
+	return b.nestedTemplateURL, b.nestedTemplateFile
+}
+
+func (b *restoredBlock) GetAPIDefinitionFile() string {
+	return b.apiDefinitionFile
+}
+
+// New returns an empty checkpoint of a run which tags dir
+func New(dir string, dryRun bool) *Checkpoint {
+	absDir, _ := filepath.Abs(dir)
+	return &Checkpoint{Version: common.Version, Directory: absDir, DryRun: dryRun, dir: dir, completed: map[string]bool{}}
+}
+
+// Load reads the checkpoint of an interrupted run of dir, or returns an empty checkpoint if the file doesn't exist. A
+// checkpoint of another directory, or of a dry run when this run writes the files (or the other way around), can't be
+// resumed.
+func Load(checkpointPath string, dir string, dryRun bool) (*Checkpoint, error) {
+	checkpoint := New(dir, dryRun)
+	// #nosec G304 - file is from user
+	checkpointBytes, err := os.ReadFile(checkpointPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoint, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint %s: %s", checkpointPath, err)
+	}
+	saved := Checkpoint{}
+	if err = json.Unmarshal(checkpointBytes, &saved); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %s", checkpointPath, err)
+	}
+	if saved.Directory != checkpoint.Directory {
+		return nil, fmt.Errorf("checkpoint %s is of the directory %s rather than %s, remove it to start over", checkpointPath, saved.Directory, checkpoint.Directory)
+	}
+	if saved.DryRun != dryRun {
+		return nil, fmt.Errorf("checkpoint %s was saved by a run with dry run set to %v, remove it to start over", checkpointPath, saved.DryRun)
+	}
+	if saved.Version != common.Version {
+		logger.Warning(fmt.Sprintf("The checkpoint was saved by yor version %s, while the current version is %s", saved.Version, common.Version))
+	}
+	saved.dir = dir
+	saved.completed = map[string]bool{}
+	for _, completedDir := range saved.CompletedDirs {
+		saved.completed[completedDir] = true
+	}
+	logger.Info(fmt.Sprintf("Resuming from checkpoint %s of %v with %d completed directories", checkpointPath, saved.UpdatedAt, len(saved.CompletedDirs)))
+	return &saved, nil
+}
+
+// IsCompleted returns whether the files of the directory were tagged by the run which saved the checkpoint
+func (c *Checkpoint) IsCompleted(dir string) bool {
+	return c.completed[c.relPath(dir)]
+}
+
+// Restore adds the results of the completed directories to the accumulator of the resumed run. traceKey is the key
+// of the yor_trace tag in the resumed run, which may be renamed by --tag-key-names.
+func (c *Checkpoint) Restore(accumulator *reports.TagChangeAccumulator, traceKey string) {
+	for _, block := range c.Blocks {
+		accumulator.AccumulateChanges(c.toStructureBlock(block, traceKey))
+	}
+	for _, skippedFile := range c.SkippedFiles {
+		accumulator.AccumulateSkippedFile(c.fullPath(skippedFile.File), skippedFile.Reason)
+	}
+	for _, runError := range c.Errors {
+		accumulator.AccumulateError(runError.Code, c.fullPath(runError.File), runError.Message)
+	}
+}
+
+// Complete marks the directory as completed, once the files under it (but not under its subdirectories) are tagged
+func (c *Checkpoint) Complete(dir string) {
+	relDir := c.relPath(dir)
+	if !c.completed[relDir] {
+		c.completed[relDir] = true
+		c.CompletedDirs = append(c.CompletedDirs, relDir)
+	}
+}
+
+// Save takes the results of the files of the completed directories from the accumulator, and writes the checkpoint
+// atomically, so an interrupted run leaves either the previous or the current checkpoint. The results of directories
+// which aren't completed are left out, since they are tagged again on resume.
+func (c *Checkpoint) Save(checkpointPath string, accumulator *reports.TagChangeAccumulator) error {
+	c.Blocks = nil
+	for _, block := range accumulator.GetScannedBlocks() {
+		if c.isCompletedFile(block.GetFilePath()) {
+			c.Blocks = append(c.Blocks, c.fromStructureBlock(block))
+		}
+	}
+	c.SkippedFiles = nil
+	for _, skippedFile := range accumulator.GetSkippedFiles() {
+		if c.isCompletedFile(skippedFile.File) {
+			c.SkippedFiles = append(c.SkippedFiles, reports.SkippedFile{File: c.relPath(skippedFile.File), Reason: skippedFile.Reason})
+		}
+	}
+	c.Errors = nil
+	for _, runError := range accumulator.GetErrors() {
+		// errors which aren't of a file are found again by the resumed run
+		if runError.File != "" && c.isCompletedFile(runError.File) {
+			runError.File = c.relPath(runError.File)
+			c.Errors = append(c.Errors, runError)
+		}
+	}
+	sort.Strings(c.CompletedDirs)
+	c.UpdatedAt = time.Now().UTC()
+	checkpointBytes, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err = utils.WriteFileAtomically(checkpointPath, checkpointBytes); err != nil {
+		return fmt.Errorf("failed to write checkpoint %s: %s", checkpointPath, err)
+	}
+	return nil
+}
+
+// Remove deletes the checkpoint once the run is completed, so the next run starts over
+func Remove(checkpointPath string) error {
+	if err := os.Remove(checkpointPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove checkpoint %s: %s", checkpointPath, err)
+	}
+	return nil
+}
+
+func (c *Checkpoint) isCompletedFile(file string) bool {
+	return c.IsCompleted(filepath.Dir(file))
+}
+
+func (c *Checkpoint) relPath(path string) string {
+	if relPath, err := filepath.Rel(c.dir, path); err == nil {
+		return filepath.ToSlash(relPath)
+	}
+	return filepath.ToSlash(path)
+}
+
+func (c *Checkpoint) fullPath(relPath string) string {
+	if relPath == "" {
+		return ""
+	}
+	return filepath.Join(c.dir, filepath.FromSlash(relPath))
+}
+
+func (c *Checkpoint) fromStructureBlock(block structure.IBlock) Block {
+	saved := Block{
+		File:         c.relPath(block.GetFilePath()),
+		ResourceID:   block.GetResourceID(),
+		ResourceType: block.GetResourceType(),
+		Taggable:     block.IsBlockTaggable(),
+		ExistingTags: fromTags(block.GetExistingTags()),
+		NewTags:      fromTags(block.GetNewTags()),
+	}
+	if importedBlock, ok := block.(structure.IImportedBlock); ok {
+		saved.Imported = importedBlock.IsImported()
+	}
+	if uneditableBlock, ok := block.(structure.IUneditableTagsBlock); ok {
+		saved.UneditableTagsReason = uneditableBlock.GetUneditableTagsReason()
+	}
+	if moduleCallBlock, ok := block.(structure.IModuleCallBlock); ok {
+		saved.ModuleSource = moduleCallBlock.GetModuleSource()
+	}
+	if nestedStackBlock, ok := block.(structure.INestedStackBlock); ok {
+		saved.NestedTemplateURL, saved.NestedTemplateFile = nestedStackBlock.GetNestedTemplate()
+	}
+	if apiBlock, ok := block.(structure.IAPIDefinitionBlock); ok {
+		saved.APIDefinitionFile = apiBlock.GetAPIDefinitionFile()
+	}
+	return saved
+}
+
+func (c *Checkpoint) toStructureBlock(saved Block, traceKey string) structure.IBlock {
+	return &restoredBlock{
+		Block: structure.Block{
+			FilePath:    c.fullPath(saved.File),
+			TraceKey:    traceKey,
+			ExitingTags: toTags(saved.ExistingTags),
+			NewTags:     toTags(saved.NewTags),
+			IsTaggable:  saved.Taggable,
+			Name:        saved.ResourceID,
+			Type:        saved.ResourceType,
+		},
+		imported:             saved.Imported,
+		uneditableTagsReason: saved.UneditableTagsReason,
+		moduleSource:         saved.ModuleSource,
+		nestedTemplateURL:    saved.NestedTemplateURL,
+		nestedTemplateFile:   saved.NestedTemplateFile,
+		apiDefinitionFile:    saved.APIDefinitionFile,
+	}
+}
+
+func fromTags(blockTags []tags.ITag) []Tag {
+	var savedTags []Tag
+	for _, tag := range blockTags {
+		savedTags = append(savedTags, Tag{Key: tag.GetKey(), Value: tag.GetValue(), Source: tags.GetSource(tag)})
+	}
+	return savedTags
+}
+
+func toTags(savedTags []Tag) []tags.ITag {
+	var blockTags []tags.ITag
+	for _, savedTag := range savedTags {
+		blockTags = append(blockTags, &tags.Tag{Key: savedTag.Key, Value: savedTag.Value, Source: savedTag.Source})
+	}
+	return blockTags
+}
@@ -0,0 +1,90 @@
+package checkpoint
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/bridgecrewio/yor/src/common"
+	"github.com/bridgecrewio/yor/src/common/reports"
+	"github.com/bridgecrewio/yor/src/common/structure"
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	checkpointPath := filepath.Join(t.TempDir(), "checkpoint.json")
+	newBlock := func(file string, name string) *structure.Block {
+		return &structure.Block{
+			FilePath:   filepath.Join(dir, file),
+			Name:       name,
+			Type:       "aws_s3_bucket",
+			IsTaggable: true,
+			NewTags:    []tags.ITag{&tags.Tag{Key: "git_repo", Value: "yor", Source: tags.GitSource}},
+		}
+	}
+	accumulator := reports.NewTagChangeAccumulator()
+	accumulator.AccumulateChanges(newBlock(filepath.Join("a", "main.tf"), "aws_s3_bucket.a"))
+	accumulator.AccumulateChanges(newBlock(filepath.Join("b", "main.tf"), "aws_s3_bucket.b"))
+	accumulator.AccumulateSkippedFile(filepath.Join(dir, "a", "binary.tf"), "file is binary")
+	accumulator.AccumulateError(common.ParseFailure, filepath.Join(dir, "b", "broken.tf"), "failed to parse")
+	accumulator.AccumulateError(common.UnsupportedFramework, "", "unknown parser kubernetes")
+
+	t.Run("Save the results of the completed directories", func(t *testing.T) {
+		checkpoint := New(dir, false)
+		checkpoint.Complete(filepath.Join(dir, "a"))
+		assert.Nil(t, checkpoint.Save(checkpointPath, accumulator))
+
+		loaded, err := Load(checkpointPath, dir, false)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a"}, loaded.CompletedDirs)
+		assert.True(t, loaded.IsCompleted(filepath.Join(dir, "a")))
+		assert.False(t, loaded.IsCompleted(filepath.Join(dir, "b")))
+		assert.False(t, loaded.IsCompleted(dir))
+
+		restored := reports.NewTagChangeAccumulator()
+		loaded.Restore(restored, tags.YorTraceTagKey)
+		newBlocks, _ := restored.GetBlockChanges()
+		assert.Equal(t, 1, len(newBlocks))
+		assert.Equal(t, filepath.Join(dir, "a", "main.tf"), newBlocks[0].GetFilePath())
+		assert.Equal(t, "aws_s3_bucket.a", newBlocks[0].GetResourceID())
+		assert.Equal(t, tags.GitSource, tags.GetSource(newBlocks[0].GetNewTags()[0]))
+		assert.Equal(t, []reports.SkippedFile{{File: filepath.Join(dir, "a", "binary.tf"), Reason: "file is binary"}}, restored.GetSkippedFiles())
+		assert.Empty(t, restored.GetErrors())
+	})
+
+	t.Run("Restore the blocks with the trace key of the run", func(t *testing.T) {
+		renamedPath := filepath.Join(t.TempDir(), "checkpoint.json")
+		renamedBlock := newBlock(filepath.Join("a", "main.tf"), "aws_s3_bucket.a")
+		renamedBlock.NewTags = []tags.ITag{&tags.Tag{Key: "corp:trace-id", Value: "f4c5f2ad-5a1e-4b7a-8d0e-0c8f1b2a3e4d"}}
+		renamedAccumulator := reports.NewTagChangeAccumulator()
+		renamedAccumulator.AccumulateChanges(renamedBlock)
+		checkpoint := New(dir, false)
+		checkpoint.Complete(filepath.Join(dir, "a"))
+		assert.Nil(t, checkpoint.Save(renamedPath, renamedAccumulator))
+
+		loaded, err := Load(renamedPath, dir, false)
+		assert.Nil(t, err)
+		restored := reports.NewTagChangeAccumulator()
+		loaded.Restore(restored, "corp:trace-id")
+		newBlocks, _ := restored.GetBlockChanges()
+		assert.Equal(t, 1, len(newBlocks))
+		assert.Equal(t, "f4c5f2ad-5a1e-4b7a-8d0e-0c8f1b2a3e4d", newBlocks[0].GetTraceID())
+	})
+
+	t.Run("Resume only the runs of the same directory and dry run", func(t *testing.T) {
+		_, err := Load(checkpointPath, t.TempDir(), false)
+		assert.NotNil(t, err)
+		_, err = Load(checkpointPath, dir, true)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Start over once the checkpoint is removed", func(t *testing.T) {
+		assert.Nil(t, Remove(checkpointPath))
+		assert.Nil(t, Remove(checkpointPath))
+		loaded, err := Load(checkpointPath, dir, false)
+		assert.Nil(t, err)
+		assert.Empty(t, loaded.CompletedDirs)
+		assert.False(t, loaded.IsCompleted(filepath.Join(dir, "a")))
+	})
+}
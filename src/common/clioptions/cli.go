@@ -4,54 +4,223 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/bridgecrewio/yor/src/common"
+	"github.com/bridgecrewio/yor/src/common/gitservice"
+	"github.com/bridgecrewio/yor/src/common/i18n"
 	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/bridgecrewio/yor/src/common/notify"
+	"github.com/bridgecrewio/yor/src/common/progress"
+	"github.com/bridgecrewio/yor/src/common/reports"
+	"github.com/bridgecrewio/yor/src/common/tagging/gittag"
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
 	taggingUtils "github.com/bridgecrewio/yor/src/common/tagging/utils"
+	"github.com/bridgecrewio/yor/src/common/tagpolicy"
 	"github.com/bridgecrewio/yor/src/common/utils"
 
 	"gopkg.in/validator.v2"
 )
 
-var allowedOutputTypes = []string{"cli", "json"}
+var allowedOutputTypes = []string{"cli", "json", "markdown"}
+var allowedPullRequestProviders = []string{"github", "gitlab"}
+
+// AllowedParsers are the frameworks yor can tag, which the commands run all of by default
+var AllowedParsers = []string{"Terraform", "CloudFormation", "Serverless", "ARM", "DockerCompose", "Packer"}
 
 type TagOptions struct {
-	Directory         string
-	Tag               []string
-	SkipTags          []string
-	CustomTagging     []string
-	SkipDirs          []string
-	Output            string `validate:"output"`
-	OutputJSONFile    string
-	TagGroups         []string `validate:"tagGroupNames"`
-	ConfigFile        string   `validate:"config-file"`
-	SkipResourceTypes []string
-	SkipResources     []string
-	Parsers           []string
-	DryRun            bool
-	TagLocalModules   bool
-	TagPrefix         string
+	Directory              string
+	Tag                    []string
+	SkipTags               []string
+	CustomTagging          []string
+	SkipDirs               []string
+	Output                 string `validate:"output"`
+	OutputJSONFile         string
+	OutputTagsFile         string
+	OutputGraphFile        string
+	OutputEnrichmentFile   string
+	SignReport             bool
+	ReportSchema           string   `validate:"report-schema"`
+	TagGroups              []string `validate:"tagGroupNames"`
+	OnlyKeys               []string
+	OnlyGroups             []string
+	ConfigFile             string `validate:"config-file"`
+	SkipResourceTypes      []string
+	SkipResources          []string
+	Parsers                []string
+	DryRun                 bool
+	Backup                 bool
+	TagLocalModules        bool
+	TagPrefix              string
+	TagKeyNames            []string `validate:"tag-key-names"`
+	Remote                 string
+	Ref                    string
+	RemoteDepth            int
+	CreatePR               bool
+	PRBranch               string
+	PRBase                 string
+	PRTitle                string
+	PRProvider             string `validate:"pr-provider"`
+	RunManifest            string
+	VerifyLastRun          bool
+	Timeout                time.Duration
+	Checkpoint             string
+	LockFile               string
+	Wait                   time.Duration
+	Progress               string `validate:"progress"`
+	PathStyle              string `validate:"path-style"`
+	PathPrefixStrip        []string
+	PathPrefixMap          []string
+	FollowSymlinks         bool
+	SkipSubmodules         bool
+	MaxFileSizeMB          int
+	MaxResourcesPerFile    int
+	AnonymizeGitIdentities string `validate:"anonymize-git-identities"`
+	AnonymizationSalt      string `json:"-"` // kept out of the run manifest, which serializes the options
+	IdentitiesMappingFile  string
+	FreezeGitTags          bool
+	ProvenanceFormat       string `validate:"provenance-format"`
+	ProvenanceDelimiter    string
+	GitRemoteRules         []string `validate:"git-remote-rules"`
+	IgnoreValueChanges     []string `validate:"ignore-value-changes"`
+	RequiredTagsFile       string   `validate:"required-tags"`
+	BaselineFile           string   `validate:"baseline"`
+	PreScanHook            string
+	PostFileWriteHook      string
+	PostRunHook            string
+	Notify                 []string `json:"-" validate:"notify"` // the webhook URLs are secrets, so they are kept out of the run manifest too
+	NotifyReportURL        string
+	ReportStore            string
+	Lang                   string `validate:"lang"`
+	Color                  string `validate:"color"`
 }
 
 type ListTagsOptions struct {
 	TagGroups []string `validate:"tagGroupNames"`
 }
 
+type TrendOptions struct {
+	ReportStore string
+	Directory   string
+	Output      string `validate:"output"`
+}
+
+type CheckPlanOptions struct {
+	PlanFile         string
+	RequiredTagsFile string   `validate:"required-tags"`
+	TagKeyNames      []string `validate:"tag-key-names"`
+	Output           string   `validate:"output"`
+}
+
+type MigrateTagsOptions struct {
+	Directory   string
+	SkipDirs    []string
+	Parsers     []string
+	From        string
+	To          string
+	MappingFile string
+	DryRun      bool
+	Output      string `validate:"output"`
+}
+
+type VerifyReportOptions struct {
+	ReportFile    string
+	SignatureFile string
+}
+
+type ImportTagPolicyOptions struct {
+	PolicyFile string
+	OutputFile string
+}
+
 func (o *TagOptions) Validate() {
 	_ = validator.SetValidationFunc("output", validateOutput)
 	_ = validator.SetValidationFunc("tagGroupNames", validateTagGroupNames)
 	_ = validator.SetValidationFunc("config-file", validateConfigFile)
+	_ = validator.SetValidationFunc("pr-provider", validatePullRequestProvider)
+	_ = validator.SetValidationFunc("progress", validateProgress)
+	_ = validator.SetValidationFunc("path-style", validatePathStyle)
+	_ = validator.SetValidationFunc("tag-key-names", validateTagKeyNames)
+	_ = validator.SetValidationFunc("anonymize-git-identities", validateAnonymizeGitIdentities)
+	_ = validator.SetValidationFunc("provenance-format", validateProvenanceFormat)
+	_ = validator.SetValidationFunc("git-remote-rules", validateGitRemoteRules)
+	_ = validator.SetValidationFunc("required-tags", validateRequiredTags)
+	_ = validator.SetValidationFunc("notify", validateNotify)
+	_ = validator.SetValidationFunc("baseline", validateBaseline)
+	_ = validator.SetValidationFunc("ignore-value-changes", validateIgnoreValueChanges)
+	_ = validator.SetValidationFunc("lang", validateLang)
+	_ = validator.SetValidationFunc("color", validateColor)
+	_ = validator.SetValidationFunc("report-schema", validateReportSchema)
 
 	o.Tag = utils.SplitStringByComma(o.Tag)
 	o.SkipTags = utils.SplitStringByComma(o.SkipTags)
 	o.CustomTagging = utils.SplitStringByComma(o.CustomTagging)
 	o.SkipDirs = utils.SplitStringByComma(o.SkipDirs)
 	o.TagGroups = utils.SplitStringByComma(o.TagGroups)
+	o.OnlyKeys = utils.SplitStringByComma(o.OnlyKeys)
+	o.OnlyGroups = utils.SplitStringByComma(o.OnlyGroups)
 	o.SkipResourceTypes = utils.SplitStringByComma(o.SkipResourceTypes)
 	o.SkipResources = utils.SplitStringByComma(o.SkipResources)
+	o.Parsers = utils.SplitStringByComma(o.Parsers)
+	o.TagKeyNames = utils.SplitStringByComma(o.TagKeyNames)
+	o.Notify = utils.SplitStringByComma(o.Notify)
+	o.IgnoreValueChanges = utils.SplitStringByComma(o.IgnoreValueChanges)
+	o.PathPrefixStrip = utils.SplitStringByComma(o.PathPrefixStrip)
+	o.PathPrefixMap = utils.SplitStringByComma(o.PathPrefixMap)
 
 	if err := validator.Validate(o); err != nil {
 		logger.Error(err.Error())
 	}
+	if err := validateParsers(o.Parsers, ""); err != nil {
+		logger.ErrorWithExitCode(common.UnsupportedFramework.ExitCode(), err.Error())
+	}
+	if o.Directory == "" && o.Remote == "" {
+		logger.Error("either a directory or a remote repository to tag must be specified")
+	}
+	if o.RemoteDepth < 0 {
+		logger.Error(fmt.Sprintf("invalid remote depth %d, expected a non negative number", o.RemoteDepth))
+	}
+	// the files of a ref of the repository are read from the git object database, so they can't be written
+	if o.Ref != "" && o.Remote == "" {
+		o.DryRun = true
+	}
+	if o.CreatePR && o.DryRun {
+		logger.Error("a pull request can't be created in a dry run")
+	}
+	if o.MaxFileSizeMB < 0 || o.MaxResourcesPerFile < 0 {
+		logger.Error("file limits must be non negative numbers")
+	}
+	if o.Timeout < 0 {
+		logger.Error(fmt.Sprintf("invalid timeout %v, expected a non negative duration", o.Timeout))
+	}
+	if o.Wait < 0 {
+		logger.Error(fmt.Sprintf("invalid wait %v, expected a non negative duration", o.Wait))
+	}
+	// the clone of a remote is removed after the run, so there is nothing to resume
+	if o.Checkpoint != "" && o.Remote != "" {
+		logger.Error("--checkpoint can't be used with --remote")
+	}
+	if o.VerifyLastRun && o.RunManifest == "" {
+		logger.Error("--verify-last-run requires the --run-manifest written by the last run")
+	}
+	// the other tag groups still own their tags, e.g. git_remote replaces the git_org and git_repo of git
+	for _, group := range o.OnlyGroups {
+		if !utils.InSlice(o.TagGroups, group) {
+			logger.Error(fmt.Sprintf("--only-groups %s isn't one of the --tag-groups %s", group, strings.Join(o.TagGroups, ",")))
+		}
+	}
+	if o.IdentitiesMappingFile != "" && o.AnonymizeGitIdentities == "" {
+		logger.Error("--identities-mapping-file requires --anonymize-git-identities")
+	}
+	if _, err := reports.ParsePathPrefixes(o.PathPrefixStrip, o.PathPrefixMap); err != nil {
+		logger.Error(err.Error())
+	}
+	if o.SignReport && o.OutputJSONFile == "" {
+		logger.Error("--sign-report requires the --output-json-file to sign")
+	}
+	if o.SignReport && os.Getenv(reports.SigningKeyEnvKey) == "" {
+		logger.Error(fmt.Sprintf("--sign-report requires the signing key in the %s environment variable", reports.SigningKeyEnvKey))
+	}
 }
 
 func (l *ListTagsOptions) Validate() {
@@ -63,8 +232,84 @@ func (l *ListTagsOptions) Validate() {
 	}
 }
 
+func (t *TrendOptions) Validate() {
+	_ = validator.SetValidationFunc("output", validateOutput)
+	if err := validator.Validate(t); err != nil {
+		logger.Error(err.Error())
+	}
+	if t.ReportStore == "" {
+		logger.Error("a report store must be specified")
+	}
+	if strings.ToLower(t.Output) == "markdown" {
+		logger.Error("the trend can be printed as cli or json")
+	}
+}
+
+func (p *CheckPlanOptions) Validate() {
+	_ = validator.SetValidationFunc("required-tags", validateRequiredTags)
+	_ = validator.SetValidationFunc("tag-key-names", validateTagKeyNames)
+	_ = validator.SetValidationFunc("output", validateOutput)
+	p.TagKeyNames = utils.SplitStringByComma(p.TagKeyNames)
+	if err := validator.Validate(p); err != nil {
+		logger.Error(err.Error())
+	}
+	if p.PlanFile == "" {
+		logger.Error("a plan file to check must be specified")
+	}
+	if strings.ToLower(p.Output) == "markdown" {
+		logger.Error("the plan check can be printed as cli or json")
+	}
+}
+
+func (m *MigrateTagsOptions) Validate() {
+	_ = validator.SetValidationFunc("output", validateOutput)
+	m.SkipDirs = utils.SplitStringByComma(m.SkipDirs)
+	m.Parsers = utils.SplitStringByComma(m.Parsers)
+	if err := validator.Validate(m); err != nil {
+		logger.Error(err.Error())
+	}
+	if (m.From == "") != (m.To == "") {
+		logger.Error("--from and --to must be set together")
+	}
+	if m.From == "" && m.MappingFile == "" {
+		logger.Error("the tag keys to rename must be set with --from and --to, or with a mapping file")
+	}
+	if m.MappingFile != "" {
+		if _, err := os.Stat(m.MappingFile); err != nil {
+			logger.Error(fmt.Sprintf("tag key mapping file %s does not exist", m.MappingFile))
+		}
+	}
+	if strings.ToLower(m.Output) == "markdown" {
+		logger.Error("the migration can be printed as cli or json")
+	}
+}
+
+func (v *VerifyReportOptions) Validate() {
+	if v.ReportFile == "" {
+		logger.Error("a report to verify must be specified")
+	}
+	if v.SignatureFile == "" {
+		v.SignatureFile = v.ReportFile + reports.SignatureSuffix
+	}
+	if os.Getenv(reports.SigningKeyEnvKey) == "" {
+		logger.Error(fmt.Sprintf("the key the report was signed with must be set in the %s environment variable", reports.SigningKeyEnvKey))
+	}
+}
+
+func (i *ImportTagPolicyOptions) Validate() {
+	if i.PolicyFile == "" {
+		logger.Error("a tag policy file to import must be specified")
+	}
+	if _, err := os.Stat(i.PolicyFile); err != nil {
+		logger.Error(fmt.Sprintf("tag policy file %s does not exist", i.PolicyFile))
+	}
+	if i.OutputFile == "" {
+		logger.Error("a file to write the required tags to must be specified")
+	}
+}
+
 func validateTagGroupNames(v interface{}, _ string) error {
-	tagGroupsNames := taggingUtils.GetAllTagGroupsNames()
+	tagGroupsNames := taggingUtils.GetSupportedTagGroupsNames()
 	val, ok := v.([]string)
 	if ok {
 		for _, gn := range val {
@@ -90,6 +335,213 @@ func validateOutput(v interface{}, _ string) error {
 	return nil
 }
 
+func validatePullRequestProvider(v interface{}, _ string) error {
+	val, ok := v.(string)
+	if !ok {
+		return validator.ErrUnsupported
+	}
+
+	if val != "" && !utils.InSlice(allowedPullRequestProviders, strings.ToLower(val)) {
+		return fmt.Errorf("unsupported pull request provider [%s]. allowed providers: %s", val, allowedPullRequestProviders)
+	}
+
+	return nil
+}
+
+func validateNotify(v interface{}, _ string) error {
+	val, ok := v.([]string)
+	if !ok {
+		return validator.ErrUnsupported
+	}
+
+	for _, notifyURL := range val {
+		if _, err := notify.ParseTarget(notifyURL); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateProgress(v interface{}, _ string) error {
+	val, ok := v.(string)
+	if !ok {
+		return validator.ErrUnsupported
+	}
+
+	if val != "" && !utils.InSlice(progress.AllowedModes, strings.ToLower(val)) {
+		return fmt.Errorf("unsupported progress mode [%s]. allowed modes: %s", val, progress.AllowedModes)
+	}
+
+	return nil
+}
+
+func validateLang(v interface{}, _ string) error {
+	val, ok := v.(string)
+	if !ok {
+		return validator.ErrUnsupported
+	}
+
+	if val != "" && !i18n.IsSupported(val) {
+		return fmt.Errorf("unsupported language [%s]. allowed languages: %s", val, i18n.SupportedLocales())
+	}
+
+	return nil
+}
+
+func validateColor(v interface{}, _ string) error {
+	val, ok := v.(string)
+	if !ok {
+		return validator.ErrUnsupported
+	}
+
+	if val != "" && !utils.InSlice(reports.AllowedColorModes, strings.ToLower(val)) {
+		return fmt.Errorf("unsupported color mode [%s]. allowed modes: %s", val, reports.AllowedColorModes)
+	}
+
+	return nil
+}
+
+func validateReportSchema(v interface{}, _ string) error {
+	val, ok := v.(string)
+	if !ok {
+		return validator.ErrUnsupported
+	}
+
+	if val != "" && !utils.InSlice(reports.ReportSchemas, strings.ToLower(val)) {
+		return fmt.Errorf("unsupported report schema [%s]. allowed schemas: %s", val, reports.ReportSchemas)
+	}
+
+	return nil
+}
+
+func validatePathStyle(v interface{}, _ string) error {
+	val, ok := v.(string)
+	if !ok {
+		return validator.ErrUnsupported
+	}
+
+	if val != "" && !utils.InSlice(reports.PathStyles, strings.ToLower(val)) {
+		return fmt.Errorf("unsupported path style [%s]. allowed styles: %s", val, reports.PathStyles)
+	}
+
+	return nil
+}
+
+func validateAnonymizeGitIdentities(v interface{}, _ string) error {
+	val, ok := v.(string)
+	if !ok {
+		return validator.ErrUnsupported
+	}
+
+	if val != "" && !utils.InSlice(gittag.AnonymizationModes, strings.ToLower(val)) {
+		return fmt.Errorf("unsupported git identities anonymization [%s]. allowed modes: %s", val, gittag.AnonymizationModes)
+	}
+
+	return nil
+}
+
+func validateProvenanceFormat(v interface{}, _ string) error {
+	val, ok := v.(string)
+	if !ok {
+		return validator.ErrUnsupported
+	}
+
+	if val != "" && !utils.InSlice(gittag.ProvenanceFormats, strings.ToLower(val)) {
+		return fmt.Errorf("unsupported provenance format [%s]. allowed formats: %s", val, gittag.ProvenanceFormats)
+	}
+
+	return nil
+}
+
+func validateGitRemoteRules(v interface{}, _ string) error {
+	val, ok := v.([]string)
+	if !ok {
+		return validator.ErrUnsupported
+	}
+
+	_, err := gitservice.ParseRemoteRules(val)
+	return err
+}
+
+func validateTagKeyNames(v interface{}, _ string) error {
+	val, ok := v.([]string)
+	if !ok {
+		return validator.ErrUnsupported
+	}
+
+	_, err := tags.ParseKeyNames(val)
+	return err
+}
+
+func validateRequiredTags(v interface{}, _ string) error {
+	val, ok := v.(string)
+	if !ok {
+		return validator.ErrUnsupported
+	}
+
+	if val != "" {
+		_, err := tagpolicy.LoadRequiredTags(val)
+		return err
+	}
+
+	return nil
+}
+
+func validateBaseline(v interface{}, _ string) error {
+	val, ok := v.(string)
+	if !ok {
+		return validator.ErrUnsupported
+	}
+
+	if val != "" {
+		_, err := reports.LoadBaseline(val)
+		return err
+	}
+
+	return nil
+}
+
+func validateIgnoreValueChanges(v interface{}, _ string) error {
+	val, ok := v.([]string)
+	if !ok {
+		return validator.ErrUnsupported
+	}
+
+	for _, change := range val {
+		if !utils.InSlice(tags.ValueChangeKinds, strings.ToLower(change)) {
+			return fmt.Errorf("unsupported value change [%s]. allowed changes: %s", change, tags.ValueChangeKinds)
+		}
+	}
+
+	return nil
+}
+
+// ValidateParsers checks the parsers are of the AllowedParsers, for the options of requests which aren't validated as
+// the options of the commands are (i.e. of the gRPC server)
+func ValidateParsers(parsers []string) error {
+	return validateParsers(parsers, "")
+}
+
+func validateParsers(v interface{}, _ string) error {
+	val, ok := v.([]string)
+	if !ok {
+		return validator.ErrUnsupported
+	}
+
+	for _, parser := range val {
+		supported := false
+		for _, allowed := range AllowedParsers {
+			supported = supported || strings.EqualFold(parser, allowed)
+		}
+		if !supported {
+			return fmt.Errorf("unsupported framework [%s]. allowed frameworks: %s", parser, AllowedParsers)
+		}
+	}
+
+	return nil
+}
+
 func validateConfigFile(v interface{}, _ string) error {
 	if v != "" {
 		val, ok := v.(string)
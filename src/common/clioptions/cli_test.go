@@ -5,6 +5,7 @@ import (
 	"os/exec"
 	"testing"
 
+	"github.com/bridgecrewio/yor/src/common"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -53,6 +54,81 @@ func TestCliArgParsing(t *testing.T) {
 		assert.Fail(t, "Should have failed already")
 	})
 
+	t.Run("Test tag argument parsing - valid frameworks", func(t *testing.T) {
+		options := TagOptions{
+			Directory: "some/dir",
+			Output:    "cli",
+			Parsers:   []string{"terraform,CloudFormation", "Serverless"},
+		}
+		// Expect the validation to pass without throwing errors
+		options.Validate()
+		assert.Equal(t, []string{"terraform", "CloudFormation", "Serverless"}, options.Parsers)
+	})
+
+	t.Run("Test tag argument parsing - invalid frameworks", func(t *testing.T) {
+		cmd := exec.Command(os.Args[0], "-test.run=TestParsersCrasher")
+		cmd.Env = append(cmd.Env, "UT_CRASH=RUN")
+		err := cmd.Run()
+		if e, ok := err.(*exec.ExitError); ok && !e.Success() {
+			assert.Equal(t, common.UnsupportedFramework.ExitCode(), e.ExitCode())
+			return
+		}
+		assert.Fail(t, "Should have failed already")
+	})
+
+	t.Run("Test tag argument parsing - verify last run without a manifest", func(t *testing.T) {
+		cmd := exec.Command(os.Args[0], "-test.run=TestVerifyLastRunCrasher")
+		cmd.Env = append(cmd.Env, "UT_CRASH=RUN")
+		err := cmd.Run()
+		if e, ok := err.(*exec.ExitError); ok && !e.Success() {
+			return
+		}
+		assert.Fail(t, "Should have failed already")
+	})
+
+	t.Run("Test tag argument parsing - only groups of the tag groups", func(t *testing.T) {
+		options := TagOptions{
+			Directory:  "some/dir",
+			Output:     "cli",
+			TagGroups:  []string{"git", "code2cloud"},
+			OnlyKeys:   []string{"git_commit,git_last_modified_*"},
+			OnlyGroups: []string{"git"},
+		}
+		options.Validate()
+		assert.Equal(t, []string{"git_commit", "git_last_modified_*"}, options.OnlyKeys)
+	})
+
+	t.Run("Test tag argument parsing - only groups which aren't tag groups", func(t *testing.T) {
+		cmd := exec.Command(os.Args[0], "-test.run=TestOnlyGroupsCrasher")
+		cmd.Env = append(cmd.Env, "UT_CRASH=RUN")
+		err := cmd.Run()
+		if e, ok := err.(*exec.ExitError); ok && !e.Success() {
+			return
+		}
+		assert.Fail(t, "Should have failed already")
+	})
+
+	t.Run("Test tag argument parsing - valid tag key names", func(t *testing.T) {
+		options := TagOptions{
+			Directory:   "some/dir",
+			Output:      "cli",
+			TagKeyNames: []string{"yor_trace=corp:trace-id,git_commit=corp:git-commit"},
+		}
+		// Expect the validation to pass without throwing errors
+		options.Validate()
+		assert.Equal(t, []string{"yor_trace=corp:trace-id", "git_commit=corp:git-commit"}, options.TagKeyNames)
+	})
+
+	t.Run("Test tag argument parsing - invalid tag key names", func(t *testing.T) {
+		cmd := exec.Command(os.Args[0], "-test.run=TestTagKeyNamesCrasher")
+		cmd.Env = append(cmd.Env, "UT_CRASH=RUN")
+		err := cmd.Run()
+		if e, ok := err.(*exec.ExitError); ok && !e.Success() {
+			return
+		}
+		assert.Fail(t, "Should have failed already")
+	})
+
 	t.Run("Test tag argument parsing - valid tag groups", func(t *testing.T) {
 		options := TagOptions{
 			Directory:      "some/dir",
@@ -114,6 +190,51 @@ func TestOutputCrasher(t *testing.T) {
 	}
 }
 
+func TestParsersCrasher(t *testing.T) {
+	if os.Getenv("UT_CRASH") == "RUN" {
+		options := TagOptions{
+			Directory: "some/dir",
+			Output:    "cli",
+			Parsers:   []string{"Terraform,Kubernetes"},
+		}
+		options.Validate()
+	}
+}
+
+func TestVerifyLastRunCrasher(t *testing.T) {
+	if os.Getenv("UT_CRASH") == "RUN" {
+		options := TagOptions{
+			Directory:     "some/dir",
+			Output:        "cli",
+			VerifyLastRun: true,
+		}
+		options.Validate()
+	}
+}
+
+func TestOnlyGroupsCrasher(t *testing.T) {
+	if os.Getenv("UT_CRASH") == "RUN" {
+		options := TagOptions{
+			Directory:  "some/dir",
+			Output:     "cli",
+			TagGroups:  []string{"code2cloud"},
+			OnlyGroups: []string{"git"},
+		}
+		options.Validate()
+	}
+}
+
+func TestTagKeyNamesCrasher(t *testing.T) {
+	if os.Getenv("UT_CRASH") == "RUN" {
+		options := TagOptions{
+			Directory:   "some/dir",
+			Output:      "cli",
+			TagKeyNames: []string{"custom_tag=corp:custom"},
+		}
+		options.Validate()
+	}
+}
+
 func TestTagGroupCrasher(t *testing.T) {
 	if os.Getenv("UT_CRASH") == "RUN" {
 		options := TagOptions{
@@ -0,0 +1,45 @@
+package common
+
+// ErrorCode classifies the errors which break a run, which are listed in the report and set the exit code of yor. A
+// run without errors exits with 0, whether it changed any files or not, and other failures (i.e. invalid options) exit
+// with 1.
+type ErrorCode string
+
+const (
+	ParseFailure         ErrorCode = "parse_failure"
+	GitUnavailable       ErrorCode = "git_unavailable"
+	WriteFailure         ErrorCode = "write_failure"
+	UnsupportedFramework ErrorCode = "unsupported_framework"
+)
+
+// ErrorCodes are ordered by precedence, a run which failed in several ways exits with the code of the first one
+var ErrorCodes = []ErrorCode{UnsupportedFramework, GitUnavailable, WriteFailure, ParseFailure}
+
+var exitCodes = map[ErrorCode]int{
+	ParseFailure:         2,
+	GitUnavailable:       3,
+	WriteFailure:         4,
+	UnsupportedFramework: 5,
+}
+
+// ExitCode returns the exit code of yor for the error code
+func (c ErrorCode) ExitCode() int {
+	if exitCode, ok := exitCodes[c]; ok {
+		return exitCode
+	}
+	return 1
+}
+
+// CodedError is an error which yor exits with the exit code of its code for
+type CodedError struct {
+	Code ErrorCode
+	Err  error
+}
+
+func (e *CodedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CodedError) Unwrap() error {
+	return e.Err
+}
@@ -10,3 +10,5 @@ var YmlFileType = FileType{Extension: ".yml", FileFormat: "yml"}
 var JSONFileType = FileType{Extension: ".json", FileFormat: "json"}
 var CFTFileType = FileType{Extension: ".template", FileFormat: "template"}
 var TfFileType = FileType{Extension: ".tf", FileFormat: "tf"}
+var TfJSONFileType = FileType{Extension: ".tf.json", FileFormat: "json"}
+var PackerFileType = FileType{Extension: ".pkr.hcl", FileFormat: "hcl"}
@@ -14,6 +14,8 @@ import (
 type GitBlame struct {
 	GitOrg        string
 	GitRepository string
+	// RepositoryURL is the https URL of the repository, see GitService.GetRepositoryURL
+	RepositoryURL string
 	BlamesByLine  map[int]*git.Line
 	FilePath      string
 	GitUserEmail  string
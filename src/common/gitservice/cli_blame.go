@@ -0,0 +1,96 @@
+package gitservice
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+const (
+	BlameEngineEnvKey = "YOR_GIT_BLAME_ENGINE"
+	GoGitBlameEngine  = "go-git"
+	// CliBlameEngine computes the blame with the git executable and parses its output as it is read. The blame result
+	// of the file is still built in memory, but unlike go-git the revisions of the file are not loaded into the process.
+	// Blames are serialized by gitGraphLock like those of go-git.
+	CliBlameEngine = "cli"
+
+	maxBlameLineSize = 10 * 1024 * 1024
+)
+
+func (g *GitService) getFileBlameFromCli(relativeFilePath string, rev plumbing.Hash) (*git.BlameResult, error) {
+	// #nosec G204 - the arguments are a commit hash and a path in the repository
+	cmd := exec.CommandContext(g.context(), "git", "blame", "--line-porcelain", rev.String(), "--", filepath.ToSlash(relativeFilePath))
+	cmd.Dir = g.repoRootDir
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to run git blame: %s", err)
+	}
+	blame, parseErr := parseLinePorcelain(stdout, relativeFilePath, rev)
+	if parseErr != nil {
+		// drain the output, so the process isn't blocked on writing it
+		_, _ = io.Copy(io.Discard, stdout)
+	}
+	if err = cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("git blame failed: %s", err)
+	}
+	return blame, parseErr
+}
+
+// parseLinePorcelain parses the output of git blame --line-porcelain, in which every line of the file is preceded by
+// the headers of the commit which introduced it
+func parseLinePorcelain(r io.Reader, path string, rev plumbing.Hash) (*git.BlameResult, error) {
+	result := &git.BlameResult{Path: path, Rev: rev}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxBlameLineSize)
+	// commits usually introduce many lines, so they share the author strings
+	authors := map[string]string{}
+	current := &git.Line{}
+	expectHeader := true
+	for scanner.Scan() {
+		line := scanner.Text()
+		if expectHeader {
+			fields := strings.Fields(line)
+			if len(fields) < 3 || !plumbing.IsHash(fields[0]) {
+				return nil, fmt.Errorf("unexpected git blame line %q", line)
+			}
+			current = &git.Line{Hash: plumbing.NewHash(fields[0])}
+			expectHeader = false
+			continue
+		}
+		switch {
+		case strings.HasPrefix(line, "\t"):
+			current.Text = line[1:]
+			result.Lines = append(result.Lines, current)
+			expectHeader = true
+		case strings.HasPrefix(line, "author-mail "):
+			author := strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(line, "author-mail "), "<"), ">")
+			if cached, ok := authors[author]; ok {
+				author = cached
+			} else {
+				authors[author] = author
+			}
+			current.Author = author
+		case strings.HasPrefix(line, "author-time "):
+			seconds, err := strconv.ParseInt(strings.TrimPrefix(line, "author-time "), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("unexpected git blame author time %q", line)
+			}
+			current.Date = time.Unix(seconds, 0)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,78 @@
+package gitservice
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bridgecrewio/yor/tests/utils"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCliBlame(t *testing.T) {
+	t.Run("Parse line porcelain", func(t *testing.T) {
+		output := `0a8a3f7e8f8b6d47d4a3d1a9ac4b1b6f4c1f7e3d 1 1 2
+author John Doe
+author-mail <john@example.com>
+author-time 1609459200
+author-tz +0000
+committer John Doe
+committer-mail <john@example.com>
+committer-time 1609459200
+committer-tz +0000
+summary add bucket
+filename main.tf
+	resource "aws_s3_bucket" "b" {
+0a8a3f7e8f8b6d47d4a3d1a9ac4b1b6f4c1f7e3d 2 2
+author John Doe
+author-mail <john@example.com>
+author-time 1609459200
+author-tz +0000
+committer John Doe
+committer-mail <john@example.com>
+committer-time 1609459200
+committer-tz +0000
+summary add bucket
+filename main.tf
+	}
+`
+		rev := plumbing.NewHash("0a8a3f7e8f8b6d47d4a3d1a9ac4b1b6f4c1f7e3d")
+		blame, err := parseLinePorcelain(strings.NewReader(output), "main.tf", rev)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(blame.Lines))
+		assert.Equal(t, "john@example.com", blame.Lines[0].Author)
+		assert.Equal(t, `resource "aws_s3_bucket" "b" {`, blame.Lines[0].Text)
+		assert.Equal(t, "}", blame.Lines[1].Text)
+		assert.Equal(t, rev, blame.Lines[1].Hash)
+		assert.True(t, time.Unix(1609459200, 0).Equal(blame.Lines[1].Date))
+
+		_, err = parseLinePorcelain(strings.NewReader("not a blame\n"), "main.tf", rev)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Blame the same lines as go-git", func(t *testing.T) {
+		terragoatPath := utils.CloneRepo(utils.TerragoatURL, "063dc2db3bb036160ed39d3705508ee8293a27c8")
+		defer os.RemoveAll(terragoatPath)
+
+		gitService, err := NewGitService(terragoatPath)
+		assert.Nil(t, err)
+		filePath := terragoatPath + "/terraform/aws/ec2.tf"
+		goGitBlame, err := gitService.GetFileBlame(filePath)
+		assert.Nil(t, err)
+
+		t.Setenv(BlameEngineEnvKey, CliBlameEngine)
+		gitService, err = NewGitService(terragoatPath)
+		assert.Nil(t, err)
+		cliBlame, err := gitService.GetFileBlame(filePath)
+		assert.Nil(t, err)
+
+		assert.Equal(t, len(goGitBlame.Lines), len(cliBlame.Lines))
+		for i := range goGitBlame.Lines {
+			assert.Equal(t, goGitBlame.Lines[i].Text, cliBlame.Lines[i].Text)
+			assert.False(t, cliBlame.Lines[i].Hash.IsZero())
+			assert.NotEmpty(t, cliBlame.Lines[i].Author)
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package gitservice
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/bridgecrewio/yor/src/common/utils"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+)
+
+const GitTokenEnvKey = "YOR_GIT_TOKEN"
+
+// CloneRepository clones the remote repository to a new temporary directory and returns its path.
+// ref may be a branch or a tag name - if empty, the remote's default branch is cloned.
+// A depth greater than 0 creates a shallow clone. Git tags are computed from the fetched history only, and the history
+// isn't deepened on demand, so lines last modified before the fetched commits are blamed on the oldest fetched commit.
+func CloneRepository(ctx context.Context, url string, ref string, depth int) (string, error) {
+	cloneDir, err := os.MkdirTemp("", "yor-remote-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create a directory to clone %s into: %s", url, err)
+	}
+	logger.Info(fmt.Sprintf("Cloning %s (ref: %q, depth: %d) into %s", url, ref, depth, cloneDir))
+
+	var referenceNames []plumbing.ReferenceName
+	if ref == "" {
+		referenceNames = []plumbing.ReferenceName{""}
+	} else {
+		referenceNames = []plumbing.ReferenceName{plumbing.NewBranchReferenceName(ref), plumbing.NewTagReferenceName(ref)}
+	}
+	for _, referenceName := range referenceNames {
+		_, err = git.PlainCloneContext(ctx, cloneDir, false, &git.CloneOptions{
+			URL:           url,
+			Auth:          GetRemoteAuth(),
+			ReferenceName: referenceName,
+			SingleBranch:  referenceName != "",
+			Depth:         depth,
+			Tags:          git.NoTags,
+		})
+		if err == nil {
+			return cloneDir, nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		// a failed clone may leave a partial repository behind
+		_ = os.RemoveAll(cloneDir)
+		if mkErr := os.MkdirAll(cloneDir, 0700); mkErr != nil {
+			return "", mkErr
+		}
+	}
+	_ = os.RemoveAll(cloneDir)
+	return "", fmt.Errorf("failed to clone %s at ref %q: %s", url, ref, err)
+}
+
+// GetClonedPath returns the path of directory, which is relative to the root of the clone, and fails if the path is
+// outside of the clone (i.e. ../..)
+func GetClonedPath(cloneDir string, directory string) (string, error) {
+	clonedPath := filepath.Join(cloneDir, directory)
+	relativePath, err := filepath.Rel(cloneDir, clonedPath)
+	if err != nil || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("directory %s is outside of the cloned repository", directory)
+	}
+	return clonedPath, nil
+}
+
+// GetRemoteAuth returns the authentication used to clone from and push to remotes, based on the token in the environment
+func GetRemoteAuth() transport.AuthMethod {
+	token := GetGitToken()
+	if token == "" {
+		return nil
+	}
+	// token based authentication accepts any non-empty username
+	return &http.BasicAuth{Username: "yor", Password: token}
+}
+
+// GetRemoteAuthForURL returns the authentication matching the scheme of the remote's url - the ssh agent for ssh
+// remotes, and the token in the environment otherwise
+func GetRemoteAuthForURL(url string) (transport.AuthMethod, error) {
+	endpoint, err := transport.NewEndpoint(url)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.Protocol == "ssh" {
+		return ssh.NewSSHAgentAuth(endpoint.User)
+	}
+	return GetRemoteAuth(), nil
+}
+
+func GetGitToken() string {
+	return utils.GetEnv(GitTokenEnvKey, utils.GetEnv("GITHUB_TOKEN", os.Getenv("GITLAB_TOKEN")))
+}
@@ -0,0 +1,25 @@
+package gitservice
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetClonedPath(t *testing.T) {
+	cloneDir := t.TempDir()
+	for directory, expected := range map[string]string{
+		".":             cloneDir,
+		"terraform/aws": filepath.Join(cloneDir, "terraform", "aws"),
+		"a/../b":        filepath.Join(cloneDir, "b"),
+	} {
+		clonedPath, err := GetClonedPath(cloneDir, directory)
+		assert.Nil(t, err, directory)
+		assert.Equal(t, expected, clonedPath)
+	}
+	for _, directory := range []string{"..", "../..", "a/../../b"} {
+		_, err := GetClonedPath(cloneDir, directory)
+		assert.NotNil(t, err, directory)
+	}
+}
@@ -1,6 +1,7 @@
 package gitservice
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"log"
@@ -9,23 +10,35 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/bridgecrewio/yor/src/common"
 	"github.com/bridgecrewio/yor/src/common/logger"
 	"github.com/bridgecrewio/yor/src/common/structure"
+	"github.com/bridgecrewio/yor/src/common/utils"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/transport"
 )
 
 type GitService struct {
 	gitRootDir       string
+	repoRootDir      string
 	scanPathFromRoot string
 	repository       *git.Repository
 	remoteURL        string
+	host             string
 	organization     string
 	repoName         string
 	BlameByFile      *sync.Map
 	currentUserEmail string
+	ctx              context.Context
+	pathStyle        string
+	blameEngine      string
+	historyIndex     *historyIndex
+	// refCommit is the commit of the ref the files are read from, which is blamed instead of HEAD
+	refCommit *object.Commit
 }
 
 var gitGraphLock sync.Mutex
@@ -54,9 +67,17 @@ func NewGitService(rootDir string) (*GitService, error) {
 
 	gitService := GitService{
 		gitRootDir:       rootDir,
+		repoRootDir:      rootDirIter,
 		scanPathFromRoot: scanPathFromRoot,
 		repository:       repository,
 		BlameByFile:      &sync.Map{},
+		ctx:              context.Background(),
+		blameEngine:      strings.ToLower(utils.GetEnv(BlameEngineEnvKey, GoGitBlameEngine)),
+		historyIndex:     newHistoryIndex(),
+	}
+	if gitService.blameEngine != GoGitBlameEngine && gitService.blameEngine != CliBlameEngine {
+		logger.Warning(fmt.Sprintf("Unknown git blame engine %s, using %s", gitService.blameEngine, GoGitBlameEngine))
+		gitService.blameEngine = GoGitBlameEngine
 	}
 	err = gitService.setOrgAndName()
 	gitService.currentUserEmail = GetGitUserEmail()
@@ -74,18 +95,10 @@ func (g *GitService) setOrgAndName() error {
 	for _, remote := range remotes {
 		if remote.Config().Name == "origin" {
 			g.remoteURL = remote.Config().URLs[0]
-			// get endpoint structured like '/github.com/bridgecrewio/yor.git
-			endpoint, err := transport.NewEndpoint(g.remoteURL)
+			g.host, g.organization, g.repoName, err = ParseRemoteURL(g.remoteURL)
 			if err != nil {
 				return err
 			}
-			// remove leading '/' from path and trailing '.git. suffix, then split by '/'
-			endpointPathParts := strings.Split(strings.TrimSuffix(strings.TrimLeft(endpoint.Path, "/"), ".git"), "/")
-			if len(endpointPathParts) < 2 {
-				return fmt.Errorf("invalid format of endpoint path: %s", endpoint.Path)
-			}
-			g.organization = endpointPathParts[0]
-			g.repoName = strings.Join(endpointPathParts[1:], "/")
 			break
 		}
 	}
@@ -93,10 +106,27 @@ func (g *GitService) setOrgAndName() error {
 	return nil
 }
 
+// ParseRemoteURL extracts the host, organization and repository name from a git remote URL
+func ParseRemoteURL(remoteURL string) (string, string, string, error) {
+	// get endpoint structured like '/github.com/bridgecrewio/yor.git
+	endpoint, err := transport.NewEndpoint(remoteURL)
+	if err != nil {
+		return "", "", "", err
+	}
+	// remove leading '/' from path and trailing '.git. suffix, then split by '/'
+	endpointPathParts := strings.Split(strings.TrimSuffix(strings.TrimLeft(endpoint.Path, "/"), ".git"), "/")
+	if len(endpointPathParts) < 2 {
+		return "", "", "", fmt.Errorf("invalid format of endpoint path: %s", endpoint.Path)
+	}
+	return endpoint.Host, endpointPathParts[0], strings.Join(endpointPathParts[1:], "/"), nil
+}
+
+// ComputeRelativeFilePath returns the path of the file relative to the repository root, in the path style of the
+// service
 func (g *GitService) ComputeRelativeFilePath(fp string) string {
 	if strings.HasPrefix(fp, g.gitRootDir) {
 		res, _ := filepath.Rel(g.gitRootDir, fp)
-		return filepath.Join(g.scanPathFromRoot, res)
+		return g.formatPath(filepath.Join(g.scanPathFromRoot, res))
 	}
 	scanPathIter := g.scanPathFromRoot
 	parent := filepath.Dir(fp)
@@ -107,7 +137,14 @@ func (g *GitService) ComputeRelativeFilePath(fp string) string {
 		}
 		scanPathIter, _ = filepath.Split(scanPathIter)
 	}
-	return filepath.Join(scanPathIter, fp)
+	return g.formatPath(filepath.Join(scanPathIter, fp))
+}
+
+func (g *GitService) formatPath(path string) string {
+	if g.pathStyle == common.PosixPathStyle {
+		return filepath.ToSlash(path)
+	}
+	return path
 }
 
 func (g *GitService) GetBlameForFileLines(filePath string, lines structure.Lines) (*GitBlame, error) {
@@ -115,7 +152,7 @@ func (g *GitService) GetBlameForFileLines(filePath string, lines structure.Lines
 	relativeFilePath := g.ComputeRelativeFilePath(filePath)
 	blame, ok := g.BlameByFile.Load(filePath)
 	if ok {
-		return NewGitBlame(relativeFilePath, lines, blame.(*git.BlameResult), g.organization, g.repoName, g.currentUserEmail), nil
+		return g.newGitBlame(relativeFilePath, lines, blame.(*git.BlameResult)), nil
 	}
 
 	var err error
@@ -126,7 +163,88 @@ func (g *GitService) GetBlameForFileLines(filePath string, lines structure.Lines
 
 	g.BlameByFile.Store(filePath, blame)
 
-	return NewGitBlame(relativeFilePath, lines, blame.(*git.BlameResult), g.organization, g.repoName, g.currentUserEmail), nil
+	return g.newGitBlame(relativeFilePath, lines, blame.(*git.BlameResult)), nil
+}
+
+func (g *GitService) newGitBlame(relativeFilePath string, lines structure.Lines, blame *git.BlameResult) *GitBlame {
+	gitBlame := NewGitBlame(relativeFilePath, lines, blame, g.organization, g.repoName, g.currentUserEmail)
+	gitBlame.RepositoryURL = g.GetRepositoryURL()
+	return gitBlame
+}
+
+// GetRepositoryRoot returns the absolute path of the root of the repository the service was created for
+// GetCommitDate returns the author date of the commit with the given hash
+func (g *GitService) GetCommitDate(hash string) (time.Time, error) {
+	if !plumbing.IsHash(hash) {
+		return time.Time{}, fmt.Errorf("invalid commit hash %s", hash)
+	}
+	gitGraphLock.Lock()
+	defer gitGraphLock.Unlock()
+	commit, err := g.repository.CommitObject(plumbing.NewHash(hash))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to find commit %s: %s", hash, err)
+	}
+	return commit.Author.When, nil
+}
+
+func (g *GitService) GetRepositoryRoot() string {
+	return g.repoRootDir
+}
+
+// FindRepositoryRoot returns the closest directory containing dir which is the root of a git repository or submodule,
+// or an empty string if there is none
+func FindRepositoryRoot(dir string) string {
+	current, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		if IsRepositoryRoot(current) {
+			return current
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return ""
+		}
+		current = parent
+	}
+}
+
+// IsRepositoryRoot returns true if dir has a .git entry - a directory in a repository or a file in a submodule
+func IsRepositoryRoot(dir string) bool {
+	_, err := os.Lstat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+// SetPathStyle sets the style of the file paths the service computes - native paths use the separator of the OS, while
+// posix paths always use forward slashes
+func (g *GitService) SetPathStyle(pathStyle string) {
+	g.pathStyle = strings.ToLower(pathStyle)
+}
+
+// SetRef sets the ref (a branch, a tag or a commit) whose files are blamed instead of the files of HEAD, for scans of
+// the files of the ref in the git object database (see RefFileSystem)
+func (g *GitService) SetRef(ref string) error {
+	gitGraphLock.Lock()
+	defer gitGraphLock.Unlock()
+	commit, err := resolveCommit(g.repository, ref)
+	if err != nil {
+		return err
+	}
+	g.refCommit = commit
+	return nil
+}
+
+// SetContext sets the context which cancels the blame computations of the service
+func (g *GitService) SetContext(ctx context.Context) {
+	g.ctx = ctx
+}
+
+func (g *GitService) context() context.Context {
+	if g.ctx == nil {
+		return context.Background()
+	}
+	return g.ctx
 }
 
 func (g *GitService) GetOrganization() string {
@@ -137,27 +255,56 @@ func (g *GitService) GetRepoName() string {
 	return g.repoName
 }
 
+func (g *GitService) GetRemoteURL() string {
+	return g.remoteURL
+}
+
+// GetRepositoryURL returns the https URL of the repository of the origin remote, without the credentials or the .git
+// suffix of the remote URL, or an empty string if the repository has no origin
+func (g *GitService) GetRepositoryURL() string {
+	if g.host == "" || g.repoName == "" {
+		return ""
+	}
+	return fmt.Sprintf("https://%s/%s/%s", g.host, g.organization, g.repoName)
+}
+
 func (g *GitService) GetFileBlame(filePath string) (*git.BlameResult, error) {
 	blame, ok := g.BlameByFile.Load(filePath)
 	if ok {
 		return blame.(*git.BlameResult), nil
 	}
 
-	relativeFilePath := g.ComputeRelativeFilePath(filePath)
+	// paths in git always use forward slashes
+	relativeFilePath := filepath.ToSlash(g.ComputeRelativeFilePath(filePath))
 	var selectedCommit *object.Commit
+	var err error
 
 	gitGraphLock.Lock() // Git is a graph, different files can lead to graph scans interfering with each other
 	defer gitGraphLock.Unlock()
-	head, err := g.repository.Head()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get repository HEAD for file %s because of error %s", filePath, err)
+	// blames of other files may have been computed while waiting for the lock, so check the context only now
+	if err = g.context().Err(); err != nil {
+		return nil, fmt.Errorf("skipped blame of file %s: %w", filePath, err)
 	}
-	selectedCommit, err = g.repository.CommitObject(head.Hash())
-	if err != nil {
-		return nil, fmt.Errorf("failed to find commit %s ", head.Hash().String())
+	if g.refCommit != nil {
+		selectedCommit = g.refCommit
+	} else {
+		var head *plumbing.Reference
+		if head, err = g.repository.Head(); err != nil {
+			return nil, fmt.Errorf("failed to get repository HEAD for file %s because of error %s", filePath, err)
+		}
+		selectedCommit, err = g.repository.CommitObject(head.Hash())
+		if err != nil {
+			return nil, fmt.Errorf("failed to find commit %s ", head.Hash().String())
+		}
 	}
 
-	blame, err = git.Blame(selectedCommit, relativeFilePath)
+	if singleCommitBlame, ok := g.getSingleCommitBlame(relativeFilePath, selectedCommit); ok {
+		blame = singleCommitBlame
+	} else if g.blameEngine == CliBlameEngine {
+		blame, err = g.getFileBlameFromCli(relativeFilePath, selectedCommit.Hash)
+	} else {
+		blame, err = git.Blame(selectedCommit, relativeFilePath)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get blame for latest commit of file %s because of error %s", filePath, err)
 	}
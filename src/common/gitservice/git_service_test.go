@@ -1,6 +1,7 @@
 package gitservice
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -127,3 +128,29 @@ func TestNewGitService(t *testing.T) {
 		assert.Equal(t, "terraform/aws/db-app.tf", targetPath)
 	})
 }
+
+func TestCloneRepository(t *testing.T) {
+	t.Run("Shallow clone a remote repository", func(t *testing.T) {
+		cloneDir, err := CloneRepository(context.Background(), utils.TerragoatURL, "master", 1)
+		if err != nil {
+			t.Fatalf("could not clone repository because %s", err)
+		}
+		defer func() {
+			_ = os.RemoveAll(cloneDir)
+		}()
+		_, err = os.Stat(filepath.Join(cloneDir, "terraform", "aws"))
+		assert.Nil(t, err)
+
+		gitService, err := NewGitService(cloneDir)
+		if err != nil {
+			t.Errorf("could not initialize git service becauses %s", err)
+		}
+		assert.Equal(t, "bridgecrewio", gitService.GetOrganization())
+		assert.Equal(t, "terragoat", gitService.GetRepoName())
+	})
+
+	t.Run("Fail on a non existing ref", func(t *testing.T) {
+		_, err := CloneRepository(context.Background(), utils.TerragoatURL, "no-such-ref-for-yor", 1)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,113 @@
+package gitservice
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+)
+
+// maxIndexedCommits caps the walk of the history, so the index of a long history doesn't delay tagging the first file
+var maxIndexedCommits = 1000
+
+// historyIndex maps the files under the scanned directory to the commits which changed them, computed with a single
+// walk of the latest maxIndexedCommits commits instead of a walk per blamed file. Files which were added by one of
+// these commits and never modified afterwards are blamed entirely to that commit without running a blame.
+type historyIndex struct {
+	once         sync.Once
+	changeCounts map[string]int
+	lastCommits  map[string]*object.Commit
+	// added holds whether the oldest indexed change of a file added it, so its history is entirely indexed
+	added map[string]bool
+	err   error
+}
+
+func newHistoryIndex() *historyIndex {
+	return &historyIndex{changeCounts: map[string]int{}, lastCommits: map[string]*object.Commit{}, added: map[string]bool{}}
+}
+
+func (g *GitService) buildHistoryIndex(head *object.Commit) {
+	index := g.historyIndex
+	prefix := filepath.ToSlash(g.scanPathFromRoot)
+	if prefix == "." {
+		prefix = ""
+	}
+	commits, err := g.repository.Log(&git.LogOptions{From: head.Hash})
+	if err != nil {
+		index.err = err
+		return
+	}
+	indexedCommits := 0
+	index.err = commits.ForEach(func(commit *object.Commit) error {
+		if indexedCommits == maxIndexedCommits {
+			return storer.ErrStop
+		}
+		indexedCommits++
+		tree, err := commit.Tree()
+		if err != nil {
+			return err
+		}
+		var parentTree *object.Tree
+		// the parent of the oldest commit of a shallow clone is missing, so all of its files are treated as added
+		if parent, err := commit.Parent(0); err == nil {
+			if parentTree, err = parent.Tree(); err != nil {
+				return err
+			}
+		}
+		changes, err := object.DiffTreeContext(g.context(), parentTree, tree)
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			name := change.To.Name
+			if name == "" {
+				name = change.From.Name
+			}
+			if prefix != "" && !strings.HasPrefix(name, prefix+"/") {
+				continue
+			}
+			index.changeCounts[name]++
+			index.lastCommits[name] = commit
+			// the history is walked from the newest commit, so the last change seen is the oldest one
+			index.added[name] = change.From.Name == ""
+		}
+		return nil
+	})
+	if index.err != nil {
+		logger.Warning(fmt.Sprintf("Failed to index the git history, blaming every file separately: %s", index.err))
+	}
+}
+
+// getSingleCommitBlame returns the blame of a file which was changed by a single commit, or false if it must be blamed
+func (g *GitService) getSingleCommitBlame(relativeFilePath string, head *object.Commit) (*git.BlameResult, bool) {
+	if g.historyIndex == nil {
+		return nil, false
+	}
+	g.historyIndex.once.Do(func() {
+		g.buildHistoryIndex(head)
+	})
+	index := g.historyIndex
+	path := filepath.ToSlash(relativeFilePath)
+	if index.err != nil || index.changeCounts[path] != 1 || !index.added[path] {
+		return nil, false
+	}
+	file, err := head.File(path)
+	if err != nil {
+		return nil, false
+	}
+	contents, err := file.Lines()
+	if err != nil {
+		return nil, false
+	}
+	commit := index.lastCommits[path]
+	blame := &git.BlameResult{Path: path, Rev: head.Hash, Lines: make([]*git.Line, 0, len(contents))}
+	for _, text := range contents {
+		blame.Lines = append(blame.Lines, &git.Line{Author: commit.Author.Email, Text: text, Date: commit.Author.When, Hash: commit.Hash})
+	}
+	return blame, true
+}
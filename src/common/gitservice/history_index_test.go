@@ -0,0 +1,170 @@
+package gitservice
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistoryIndex(t *testing.T) {
+	dir := t.TempDir()
+	repository, err := git.PlainInit(dir, false)
+	assert.Nil(t, err)
+	worktree, err := repository.Worktree()
+	assert.Nil(t, err)
+	commitFiles := func(message string, email string, files map[string]string) {
+		for name, content := range files {
+			assert.Nil(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0700))
+			assert.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+			_, err := worktree.Add(name)
+			assert.Nil(t, err)
+		}
+		_, err := worktree.Commit(message, &git.CommitOptions{Author: &object.Signature{Name: email, Email: email, When: time.Now()}})
+		assert.Nil(t, err)
+	}
+	commitFiles("first", "first@example.com", map[string]string{
+		"aws/unchanged.tf": "resource \"aws_s3_bucket\" \"a\" {\n}\n",
+		"aws/changed.tf":   "resource \"aws_s3_bucket\" \"b\" {\n}\n",
+		"other/main.tf":    "resource \"aws_s3_bucket\" \"c\" {\n}\n",
+	})
+	commitFiles("second", "second@example.com", map[string]string{
+		"aws/changed.tf": "resource \"aws_s3_bucket\" \"b\" {\n  acl = \"private\"\n}\n",
+	})
+
+	gitService, err := NewGitService(filepath.Join(dir, "aws"))
+	if err != nil && gitService == nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Blame files changed by a single commit from the index", func(t *testing.T) {
+		blame, err := gitService.GetFileBlame(filepath.Join(dir, "aws", "unchanged.tf"))
+		assert.Nil(t, err)
+		assert.Equal(t, 1, gitService.historyIndex.changeCounts["aws/unchanged.tf"])
+		assert.Equal(t, 2, len(blame.Lines))
+		for _, line := range blame.Lines {
+			assert.Equal(t, "first@example.com", line.Author)
+		}
+
+		head, _ := repository.Head()
+		headCommit, _ := repository.CommitObject(head.Hash())
+		expected, err := git.Blame(headCommit, "aws/unchanged.tf")
+		assert.Nil(t, err)
+		for i := range expected.Lines {
+			assert.Equal(t, *expected.Lines[i], *blame.Lines[i])
+		}
+	})
+
+	t.Run("Blame modified files", func(t *testing.T) {
+		blame, err := gitService.GetFileBlame(filepath.Join(dir, "aws", "changed.tf"))
+		assert.Nil(t, err)
+		assert.Equal(t, 2, gitService.historyIndex.changeCounts["aws/changed.tf"])
+		assert.Equal(t, "first@example.com", blame.Lines[0].Author)
+		assert.Equal(t, "second@example.com", blame.Lines[1].Author)
+	})
+
+	t.Run("Index only the scanned directory", func(t *testing.T) {
+		_, indexed := gitService.historyIndex.changeCounts["other/main.tf"]
+		assert.False(t, indexed)
+	})
+}
+
+func TestHistoryIndexCap(t *testing.T) {
+	defaultMaxIndexedCommits := maxIndexedCommits
+	maxIndexedCommits = 2
+	defer func() { maxIndexedCommits = defaultMaxIndexedCommits }()
+	dir := t.TempDir()
+	repository := commitLongHistory(t, dir, 3, []string{"old.tf"})
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "new.tf"), []byte("resource \"aws_s3_bucket\" \"new\" {\n}\n"), 0600))
+	worktree, err := repository.Worktree()
+	assert.Nil(t, err)
+	_, err = worktree.Add("new.tf")
+	assert.Nil(t, err)
+	_, err = worktree.Commit("add new.tf", &git.CommitOptions{Author: &object.Signature{Name: "new", Email: "new@example.com", When: time.Now()}})
+	assert.Nil(t, err)
+
+	gitService, err := NewGitService(dir)
+	if err != nil && gitService == nil {
+		t.Fatal(err)
+	}
+	head, _ := repository.Head()
+	headCommit, _ := repository.CommitObject(head.Hash())
+
+	t.Run("Blame files added before the indexed commits", func(t *testing.T) {
+		_, ok := gitService.getSingleCommitBlame("old.tf", headCommit)
+		assert.False(t, ok)
+		_, indexed := gitService.historyIndex.changeCounts["old.tf"]
+		assert.False(t, indexed)
+		blame, err := gitService.GetFileBlame(filepath.Join(dir, "old.tf"))
+		assert.Nil(t, err)
+		assert.Equal(t, "first@example.com", blame.Lines[0].Author)
+	})
+
+	t.Run("Blame files added by the indexed commits from the index", func(t *testing.T) {
+		blame, ok := gitService.getSingleCommitBlame("new.tf", headCommit)
+		assert.True(t, ok)
+		assert.Equal(t, "new@example.com", blame.Lines[0].Author)
+	})
+}
+
+// commitLongHistory commits the files, and then commitsNum commits which change only churn.tf
+func commitLongHistory(tb testing.TB, dir string, commitsNum int, files []string) *git.Repository {
+	repository, err := git.PlainInit(dir, false)
+	assert.Nil(tb, err)
+	worktree, err := repository.Worktree()
+	assert.Nil(tb, err)
+	commit := func(message string, email string, files map[string]string) {
+		for name, content := range files {
+			assert.Nil(tb, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+			_, err := worktree.Add(name)
+			assert.Nil(tb, err)
+		}
+		_, err := worktree.Commit(message, &git.CommitOptions{Author: &object.Signature{Name: email, Email: email, When: time.Now()}})
+		assert.Nil(tb, err)
+	}
+	initialFiles := map[string]string{}
+	for _, name := range files {
+		initialFiles[name] = fmt.Sprintf("resource \"aws_s3_bucket\" %q {\n}\n", name)
+	}
+	commit("first", "first@example.com", initialFiles)
+	churn := ""
+	for i := 0; i < commitsNum; i++ {
+		churn += fmt.Sprintf("# change %d\n", i)
+		commit(fmt.Sprintf("change %d", i), "churn@example.com", map[string]string{"churn.tf": churn})
+	}
+	return repository
+}
+
+// BenchmarkGetFileBlame compares blaming the files of a long history with and without the history index
+func BenchmarkGetFileBlame(b *testing.B) {
+	dir := b.TempDir()
+	var files []string
+	for i := 0; i < 20; i++ {
+		files = append(files, fmt.Sprintf("main%d.tf", i))
+	}
+	commitLongHistory(b, dir, 500, files)
+
+	for _, useIndex := range []bool{true, false} {
+		b.Run(fmt.Sprintf("history index %v", useIndex), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				gitService, err := NewGitService(dir)
+				if err != nil && gitService == nil {
+					b.Fatal(err)
+				}
+				if !useIndex {
+					gitService.historyIndex = nil
+				}
+				for _, file := range files {
+					if _, err := gitService.GetFileBlame(filepath.Join(dir, file)); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
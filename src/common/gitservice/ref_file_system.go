@@ -0,0 +1,217 @@
+package gitservice
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// RefFileSystem is a read only utils.FileSystem of the files of a repository at a ref (a branch, a tag or a commit),
+// which are read from the git object database rather than from the working tree, so a ref can be scanned while the
+// working tree is checked out at another one. Its paths are the paths the files would have if the ref was checked out.
+// The submodules and the symlinks of the ref aren't in the file system, as their files aren't in the object database.
+type RefFileSystem struct {
+	repoRootDir string
+	commit      *object.Commit
+	tree        *object.Tree
+	// the trees of go-git cache their entries without locking
+	lock sync.Mutex
+}
+
+// NewRefFileSystem returns the file system of the repository of dir at the ref
+func NewRefFileSystem(dir string, ref string) (*RefFileSystem, error) {
+	repoRootDir := FindRepositoryRoot(dir)
+	if repoRootDir == "" {
+		return nil, fmt.Errorf("%s is not in a git repository", dir)
+	}
+	repository, err := git.PlainOpen(repoRootDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the git repository %s: %s", repoRootDir, err)
+	}
+	commit, err := resolveCommit(repository, ref)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the tree of %s: %s", ref, err)
+	}
+	return &RefFileSystem{repoRootDir: repoRootDir, commit: commit, tree: tree}, nil
+}
+
+func resolveCommit(repository *git.Repository, ref string) (*object.Commit, error) {
+	hash, err := repository.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve the git ref %s: %s", ref, err)
+	}
+	commit, err := repository.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find commit %s of the git ref %s: %s", hash.String(), ref, err)
+	}
+	return commit, nil
+}
+
+// GetCommitHash returns the hash of the commit the ref was resolved to
+func (r *RefFileSystem) GetCommitHash() string {
+	return r.commit.Hash.String()
+}
+
+// treePath returns the slash separated path of the file in the tree, which is empty for the root of the repository
+func (r *RefFileSystem) treePath(name string) (string, bool) {
+	absPath, err := filepath.Abs(name)
+	if err != nil {
+		return "", false
+	}
+	relativePath, err := filepath.Rel(r.repoRootDir, absPath)
+	if err != nil || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	if relativePath == "." {
+		return "", true
+	}
+	return filepath.ToSlash(relativePath), true
+}
+
+func (r *RefFileSystem) ReadFile(name string) ([]byte, error) {
+	path, ok := r.treePath(name)
+	if !ok || path == "" {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry, err := r.tree.FindEntry(path)
+	if err != nil || !entry.Mode.IsFile() || entry.Mode == filemode.Symlink {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	file, err := r.tree.TreeEntryFile(entry)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+	contents, err := file.Contents()
+	if err != nil {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: err}
+	}
+	return []byte(contents), nil
+}
+
+func (r *RefFileSystem) WriteFile(name string, _ []byte) error {
+	return &fs.PathError{Op: "write", Path: name, Err: fs.ErrPermission}
+}
+
+func (r *RefFileSystem) CreateTemp(dir string, _ string) (string, error) {
+	return "", &fs.PathError{Op: "createtemp", Path: dir, Err: fs.ErrPermission}
+}
+
+func (r *RefFileSystem) Remove(name string) error {
+	return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrPermission}
+}
+
+func (r *RefFileSystem) Stat(name string) (fs.FileInfo, error) {
+	path, ok := r.treePath(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	if path == "" {
+		return refFileInfo{name: filepath.Base(r.repoRootDir), isDir: true, modTime: r.commit.Committer.When}, nil
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry, err := r.tree.FindEntry(path)
+	if err != nil {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	info, ok := r.entryInfo(path, entry)
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	return info, nil
+}
+
+func (r *RefFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	path, ok := r.treePath(name)
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	tree := r.tree
+	if path != "" {
+		var err error
+		if tree, err = r.tree.Tree(path); err != nil {
+			return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+		}
+	}
+	dirEntries := make([]fs.DirEntry, 0, len(tree.Entries))
+	for i := range tree.Entries {
+		entry := &tree.Entries[i]
+		if info, ok := r.entryInfo(strings.TrimPrefix(path+"/"+entry.Name, "/"), entry); ok {
+			dirEntries = append(dirEntries, fs.FileInfoToDirEntry(info))
+		}
+	}
+	// git sorts the directories of a tree as if their names ended with a slash, while listings are sorted by name
+	sort.Slice(dirEntries, func(i, j int) bool {
+		return dirEntries[i].Name() < dirEntries[j].Name()
+	})
+	return dirEntries, nil
+}
+
+// entryInfo returns the info of the entry of a file or a directory, or false for the entries of submodules and symlinks
+func (r *RefFileSystem) entryInfo(path string, entry *object.TreeEntry) (refFileInfo, bool) {
+	info := refFileInfo{name: entry.Name, modTime: r.commit.Committer.When}
+	switch {
+	case entry.Mode == filemode.Dir:
+		info.isDir = true
+	case entry.Mode.IsFile() && entry.Mode != filemode.Symlink:
+		size, err := r.tree.Size(path)
+		if err != nil {
+			return info, false
+		}
+		info.size = size
+	default:
+		return info, false
+	}
+	return info, true
+}
+
+type refFileInfo struct {
+	name    string
+	size    int64
+	isDir   bool
+	modTime time.Time
+}
+
+func (i refFileInfo) Name() string {
+	return i.name
+}
+
+func (i refFileInfo) Size() int64 {
+	return i.size
+}
+
+func (i refFileInfo) Mode() fs.FileMode {
+	if i.isDir {
+		return fs.ModeDir | 0500
+	}
+	return 0400
+}
+
+func (i refFileInfo) ModTime() time.Time {
+	return i.modTime
+}
+
+func (i refFileInfo) IsDir() bool {
+	return i.isDir
+}
+
+func (i refFileInfo) Sys() interface{} {
+	return nil
+}
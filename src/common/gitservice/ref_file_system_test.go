@@ -0,0 +1,88 @@
+package gitservice
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefFileSystem(t *testing.T) {
+	dir := t.TempDir()
+	repository, err := git.PlainInit(dir, false)
+	assert.Nil(t, err)
+	worktree, err := repository.Worktree()
+	assert.Nil(t, err)
+	commitFiles := func(message string, email string, files map[string]string) {
+		for name, content := range files {
+			assert.Nil(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0700))
+			assert.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+			_, err := worktree.Add(name)
+			assert.Nil(t, err)
+		}
+		_, err := worktree.Commit(message, &git.CommitOptions{Author: &object.Signature{Name: email, Email: email, When: time.Now()}})
+		assert.Nil(t, err)
+	}
+	first := "resource \"aws_s3_bucket\" \"a\" {\n}\n"
+	commitFiles("first", "first@example.com", map[string]string{"aws/main.tf": first, "README.md": "# infra\n"})
+	commitFiles("second", "second@example.com", map[string]string{
+		"aws/main.tf":     "resource \"aws_s3_bucket\" \"a\" {\n  acl = \"private\"\n}\n",
+		"aws/new/main.tf": "resource \"aws_s3_bucket\" \"b\" {\n}\n",
+	})
+	// the working tree isn't clean, which doesn't change the files of the ref
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "aws", "main.tf"), []byte("uncommitted"), 0600))
+
+	refFileSystem, err := NewRefFileSystem(filepath.Join(dir, "aws"), "HEAD~1")
+	assert.Nil(t, err)
+
+	t.Run("Read the files of the ref", func(t *testing.T) {
+		content, err := refFileSystem.ReadFile(filepath.Join(dir, "aws", "main.tf"))
+		assert.Nil(t, err)
+		assert.Equal(t, first, string(content))
+		_, err = refFileSystem.ReadFile(filepath.Join(dir, "aws", "new", "main.tf"))
+		assert.True(t, errors.Is(err, fs.ErrNotExist))
+		_, err = refFileSystem.ReadFile(filepath.Join(dir, "aws"))
+		assert.True(t, errors.Is(err, fs.ErrNotExist))
+	})
+
+	t.Run("List the directories of the ref", func(t *testing.T) {
+		entries, err := refFileSystem.ReadDir(dir)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(entries))
+		assert.Equal(t, "README.md", entries[0].Name())
+		assert.Equal(t, "aws", entries[1].Name())
+		assert.True(t, entries[1].IsDir())
+
+		info, err := refFileSystem.Stat(filepath.Join(dir, "aws", "main.tf"))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(len(first)), info.Size())
+		_, err = refFileSystem.Stat(filepath.Join(dir, ".."))
+		assert.True(t, errors.Is(err, fs.ErrNotExist))
+	})
+
+	t.Run("Reject writes", func(t *testing.T) {
+		err := refFileSystem.WriteFile(filepath.Join(dir, "aws", "main.tf"), []byte(first))
+		assert.True(t, errors.Is(err, fs.ErrPermission))
+	})
+
+	t.Run("Blame the files of the ref", func(t *testing.T) {
+		gitService, err := NewGitService(filepath.Join(dir, "aws"))
+		if err != nil && gitService == nil {
+			t.Fatal(err)
+		}
+		assert.Nil(t, gitService.SetRef("HEAD~1"))
+		blame, err := gitService.GetFileBlame(filepath.Join(dir, "aws", "main.tf"))
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(blame.Lines))
+		for _, line := range blame.Lines {
+			assert.Equal(t, "first@example.com", line.Author)
+		}
+		assert.NotNil(t, gitService.SetRef("unknown"))
+	})
+}
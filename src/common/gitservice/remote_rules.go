@@ -0,0 +1,87 @@
+package gitservice
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// RemoteRule parses the organization and the repository name of a git remote by a regular expression with the named
+// groups org and repo, which is matched against the normalized remote, see NormalizeRemoteURL. For example,
+// git\.example\.com/scm/(?P<org>[^/]+)/(?P<repo>[^/]+) parses the remotes of a Bitbucket server.
+type RemoteRule struct {
+	regex *regexp.Regexp
+}
+
+// DefaultRemoteRules parse the remotes of the self-hosted providers whose paths have more than the organization and the
+// repository name. The last rule takes the last segment of the path as the repository name and the rest as the
+// organization, which keeps the subgroups of GitLab in the organization.
+var DefaultRemoteRules = mustParseRemoteRules([]string{
+	// Azure DevOps, whose projects are in the organization: dev.azure.com/org/project/_git/repo
+	`^dev\.azure\.com/(?P<org>[^/]+/[^/]+)/_git/(?P<repo>[^/]+)$`,
+	`^ssh\.dev\.azure\.com/v3/(?P<org>[^/]+/[^/]+)/(?P<repo>[^/]+)$`,
+	// Bitbucket server and data center, which serve git under /scm, e.g. bitbucket.example.com/scm/project/repo
+	`^[^/]+(?:/.*)?/scm/(?P<org>[^/]+)/(?P<repo>[^/]+)$`,
+	`^[^/]+/(?P<org>.+)/(?P<repo>[^/]+)$`,
+})
+
+// ParseRemoteRules parses the rules, which must have the named groups org and repo
+func ParseRemoteRules(rules []string) ([]*RemoteRule, error) {
+	var remoteRules []*RemoteRule
+	for _, rule := range rules {
+		regex, err := regexp.Compile(rule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid git remote rule %s: %s", rule, err)
+		}
+		if regex.SubexpIndex("org") < 0 || regex.SubexpIndex("repo") < 0 {
+			return nil, fmt.Errorf("invalid git remote rule %s, expected the named groups (?P<org>...) and (?P<repo>...)", rule)
+		}
+		remoteRules = append(remoteRules, &RemoteRule{regex: regex})
+	}
+	return remoteRules, nil
+}
+
+func mustParseRemoteRules(rules []string) []*RemoteRule {
+	remoteRules, err := ParseRemoteRules(rules)
+	if err != nil {
+		panic(err)
+	}
+	return remoteRules
+}
+
+// NormalizeRemoteURL returns the host and the path of a git remote URL, without its scheme, credentials, port, .git
+// suffix and trailing slashes, e.g. ssh://git@bitbucket.example.com:7999/proj/repo.git is bitbucket.example.com/proj/repo
+func NormalizeRemoteURL(remoteURL string) (string, error) {
+	endpoint, err := transport.NewEndpoint(remoteURL)
+	if err != nil {
+		return "", err
+	}
+	path := strings.TrimSuffix(strings.Trim(endpoint.Path, "/"), ".git")
+	if unescapedPath, err := url.PathUnescape(path); err == nil {
+		path = unescapedPath
+	}
+	return strings.ToLower(endpoint.Host) + "/" + path, nil
+}
+
+// ParseRemote returns the organization and the repository name of a git remote by the first of the rules which matches
+// it, or by the first of DefaultRemoteRules if none does
+func ParseRemote(remoteURL string, rules []*RemoteRule) (string, string, error) {
+	remote, err := NormalizeRemoteURL(remoteURL)
+	if err != nil {
+		return "", "", err
+	}
+	for _, rule := range append(append([]*RemoteRule{}, rules...), DefaultRemoteRules...) {
+		match := rule.regex.FindStringSubmatch(remote)
+		if match == nil {
+			continue
+		}
+		org, repo := match[rule.regex.SubexpIndex("org")], match[rule.regex.SubexpIndex("repo")]
+		if org != "" && repo != "" {
+			return org, repo, nil
+		}
+	}
+	return "", "", fmt.Errorf("no git remote rule matches the remote %s", remote)
+}
@@ -0,0 +1,179 @@
+package grpcapi
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The messages of yor.proto are encoded by hand with protowire, so the server doesn't depend on generated code.
+// Clients generate their code from yor.proto as usual, as the encoding is the standard protobuf wire format.
+
+type Change int32
+
+const (
+	ChangeUnspecified Change = 0
+	ChangeNew         Change = 1
+	ChangeUpdated     Change = 2
+	ChangeImported    Change = 3
+)
+
+// TagRequest selects the directory to tag and how to tag it. Fields which aren't set take the options of the server.
+type TagRequest struct {
+	Directory string
+	TagGroups []string
+	Tags      []string
+	SkipTags  []string
+	Parsers   []string
+	SkipDirs  []string
+}
+
+// TagRecord is a tag added to, or updated in, a resource, like the tag records of the JSON report
+type TagRecord struct {
+	File         string
+	ResourceID   string
+	Key          string
+	OldValue     string
+	UpdatedValue string
+	YorTraceID   string
+	Source       string
+	Change       Change
+}
+
+// The messages implement the Marshal and Unmarshal methods which the default codec of gRPC uses instead of reflection,
+// so the server and its Go clients use the default codec, like the clients generated from yor.proto do.
+
+func (m *TagRequest) Reset() {
+	*m = TagRequest{}
+}
+
+func (m *TagRequest) String() string {
+	return fmt.Sprintf("%+v", *m)
+}
+
+func (*TagRequest) ProtoMessage() {}
+
+func (m *TagRecord) Reset() {
+	*m = TagRecord{}
+}
+
+func (m *TagRecord) String() string {
+	return fmt.Sprintf("%+v", *m)
+}
+
+func (*TagRecord) ProtoMessage() {}
+
+func (m *TagRequest) Marshal() ([]byte, error) {
+	var b []byte
+	b = appendString(b, 1, m.Directory)
+	for i, values := range [][]string{m.TagGroups, m.Tags, m.SkipTags, m.Parsers, m.SkipDirs} {
+		num := protowire.Number(i + 2)
+		for _, value := range values {
+			b = protowire.AppendTag(b, num, protowire.BytesType)
+			b = protowire.AppendString(b, value)
+		}
+	}
+	return b, nil
+}
+
+func (m *TagRequest) Unmarshal(b []byte) error {
+	return consumeFields(b, func(num protowire.Number, value string) {
+		switch num {
+		case 1:
+			m.Directory = value
+		case 2:
+			m.TagGroups = append(m.TagGroups, value)
+		case 3:
+			m.Tags = append(m.Tags, value)
+		case 4:
+			m.SkipTags = append(m.SkipTags, value)
+		case 5:
+			m.Parsers = append(m.Parsers, value)
+		case 6:
+			m.SkipDirs = append(m.SkipDirs, value)
+		}
+	}, nil)
+}
+
+func (m *TagRecord) Marshal() ([]byte, error) {
+	var b []byte
+	b = appendString(b, 1, m.File)
+	b = appendString(b, 2, m.ResourceID)
+	b = appendString(b, 3, m.Key)
+	b = appendString(b, 4, m.OldValue)
+	b = appendString(b, 5, m.UpdatedValue)
+	b = appendString(b, 6, m.YorTraceID)
+	b = appendString(b, 7, m.Source)
+	if m.Change != ChangeUnspecified {
+		b = protowire.AppendTag(b, 8, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(m.Change))
+	}
+	return b, nil
+}
+
+func (m *TagRecord) Unmarshal(b []byte) error {
+	return consumeFields(b, func(num protowire.Number, value string) {
+		switch num {
+		case 1:
+			m.File = value
+		case 2:
+			m.ResourceID = value
+		case 3:
+			m.Key = value
+		case 4:
+			m.OldValue = value
+		case 5:
+			m.UpdatedValue = value
+		case 6:
+			m.YorTraceID = value
+		case 7:
+			m.Source = value
+		}
+	}, func(num protowire.Number, value uint64) {
+		if num == 8 {
+			m.Change = Change(value)
+		}
+	})
+}
+
+// appendString appends a string field, unless it has the default (empty) value
+func appendString(b []byte, num protowire.Number, value string) []byte {
+	if value == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, value)
+}
+
+// consumeFields calls the given functions with the value of each string and varint field of the encoded message.
+// Fields of other types are skipped, so messages of newer versions of yor.proto can be read.
+func consumeFields(b []byte, stringField func(protowire.Number, string), varintField func(protowire.Number, uint64)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		switch {
+		case typ == protowire.BytesType && stringField != nil:
+			var value string
+			value, n = protowire.ConsumeString(b)
+			if n >= 0 {
+				stringField(num, value)
+			}
+		case typ == protowire.VarintType && varintField != nil:
+			var value uint64
+			value, n = protowire.ConsumeVarint(b)
+			if n >= 0 {
+				varintField(num, value)
+			}
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	return nil
+}
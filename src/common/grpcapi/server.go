@@ -0,0 +1,340 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bridgecrewio/yor/src/common/clioptions"
+	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/bridgecrewio/yor/src/common/reports"
+	"github.com/bridgecrewio/yor/src/common/runlock"
+	"github.com/bridgecrewio/yor/src/common/runner"
+	taggingUtils "github.com/bridgecrewio/yor/src/common/tagging/utils"
+	"github.com/bridgecrewio/yor/src/common/utils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	ServiceName             = "yor.v1.Yor"
+	TagDirectoryMethod      = "TagDirectory"
+	ValidateDirectoryMethod = "ValidateDirectory"
+	// TokenEnvKey holds the token the requests are authenticated with, which is kept out of the arguments so it doesn't
+	// show up in the process list
+	TokenEnvKey = "YOR_GRPC_TOKEN"
+)
+
+// Server serves the Yor service of yor.proto. The directories of the requests are tagged under the directory of its
+// options, or under the directory of the tenant of the request, with its options unless the tenant or the request set
+// them.
+type Server struct {
+	options       *clioptions.TagOptions
+	serverOptions ServerOptions
+}
+
+// ServerOptions secure the server. It serves on a loopback address only, unless it is served over TLS and authenticates
+// its clients, by a token, by the tokens of its tenants or by their certificates.
+type ServerOptions struct {
+	// Token is sent by the clients as a bearer token, in the authorization metadata of their requests
+	Token string
+	// Tenants authenticate with their own bearer tokens instead, and are confined to their directories
+	Tenants     []Tenant
+	TLSCertFile string
+	TLSKeyFile  string
+	// ClientCAFile requires the clients to present a certificate signed by one of its CAs (mutual TLS)
+	ClientCAFile string
+}
+
+// exitError ends the request in which yor failed, instead of the server
+type exitError int
+
+type yorServer interface {
+	TagDirectory(req *TagRequest, stream grpc.ServerStream) error
+	ValidateDirectory(req *TagRequest, stream grpc.ServerStream) error
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*yorServer)(nil),
+	Streams: []grpc.StreamDesc{
+		{StreamName: TagDirectoryMethod, Handler: tagDirectoryHandler, ServerStreams: true},
+		{StreamName: ValidateDirectoryMethod, Handler: validateDirectoryHandler, ServerStreams: true},
+	},
+	Metadata: "yor.proto",
+}
+
+func NewServer(options *clioptions.TagOptions, serverOptions ServerOptions) *Server {
+	return &Server{options: options, serverOptions: serverOptions}
+}
+
+// Serve serves the requests of the listener until the context is done, and lets the runs in progress finish. The
+// errors which end yor end the request they happen in instead, as the errors of a run in src/wasm do.
+func (s *Server) Serve(ctx context.Context, lis net.Listener) error {
+	grpcOptions, err := s.getGrpcOptions(lis.Addr())
+	if err != nil {
+		return err
+	}
+	logger.SetExitFunc(func(code int) {
+		panic(exitError(code))
+	})
+	defer logger.SetExitFunc(os.Exit)
+	grpcServer := grpc.NewServer(grpcOptions...)
+	grpcServer.RegisterService(&serviceDesc, s)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			grpcServer.GracefulStop()
+		case <-done:
+		}
+	}()
+	logger.Info(fmt.Sprintf("Serving %s on %s", ServiceName, lis.Addr()))
+	return grpcServer.Serve(lis)
+}
+
+// getGrpcOptions returns the credentials and the authentication of the server, and refuses to serve an address which
+// isn't a loopback address without them
+func (s *Server) getGrpcOptions(addr net.Addr) ([]grpc.ServerOption, error) {
+	var grpcOptions []grpc.ServerOption
+	isTLS := s.serverOptions.TLSCertFile != "" || s.serverOptions.TLSKeyFile != ""
+	if isTLS {
+		cert, err := tls.LoadX509KeyPair(s.serverOptions.TLSCertFile, s.serverOptions.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the TLS certificate of the server: %s", err)
+		}
+		tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+		if s.serverOptions.ClientCAFile != "" {
+			// #nosec G304 - file is from user
+			caBytes, err := os.ReadFile(s.serverOptions.ClientCAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read the client CAs %s: %s", s.serverOptions.ClientCAFile, err)
+			}
+			clientCAs := x509.NewCertPool()
+			if !clientCAs.AppendCertsFromPEM(caBytes) {
+				return nil, fmt.Errorf("no PEM certificates in the client CAs %s", s.serverOptions.ClientCAFile)
+			}
+			tlsConfig.ClientCAs = clientCAs
+			tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+		grpcOptions = append(grpcOptions, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	} else if s.serverOptions.ClientCAFile != "" {
+		return nil, fmt.Errorf("client certificates require the server to be served over TLS")
+	}
+	if s.serverOptions.Token != "" && len(s.serverOptions.Tenants) > 0 {
+		return nil, fmt.Errorf("the token of %s can't be used with tenants, which authenticate with their own tokens", TokenEnvKey)
+	}
+	isAuthenticated := s.serverOptions.Token != "" || len(s.serverOptions.Tenants) > 0 || s.serverOptions.ClientCAFile != ""
+	if !isLoopback(addr) && !(isTLS && isAuthenticated) {
+		return nil, fmt.Errorf("refusing to serve on %s, which isn't a loopback address, without TLS and the authentication of the clients by a token (%s), by tenants or by their certificates", addr, TokenEnvKey)
+	}
+	if s.serverOptions.Token != "" || len(s.serverOptions.Tenants) > 0 {
+		grpcOptions = append(grpcOptions, grpc.StreamInterceptor(s.authenticate))
+	}
+	return grpcOptions, nil
+}
+
+// authenticate checks the bearer token of the request, and passes the tenant of the token on to the handler
+func (s *Server) authenticate(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	for _, authorization := range md.Get("authorization") {
+		token := strings.TrimPrefix(authorization, "Bearer ")
+		if len(s.serverOptions.Tenants) > 0 {
+			if tenant := getTenant(s.serverOptions.Tenants, token); tenant != nil {
+				ctx := context.WithValue(stream.Context(), tenantKey{}, tenant)
+				return handler(srv, &tenantStream{ServerStream: stream, ctx: ctx})
+			}
+		} else if subtle.ConstantTimeCompare([]byte(token), []byte(s.serverOptions.Token)) == 1 {
+			return handler(srv, stream)
+		}
+	}
+	return status.Error(codes.Unauthenticated, "missing or invalid bearer token")
+}
+
+func isLoopback(addr net.Addr) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	return ok && tcpAddr.IP.IsLoopback()
+}
+
+func tagDirectoryHandler(srv interface{}, stream grpc.ServerStream) error {
+	req := new(TagRequest)
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+	return srv.(yorServer).TagDirectory(req, stream)
+}
+
+func validateDirectoryHandler(srv interface{}, stream grpc.ServerStream) error {
+	req := new(TagRequest)
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+	return srv.(yorServer).ValidateDirectory(req, stream)
+}
+
+func (s *Server) TagDirectory(req *TagRequest, stream grpc.ServerStream) error {
+	return s.run(req, false, stream)
+}
+
+func (s *Server) ValidateDirectory(req *TagRequest, stream grpc.ServerStream) error {
+	return s.run(req, true, stream)
+}
+
+func (s *Server) run(req *TagRequest, dryRun bool, stream grpc.ServerStream) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			code, ok := r.(exitError)
+			if !ok {
+				panic(r)
+			}
+			err = status.Error(codes.Internal, fmt.Sprintf("yor failed with exit code %d, the log of the server has its errors", int(code)))
+		}
+	}()
+	ctx := stream.Context()
+	tenant := tenantFromContext(ctx)
+	rootDir := s.getRootDirectory(tenant)
+	options, err := s.getRequestOptions(req, dryRun, rootDir, tenant)
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if !dryRun {
+		lock, err := runlock.Acquire(ctx, filepath.Join(options.Directory, runlock.DefaultLockFileName), 0)
+		if err != nil {
+			return status.Error(codes.FailedPrecondition, err.Error())
+		}
+		defer func() {
+			if err := lock.Release(); err != nil {
+				logger.Warning(err.Error())
+			}
+		}()
+	}
+	yorRunner := new(runner.Runner)
+	if err = yorRunner.InitWithContext(ctx, options); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	reportService, err := yorRunner.TagDirectory()
+	if reportService == nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	report := reportService.CreateReport()
+	for _, records := range []struct {
+		tagRecords []reports.TagRecord
+		change     Change
+	}{
+		{report.NewResourceTags, ChangeNew},
+		{report.UpdatedResourceTags, ChangeUpdated},
+		{report.ImportedResourceTags, ChangeImported},
+	} {
+		for _, tagRecord := range records.tagRecords {
+			if err := stream.SendMsg(toTagRecord(tagRecord, records.change, rootDir)); err != nil {
+				return err
+			}
+		}
+	}
+	if err != nil {
+		return status.Error(codes.Canceled, err.Error())
+	}
+	if _, ok := report.GetErrorCode(); ok {
+		firstError := report.Errors[0]
+		return status.Error(codes.Internal, fmt.Sprintf("the run failed with %d errors, the first is %s in %s: %s", len(report.Errors), firstError.Code, firstError.File, firstError.Message))
+	}
+	return nil
+}
+
+// getRootDirectory returns the directory the requests of the tenant are confined to, the root directory of the server
+// when it has no tenants
+func (s *Server) getRootDirectory(tenant *Tenant) string {
+	if tenant == nil {
+		return s.options.Directory
+	}
+	return filepath.Join(s.options.Directory, tenant.Directory)
+}
+
+// getRequestOptions returns the options of the server, with the options of the tenant, the directory of the request
+// under the root directory and the options the request sets
+func (s *Server) getRequestOptions(req *TagRequest, dryRun bool, rootDir string, tenant *Tenant) (*clioptions.TagOptions, error) {
+	options := *s.options
+	options.DryRun = dryRun
+	if tenant != nil {
+		tenant.applyTo(&options)
+	}
+	options.Directory = filepath.Join(rootDir, req.Directory)
+	if !isInDirectory(rootDir, options.Directory) {
+		return nil, fmt.Errorf("directory %s is outside of the root directory", req.Directory)
+	}
+	if info, err := os.Stat(options.Directory); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("directory %s doesn't exist", req.Directory)
+	}
+	// the directory may be a symlink, or under one, to a directory outside of the root directory
+	realRootDir, err := filepath.EvalSymlinks(rootDir)
+	if err != nil {
+		return nil, err
+	}
+	realDir, err := filepath.EvalSymlinks(options.Directory)
+	if err != nil || !isInDirectory(realRootDir, realDir) {
+		return nil, fmt.Errorf("directory %s is outside of the root directory", req.Directory)
+	}
+	if len(req.TagGroups) > 0 {
+		for _, tagGroup := range req.TagGroups {
+			if !utils.InSlice(taggingUtils.GetSupportedTagGroupsNames(), tagGroup) {
+				return nil, fmt.Errorf("tag group %s is not one of the supported tag groups. supported groups: %v", tagGroup, taggingUtils.GetSupportedTagGroupsNames())
+			}
+		}
+		options.TagGroups = req.TagGroups
+	}
+	if len(req.Tags) > 0 {
+		options.Tag = req.Tags
+	}
+	if len(req.SkipTags) > 0 {
+		options.SkipTags = req.SkipTags
+	}
+	if len(req.Parsers) > 0 {
+		if err := clioptions.ValidateParsers(req.Parsers); err != nil {
+			return nil, err
+		}
+		options.Parsers = req.Parsers
+	}
+	if len(req.SkipDirs) > 0 {
+		// skipped dirs are matched as prefixes of the files under the directory, which are joined to it by a slash
+		options.SkipDirs = make([]string, 0, len(req.SkipDirs))
+		for _, skipDir := range req.SkipDirs {
+			options.SkipDirs = append(options.SkipDirs, filepath.ToSlash(options.Directory)+"/"+strings.Trim(filepath.ToSlash(skipDir), "/"))
+		}
+	}
+	return &options, nil
+}
+
+// isInDirectory returns whether the path is the directory or under it
+func isInDirectory(dir string, path string) bool {
+	relativePath, err := filepath.Rel(dir, path)
+	return err == nil && relativePath != ".." && !strings.HasPrefix(relativePath, ".."+string(filepath.Separator))
+}
+
+// toTagRecord returns the message of the tag record, with its file relative to the root directory of the request, so
+// tenants don't learn where their directories are on the server
+func toTagRecord(tagRecord reports.TagRecord, change Change, rootDir string) *TagRecord {
+	file := tagRecord.File
+	if relativeFile, err := filepath.Rel(rootDir, file); err == nil {
+		file = filepath.ToSlash(relativeFile)
+	}
+	return &TagRecord{
+		File:         file,
+		ResourceID:   tagRecord.ResourceID,
+		Key:          tagRecord.TagKey,
+		OldValue:     tagRecord.OldValue,
+		UpdatedValue: tagRecord.UpdatedValue,
+		YorTraceID:   tagRecord.YorTraceID,
+		Source:       tagRecord.Source,
+		Change:       change,
+	}
+}
@@ -0,0 +1,246 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bridgecrewio/yor/src/common/clioptions"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestMessages(t *testing.T) {
+	t.Run("Round trip the messages", func(t *testing.T) {
+		req := &TagRequest{Directory: "team-a", TagGroups: []string{"git", "code2cloud"}, SkipDirs: []string{"modules"}}
+		var decodedReq TagRequest
+		b, err := req.Marshal()
+		assert.Nil(t, err)
+		assert.Nil(t, decodedReq.Unmarshal(b))
+		assert.Equal(t, *req, decodedReq)
+
+		record := &TagRecord{File: "team-a/main.tf", ResourceID: "aws_s3_bucket.a", Key: "yor_trace", UpdatedValue: "uuid", Change: ChangeNew}
+		var decodedRecord TagRecord
+		b, err = record.Marshal()
+		assert.Nil(t, err)
+		assert.Nil(t, decodedRecord.Unmarshal(b))
+		assert.Equal(t, *record, decodedRecord)
+	})
+
+	t.Run("Skip unknown fields", func(t *testing.T) {
+		// field 15 is a fixed 32 bit value, which TagRecord doesn't have
+		b, err := (&TagRecord{Key: "yor_trace"}).Marshal()
+		assert.Nil(t, err)
+		b = append(b, 0x7d, 1, 2, 3, 4)
+		var record TagRecord
+		assert.Nil(t, record.Unmarshal(b))
+		assert.Equal(t, TagRecord{Key: "yor_trace"}, record)
+		record.Reset()
+		assert.NotNil(t, record.Unmarshal([]byte{0x0a, 5, 'a'}))
+	})
+}
+
+func TestServer(t *testing.T) {
+	root := t.TempDir()
+	assert.Nil(t, os.MkdirAll(filepath.Join(root, "team-a"), 0700))
+	src := "resource \"aws_s3_bucket\" \"a\" {\n}\n"
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "team-a", "main.tf"), []byte(src), 0600))
+	lis, err := net.Listen("tcp", "localhost:0")
+	assert.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := NewServer(&clioptions.TagOptions{Directory: root, TagGroups: []string{"code2cloud"}, Parsers: []string{"Terraform"}}, ServerOptions{})
+	go func() {
+		_ = server.Serve(ctx, lis)
+	}()
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	assert.Nil(t, err)
+	defer conn.Close()
+
+	t.Run("Stream the records of a validation", func(t *testing.T) {
+		records, err := call(ctx, conn, ValidateDirectoryMethod, &TagRequest{Directory: "team-a"})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(records))
+		assert.Equal(t, "team-a/main.tf", records[0].File)
+		assert.Equal(t, "aws_s3_bucket.a", records[0].ResourceID)
+		assert.Equal(t, "yor_trace", records[0].Key)
+		assert.Equal(t, ChangeNew, records[0].Change)
+		content, _ := os.ReadFile(filepath.Join(root, "team-a", "main.tf"))
+		assert.Equal(t, src, string(content))
+	})
+
+	t.Run("Stream the records of tagging", func(t *testing.T) {
+		records, err := call(ctx, conn, TagDirectoryMethod, &TagRequest{Directory: "team-a"})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(records))
+		content, _ := os.ReadFile(filepath.Join(root, "team-a", "main.tf"))
+		assert.Contains(t, string(content), records[0].UpdatedValue)
+
+		records, err = call(ctx, conn, ValidateDirectoryMethod, &TagRequest{Directory: "team-a"})
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(records))
+	})
+
+	t.Run("Reject invalid requests", func(t *testing.T) {
+		outside := t.TempDir()
+		assert.Nil(t, os.Symlink(outside, filepath.Join(root, "outside")))
+		for _, req := range []*TagRequest{
+			{Directory: "../"},
+			{Directory: "team-b"},
+			{Directory: "outside"},
+			{Directory: "team-a", TagGroups: []string{"unknown"}},
+			{Directory: "team-a", Parsers: []string{"kubernetes"}},
+		} {
+			_, err := call(ctx, conn, ValidateDirectoryMethod, req)
+			assert.Equal(t, codes.InvalidArgument, status.Code(err), req.Directory)
+		}
+	})
+}
+
+func TestServerSecurity(t *testing.T) {
+	root := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(root, "main.tf"), []byte("resource \"aws_s3_bucket\" \"a\" {\n}\n"), 0600))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	serve := func(t *testing.T, options *clioptions.TagOptions, serverOptions ServerOptions) *grpc.ClientConn {
+		lis, err := net.Listen("tcp", "localhost:0")
+		assert.Nil(t, err)
+		go func() {
+			_ = NewServer(options, serverOptions).Serve(ctx, lis)
+		}()
+		conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+		assert.Nil(t, err)
+		return conn
+	}
+
+	t.Run("Authenticate the requests by their token", func(t *testing.T) {
+		conn := serve(t, &clioptions.TagOptions{Directory: root, TagGroups: []string{"code2cloud"}, Parsers: []string{"Terraform"}}, ServerOptions{Token: "secret"})
+		defer conn.Close()
+		_, err := call(ctx, conn, ValidateDirectoryMethod, &TagRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		_, err = call(metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer other"), conn, ValidateDirectoryMethod, &TagRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		records, err := call(metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer secret"), conn, ValidateDirectoryMethod, &TagRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(records))
+	})
+
+	t.Run("Refuse to serve other hosts without authentication", func(t *testing.T) {
+		lis, err := net.Listen("tcp", ":0")
+		assert.Nil(t, err)
+		defer lis.Close()
+		err = NewServer(&clioptions.TagOptions{Directory: root}, ServerOptions{Token: "secret"}).Serve(ctx, lis)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Fail only the request which ends yor", func(t *testing.T) {
+		// the root directory isn't a git repository, which ends yor tag
+		conn := serve(t, &clioptions.TagOptions{Directory: root, TagGroups: []string{"git"}, Parsers: []string{"Terraform"}}, ServerOptions{})
+		defer conn.Close()
+		_, err := call(ctx, conn, ValidateDirectoryMethod, &TagRequest{})
+		assert.Equal(t, codes.Internal, status.Code(err))
+		_, err = call(ctx, conn, ValidateDirectoryMethod, &TagRequest{TagGroups: []string{"code2cloud"}})
+		assert.Nil(t, err)
+	})
+}
+
+func TestServerTenants(t *testing.T) {
+	root := t.TempDir()
+	for _, team := range []string{"team-a", "team-b"} {
+		assert.Nil(t, os.MkdirAll(filepath.Join(root, team), 0700))
+		assert.Nil(t, os.WriteFile(filepath.Join(root, team, "main.tf"), []byte("resource \"aws_s3_bucket\" \"a\" {\n}\n"), 0600))
+	}
+	tenants := []Tenant{
+		{Name: "team-a", TokenSHA256: tokenSHA256("token-a"), Directory: "team-a"},
+		{Name: "team-b", TokenSHA256: tokenSHA256("token-b"), Directory: "team-b", TagPrefix: "team_b_"},
+	}
+	lis, err := net.Listen("tcp", "localhost:0")
+	assert.Nil(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := NewServer(&clioptions.TagOptions{Directory: root, TagGroups: []string{"code2cloud"}, Parsers: []string{"Terraform"}}, ServerOptions{Tenants: tenants})
+	go func() {
+		_ = server.Serve(ctx, lis)
+	}()
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	assert.Nil(t, err)
+	defer conn.Close()
+	tenantCtx := func(token string) context.Context {
+		return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	}
+
+	t.Run("Tag the directory of the tenant with its options", func(t *testing.T) {
+		records, err := call(tenantCtx("token-a"), conn, ValidateDirectoryMethod, &TagRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(records))
+		assert.Equal(t, "main.tf", records[0].File)
+		assert.Equal(t, "yor_trace", records[0].Key)
+
+		records, err = call(tenantCtx("token-b"), conn, ValidateDirectoryMethod, &TagRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(records))
+		assert.Equal(t, "team_b_yor_trace", records[0].Key)
+	})
+
+	t.Run("Confine the tenants to their directories", func(t *testing.T) {
+		_, err := call(tenantCtx("token-a"), conn, ValidateDirectoryMethod, &TagRequest{Directory: "../team-b"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		_, err = call(tenantCtx("token-b"), conn, TagDirectoryMethod, &TagRequest{Directory: "../team-a"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		content, _ := os.ReadFile(filepath.Join(root, "team-a", "main.tf"))
+		assert.NotContains(t, string(content), "yor_trace")
+	})
+
+	t.Run("Reject the tokens of no tenant", func(t *testing.T) {
+		_, err := call(ctx, conn, ValidateDirectoryMethod, &TagRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		_, err = call(tenantCtx(tokenSHA256("token-a")), conn, ValidateDirectoryMethod, &TagRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("Refuse a token of the environment with tenants", func(t *testing.T) {
+		lis, err := net.Listen("tcp", "localhost:0")
+		assert.Nil(t, err)
+		defer lis.Close()
+		err = NewServer(&clioptions.TagOptions{Directory: root}, ServerOptions{Token: "secret", Tenants: tenants}).Serve(ctx, lis)
+		assert.NotNil(t, err)
+	})
+}
+
+func tokenSHA256(token string) string {
+	digest := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(digest[:])
+}
+
+func call(ctx context.Context, conn *grpc.ClientConn, method string, req *TagRequest) ([]TagRecord, error) {
+	stream, err := conn.NewStream(ctx, &grpc.StreamDesc{ServerStreams: true}, "/"+ServiceName+"/"+method)
+	if err != nil {
+		return nil, err
+	}
+	if err = stream.SendMsg(req); err != nil {
+		return nil, err
+	}
+	if err = stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	var records []TagRecord
+	for {
+		var record TagRecord
+		err = stream.RecvMsg(&record)
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, record)
+	}
+}
@@ -0,0 +1,141 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bridgecrewio/yor/src/common/clioptions"
+	taggingUtils "github.com/bridgecrewio/yor/src/common/tagging/utils"
+	"github.com/bridgecrewio/yor/src/common/utils"
+	"google.golang.org/grpc"
+	"gopkg.in/yaml.v2"
+)
+
+// Tenant is a client of a server shared by several teams. It authenticates with its own token, its requests are confined
+// to its directory under the root directory of the server, and its options replace the options of the server, while the
+// requests can still set their own.
+type Tenant struct {
+	Name string `yaml:"name"`
+	// TokenSHA256 is the hex encoded SHA-256 digest of the token of the tenant, so the file doesn't hold the tokens
+	TokenSHA256 string `yaml:"token_sha256"`
+	// Directory is relative to the root directory of the server
+	Directory string   `yaml:"directory"`
+	TagGroups []string `yaml:"tag_groups,omitempty"`
+	Tags      []string `yaml:"tags,omitempty"`
+	SkipTags  []string `yaml:"skip_tags,omitempty"`
+	Parsers   []string `yaml:"parsers,omitempty"`
+	TagPrefix string   `yaml:"tag_prefix,omitempty"`
+}
+
+type tenantsConfig struct {
+	Tenants []Tenant `yaml:"tenants"`
+}
+
+type tenantKey struct{}
+
+// tenantStream carries the tenant which authenticated the stream in its context
+type tenantStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tenantStream) Context() context.Context {
+	return s.ctx
+}
+
+// LoadTenants reads the tenants file of the server
+func LoadTenants(tenantsPath string) ([]Tenant, error) {
+	// #nosec G304 - file is from user
+	tenantsBytes, err := os.ReadFile(tenantsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the tenants file %s: %s", tenantsPath, err)
+	}
+	config := &tenantsConfig{}
+	if err = yaml.UnmarshalStrict(tenantsBytes, config); err != nil {
+		return nil, fmt.Errorf("failed to parse the tenants file %s: %s", tenantsPath, err)
+	}
+	if len(config.Tenants) == 0 {
+		return nil, fmt.Errorf("the tenants file %s has no tenants", tenantsPath)
+	}
+	names := make(map[string]bool)
+	tokenDigests := make(map[string]bool)
+	for _, tenant := range config.Tenants {
+		if err = tenant.validate(); err != nil {
+			return nil, fmt.Errorf("the tenants file %s has an invalid tenant %s: %s", tenantsPath, tenant.Name, err)
+		}
+		if names[tenant.Name] {
+			return nil, fmt.Errorf("the tenants file %s has more than one tenant named %s", tenantsPath, tenant.Name)
+		}
+		if tokenDigests[tenant.TokenSHA256] {
+			return nil, fmt.Errorf("the tenants file %s has more than one tenant with the token of %s", tenantsPath, tenant.Name)
+		}
+		names[tenant.Name] = true
+		tokenDigests[tenant.TokenSHA256] = true
+	}
+	return config.Tenants, nil
+}
+
+func (t *Tenant) validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("the tenant has no name")
+	}
+	if digest, err := hex.DecodeString(t.TokenSHA256); err != nil || len(digest) != sha256.Size {
+		return fmt.Errorf("token_sha256 isn't a hex encoded SHA-256 digest")
+	}
+	if t.Directory == "" || filepath.IsAbs(t.Directory) || !isInDirectory(".", filepath.Clean(t.Directory)) {
+		return fmt.Errorf("directory %s isn't a directory under the root directory of the server", t.Directory)
+	}
+	for _, tagGroup := range t.TagGroups {
+		if !utils.InSlice(taggingUtils.GetSupportedTagGroupsNames(), tagGroup) {
+			return fmt.Errorf("tag group %s is not one of the supported tag groups. supported groups: %v", tagGroup, taggingUtils.GetSupportedTagGroupsNames())
+		}
+	}
+	if len(t.Parsers) > 0 {
+		return clioptions.ValidateParsers(t.Parsers)
+	}
+	return nil
+}
+
+// getTenant returns the tenant of the token. All the tenants are compared, so the time it takes doesn't tell which of
+// them matched.
+func getTenant(tenants []Tenant, token string) *Tenant {
+	tokenDigest := sha256.Sum256([]byte(token))
+	var matchedTenant *Tenant
+	for i := range tenants {
+		tenantDigest, _ := hex.DecodeString(tenants[i].TokenSHA256)
+		if subtle.ConstantTimeCompare(tokenDigest[:], tenantDigest) == 1 {
+			matchedTenant = &tenants[i]
+		}
+	}
+	return matchedTenant
+}
+
+// tenantFromContext returns the tenant which authenticated the request, or nil when the server has no tenants
+func tenantFromContext(ctx context.Context) *Tenant {
+	tenant, _ := ctx.Value(tenantKey{}).(*Tenant)
+	return tenant
+}
+
+// applyTo replaces the options of the server with the options the tenant sets
+func (t *Tenant) applyTo(options *clioptions.TagOptions) {
+	if len(t.TagGroups) > 0 {
+		options.TagGroups = t.TagGroups
+	}
+	if len(t.Tags) > 0 {
+		options.Tag = t.Tags
+	}
+	if len(t.SkipTags) > 0 {
+		options.SkipTags = t.SkipTags
+	}
+	if len(t.Parsers) > 0 {
+		options.Parsers = t.Parsers
+	}
+	if t.TagPrefix != "" {
+		options.TagPrefix = t.TagPrefix
+	}
+}
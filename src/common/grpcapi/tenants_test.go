@@ -0,0 +1,47 @@
+package grpcapi
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadTenants(t *testing.T) {
+	tokenDigest := tokenSHA256("token-a")
+	writeTenants := func(t *testing.T, content string) string {
+		tenantsPath := filepath.Join(t.TempDir(), "tenants.yaml")
+		assert.Nil(t, os.WriteFile(tenantsPath, []byte(content), 0600))
+		return tenantsPath
+	}
+
+	t.Run("Load the tenants", func(t *testing.T) {
+		tenants, err := LoadTenants(writeTenants(t, `tenants:
+  - name: team-a
+    token_sha256: `+tokenDigest+`
+    directory: teams/a
+    tag_groups: [code2cloud]
+    parsers: [Terraform]
+`))
+		assert.Nil(t, err)
+		assert.Equal(t, []Tenant{{Name: "team-a", TokenSHA256: tokenDigest, Directory: "teams/a", TagGroups: []string{"code2cloud"}, Parsers: []string{"Terraform"}}}, tenants)
+	})
+
+	t.Run("Reject invalid tenants", func(t *testing.T) {
+		for _, content := range []string{
+			"tenants: []\n",
+			"tenants:\n  - name: team-a\n    token_sha256: " + tokenDigest + "\n    directory: a\n    token: plain\n",
+			"tenants:\n  - name: team-a\n    token_sha256: token-a\n    directory: a\n",
+			"tenants:\n  - name: team-a\n    token_sha256: " + tokenDigest + "\n    directory: ../a\n",
+			"tenants:\n  - name: team-a\n    token_sha256: " + tokenDigest + "\n    directory: /srv/a\n",
+			"tenants:\n  - name: team-a\n    token_sha256: " + tokenDigest + "\n    directory: a\n    tag_groups: [unknown]\n",
+			"tenants:\n  - name: team-a\n    token_sha256: " + tokenDigest + "\n    directory: a\n  - name: team-b\n    token_sha256: " + tokenDigest + "\n    directory: b\n",
+		} {
+			_, err := LoadTenants(writeTenants(t, content))
+			assert.NotNil(t, err, content)
+		}
+		_, err := LoadTenants(filepath.Join(t.TempDir(), "missing.yaml"))
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,76 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"github.com/bridgecrewio/yor/src/common/reports"
+)
+
+// Event is the point of the run at which a hook command is run
+type Event string
+
+const (
+	// PreScan runs once the files to scan are listed, before any of them is tagged. A failing pre-scan hook aborts the run.
+	PreScan Event = "pre-scan"
+	// PostFileWrite runs after each file which yor wrote tags to, e.g. to format it
+	PostFileWrite Event = "post-file-write"
+	// PostRun runs once the run finished, with its report, e.g. to notify about it
+	PostRun Event = "post-run"
+)
+
+// Context is the JSON written to the stdin of the hook commands
+type Context struct {
+	Event     Event           `json:"event"`
+	Directory string          `json:"directory"`
+	DryRun    bool            `json:"dryRun"`
+	Files     []string        `json:"files,omitempty"`
+	File      string          `json:"file,omitempty"`
+	Resources []string        `json:"resources,omitempty"`
+	Report    *reports.Report `json:"report,omitempty"`
+}
+
+// Hooks holds the shell command of each event. Events without a command, and a nil Hooks, run nothing.
+type Hooks struct {
+	commands map[Event]string
+}
+
+func New(preScan string, postFileWrite string, postRun string) *Hooks {
+	return &Hooks{commands: map[Event]string{
+		PreScan:       preScan,
+		PostFileWrite: postFileWrite,
+		PostRun:       postRun,
+	}}
+}
+
+// Run runs the command of the context's event in the working directory of yor, where the paths of the context are
+// resolved. The command's output goes to stderr, so it doesn't mix with the report of yor.
+func (h *Hooks) Run(ctx context.Context, hookContext Context) error {
+	if h == nil || h.commands[hookContext.Event] == "" {
+		return nil
+	}
+	command := h.commands[hookContext.Event]
+	input, err := json.Marshal(hookContext)
+	if err != nil {
+		return fmt.Errorf("failed to create the context of the %s hook: %s", hookContext.Event, err)
+	}
+	shell, shellArg := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, shellArg = "cmd", "/C"
+	}
+	// #nosec G204 - the hook commands are given by the user who runs yor
+	cmd := exec.CommandContext(ctx, shell, shellArg, command)
+	cmd.Env = append(os.Environ(), "YOR_HOOK_EVENT="+string(hookContext.Event))
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err = cmd.Run(); err != nil {
+		return fmt.Errorf("the %s hook %#v failed: %s", hookContext.Event, command, err)
+	}
+	return nil
+}
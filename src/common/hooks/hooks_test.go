@@ -0,0 +1,50 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bridgecrewio/yor/src/common/reports"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHooks(t *testing.T) {
+	t.Run("the context is written to the stdin of the hook", func(t *testing.T) {
+		outputPath := filepath.Join(t.TempDir(), "context.json")
+		hooks := New("", fmt.Sprintf("cat > %s", outputPath), "")
+		err := hooks.Run(context.Background(), Context{Event: PostFileWrite, Directory: "tests", File: "tests/main.tf", Resources: []string{"aws_s3_bucket.bucket"}})
+		assert.Nil(t, err)
+
+		contextBytes, err := os.ReadFile(outputPath)
+		assert.Nil(t, err)
+		var hookContext Context
+		assert.Nil(t, json.Unmarshal(contextBytes, &hookContext))
+		assert.Equal(t, Context{Event: PostFileWrite, Directory: "tests", File: "tests/main.tf", Resources: []string{"aws_s3_bucket.bucket"}}, hookContext)
+	})
+
+	t.Run("the event is set in the environment of the hook", func(t *testing.T) {
+		outputPath := filepath.Join(t.TempDir(), "event")
+		hooks := New("", "", fmt.Sprintf("echo $YOR_HOOK_EVENT > %s", outputPath))
+		assert.Nil(t, hooks.Run(context.Background(), Context{Event: PostRun, Report: &reports.Report{}}))
+		eventBytes, err := os.ReadFile(outputPath)
+		assert.Nil(t, err)
+		assert.Equal(t, "post-run\n", string(eventBytes))
+	})
+
+	t.Run("failing hooks return an error", func(t *testing.T) {
+		hooks := New("exit 3", "", "")
+		err := hooks.Run(context.Background(), Context{Event: PreScan})
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "the pre-scan hook \"exit 3\" failed")
+	})
+
+	t.Run("events without a command run nothing", func(t *testing.T) {
+		assert.Nil(t, New("exit 1", "", "").Run(context.Background(), Context{Event: PostRun}))
+		var hooks *Hooks
+		assert.Nil(t, hooks.Run(context.Background(), Context{Event: PreScan}))
+	})
+}
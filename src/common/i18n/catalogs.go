@@ -0,0 +1,227 @@
+package i18n
+
+// catalogs holds the messages of the output of yor by language, keyed by their english text
+var catalogs = map[string]map[string]string{
+	"de": {
+		"Yor Findings Summary":                             "Yor-Ergebniszusammenfassung",
+		"Scanned Resources":                                "Gescannte Ressourcen",
+		"New Resources Traced":                             "Neu verfolgte Ressourcen",
+		"Updated Resources":                                "Aktualisierte Ressourcen",
+		"Imported Resources":                               "Importierte Ressourcen",
+		"Imported Resources Traced":                        "Importierte verfolgte Ressourcen",
+		"Updated Resource Traces":                          "Aktualisierte Ressourcen-Traces",
+		"Required Tag Violations":                          "Verstöße gegen Pflicht-Tags",
+		"Suppressed by Baseline":                           "Durch Baseline unterdrückt",
+		"The run was interrupted, the results are partial": "Der Lauf wurde unterbrochen, die Ergebnisse sind unvollständig",
+		"%d findings of the baseline are not shown":        "%d Befunde der Baseline werden nicht angezeigt",
+		"Nested Stacks":                                    "Verschachtelte Stacks",
+		"API Definitions":                                  "API-Definitionen",
+		"Skipped Files":                                    "Übersprungene Dateien",
+		"Skipped Resources":                                "Übersprungene Ressourcen",
+		"Errors":                                           "Fehler",
+		"Code":                                             "Code",
+		"File":                                             "Datei",
+		"Message":                                          "Meldung",
+		"Resource":                                         "Ressource",
+		"Tag Key":                                          "Tag-Schlüssel",
+		"Tag Value":                                        "Tag-Wert",
+		"Old Value":                                        "Alter Wert",
+		"Updated Value":                                    "Neuer Wert",
+		"Reason":                                           "Grund",
+		"Severity":                                         "Schweregrad",
+		"Source":                                           "Quelle",
+		"Template":                                         "Vorlage",
+		"Template URL":                                     "Vorlagen-URL",
+		"Template File":                                    "Vorlagendatei",
+		"Definition File":                                  "Definitionsdatei",
+		"Yor ID":                                           "Yor-ID",
+		"Group":                                            "Gruppe",
+		"Description":                                      "Beschreibung",
+		"Time":                                             "Zeit",
+		"Directory":                                        "Verzeichnis",
+		"Scanned":                                          "Gescannt",
+		"New":                                              "Neu",
+		"Updated":                                          "Aktualisiert",
+		"Coverage":                                         "Abdeckung",
+		"dry run":                                          "Probelauf",
+
+		// yor check-plan
+		"Resources to Create": "Zu erstellende Ressourcen",
+		"Taggable Resources":  "Taggbare Ressourcen",
+
+		// yor benchmark
+		"Benchmark of %s: %d files, %d resources, %d workers":                             "Benchmark von %s: %d Dateien, %d Ressourcen, %d Worker",
+		"The parse, tags, git and write durations are summed over the concurrent workers": "Die Dauern von Parsen, Tags, Git und Schreiben sind über die parallelen Worker summiert",
+		"Phase":     "Phase",
+		"Duration":  "Dauer",
+		"Framework": "Framework",
+		"Files":     "Dateien",
+		"Resources": "Ressourcen",
+		"Walk":      "Verzeichnissuche",
+		"Parse":     "Parsen",
+		"Tags":      "Tags",
+		"Git":       "Git",
+		"Write":     "Schreiben",
+		"Report":    "Bericht",
+		"Total":     "Gesamt",
+
+		// yor doctor
+		"Traced Resources":   "Verfolgte Ressourcen",
+		"Duplicate Yor IDs":  "Doppelte Yor-IDs",
+		"Repaired Resources": "Reparierte Ressourcen",
+
+		// yor migrate-tags
+		"Renamed Tags":     "Umbenannte Tags",
+		"Tags Not Renamed": "Nicht umbenannte Tags",
+		"Old Key":          "Alter Schlüssel",
+		"New Key":          "Neuer Schlüssel",
+	},
+	"es": {
+		"Yor Findings Summary":                             "Resumen de resultados de Yor",
+		"Scanned Resources":                                "Recursos analizados",
+		"New Resources Traced":                             "Nuevos recursos rastreados",
+		"Updated Resources":                                "Recursos actualizados",
+		"Imported Resources":                               "Recursos importados",
+		"Imported Resources Traced":                        "Recursos importados rastreados",
+		"Updated Resource Traces":                          "Rastros de recursos actualizados",
+		"Required Tag Violations":                          "Infracciones de etiquetas obligatorias",
+		"Suppressed by Baseline":                           "Suprimidos por la línea base",
+		"The run was interrupted, the results are partial": "La ejecución se interrumpió, los resultados son parciales",
+		"%d findings of the baseline are not shown":        "No se muestran %d hallazgos de la línea base",
+		"Nested Stacks":                                    "Stacks anidados",
+		"API Definitions":                                  "Definiciones de API",
+		"Skipped Files":                                    "Archivos omitidos",
+		"Skipped Resources":                                "Recursos omitidos",
+		"Errors":                                           "Errores",
+		"Code":                                             "Código",
+		"File":                                             "Archivo",
+		"Message":                                          "Mensaje",
+		"Resource":                                         "Recurso",
+		"Tag Key":                                          "Clave de etiqueta",
+		"Tag Value":                                        "Valor de etiqueta",
+		"Old Value":                                        "Valor anterior",
+		"Updated Value":                                    "Valor actualizado",
+		"Reason":                                           "Motivo",
+		"Severity":                                         "Gravedad",
+		"Source":                                           "Origen",
+		"Template":                                         "Plantilla",
+		"Template URL":                                     "URL de la plantilla",
+		"Template File":                                    "Archivo de la plantilla",
+		"Definition File":                                  "Archivo de la definición",
+		"Yor ID":                                           "ID de Yor",
+		"Group":                                            "Grupo",
+		"Description":                                      "Descripción",
+		"Time":                                             "Hora",
+		"Directory":                                        "Directorio",
+		"Scanned":                                          "Analizados",
+		"New":                                              "Nuevos",
+		"Updated":                                          "Actualizados",
+		"Coverage":                                         "Cobertura",
+		"dry run":                                          "ejecución de prueba",
+
+		// yor check-plan
+		"Resources to Create": "Recursos a crear",
+		"Taggable Resources":  "Recursos etiquetables",
+
+		// yor benchmark
+		"Benchmark of %s: %d files, %d resources, %d workers":                             "Benchmark de %s: %d archivos, %d recursos, %d workers",
+		"The parse, tags, git and write durations are summed over the concurrent workers": "Las duraciones de análisis, etiquetas, git y escritura se suman entre los workers concurrentes",
+		"Phase":     "Fase",
+		"Duration":  "Duración",
+		"Framework": "Framework",
+		"Files":     "Archivos",
+		"Resources": "Recursos",
+		"Walk":      "Recorrido",
+		"Parse":     "Análisis",
+		"Tags":      "Etiquetas",
+		"Git":       "Git",
+		"Write":     "Escritura",
+		"Report":    "Informe",
+		"Total":     "Total",
+
+		// yor doctor
+		"Traced Resources":   "Recursos rastreados",
+		"Duplicate Yor IDs":  "IDs de Yor duplicados",
+		"Repaired Resources": "Recursos reparados",
+
+		// yor migrate-tags
+		"Renamed Tags":     "Etiquetas renombradas",
+		"Tags Not Renamed": "Etiquetas no renombradas",
+		"Old Key":          "Clave anterior",
+		"New Key":          "Clave nueva",
+	},
+	"fr": {
+		"Yor Findings Summary":                             "Résumé des résultats de Yor",
+		"Scanned Resources":                                "Ressources analysées",
+		"New Resources Traced":                             "Nouvelles ressources tracées",
+		"Updated Resources":                                "Ressources mises à jour",
+		"Imported Resources":                               "Ressources importées",
+		"Imported Resources Traced":                        "Ressources importées tracées",
+		"Updated Resource Traces":                          "Traces de ressources mises à jour",
+		"Required Tag Violations":                          "Violations des tags obligatoires",
+		"Suppressed by Baseline":                           "Masqués par la référence",
+		"The run was interrupted, the results are partial": "L'exécution a été interrompue, les résultats sont partiels",
+		"%d findings of the baseline are not shown":        "%d résultats de la référence ne sont pas affichés",
+		"Nested Stacks":                                    "Stacks imbriquées",
+		"API Definitions":                                  "Définitions d'API",
+		"Skipped Files":                                    "Fichiers ignorés",
+		"Skipped Resources":                                "Ressources ignorées",
+		"Errors":                                           "Erreurs",
+		"Code":                                             "Code",
+		"File":                                             "Fichier",
+		"Message":                                          "Message",
+		"Resource":                                         "Ressource",
+		"Tag Key":                                          "Clé du tag",
+		"Tag Value":                                        "Valeur du tag",
+		"Old Value":                                        "Ancienne valeur",
+		"Updated Value":                                    "Nouvelle valeur",
+		"Reason":                                           "Raison",
+		"Severity":                                         "Gravité",
+		"Source":                                           "Source",
+		"Template":                                         "Modèle",
+		"Template URL":                                     "URL du modèle",
+		"Template File":                                    "Fichier du modèle",
+		"Definition File":                                  "Fichier de la définition",
+		"Yor ID":                                           "ID Yor",
+		"Group":                                            "Groupe",
+		"Description":                                      "Description",
+		"Time":                                             "Heure",
+		"Directory":                                        "Répertoire",
+		"Scanned":                                          "Analysées",
+		"New":                                              "Nouvelles",
+		"Updated":                                          "Mises à jour",
+		"Coverage":                                         "Couverture",
+		"dry run":                                          "simulation",
+
+		// yor check-plan
+		"Resources to Create": "Ressources à créer",
+		"Taggable Resources":  "Ressources taggables",
+
+		// yor benchmark
+		"Benchmark of %s: %d files, %d resources, %d workers":                             "Benchmark de %s : %d fichiers, %d ressources, %d workers",
+		"The parse, tags, git and write durations are summed over the concurrent workers": "Les durées d'analyse, des tags, de git et d'écriture sont additionnées sur les workers concurrents",
+		"Phase":     "Phase",
+		"Duration":  "Durée",
+		"Framework": "Framework",
+		"Files":     "Fichiers",
+		"Resources": "Ressources",
+		"Walk":      "Parcours",
+		"Parse":     "Analyse",
+		"Tags":      "Tags",
+		"Git":       "Git",
+		"Write":     "Écriture",
+		"Report":    "Rapport",
+		"Total":     "Total",
+
+		// yor doctor
+		"Traced Resources":   "Ressources tracées",
+		"Duplicate Yor IDs":  "ID Yor en double",
+		"Repaired Resources": "Ressources réparées",
+
+		// yor migrate-tags
+		"Renamed Tags":     "Tags renommés",
+		"Tags Not Renamed": "Tags non renommés",
+		"Old Key":          "Ancienne clé",
+		"New Key":          "Nouvelle clé",
+	},
+}
@@ -0,0 +1,93 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// LangEnvKey sets the locale of the output of yor, unless it is set with --lang. Without either, the locale of the
+// environment (LC_ALL, LC_MESSAGES or LANG) is used if yor has its messages.
+const LangEnvKey = "YOR_LANG"
+
+const DefaultLocale = "en"
+
+var locale = DefaultLocale
+
+// SetLocale sets the locale of the messages, i.e. de or fr_FR.UTF-8. An empty lang selects the locale of the
+// environment, which falls back to english if yor doesn't have its messages, while an explicit lang must be supported.
+func SetLocale(lang string) error {
+	if lang == "" {
+		locale = DetectLocale()
+		return nil
+	}
+	normalized := normalize(lang)
+	if !IsSupported(normalized) {
+		return fmt.Errorf("unsupported language %s, expected one of %s", lang, strings.Join(SupportedLocales(), ", "))
+	}
+	locale = normalized
+	return nil
+}
+
+func GetLocale() string {
+	return locale
+}
+
+// DetectLocale returns the supported locale set by the environment, or the default locale
+func DetectLocale() string {
+	for _, envKey := range []string{LangEnvKey, "LC_ALL", "LC_MESSAGES", "LANG"} {
+		if lang := os.Getenv(envKey); lang != "" {
+			if normalized := normalize(lang); IsSupported(normalized) {
+				return normalized
+			}
+			// like gettext, the first variable which is set decides, even if its locale isn't supported
+			break
+		}
+	}
+	return DefaultLocale
+}
+
+func IsSupported(lang string) bool {
+	if normalize(lang) == DefaultLocale {
+		return true
+	}
+	_, ok := catalogs[normalize(lang)]
+	return ok
+}
+
+func SupportedLocales() []string {
+	locales := []string{DefaultLocale}
+	for lang := range catalogs {
+		locales = append(locales, lang)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// normalize returns the language of the locale, i.e. pt for pt_BR.UTF-8 or pt-BR, as the catalogs are by language
+func normalize(lang string) string {
+	lang = strings.ToLower(lang)
+	if i := strings.IndexAny(lang, "_-.@"); i >= 0 {
+		lang = lang[:i]
+	}
+	return lang
+}
+
+// T returns the message in the language of the locale. Messages are identified by their english text, which is
+// returned if the catalog of the locale doesn't have them.
+func T(message string) string {
+	if translated, ok := catalogs[locale][message]; ok {
+		return translated
+	}
+	return message
+}
+
+// Headers returns the headers of a table in the language of the locale
+func Headers(headers ...string) []string {
+	translated := make([]string, len(headers))
+	for i, header := range headers {
+		translated[i] = T(header)
+	}
+	return translated
+}
@@ -0,0 +1,53 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocale(t *testing.T) {
+	defer func() {
+		_ = SetLocale(DefaultLocale)
+	}()
+
+	t.Run("Translate the messages of the locale", func(t *testing.T) {
+		assert.Nil(t, SetLocale("de_DE.UTF-8"))
+		assert.Equal(t, "de", GetLocale())
+		assert.Equal(t, "Datei", T("File"))
+		assert.Equal(t, []string{"Datei", "Ressource"}, Headers("File", "Resource"))
+		assert.Equal(t, "not translated", T("not translated"))
+
+		assert.Nil(t, SetLocale("EN"))
+		assert.Equal(t, "File", T("File"))
+		assert.NotNil(t, SetLocale("xx"))
+		assert.Equal(t, "en", GetLocale())
+	})
+
+	t.Run("Select the locale of the environment", func(t *testing.T) {
+		t.Setenv(LangEnvKey, "")
+		t.Setenv("LC_ALL", "")
+		t.Setenv("LC_MESSAGES", "fr_FR.UTF-8")
+		t.Setenv("LANG", "es_ES.UTF-8")
+		assert.Nil(t, SetLocale(""))
+		assert.Equal(t, "fr", GetLocale())
+
+		t.Setenv(LangEnvKey, "es")
+		assert.Equal(t, "es", DetectLocale())
+		// the first variable which is set decides, even if yor doesn't have its messages
+		t.Setenv(LangEnvKey, "")
+		t.Setenv("LC_ALL", "C.UTF-8")
+		assert.Equal(t, DefaultLocale, DetectLocale())
+	})
+
+	t.Run("Catalogs have the same messages", func(t *testing.T) {
+		for lang, catalog := range catalogs {
+			for otherLang, otherCatalog := range catalogs {
+				for message := range catalog {
+					_, ok := otherCatalog[message]
+					assert.True(t, ok, "%s of %s is missing from %s", message, lang, otherLang)
+				}
+			}
+		}
+	})
+}
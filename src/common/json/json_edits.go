@@ -0,0 +1,96 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/bridgecrewio/yor/src/common/structure"
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+	"github.com/bridgecrewio/yor/src/common/utils"
+)
+
+// GetTagsEdits returns the edits which update and add the tags of the object node, indented like the rest of the file.
+// escapeValue encodes the tag values the way the file format expects them.
+func GetTagsEdits(src string, node *Node, tagsAttributeName string, diff *structure.TagDiff, escapeValue func(string) string) []utils.TextEdit {
+	edits := make([]utils.TextEdit, 0)
+	if len(node.Members) == 0 {
+		return edits
+	}
+	firstMember := node.Members[0]
+	separator := src[firstMember.KeyEnd:firstMember.Value.Start]
+	// the tags are added sorted by key, so the file doesn't change between runs computing the same tags
+	addedTags := make([]tags.ITag, len(diff.Added))
+	copy(addedTags, diff.Added)
+	sort.Slice(addedTags, func(i, j int) bool {
+		return addedTags[i].GetKey() < addedTags[j].GetKey()
+	})
+	addedEntries := make([]string, 0, len(addedTags))
+	for _, tag := range addedTags {
+		addedEntries = append(addedEntries, EncodeString(tag.GetKey())+separator+EncodeString(escapeValue(tag.GetValue())))
+	}
+
+	tagsMember := node.GetMember(tagsAttributeName)
+	if tagsMember == nil {
+		if !isOnOwnLine(src, firstMember.KeyStart) {
+			text := ", " + EncodeString(tagsAttributeName) + separator + "{" + strings.Join(addedEntries, ", ") + "}"
+			return append(edits, utils.TextEdit{Start: lastMemberEnd(node), End: lastMemberEnd(node), Text: text})
+		}
+		indent := LineIndent(src, firstMember.KeyStart)
+		text := ",\n" + indent + EncodeString(tagsAttributeName) + separator + getMultilineObject(addedEntries, indent, getIndentUnit(src, node))
+		return append(edits, utils.TextEdit{Start: lastMemberEnd(node), End: lastMemberEnd(node), Text: text})
+	}
+
+	tagsNode := tagsMember.Value
+	for _, tag := range diff.Updated {
+		if member := tagsNode.GetMember(tag.Key); member != nil {
+			edits = append(edits, utils.TextEdit{Start: member.Value.Start, End: member.Value.End, Text: EncodeString(escapeValue(tag.NewValue))})
+		}
+	}
+	if len(addedEntries) == 0 {
+		return edits
+	}
+	if len(tagsNode.Members) == 0 {
+		indent := LineIndent(src, tagsMember.KeyStart)
+		return append(edits, utils.TextEdit{Start: tagsNode.Start, End: tagsNode.End, Text: getMultilineObject(addedEntries, indent, getIndentUnit(src, node))})
+	}
+	entriesSeparator := ", "
+	if firstTag := tagsNode.Members[0]; isOnOwnLine(src, firstTag.KeyStart) {
+		entriesSeparator = ",\n" + LineIndent(src, firstTag.KeyStart)
+	}
+	text := entriesSeparator + strings.Join(addedEntries, entriesSeparator)
+	return append(edits, utils.TextEdit{Start: lastMemberEnd(tagsNode), End: lastMemberEnd(tagsNode), Text: text})
+}
+
+// EncodeString returns the JSON string literal of str, without escaping HTML characters
+func EncodeString(str string) string {
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(str)
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
+
+func getMultilineObject(entries []string, indent string, indentUnit string) string {
+	return "{\n" + indent + indentUnit + strings.Join(entries, ",\n"+indent+indentUnit) + "\n" + indent + "}"
+}
+
+func lastMemberEnd(node *Node) int {
+	return node.Members[len(node.Members)-1].Value.End
+}
+
+// getIndentUnit returns the indentation of the node's keys relative to the node itself
+func getIndentUnit(src string, node *Node) string {
+	nodeIndent := LineIndent(src, node.Start)
+	memberIndent := LineIndent(src, node.Members[0].KeyStart)
+	if len(memberIndent) > len(nodeIndent) && strings.HasPrefix(memberIndent, nodeIndent) {
+		return memberIndent[len(nodeIndent):]
+	}
+	return "  "
+}
+
+func isOnOwnLine(src string, index int) bool {
+	lineStart := strings.LastIndex(src[:index], "\n") + 1
+	return strings.TrimLeft(src[lineStart:index], " \t") == ""
+}
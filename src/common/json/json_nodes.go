@@ -0,0 +1,236 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+type NodeKind int
+
+const (
+	ObjectNode NodeKind = iota + 1
+	ArrayNode
+	StringNode
+	LiteralNode
+)
+
+// Node is a JSON value together with its position in the source, so it can be edited in place without re-encoding
+// (and reformatting) the whole document. Start is the index of the first char of the value and End the index after
+// its last char.
+type Node struct {
+	Kind     NodeKind
+	Start    int
+	End      int
+	Members  []*Member
+	Elements []*Node
+	Value    interface{}
+}
+
+// Member is a key-value pair of an object node, in the order it appears in the source
+type Member struct {
+	Key      string
+	KeyStart int
+	KeyEnd   int
+	Value    *Node
+}
+
+// Get returns the value of the member with the given key, or nil if the node isn't an object or has no such member
+func (n *Node) Get(key string) *Node {
+	if member := n.GetMember(key); member != nil {
+		return member.Value
+	}
+	return nil
+}
+
+func (n *Node) GetMember(key string) *Member {
+	if n == nil || n.Kind != ObjectNode {
+		return nil
+	}
+	for _, member := range n.Members {
+		if member.Key == key {
+			return member
+		}
+	}
+	return nil
+}
+
+// GetString returns the value of a string node, or an empty string for any other node
+func (n *Node) GetString() string {
+	if n == nil || n.Kind != StringNode {
+		return ""
+	}
+	return n.Value.(string)
+}
+
+type nodeParser struct {
+	src   string
+	index int
+}
+
+// ParseJSONNodes parses a JSON document into nodes which keep their positions in it
+func ParseJSONNodes(src string) (*Node, error) {
+	p := &nodeParser{src: src}
+	// templates saved by some editors start with a byte order mark
+	if strings.HasPrefix(src, "\ufeff") {
+		p.index = len("\ufeff")
+	}
+	node, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	p.skipWhitespace()
+	if p.index < len(p.src) {
+		return nil, p.errorf("unexpected trailing content")
+	}
+	return node, nil
+}
+
+// LineAt returns the 1-based line number of the char index in the source
+func LineAt(src string, index int) int {
+	return strings.Count(src[:index], "\n") + 1
+}
+
+// LineIndent returns the whitespace the line containing the char index starts with
+func LineIndent(src string, index int) string {
+	lineStart := strings.LastIndex(src[:index], "\n") + 1
+	lineEnd := lineStart
+	for lineEnd < len(src) && (src[lineEnd] == ' ' || src[lineEnd] == '\t') {
+		lineEnd++
+	}
+	return src[lineStart:lineEnd]
+}
+
+func (p *nodeParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid json at line %d: %s", LineAt(p.src, p.index), fmt.Sprintf(format, args...))
+}
+
+func (p *nodeParser) skipWhitespace() {
+	for p.index < len(p.src) && strings.IndexByte(" \t\r\n", p.src[p.index]) >= 0 {
+		p.index++
+	}
+}
+
+func (p *nodeParser) parseValue() (*Node, error) {
+	p.skipWhitespace()
+	if p.index >= len(p.src) {
+		return nil, p.errorf("unexpected end of input")
+	}
+	switch p.src[p.index] {
+	case '{':
+		return p.parseObject()
+	case '[':
+		return p.parseArray()
+	case '"':
+		return p.parseString()
+	default:
+		return p.parseLiteral()
+	}
+}
+
+func (p *nodeParser) parseObject() (*Node, error) {
+	node := &Node{Kind: ObjectNode, Start: p.index, Members: make([]*Member, 0)}
+	p.index++
+	p.skipWhitespace()
+	if p.index < len(p.src) && p.src[p.index] == '}' {
+		p.index++
+		node.End = p.index
+		return node, nil
+	}
+	for {
+		p.skipWhitespace()
+		if p.index >= len(p.src) || p.src[p.index] != '"' {
+			return nil, p.errorf("expected an object key")
+		}
+		key, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		p.skipWhitespace()
+		if p.index >= len(p.src) || p.src[p.index] != ':' {
+			return nil, p.errorf("expected ':' after object key")
+		}
+		p.index++
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		node.Members = append(node.Members, &Member{Key: key.GetString(), KeyStart: key.Start, KeyEnd: key.End, Value: value})
+		p.skipWhitespace()
+		if p.index >= len(p.src) {
+			return nil, p.errorf("unexpected end of input")
+		}
+		if p.src[p.index] == '}' {
+			p.index++
+			node.End = p.index
+			return node, nil
+		}
+		if p.src[p.index] != ',' {
+			return nil, p.errorf("expected ',' or '}' in object")
+		}
+		p.index++
+	}
+}
+
+func (p *nodeParser) parseArray() (*Node, error) {
+	node := &Node{Kind: ArrayNode, Start: p.index, Elements: make([]*Node, 0)}
+	p.index++
+	p.skipWhitespace()
+	if p.index < len(p.src) && p.src[p.index] == ']' {
+		p.index++
+		node.End = p.index
+		return node, nil
+	}
+	for {
+		element, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		node.Elements = append(node.Elements, element)
+		p.skipWhitespace()
+		if p.index >= len(p.src) {
+			return nil, p.errorf("unexpected end of input")
+		}
+		if p.src[p.index] == ']' {
+			p.index++
+			node.End = p.index
+			return node, nil
+		}
+		if p.src[p.index] != ',' {
+			return nil, p.errorf("expected ',' or ']' in array")
+		}
+		p.index++
+	}
+}
+
+func (p *nodeParser) parseString() (*Node, error) {
+	start := p.index
+	p.index++
+	for p.index < len(p.src) && p.src[p.index] != '"' {
+		if p.src[p.index] == '\\' {
+			p.index++
+		}
+		p.index++
+	}
+	if p.index >= len(p.src) {
+		return nil, p.errorf("unterminated string")
+	}
+	p.index++
+	var value string
+	if err := json.Unmarshal([]byte(p.src[start:p.index]), &value); err != nil {
+		return nil, p.errorf("%s", err)
+	}
+	return &Node{Kind: StringNode, Start: start, End: p.index, Value: value}, nil
+}
+
+func (p *nodeParser) parseLiteral() (*Node, error) {
+	start := p.index
+	for p.index < len(p.src) && strings.IndexByte(",]} \t\r\n", p.src[p.index]) < 0 {
+		p.index++
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(p.src[start:p.index]), &value); err != nil {
+		return nil, p.errorf("%s", err)
+	}
+	return &Node{Kind: LiteralNode, Start: start, End: p.index, Value: value}, nil
+}
@@ -0,0 +1,37 @@
+package json
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseJSONNodes(t *testing.T) {
+	t.Run("Keep the positions of the values", func(t *testing.T) {
+		src := "{\n  \"a\": [1, \"two\", {\"b\": null}],\n  \"c\" : \"d\\\"e\"\n}"
+		root, err := ParseJSONNodes(src)
+		assert.Nil(t, err)
+		assert.Equal(t, ObjectNode, root.Kind)
+		assert.Equal(t, 2, len(root.Members))
+
+		a := root.Get("a")
+		assert.Equal(t, ArrayNode, a.Kind)
+		assert.Equal(t, "[1, \"two\", {\"b\": null}]", src[a.Start:a.End])
+		assert.Equal(t, float64(1), a.Elements[0].Value)
+		assert.Equal(t, "two", a.Elements[1].GetString())
+		assert.Nil(t, a.Elements[2].Get("b").Value)
+
+		c := root.GetMember("c")
+		assert.Equal(t, "d\"e", c.Value.GetString())
+		assert.Equal(t, " : ", src[c.KeyEnd:c.Value.Start])
+		assert.Equal(t, 3, LineAt(src, c.KeyStart))
+		assert.Equal(t, "  ", LineIndent(src, c.Value.Start))
+	})
+
+	t.Run("Fail on malformed json", func(t *testing.T) {
+		for _, src := range []string{"{\"a\": }", "{\"a\": 1", "[1 2]", "{} {}"} {
+			_, err := ParseJSONNodes(src)
+			assert.NotNil(t, err, src)
+		}
+	})
+}
@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"math"
-	"os"
 	"regexp"
 	"sort"
 	"strings"
@@ -18,8 +17,7 @@ import (
 // WriteJSONFile updates the content of `readFilePath` with updated tags from `blocks` and writes it to `writeFilePath`
 func WriteJSONFile(readFilePath string, blocks []structure.IBlock, writeFilePath string, fileBracketsPairs map[int]BracketPair) error {
 
-	// #nosec G304
-	originFileSrc, err := os.ReadFile(readFilePath)
+	originFileSrc, err := utils.ReadFile(readFilePath)
 	if err != nil {
 		return fmt.Errorf("failed to read file %s because %s", readFilePath, err)
 	}
@@ -63,7 +61,7 @@ func WriteJSONFile(readFilePath string, blocks []structure.IBlock, writeFilePath
 		textToWrite += originFileStr[lastReplacedIndex:]
 	}
 
-	err = os.WriteFile(writeFilePath, []byte(textToWrite), 0600)
+	err = utils.WriteFile(writeFilePath, utils.MatchLineEndings(originFileSrc, []byte(textToWrite)))
 	return err
 }
 
@@ -277,8 +275,7 @@ func getJSONStr(jsonData interface{}) string {
 // MapResourcesLineJSON maps the lines of all resources in a file and return it with the brackets mapping
 func MapResourcesLineJSON(filePath string, resourceNames []string) (map[string]*structure.Lines, map[int]BracketPair) {
 	resourceToLines := make(map[string]*structure.Lines)
-	// #nosec G304
-	file, err := os.ReadFile(filePath)
+	file, err := utils.ReadFile(filePath)
 	if err != nil {
 		logger.Warning(fmt.Sprintf("failed to read file %s", filePath))
 		return nil, nil
@@ -15,6 +15,7 @@ type loggingService struct {
 	tempWriter *os.File
 	disabled   bool
 	muteLock   sync.Mutex
+	exit       func(code int)
 }
 
 type LogLevel int
@@ -46,7 +47,7 @@ var Logger loggingService
 
 func init() {
 	log.SetFlags(log.Ldate | log.Ltime)
-	Logger = loggingService{logLevel: WARNING, stdout: os.Stdout, stderr: os.Stderr}
+	Logger = loggingService{logLevel: WARNING, stdout: os.Stdout, stderr: os.Stderr, exit: os.Exit}
 
 	val, ok := os.LookupEnv("LOG_LEVEL")
 	if ok {
@@ -55,6 +56,10 @@ func init() {
 }
 
 func (e *loggingService) log(logLevel LogLevel, args ...string) {
+	e.logWithExitCode(logLevel, 1, args...)
+}
+
+func (e *loggingService) logWithExitCode(logLevel LogLevel, exitCode int, args ...string) {
 	if logLevel >= e.logLevel {
 		var strArgs string
 		if len(args) == 2 {
@@ -78,7 +83,7 @@ func (e *loggingService) log(logLevel LogLevel, args ...string) {
 			} else {
 				log.Println(strArgs)
 			}
-			os.Exit(1)
+			e.exit(exitCode)
 		}
 	}
 }
@@ -99,6 +104,17 @@ func Error(args ...string) {
 	Logger.log(ERROR, args...)
 }
 
+// ErrorWithExitCode logs the error and exits with the given code, so callers of yor can tell failures apart
+func ErrorWithExitCode(exitCode int, args ...string) {
+	Logger.logWithExitCode(ERROR, exitCode, args...)
+}
+
+// SetExitFunc replaces os.Exit as the way the errors end yor, for where yor runs in a process it must not end, i.e. the
+// browser (see src/wasm)
+func SetExitFunc(exit func(code int)) {
+	Logger.exit = exit
+}
+
 func (e *loggingService) SetLogLevel(inputLogLevel string) {
 	logLevel := WARNING
 	switch strings.ToUpper(inputLogLevel) {
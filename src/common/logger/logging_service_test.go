@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"os"
 	"regexp"
 	"strings"
 	"testing"
@@ -81,4 +82,15 @@ func TestLogger(t *testing.T) {
 		assert.True(t, strings.Contains(result, debugMsg))
 		Logger.SetLogLevel("WARNING")
 	})
+
+	t.Run("Test errors end yor with the exit function", func(t *testing.T) {
+		var exitCode int
+		SetExitFunc(func(code int) {
+			exitCode = code
+		})
+		defer SetExitFunc(os.Exit)
+		logs := utils.CaptureOutput(func() { ErrorWithExitCode(3, "Test error") })
+		assert.True(t, strings.Contains(logs, "[ERROR] Test error"))
+		assert.Equal(t, 3, exitCode)
+	})
 }
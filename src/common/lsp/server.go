@@ -0,0 +1,318 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bridgecrewio/yor/src/common"
+	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/bridgecrewio/yor/src/common/runner"
+	"github.com/bridgecrewio/yor/src/common/structure"
+)
+
+const (
+	InitializeMethod   = "initialize"
+	InitializedMethod  = "initialized"
+	ShutdownMethod     = "shutdown"
+	ExitMethod         = "exit"
+	ComputeTagsMethod  = "yor/computeTags"
+	ExplainTagsMethod  = "yor/explainTags"
+	contentLengthField = "Content-Length"
+	jsonRPCVersion     = "2.0"
+	methodNotFoundCode = -32601
+	invalidParamsCode  = -32602
+	parseErrorCode     = -32700
+)
+
+// Server is a minimal JSON-RPC 2.0 server which uses the LSP base protocol (Content-Length framed messages).
+// It exposes the tags yor would apply for a file, so editors can show them inline without modifying the file.
+type Server struct {
+	runner       *runner.Runner
+	reader       *bufio.Reader
+	writer       io.Writer
+	writeLock    sync.Mutex
+	shutdown     bool
+	descriptions map[string]string
+}
+
+type Request struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+}
+
+type Response struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id"`
+	Result  interface{}      `json:"result"`
+	Error   *ResponseError   `json:"error,omitempty"`
+}
+
+type ResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// BufferParams identifies the file to compute the tags for. When Text is set, it is used instead of the file's
+// content on disk, which allows computing the tags of unsaved buffers.
+type BufferParams struct {
+	Path string  `json:"path"`
+	Text *string `json:"text,omitempty"`
+}
+
+type ExplainParams struct {
+	BufferParams
+	Line int `json:"line"`
+}
+
+type TagProposal struct {
+	Key           string `json:"key"`
+	Value         string `json:"value"`
+	PreviousValue string `json:"previousValue,omitempty"`
+	Status        string `json:"status"`
+	Description   string `json:"description,omitempty"`
+}
+
+type ResourceTags struct {
+	ResourceID   string            `json:"resourceId"`
+	ResourceType string            `json:"resourceType"`
+	File         string            `json:"file"`
+	Lines        structure.Lines   `json:"lines"`
+	Taggable     bool              `json:"taggable"`
+	Tags         []TagProposal     `json:"tags"`
+	ExistingTags map[string]string `json:"existingTags"`
+}
+
+type ComputeTagsResult struct {
+	Resources []ResourceTags `json:"resources"`
+}
+
+type ExplainTagsResult struct {
+	Resource *ResourceTags `json:"resource"`
+}
+
+func NewServer(yorRunner *runner.Runner, in io.Reader, out io.Writer) *Server {
+	descriptions := make(map[string]string)
+	for _, tagGroup := range yorRunner.TagGroups {
+		for _, tag := range tagGroup.GetTags() {
+			descriptions[tag.GetKey()] = tag.GetDescription()
+		}
+	}
+	return &Server{
+		runner:       yorRunner,
+		reader:       bufio.NewReader(in),
+		writer:       out,
+		descriptions: descriptions,
+	}
+}
+
+// Serve reads requests until the exit notification is received or the input is closed
+func (s *Server) Serve() error {
+	for {
+		body, err := s.readMessage()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		var req Request
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.writeError(nil, parseErrorCode, fmt.Sprintf("failed to parse request: %s", err))
+			continue
+		}
+		if req.Method == ExitMethod {
+			return nil
+		}
+		s.handle(&req)
+	}
+}
+
+func (s *Server) handle(req *Request) {
+	logger.Debug(fmt.Sprintf("Handling %v request", req.Method))
+	switch req.Method {
+	case InitializeMethod:
+		s.writeResult(req.ID, map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"experimental": map[string]bool{ComputeTagsMethod: true, ExplainTagsMethod: true},
+			},
+			"serverInfo": map[string]string{"name": "yor", "version": common.Version},
+		})
+	case InitializedMethod:
+		return
+	case ShutdownMethod:
+		s.shutdown = true
+		s.writeResult(req.ID, nil)
+	case ComputeTagsMethod:
+		var params BufferParams
+		if err := json.Unmarshal(req.Params, &params); err != nil || params.Path == "" {
+			s.writeError(req.ID, invalidParamsCode, "expected params with a non empty path")
+			return
+		}
+		resources, err := s.ComputeTags(params)
+		if err != nil {
+			s.writeError(req.ID, invalidParamsCode, err.Error())
+			return
+		}
+		s.writeResult(req.ID, ComputeTagsResult{Resources: resources})
+	case ExplainTagsMethod:
+		var params ExplainParams
+		if err := json.Unmarshal(req.Params, &params); err != nil || params.Path == "" {
+			s.writeError(req.ID, invalidParamsCode, "expected params with a non empty path and a line")
+			return
+		}
+		resource, err := s.ExplainTags(params)
+		if err != nil {
+			s.writeError(req.ID, invalidParamsCode, err.Error())
+			return
+		}
+		s.writeResult(req.ID, ExplainTagsResult{Resource: resource})
+	default:
+		if req.ID != nil {
+			s.writeError(req.ID, methodNotFoundCode, fmt.Sprintf("method %v is not supported", req.Method))
+		}
+	}
+}
+
+// ComputeTags returns the tags yor would set for each resource in the buffer
+func (s *Server) ComputeTags(params BufferParams) ([]ResourceTags, error) {
+	if s.shutdown {
+		return nil, fmt.Errorf("server is shutting down")
+	}
+	var blocks []structure.IBlock
+	if params.Text != nil {
+		// the tags are computed for the original path, so git tags and relative paths (i.e. modules) are resolved as
+		// they would be once the buffer is saved
+		var err error
+		if blocks, err = s.runner.ComputeTagsForBuffer(params.Path, []byte(*params.Text)); err != nil {
+			return nil, err
+		}
+	} else {
+		if _, err := os.Stat(params.Path); err != nil {
+			return nil, fmt.Errorf("file %v does not exist", params.Path)
+		}
+		blocks = s.runner.ComputeTagsForFile(params.Path)
+	}
+
+	resources := make([]ResourceTags, 0)
+	for _, block := range blocks {
+		resources = append(resources, s.toResourceTags(block, params.Path))
+	}
+	sort.SliceStable(resources, func(i, j int) bool {
+		return resources[i].Lines.Start < resources[j].Lines.Start
+	})
+	return resources, nil
+}
+
+// ExplainTags returns the resource which contains the given line (1 based), along with its tags and their descriptions
+func (s *Server) ExplainTags(params ExplainParams) (*ResourceTags, error) {
+	resources, err := s.ComputeTags(params.BufferParams)
+	if err != nil {
+		return nil, err
+	}
+	for _, resource := range resources {
+		if resource.Lines.Start <= params.Line && params.Line <= resource.Lines.End {
+			resource := resource
+			for i, tag := range resource.Tags {
+				resource.Tags[i].Description = s.descriptions[tag.Key]
+			}
+			return &resource, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Server) toResourceTags(block structure.IBlock, filePath string) ResourceTags {
+	existingTags := make(map[string]string)
+	for _, tag := range block.GetExistingTags() {
+		existingTags[tag.GetKey()] = tag.GetValue()
+	}
+	proposals := make([]TagProposal, 0)
+	diff := block.CalculateTagsDiff()
+	updated := make(map[string]string)
+	for _, val := range diff.Updated {
+		updated[val.Key] = val.PrevValue
+	}
+	for _, tag := range block.MergeTags() {
+		proposal := TagProposal{Key: tag.GetKey(), Value: tag.GetValue(), Status: "unchanged"}
+		if prevValue, ok := updated[tag.GetKey()]; ok {
+			proposal.Status = "updated"
+			proposal.PreviousValue = prevValue
+		} else if _, ok := existingTags[tag.GetKey()]; !ok {
+			proposal.Status = "new"
+		}
+		proposals = append(proposals, proposal)
+	}
+	sort.SliceStable(proposals, func(i, j int) bool {
+		return proposals[i].Key < proposals[j].Key
+	})
+	return ResourceTags{
+		ResourceID:   block.GetResourceID(),
+		ResourceType: block.GetResourceType(),
+		File:         filePath,
+		Lines:        block.GetLines(),
+		Taggable:     block.IsBlockTaggable(),
+		Tags:         proposals,
+		ExistingTags: existingTags,
+	}
+}
+
+func (s *Server) readMessage() ([]byte, error) {
+	contentLength := -1
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		parts := strings.SplitN(line, ":", 2)
+		if len(parts) == 2 && strings.EqualFold(strings.TrimSpace(parts[0]), contentLengthField) {
+			contentLength, err = strconv.Atoi(strings.TrimSpace(parts[1]))
+			if err != nil {
+				return nil, fmt.Errorf("invalid %v header %q", contentLengthField, line)
+			}
+		}
+	}
+	if contentLength < 0 {
+		return nil, fmt.Errorf("missing %v header", contentLengthField)
+	}
+	body := make([]byte, contentLength)
+	_, err := io.ReadFull(s.reader, body)
+	return body, err
+}
+
+func (s *Server) writeResult(id *json.RawMessage, result interface{}) {
+	if id == nil {
+		return
+	}
+	s.writeMessage(Response{JSONRPC: jsonRPCVersion, ID: id, Result: result})
+}
+
+func (s *Server) writeError(id *json.RawMessage, code int, message string) {
+	s.writeMessage(Response{JSONRPC: jsonRPCVersion, ID: id, Error: &ResponseError{Code: code, Message: message}})
+}
+
+func (s *Server) writeMessage(response Response) {
+	body, err := json.Marshal(response)
+	if err != nil {
+		logger.Warning(fmt.Sprintf("Failed to marshal response: %s", err))
+		return
+	}
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+	_, err = fmt.Fprintf(s.writer, "%v: %d\r\n\r\n%s", contentLengthField, len(body), body)
+	if err != nil {
+		logger.Warning(fmt.Sprintf("Failed to write response: %s", err))
+	}
+}
@@ -0,0 +1,160 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bridgecrewio/yor/src/common/clioptions"
+	"github.com/bridgecrewio/yor/src/common/runner"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+)
+
+const bucketResource = `resource "aws_s3_bucket" "bucket" {
+  bucket = "my-bucket"
+  tags = {
+    env = "dev"
+  }
+}
+`
+
+func frame(t *testing.T, id int, method string, params interface{}) string {
+	body, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func readResponses(t *testing.T, out *bytes.Buffer) []Response {
+	var responses []Response
+	s := &Server{reader: bufio.NewReader(out)}
+	for {
+		body, err := s.readMessage()
+		if err == io.EOF {
+			return responses
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		var response Response
+		assert.NoError(t, json.Unmarshal(body, &response))
+		responses = append(responses, response)
+	}
+}
+
+func TestServer(t *testing.T) {
+	rootDir := t.TempDir()
+	yorRunner := new(runner.Runner)
+	err := yorRunner.Init(&clioptions.TagOptions{
+		Directory: rootDir,
+		TagGroups: []string{"code2cloud"},
+		Parsers:   []string{"Terraform"},
+		DryRun:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := bucketResource
+	filePath := filepath.Join(rootDir, "main.tf")
+
+	t.Run("compute tags of an unsaved buffer", func(t *testing.T) {
+		in := bytes.NewBufferString(frame(t, 1, InitializeMethod, nil) +
+			frame(t, 2, ComputeTagsMethod, BufferParams{Path: filePath, Text: &text}) +
+			frame(t, 3, ExplainTagsMethod, ExplainParams{BufferParams: BufferParams{Path: filePath, Text: &text}, Line: 4}) +
+			frame(t, 4, "yor/unknown", nil))
+		out := &bytes.Buffer{}
+		assert.NoError(t, NewServer(yorRunner, in, out).Serve())
+
+		responses := readResponses(t, out)
+		assert.Equal(t, 4, len(responses))
+		assert.Nil(t, responses[0].Error)
+
+		var computed ComputeTagsResult
+		resultBytes, _ := json.Marshal(responses[1].Result)
+		assert.NoError(t, json.Unmarshal(resultBytes, &computed))
+		assert.Equal(t, 1, len(computed.Resources))
+		resource := computed.Resources[0]
+		assert.Equal(t, "aws_s3_bucket.bucket", resource.ResourceID)
+		assert.Equal(t, filePath, resource.File)
+		assert.Equal(t, map[string]string{"env": "dev"}, resource.ExistingTags)
+		statuses := map[string]string{}
+		for _, tag := range resource.Tags {
+			statuses[tag.Key] = tag.Status
+		}
+		assert.Equal(t, map[string]string{"env": "unchanged", "yor_trace": "new"}, statuses)
+
+		var explained ExplainTagsResult
+		resultBytes, _ = json.Marshal(responses[2].Result)
+		assert.NoError(t, json.Unmarshal(resultBytes, &explained))
+		assert.NotNil(t, explained.Resource)
+		for _, tag := range explained.Resource.Tags {
+			if tag.Key == "yor_trace" {
+				assert.NotEmpty(t, tag.Description)
+			}
+		}
+
+		assert.NotNil(t, responses[3].Error)
+		assert.Equal(t, methodNotFoundCode, responses[3].Error.Code)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		in := bytes.NewBufferString(frame(t, 1, ComputeTagsMethod, BufferParams{Path: filePath}))
+		out := &bytes.Buffer{}
+		assert.NoError(t, NewServer(yorRunner, in, out).Serve())
+		responses := readResponses(t, out)
+		assert.Equal(t, 1, len(responses))
+		assert.Equal(t, invalidParamsCode, responses[0].Error.Code)
+	})
+}
+
+func TestServer_GitTagsOfUnsavedBuffer(t *testing.T) {
+	rootDir := t.TempDir()
+	repository, err := git.PlainInit(rootDir, false)
+	assert.Nil(t, err)
+	_, err = repository.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{"https://github.com/bridgecrewio/lsp-example.git"}})
+	assert.Nil(t, err)
+	filePath := filepath.Join(rootDir, "main.tf")
+	assert.Nil(t, os.WriteFile(filePath, []byte(bucketResource), 0600))
+	worktree, err := repository.Worktree()
+	assert.Nil(t, err)
+	_, err = worktree.Add("main.tf")
+	assert.Nil(t, err)
+	commit, err := worktree.Commit("add bucket", &git.CommitOptions{Author: &object.Signature{Name: "author", Email: "author@example.com", When: time.Now()}})
+	assert.Nil(t, err)
+
+	yorRunner := new(runner.Runner)
+	err = yorRunner.Init(&clioptions.TagOptions{
+		Directory: rootDir,
+		TagGroups: []string{"git"},
+		Parsers:   []string{"Terraform"},
+		DryRun:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the unsaved lines move the bucket down, its lines must still be mapped to the committed ones
+	text := "locals {\n  name = \"my-bucket\"\n}\n\n" + bucketResource
+	resources, err := NewServer(yorRunner, &bytes.Buffer{}, &bytes.Buffer{}).ComputeTags(BufferParams{Path: filePath, Text: &text})
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(resources))
+	assert.Equal(t, 5, resources[0].Lines.Start)
+	values := map[string]string{}
+	for _, tag := range resources[0].Tags {
+		values[tag.Key] = tag.Value
+	}
+	assert.Equal(t, "main.tf", values["git_file"])
+	assert.Equal(t, commit.String(), values["git_commit"])
+	assert.Equal(t, "author@example.com", values["git_last_modified_by"])
+	assert.Equal(t, "lsp-example", values["git_repo"])
+}
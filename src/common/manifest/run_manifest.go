@@ -0,0 +1,159 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bridgecrewio/yor/src/common"
+	"github.com/bridgecrewio/yor/src/common/clioptions"
+	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/bridgecrewio/yor/src/common/reports"
+	"github.com/bridgecrewio/yor/src/common/utils"
+)
+
+var hashedExtensions = []string{
+	common.TfFileType.Extension,
+	common.YamlFileType.Extension,
+	common.YmlFileType.Extension,
+	common.JSONFileType.Extension,
+	common.CFTFileType.Extension,
+	common.PackerFileType.Extension,
+}
+var ignoredDirs = []string{".git", ".terraform"}
+
+// RunManifest records the outcome of a run, so a later run can verify the directory is still fully tagged
+// without parsing the files or computing git blame
+type RunManifest struct {
+	Timestamp   time.Time              `json:"timestamp"`
+	Version     string                 `json:"version"`
+	Options     *clioptions.TagOptions `json:"options"`
+	Summary     reports.ReportSummary  `json:"summary"`
+	SummaryHash string                 `json:"summaryHash"`
+	FilesHash   string                 `json:"filesHash"`
+	FullyTagged bool                   `json:"fullyTagged"`
+}
+
+// Write creates the manifest of the run which produced the report. It must be called after the files are written.
+func Write(manifestPath string, options *clioptions.TagOptions, report *reports.Report) error {
+	reportBytes, err := report.AsJSONBytes()
+	if err != nil {
+		return err
+	}
+	filesHash, err := HashFiles(options.Directory, options.SkipDirs, manifestPath)
+	if err != nil {
+		return err
+	}
+	summaryHash := sha256.Sum256(reportBytes)
+	runManifest := RunManifest{
+		Timestamp:   time.Now().UTC(),
+		Version:     common.Version,
+		Options:     options,
+		Summary:     report.Summary,
+		SummaryHash: hex.EncodeToString(summaryHash[:]),
+		FilesHash:   filesHash,
+		// a dry run leaves the files untouched, so they are tagged only if there was nothing to change
+		FullyTagged: len(report.SkippedFiles) == 0 && len(report.SkippedResources) == 0 &&
+			(!options.DryRun || (report.Summary.NewResources == 0 && report.Summary.UpdatedResources == 0 && report.Summary.ImportedResources == 0)),
+	}
+	manifestBytes, err := json.MarshalIndent(runManifest, "", "    ")
+	if err != nil {
+		return err
+	}
+	logger.Info(fmt.Sprintf("Writing run manifest to %s", manifestPath))
+	return os.WriteFile(manifestPath, manifestBytes, 0600)
+}
+
+func Read(manifestPath string) (*RunManifest, error) {
+	// #nosec G304 - file is from user
+	manifestBytes, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read run manifest %s: %s", manifestPath, err)
+	}
+	runManifest := RunManifest{}
+	if err = json.Unmarshal(manifestBytes, &runManifest); err != nil {
+		return nil, fmt.Errorf("failed to parse run manifest %s: %s", manifestPath, err)
+	}
+	return &runManifest, nil
+}
+
+// Verify returns an error unless the last run left dir fully tagged and no IaC file changed since
+func Verify(manifestPath string, dir string, skipDirs []string) (*RunManifest, error) {
+	runManifest, err := Read(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	if runManifest.Version != common.Version {
+		logger.Warning(fmt.Sprintf("The last run used yor version %s, while the current version is %s", runManifest.Version, common.Version))
+	}
+	if !runManifest.FullyTagged {
+		return runManifest, fmt.Errorf("the last run at %v was a dry run with %d new, %d updated and %d imported resources",
+			runManifest.Timestamp, runManifest.Summary.NewResources, runManifest.Summary.UpdatedResources, runManifest.Summary.ImportedResources)
+	}
+	filesHash, err := HashFiles(dir, skipDirs, manifestPath)
+	if err != nil {
+		return runManifest, err
+	}
+	if filesHash != runManifest.FilesHash {
+		return runManifest, fmt.Errorf("IaC files in %s changed since the last run at %v", dir, runManifest.Timestamp)
+	}
+	return runManifest, nil
+}
+
+// HashFiles computes a hash of the paths and contents of the IaC files under dir, except for the excluded files
+func HashFiles(dir string, skipDirs []string, excludedFiles ...string) (string, error) {
+	excluded := make(map[string]bool)
+	for _, file := range excludedFiles {
+		if absPath, err := filepath.Abs(file); err == nil {
+			excluded[absPath] = true
+		}
+	}
+	var files []string
+	skipped := append(append([]string{}, skipDirs...), ignoredDirs...)
+	err := utils.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			for _, skipDir := range skipped {
+				if path != dir && (entry.Name() == skipDir || filepath.Clean(path) == filepath.Clean(skipDir)) {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		if absPath, err := filepath.Abs(path); err == nil && excluded[absPath] {
+			return nil
+		}
+		for _, extension := range hashedExtensions {
+			if strings.HasSuffix(path, extension) {
+				files = append(files, path)
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list the files of %s: %s", dir, err)
+	}
+	sort.Strings(files)
+
+	hash := sha256.New()
+	for _, file := range files {
+		relPath, _ := filepath.Rel(dir, file)
+		_, _ = hash.Write([]byte(filepath.ToSlash(relPath)))
+		content, err := utils.ReadFile(file)
+		if err != nil {
+			return "", err
+		}
+		_, _ = hash.Write(content)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
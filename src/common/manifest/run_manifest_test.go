@@ -0,0 +1,110 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bridgecrewio/yor/src/common/clioptions"
+	"github.com/bridgecrewio/yor/src/common/reports"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunManifest(t *testing.T) {
+	writeDir := func(t *testing.T) string {
+		dir := t.TempDir()
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte("resource \"aws_s3_bucket\" \"b\" {}\n"), 0600))
+		assert.Nil(t, os.Mkdir(filepath.Join(dir, ".terraform"), 0700))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, ".terraform", "module.tf"), []byte(""), 0600))
+		return dir
+	}
+
+	t.Run("Verify an unchanged directory", func(t *testing.T) {
+		dir := writeDir(t)
+		manifestPath := filepath.Join(dir, ".yor-run")
+		options := &clioptions.TagOptions{Directory: dir}
+		err := Write(manifestPath, options, &reports.Report{Summary: reports.ReportSummary{Scanned: 1, NewResources: 1}})
+		assert.Nil(t, err)
+
+		runManifest, err := Verify(manifestPath, dir, nil)
+		assert.Nil(t, err)
+		assert.True(t, runManifest.FullyTagged)
+		assert.Equal(t, 1, runManifest.Summary.Scanned)
+		assert.NotEmpty(t, runManifest.SummaryHash)
+
+		// files which are not hashed don't affect the verification
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, ".terraform", "module.tf"), []byte("changed"), 0600))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("changed"), 0600))
+		_, err = Verify(manifestPath, dir, nil)
+		assert.Nil(t, err)
+	})
+
+	t.Run("Fail verification of a changed directory", func(t *testing.T) {
+		dir := writeDir(t)
+		manifestPath := filepath.Join(dir, "run.json")
+		err := Write(manifestPath, &clioptions.TagOptions{Directory: dir}, &reports.Report{})
+		assert.Nil(t, err)
+
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte("resource \"aws_s3_bucket\" \"c\" {}\n"), 0600))
+		_, err = Verify(manifestPath, dir, nil)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Fail verification of a changed packer template", func(t *testing.T) {
+		dir := writeDir(t)
+		templatePath := filepath.Join(dir, "image.pkr.hcl")
+		assert.Nil(t, os.WriteFile(templatePath, []byte("source \"amazon-ebs\" \"a\" {}\n"), 0600))
+		manifestPath := filepath.Join(dir, "run.json")
+		err := Write(manifestPath, &clioptions.TagOptions{Directory: dir}, &reports.Report{})
+		assert.Nil(t, err)
+
+		assert.Nil(t, os.WriteFile(templatePath, []byte("source \"amazon-ebs\" \"b\" {}\n"), 0600))
+		_, err = Verify(manifestPath, dir, nil)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Fail verification after a dry run with changes", func(t *testing.T) {
+		dir := writeDir(t)
+		manifestPath := filepath.Join(dir, ".yor-run")
+		options := &clioptions.TagOptions{Directory: dir, DryRun: true}
+		err := Write(manifestPath, options, &reports.Report{Summary: reports.ReportSummary{Scanned: 1, UpdatedResources: 1}})
+		assert.Nil(t, err)
+
+		runManifest, err := Verify(manifestPath, dir, nil)
+		assert.NotNil(t, err)
+		assert.False(t, runManifest.FullyTagged)
+	})
+
+	t.Run("Fail verification without a manifest", func(t *testing.T) {
+		dir := writeDir(t)
+		_, err := Verify(filepath.Join(dir, ".yor-run"), dir, nil)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Verify the manifest written by the run with the same options", func(t *testing.T) {
+		dir := writeDir(t)
+		options := &clioptions.TagOptions{Directory: dir, RunManifest: filepath.Join(t.TempDir(), "run.json")}
+		err := Write(options.RunManifest, options, &reports.Report{Summary: reports.ReportSummary{Scanned: 1}})
+		assert.Nil(t, err)
+
+		runManifest, err := Verify(options.RunManifest, options.Directory, options.SkipDirs)
+		assert.Nil(t, err)
+		assert.True(t, runManifest.FullyTagged)
+	})
+
+	t.Run("Keep the secrets of the options out of the manifest", func(t *testing.T) {
+		dir := writeDir(t)
+		manifestPath := filepath.Join(t.TempDir(), "run.json")
+		options := &clioptions.TagOptions{
+			Directory:         dir,
+			AnonymizationSalt: "salt-secret",
+			Notify:            []string{"slack://hooks.slack.com/services/T000/B000/webhook-secret"},
+		}
+		assert.Nil(t, Write(manifestPath, options, &reports.Report{}))
+
+		content, err := os.ReadFile(manifestPath)
+		assert.Nil(t, err)
+		assert.NotContains(t, string(content), "salt-secret")
+		assert.NotContains(t, string(content), "webhook-secret")
+	})
+}
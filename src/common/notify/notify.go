@@ -0,0 +1,191 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bridgecrewio/yor/src/common/reports"
+)
+
+const (
+	SlackScheme = "slack"
+	TeamsScheme = "teams"
+	// TargetsEnvKey holds comma separated notification targets, which are added to the ones of --notify. The webhook
+	// URLs are secrets, so they are better kept out of the arguments, where they show up in the process list.
+	TargetsEnvKey = "YOR_NOTIFY"
+
+	requestTimeout = 30 * time.Second
+	// TopChangedFiles is the number of files listed in the notification, the files with the most tag changes first
+	TopChangedFiles = 5
+)
+
+var Schemes = []string{SlackScheme, TeamsScheme}
+
+// Target is an incoming webhook of Slack or Microsoft Teams, given as slack://<webhook host and path> or
+// teams://<webhook host and path>. The webhook is always called over https.
+type Target struct {
+	Scheme     string
+	WebhookURL string
+	client     *http.Client
+}
+
+// ChangedFile is a file of the run, with the number of tags yor added or updated in it
+type ChangedFile struct {
+	File    string
+	Changes int
+}
+
+// Summary is the part of the run's report which is posted to the chat
+type Summary struct {
+	Directory     string
+	DryRun        bool
+	ReportSummary reports.ReportSummary
+	Errors        int
+	TopFiles      []ChangedFile
+	ReportURL     string
+}
+
+// TargetsFromEnv returns the notification targets of the TargetsEnvKey environment variable
+func TargetsFromEnv() []string {
+	var targets []string
+	for _, target := range strings.Split(os.Getenv(TargetsEnvKey), ",") {
+		if target = strings.TrimSpace(target); target != "" {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+func ParseTarget(notifyURL string) (*Target, error) {
+	parsedURL, err := url.Parse(notifyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid notification target %s: %s", notifyURL, err)
+	}
+	scheme := strings.ToLower(parsedURL.Scheme)
+	if scheme != SlackScheme && scheme != TeamsScheme {
+		return nil, fmt.Errorf("unsupported notification target %s, expected one of %s://<webhook host and path>", notifyURL, strings.Join(Schemes, "://, "))
+	}
+	if parsedURL.Host == "" {
+		return nil, fmt.Errorf("invalid notification target %s, the webhook host is missing", notifyURL)
+	}
+	parsedURL.Scheme = "https"
+	return &Target{Scheme: scheme, WebhookURL: parsedURL.String(), client: &http.Client{Timeout: requestTimeout}}, nil
+}
+
+// NewSummary summarizes the report, with the files which had the most tag changes
+func NewSummary(report *reports.Report, directory string, dryRun bool, reportURL string) *Summary {
+	changesPerFile := map[string]int{}
+	for _, records := range [][]reports.TagRecord{report.NewResourceTags, report.UpdatedResourceTags} {
+		for _, record := range records {
+			changesPerFile[record.File]++
+		}
+	}
+	var topFiles []ChangedFile
+	for file, changes := range changesPerFile {
+		topFiles = append(topFiles, ChangedFile{File: file, Changes: changes})
+	}
+	sort.Slice(topFiles, func(i, j int) bool {
+		if topFiles[i].Changes != topFiles[j].Changes {
+			return topFiles[i].Changes > topFiles[j].Changes
+		}
+		return topFiles[i].File < topFiles[j].File
+	})
+	if len(topFiles) > TopChangedFiles {
+		topFiles = topFiles[:TopChangedFiles]
+	}
+	return &Summary{
+		Directory:     directory,
+		DryRun:        dryRun,
+		ReportSummary: report.Summary,
+		Errors:        len(report.Errors),
+		TopFiles:      topFiles,
+		ReportURL:     reportURL,
+	}
+}
+
+func (s *Summary) title() string {
+	if s.DryRun {
+		return fmt.Sprintf("Yor dry run on %s", s.Directory)
+	}
+	return fmt.Sprintf("Yor tagged %s", s.Directory)
+}
+
+// lines returns the text of the notification, with the bold markers of the chat
+func (s *Summary) lines(bold func(string) string) []string {
+	lines := []string{fmt.Sprintf("%s scanned, %s new, %s updated",
+		bold(fmt.Sprint(s.ReportSummary.Scanned)), bold(fmt.Sprint(s.ReportSummary.NewResources)), bold(fmt.Sprint(s.ReportSummary.UpdatedResources)))}
+	if s.ReportSummary.RequiredTagViolations > 0 {
+		lines = append(lines, fmt.Sprintf("%s required tag violations", bold(fmt.Sprint(s.ReportSummary.RequiredTagViolations))))
+	}
+	if s.Errors > 0 {
+		lines = append(lines, fmt.Sprintf("%s errors", bold(fmt.Sprint(s.Errors))))
+	}
+	if len(s.TopFiles) > 0 {
+		lines = append(lines, "Top changed files:")
+		for _, file := range s.TopFiles {
+			lines = append(lines, fmt.Sprintf("- %s (%d tags)", file.File, file.Changes))
+		}
+	}
+	return lines
+}
+
+// Send posts the summary to the webhook, as a message for Slack or as a message card for Teams
+func (t *Target) Send(summary *Summary) error {
+	var payload interface{}
+	switch t.Scheme {
+	case SlackScheme:
+		lines := append([]string{fmt.Sprintf("*%s*", summary.title())}, summary.lines(func(s string) string { return "*" + s + "*" })...)
+		if summary.ReportURL != "" {
+			lines = append(lines, fmt.Sprintf("<%s|Full report>", summary.ReportURL))
+		}
+		payload = map[string]string{"text": strings.Join(lines, "\n")}
+	case TeamsScheme:
+		card := map[string]interface{}{
+			"@type":    "MessageCard",
+			"@context": "http://schema.org/extensions",
+			"summary":  summary.title(),
+			"title":    summary.title(),
+			// Teams renders markdown, where lines are broken by blank lines
+			"text": strings.Join(summary.lines(func(s string) string { return "**" + s + "**" }), "\n\n"),
+		}
+		if summary.ReportURL != "" {
+			card["potentialAction"] = []map[string]interface{}{{
+				"@type":   "OpenUri",
+				"name":    "Full report",
+				"targets": []map[string]string{{"os": "default", "uri": summary.ReportURL}},
+			}}
+		}
+		payload = card
+	default:
+		return fmt.Errorf("unsupported notification target %s", t.Scheme)
+	}
+	return t.post(payload)
+}
+
+func (t *Target) post(payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	// the webhook URL holds its secret, so it's kept out of the errors
+	resp, err := t.client.Post(t.WebhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to send the %s notification", t.Scheme)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("the %s notification failed with status %d: %s", t.Scheme, resp.StatusCode, string(respBody))
+	}
+	return nil
+}
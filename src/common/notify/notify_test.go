@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bridgecrewio/yor/src/common/reports"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotify(t *testing.T) {
+	report := &reports.Report{
+		Summary: reports.ReportSummary{Scanned: 4, NewResources: 2, UpdatedResources: 1},
+		NewResourceTags: []reports.TagRecord{
+			{File: "main.tf", ResourceID: "aws_s3_bucket.a", TagKey: "yor_trace"},
+			{File: "main.tf", ResourceID: "aws_s3_bucket.a", TagKey: "git_repo"},
+			{File: "network.tf", ResourceID: "aws_vpc.a", TagKey: "yor_trace"},
+		},
+		UpdatedResourceTags: []reports.TagRecord{{File: "network.tf", ResourceID: "aws_vpc.b", TagKey: "git_modifiers"}},
+		Errors:              []reports.RunError{},
+	}
+
+	t.Run("parse the notification targets", func(t *testing.T) {
+		target, err := ParseTarget("slack://hooks.slack.com/services/T000/B000/XXXX")
+		assert.Nil(t, err)
+		assert.Equal(t, SlackScheme, target.Scheme)
+		assert.Equal(t, "https://hooks.slack.com/services/T000/B000/XXXX", target.WebhookURL)
+
+		target, err = ParseTarget("teams://contoso.webhook.office.com/webhookb2/1234?key=value")
+		assert.Nil(t, err)
+		assert.Equal(t, TeamsScheme, target.Scheme)
+		assert.Equal(t, "https://contoso.webhook.office.com/webhookb2/1234?key=value", target.WebhookURL)
+
+		_, err = ParseTarget("https://hooks.slack.com/services/T000/B000/XXXX")
+		assert.NotNil(t, err)
+		_, err = ParseTarget("slack:///services")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("read the notification targets from the environment", func(t *testing.T) {
+		t.Setenv(TargetsEnvKey, "slack://hooks.slack.com/services/T000/B000/XXXX, teams://contoso.webhook.office.com/webhookb2/1234,")
+		assert.Equal(t, []string{"slack://hooks.slack.com/services/T000/B000/XXXX", "teams://contoso.webhook.office.com/webhookb2/1234"}, TargetsFromEnv())
+		t.Setenv(TargetsEnvKey, "")
+		assert.Empty(t, TargetsFromEnv())
+	})
+
+	t.Run("summarize the top changed files", func(t *testing.T) {
+		summary := NewSummary(report, "terraform", false, "https://ci.example.com/artifacts/report.json")
+		assert.Equal(t, []ChangedFile{{File: "main.tf", Changes: 2}, {File: "network.tf", Changes: 2}}, summary.TopFiles)
+		assert.Equal(t, 0, summary.Errors)
+	})
+
+	t.Run("post to Slack", func(t *testing.T) {
+		var payload map[string]string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+			_, _ = w.Write([]byte("ok"))
+		}))
+		defer server.Close()
+
+		target, _ := ParseTarget("slack://hooks.slack.com/services/T000/B000/XXXX")
+		target.WebhookURL = server.URL
+		assert.Nil(t, target.Send(NewSummary(report, "terraform", false, "https://ci.example.com/artifacts/report.json")))
+		assert.Equal(t, "*Yor tagged terraform*\n*4* scanned, *2* new, *1* updated\nTop changed files:\n- main.tf (2 tags)\n- network.tf (2 tags)\n<https://ci.example.com/artifacts/report.json|Full report>", payload["text"])
+	})
+
+	t.Run("post a message card to Teams", func(t *testing.T) {
+		var card map[string]interface{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewDecoder(r.Body).Decode(&card)
+			_, _ = w.Write([]byte("1"))
+		}))
+		defer server.Close()
+
+		target, _ := ParseTarget("teams://contoso.webhook.office.com/webhookb2/1234")
+		target.WebhookURL = server.URL
+		assert.Nil(t, target.Send(NewSummary(report, "terraform", true, "")))
+		assert.Equal(t, "MessageCard", card["@type"])
+		assert.Equal(t, "Yor dry run on terraform", card["title"])
+		assert.Equal(t, "**4** scanned, **2** new, **1** updated\n\nTop changed files:\n\n- main.tf (2 tags)\n\n- network.tf (2 tags)", card["text"])
+		assert.Nil(t, card["potentialAction"])
+	})
+
+	t.Run("failed notifications", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte("invalid_token"))
+		}))
+		defer server.Close()
+
+		target, _ := ParseTarget("slack://hooks.slack.com/services/T000/B000/XXXX")
+		target.WebhookURL = server.URL
+		err := target.Send(NewSummary(report, "terraform", false, ""))
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "the slack notification failed with status 403: invalid_token")
+	})
+}
@@ -0,0 +1,258 @@
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest/adal"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	S3Scheme    = "s3"
+	GCSScheme   = "gs"
+	AzureScheme = "az"
+
+	requestTimeout       = 30 * time.Second
+	azureVersion         = "2020-10-02"
+	azureStorageResource = "https://storage.azure.com/"
+	azureAuthorityHost   = "https://login.microsoftonline.com/"
+	gcsScope             = "https://www.googleapis.com/auth/devstorage.read_write"
+)
+
+var Schemes = []string{S3Scheme, GCSScheme, AzureScheme}
+
+var client = &http.Client{Timeout: requestTimeout}
+
+// now is the time the requests are signed at, and gcsEndpoint the endpoint of GCS, set by the tests
+var (
+	now         = time.Now
+	gcsEndpoint = "https://storage.googleapis.com"
+)
+
+// IsURL returns whether the path is an object in a bucket, i.e. s3://bucket/key, gs://bucket/key or
+// az://account/container/blob, rather than a local file
+func IsURL(path string) bool {
+	for _, scheme := range Schemes {
+		if strings.HasPrefix(path, scheme+"://") {
+			return true
+		}
+	}
+	return false
+}
+
+// Upload writes the data to the object, with the credentials of the environment:
+//   - S3: the credential chain of the AWS SDK, i.e. AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, AWS_PROFILE, web
+//     identity or the role of the instance, in the region of AWS_REGION (or AWS_DEFAULT_REGION), and AWS_ENDPOINT_URL for
+//     S3 compatible storage
+//   - GCS: GOOGLE_OAUTH_ACCESS_TOKEN, e.g. from gcloud auth print-access-token, or else the application default
+//     credentials, and STORAGE_EMULATOR_HOST for emulators
+//   - Azure Blob: AZURE_STORAGE_SAS_TOKEN, or else an Azure AD token of the service principal of AZURE_TENANT_ID,
+//     AZURE_CLIENT_ID and AZURE_CLIENT_SECRET, or of the managed identity, and AZURE_STORAGE_BLOB_ENDPOINT for emulators
+func Upload(destination string, data []byte, contentType string) error {
+	parsedURL, err := url.Parse(destination)
+	if err != nil {
+		return fmt.Errorf("invalid object url %s: %s", destination, err)
+	}
+	objectPath := strings.TrimPrefix(parsedURL.Path, "/")
+	if parsedURL.Host == "" || objectPath == "" {
+		return fmt.Errorf("invalid object url %s, expected %s://<bucket>/<key>", destination, parsedURL.Scheme)
+	}
+	var req *http.Request
+	switch parsedURL.Scheme {
+	case S3Scheme:
+		req, err = newS3Request(parsedURL.Host, objectPath, data, contentType)
+	case GCSScheme:
+		req, err = newGCSRequest(parsedURL.Host, objectPath, data, contentType)
+	case AzureScheme:
+		req, err = newAzureRequest(parsedURL.Host, objectPath, data, contentType)
+	default:
+		return fmt.Errorf("unsupported object url %s, expected one of the schemes %v", destination, Schemes)
+	}
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %s", destination, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("upload of %s failed with status %d: %s", destination, resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+func newS3Request(bucket string, key string, data []byte, contentType string) (*http.Request, error) {
+	sess, err := session.NewSessionWithOptions(session.Options{SharedConfigState: session.SharedConfigEnable})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the AWS configuration: %s", err)
+	}
+	region := aws.StringValue(sess.Config.Region)
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+	req, err := http.NewRequest(http.MethodPut, getS3URL(bucket, key, region), bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if _, err = newS3Signer(sess.Config.Credentials).Sign(req, bytes.NewReader(data), "s3", region, now()); err != nil {
+		return nil, fmt.Errorf("failed to upload to s3://%s with the AWS credentials of the environment: %s", bucket, err)
+	}
+	return req, nil
+}
+
+// getS3URL returns the path-style url of the object. Virtual-hosted urls put the bucket in the host name, which doesn't
+// match the wildcard certificate of S3 when the bucket name has dots.
+func getS3URL(bucket string, key string, region string) string {
+	endpoint := fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	if customEndpoint := os.Getenv("AWS_ENDPOINT_URL"); customEndpoint != "" {
+		endpoint = strings.TrimSuffix(customEndpoint, "/")
+	}
+	return fmt.Sprintf("%s/%s/%s", endpoint, bucket, uriEncode(key, false))
+}
+
+// newS3Signer returns the Signature Version 4 signer of S3, which signs the escaped path of the request as is
+func newS3Signer(creds *credentials.Credentials) *v4.Signer {
+	return v4.NewSigner(creds, func(signer *v4.Signer) {
+		signer.DisableURIPathEscaping = true
+	})
+}
+
+func newGCSRequest(bucket string, key string, data []byte, contentType string) (*http.Request, error) {
+	endpoint := gcsEndpoint
+	emulatorHost := os.Getenv("STORAGE_EMULATOR_HOST")
+	if emulatorHost != "" {
+		endpoint = emulatorHost
+		if !strings.Contains(endpoint, "://") {
+			endpoint = "http://" + endpoint
+		}
+	}
+	req, err := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(endpoint, "/"), bucket, uriEncode(key, false)), bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	// emulators don't authenticate their clients
+	if emulatorHost != "" && os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN") == "" {
+		return req, nil
+	}
+	token, err := getGCSToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload to gs://%s with the Google credentials of the environment: %s", bucket, err)
+	}
+	token.SetAuthHeader(req)
+	return req, nil
+}
+
+// getGCSToken returns the token of GOOGLE_OAUTH_ACCESS_TOKEN, or else a token of the application default credentials
+func getGCSToken() (*oauth2.Token, error) {
+	if accessToken := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN"); accessToken != "" {
+		return &oauth2.Token{AccessToken: accessToken, TokenType: "Bearer"}, nil
+	}
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), oauth2.HTTPClient, client), requestTimeout)
+	defer cancel()
+	tokenSource, err := google.DefaultTokenSource(ctx, gcsScope)
+	if err != nil {
+		return nil, err
+	}
+	return tokenSource.Token()
+}
+
+// newAzureRequest puts a block blob, where the host of the url is the storage account and the path starts with the
+// container
+func newAzureRequest(account string, blobPath string, data []byte, contentType string) (*http.Request, error) {
+	if !strings.Contains(blobPath, "/") {
+		return nil, fmt.Errorf("invalid object url az://%s/%s, expected az://<account>/<container>/<blob>", account, blobPath)
+	}
+	sasToken := strings.TrimPrefix(os.Getenv("AZURE_STORAGE_SAS_TOKEN"), "?")
+	endpoint := fmt.Sprintf("https://%s.blob.core.windows.net", account)
+	if customEndpoint := os.Getenv("AZURE_STORAGE_BLOB_ENDPOINT"); customEndpoint != "" {
+		endpoint = strings.TrimSuffix(customEndpoint, "/")
+	}
+	blobURL := fmt.Sprintf("%s/%s", endpoint, uriEncode(blobPath, false))
+	if sasToken != "" {
+		blobURL += "?" + sasToken
+	}
+	req, err := http.NewRequest(http.MethodPut, blobURL, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Ms-Blob-Type", "BlockBlob")
+	req.Header.Set("X-Ms-Date", now().UTC().Format(http.TimeFormat))
+	req.Header.Set("X-Ms-Version", azureVersion)
+	if sasToken == "" {
+		token, err := getAzureToken()
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload to az://%s, set AZURE_STORAGE_SAS_TOKEN, or the Azure AD credentials of a service principal or a managed identity: %s", account, err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req, nil
+}
+
+// getAzureToken returns an Azure AD token of the storage, for the service principal of AZURE_TENANT_ID, AZURE_CLIENT_ID
+// and AZURE_CLIENT_SECRET, or else for the managed identity of the machine
+func getAzureToken() (string, error) {
+	var token *adal.ServicePrincipalToken
+	tenantID, clientID, clientSecret := os.Getenv("AZURE_TENANT_ID"), os.Getenv("AZURE_CLIENT_ID"), os.Getenv("AZURE_CLIENT_SECRET")
+	if tenantID != "" && clientID != "" && clientSecret != "" {
+		authorityHost := os.Getenv("AZURE_AUTHORITY_HOST")
+		if authorityHost == "" {
+			authorityHost = azureAuthorityHost
+		}
+		oauthConfig, err := adal.NewOAuthConfig(authorityHost, tenantID)
+		if err != nil {
+			return "", err
+		}
+		if token, err = adal.NewServicePrincipalToken(*oauthConfig, clientID, clientSecret, azureStorageResource); err != nil {
+			return "", err
+		}
+	} else {
+		msiEndpoint, err := adal.GetMSIVMEndpoint()
+		if err != nil {
+			return "", err
+		}
+		if token, err = adal.NewServicePrincipalTokenFromMSI(msiEndpoint, azureStorageResource); err != nil {
+			return "", err
+		}
+	}
+	token.SetSender(client)
+	if err := token.EnsureFresh(); err != nil {
+		return "", err
+	}
+	return token.OAuthToken(), nil
+}
+
+// uriEncode escapes everything but the unreserved characters, and the slashes of paths
+func uriEncode(value string, encodeSlash bool) string {
+	var encoded strings.Builder
+	for _, b := range []byte(value) {
+		if (b >= 'A' && b <= 'Z') || (b >= 'a' && b <= 'z') || (b >= '0' && b <= '9') || b == '-' || b == '_' || b == '.' || b == '~' || (b == '/' && !encodeSlash) {
+			encoded.WriteByte(b)
+		} else {
+			encoded.WriteString(fmt.Sprintf("%%%02X", b))
+		}
+	}
+	return encoded.String()
+}
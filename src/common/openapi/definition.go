@@ -0,0 +1,256 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	yorJson "github.com/bridgecrewio/yor/src/common/json"
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+	"gopkg.in/yaml.v2"
+)
+
+// TagValueExtension is the extension of the root-level tag objects of an OpenAPI definition which holds the value of an
+// AWS tag of the API Gateway API imported from it, whose key is the name of the tag object
+const TagValueExtension = "x-amazon-apigateway-tag-value"
+
+var yamlTagsKeyRegex = regexp.MustCompile(`^tags:[ \t]*(#.*)?$`)
+var yamlKeyRegex = regexp.MustCompile(`^([^\s#:][^:#]*?)[ \t]*:(?:[ \t]+(.*))?$`)
+
+// SetTag returns the OpenAPI definition of the file with the tag set in its root-level tags. The rest of the definition
+// is left untouched, so its formatting and comments are kept.
+func SetTag(file string, src []byte, tag tags.ITag) ([]byte, error) {
+	var tagged string
+	var err error
+	if isJSONDefinition(file, src) {
+		tagged, err = setJSONTag(string(src), tag.GetKey(), tag.GetValue())
+	} else {
+		tagged, err = setYAMLTag(string(src), tag.GetKey(), tag.GetValue())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to tag the OpenAPI definition %s: %w", file, err)
+	}
+	return []byte(tagged), nil
+}
+
+func isJSONDefinition(file string, src []byte) bool {
+	if strings.EqualFold(filepath.Ext(file), ".json") {
+		return true
+	}
+	return strings.HasPrefix(strings.TrimLeft(strings.TrimPrefix(string(src), "\ufeff"), " \t\r\n"), "{")
+}
+
+// quote returns the value as a double quoted string, which is valid in both JSON and YAML
+func quote(value string) string {
+	quoted, _ := json.Marshal(value)
+	return string(quoted)
+}
+
+// setJSONTag sets the tag in the tags of a JSON definition, whose members are added on the lines of their neighbours
+func setJSONTag(src string, key string, value string) (string, error) {
+	root, err := yorJson.ParseJSONNodes(src)
+	if err != nil {
+		return "", err
+	}
+	if root.Kind != yorJson.ObjectNode {
+		return "", fmt.Errorf("the definition isn't an object")
+	}
+	newTag := fmt.Sprintf(`{"name": %s, "%s": %s}`, quote(key), TagValueExtension, quote(value))
+	tagsMember := root.GetMember("tags")
+	if tagsMember == nil {
+		if len(root.Members) == 0 {
+			return src[:root.Start] + fmt.Sprintf(`{"tags": [%s]}`, newTag) + src[root.End:], nil
+		}
+		last := root.Members[len(root.Members)-1]
+		return src[:last.Value.End] + jsonSeparator(src, root.Start, last.KeyStart) + fmt.Sprintf(`"tags": [%s]`, newTag) + src[last.Value.End:], nil
+	}
+	tagsNode := tagsMember.Value
+	if tagsNode.Kind != yorJson.ArrayNode {
+		return "", fmt.Errorf("the tags of the definition aren't an array")
+	}
+	for _, element := range tagsNode.Elements {
+		nameMember := element.GetMember("name")
+		if nameMember == nil || nameMember.Value.GetString() != key {
+			continue
+		}
+		valueNode := element.Get(TagValueExtension)
+		if valueNode == nil {
+			return src[:nameMember.Value.End] + jsonSeparator(src, element.Start, nameMember.KeyStart) +
+				fmt.Sprintf(`"%s": %s`, TagValueExtension, quote(value)) + src[nameMember.Value.End:], nil
+		}
+		if valueNode.Kind == yorJson.StringNode && valueNode.GetString() == value {
+			return src, nil
+		}
+		return src[:valueNode.Start] + quote(value) + src[valueNode.End:], nil
+	}
+	if len(tagsNode.Elements) == 0 {
+		return src[:tagsNode.Start] + "[" + newTag + "]" + src[tagsNode.End:], nil
+	}
+	last := tagsNode.Elements[len(tagsNode.Elements)-1]
+	return src[:last.End] + jsonSeparator(src, tagsNode.Start, last.Start) + newTag + src[last.End:], nil
+}
+
+// jsonSeparator returns the separator of a value added after the value at index, which is on a line of its own if the
+// value is on another line than the start of its parent
+func jsonSeparator(src string, parentStart int, index int) string {
+	if yorJson.LineAt(src, parentStart) == yorJson.LineAt(src, index) {
+		return ", "
+	}
+	newLine := "\n"
+	if strings.Contains(src, "\r\n") {
+		newLine = "\r\n"
+	}
+	return "," + newLine + yorJson.LineIndent(src, index)
+}
+
+// yamlKey is a key of a mapping of a YAML definition, with the index its value starts at in its line
+type yamlKey struct {
+	line       int
+	name       string
+	value      string
+	valueStart int
+}
+
+// setYAMLTag sets the tag in the block sequence of the root-level tags of a YAML definition. The tags are added to the
+// end of the definition if it has none.
+func setYAMLTag(src string, key string, value string) (string, error) {
+	newLine := "\n"
+	if strings.Contains(src, "\r\n") {
+		newLine = "\r\n"
+	}
+	lines := strings.SplitAfter(src, "\n")
+	tagsLine := -1
+	for i, line := range lines {
+		trimmed := strings.TrimRight(line, "\r\n")
+		if yamlTagsKeyRegex.MatchString(trimmed) {
+			tagsLine = i
+			break
+		}
+		if strings.HasPrefix(trimmed, "tags:") {
+			return "", fmt.Errorf("the tags of the definition aren't a block sequence")
+		}
+	}
+	if tagsLine < 0 {
+		if src != "" && !strings.HasSuffix(src, "\n") {
+			src += newLine
+		}
+		return src + "tags:" + newLine + newYAMLTag("  ", key, value, newLine), nil
+	}
+
+	// the items of the sequence end at the next root-level key
+	itemIndent := -1
+	var items [][]int
+	for i := tagsLine + 1; i < len(lines); i++ {
+		trimmed := strings.TrimRight(lines[i], "\r\n")
+		content := strings.TrimLeft(trimmed, " ")
+		if content == "" || strings.HasPrefix(content, "#") {
+			continue
+		}
+		indent := len(trimmed) - len(content)
+		isItem := content == "-" || strings.HasPrefix(content, "- ")
+		if indent == 0 && !isItem {
+			break
+		}
+		if itemIndent < 0 && isItem {
+			itemIndent = indent
+		}
+		if isItem && indent == itemIndent {
+			items = append(items, []int{i})
+		} else if len(items) > 0 {
+			items[len(items)-1] = append(items[len(items)-1], i)
+		}
+	}
+
+	for _, item := range items {
+		keys := getYAMLItemKeys(lines, item, itemIndent)
+		var nameKey, valueKey *yamlKey
+		for i := range keys {
+			switch keys[i].name {
+			case "name":
+				nameKey = &keys[i]
+			case TagValueExtension:
+				valueKey = &keys[i]
+			}
+		}
+		if nameKey == nil || parseYAMLScalar(nameKey.value) != key {
+			continue
+		}
+		if valueKey == nil {
+			keyIndent := len(lines[nameKey.line]) - len(strings.TrimLeft(lines[nameKey.line], " -"))
+			valueLine := strings.Repeat(" ", keyIndent) + TagValueExtension + ": " + quote(value) + newLine
+			return insertLines(lines, nameKey.line+1, valueLine), nil
+		}
+		if parseYAMLScalar(valueKey.value) == value {
+			return src, nil
+		}
+		line := lines[valueKey.line]
+		lineEnd := line[len(strings.TrimRight(line, "\r\n")):]
+		lines[valueKey.line] = line[:valueKey.valueStart] + quote(value) + lineEnd
+		return strings.Join(lines, ""), nil
+	}
+
+	insertAt := tagsLine + 1
+	indent := "  "
+	if len(items) > 0 {
+		lastItem := items[len(items)-1]
+		insertAt = lastItem[len(lastItem)-1] + 1
+		indent = strings.Repeat(" ", itemIndent)
+	}
+	if insertAt == len(lines) && !strings.HasSuffix(src, "\n") {
+		lines[len(lines)-1] += newLine
+	}
+	return insertLines(lines, insertAt, newYAMLTag(indent, key, value, newLine)), nil
+}
+
+// getYAMLItemKeys returns the keys of the mapping of an item of a block sequence, without the keys of nested mappings
+func getYAMLItemKeys(lines []string, item []int, itemIndent int) []yamlKey {
+	var keys []yamlKey
+	keyIndent := -1
+	for _, i := range item {
+		line := strings.TrimRight(lines[i], "\r\n")
+		start := len(line) - len(strings.TrimLeft(line, " "))
+		if i == item[0] {
+			start = itemIndent + 1
+			start += len(line[start:]) - len(strings.TrimLeft(line[start:], " "))
+			if start == len(line) {
+				continue
+			}
+		}
+		if keyIndent < 0 {
+			keyIndent = start
+		}
+		if start != keyIndent {
+			continue
+		}
+		match := yamlKeyRegex.FindStringSubmatchIndex(line[start:])
+		if match == nil {
+			continue
+		}
+		key := yamlKey{line: i, name: parseYAMLScalar(line[start+match[2] : start+match[3]]), valueStart: len(line)}
+		if match[4] >= 0 {
+			key.value = line[start+match[4] : start+match[5]]
+			key.valueStart = start + match[4]
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// parseYAMLScalar returns the value of a plain or quoted scalar, without its trailing comment
+func parseYAMLScalar(scalar string) string {
+	var value string
+	if err := yaml.Unmarshal([]byte(scalar), &value); err != nil {
+		return strings.TrimSpace(scalar)
+	}
+	return value
+}
+
+func newYAMLTag(indent string, key string, value string, newLine string) string {
+	return indent + "- name: " + quote(key) + newLine + indent + "  " + TagValueExtension + ": " + quote(value) + newLine
+}
+
+func insertLines(lines []string, index int, text string) string {
+	return strings.Join(lines[:index], "") + text + strings.Join(lines[index:], "")
+}
@@ -0,0 +1,86 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetYAMLTag(t *testing.T) {
+	t.Run("add the tags to a definition without tags", func(t *testing.T) {
+		src := "openapi: 3.0.1\ninfo:\n  title: pets\n"
+		tagged, err := setYAMLTag(src, "yor_trace", "abc")
+		assert.Nil(t, err)
+		assert.Equal(t, src+"tags:\n  - name: \"yor_trace\"\n    x-amazon-apigateway-tag-value: \"abc\"\n", tagged)
+	})
+
+	t.Run("add a tag after the tags of the definition", func(t *testing.T) {
+		src := "openapi: 3.0.1\ntags:\n- name: pets # the pets\n  description: Everything about pets\npaths:\n  /pets:\n    get:\n      tags:\n        - pets\n"
+		tagged, err := setYAMLTag(src, "yor_trace", "abc")
+		assert.Nil(t, err)
+		assert.Equal(t, "openapi: 3.0.1\ntags:\n- name: pets # the pets\n  description: Everything about pets\n- name: \"yor_trace\"\n  x-amazon-apigateway-tag-value: \"abc\"\npaths:\n  /pets:\n    get:\n      tags:\n        - pets\n", tagged)
+	})
+
+	t.Run("update the value of the tag", func(t *testing.T) {
+		src := "tags:\n  - name: yor_trace\n    x-amazon-apigateway-tag-value: old\n  - name: pets\n"
+		tagged, err := setYAMLTag(src, "yor_trace", "abc")
+		assert.Nil(t, err)
+		assert.Equal(t, "tags:\n  - name: yor_trace\n    x-amazon-apigateway-tag-value: \"abc\"\n  - name: pets\n", tagged)
+
+		unchanged, err := setYAMLTag(tagged, "yor_trace", "abc")
+		assert.Nil(t, err)
+		assert.Equal(t, tagged, unchanged)
+	})
+
+	t.Run("add the value to the tag", func(t *testing.T) {
+		src := "tags:\n  - x-other: 1\n    name: 'yor_trace'\n"
+		tagged, err := setYAMLTag(src, "yor_trace", "abc")
+		assert.Nil(t, err)
+		assert.Equal(t, "tags:\n  - x-other: 1\n    name: 'yor_trace'\n    x-amazon-apigateway-tag-value: \"abc\"\n", tagged)
+	})
+
+	t.Run("flow collection of tags", func(t *testing.T) {
+		_, err := setYAMLTag("tags: [{name: pets}]\n", "yor_trace", "abc")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestSetJSONTag(t *testing.T) {
+	t.Run("add the tags to a definition without tags", func(t *testing.T) {
+		tagged, err := setJSONTag("{\n  \"openapi\": \"3.0.1\"\n}\n", "yor_trace", "abc")
+		assert.Nil(t, err)
+		assert.Equal(t, "{\n  \"openapi\": \"3.0.1\",\n  \"tags\": [{\"name\": \"yor_trace\", \"x-amazon-apigateway-tag-value\": \"abc\"}]\n}\n", tagged)
+	})
+
+	t.Run("add a tag after the tags of the definition", func(t *testing.T) {
+		tagged, err := setJSONTag(`{"tags": [{"name": "pets"}]}`, "yor_trace", "abc")
+		assert.Nil(t, err)
+		assert.Equal(t, `{"tags": [{"name": "pets"}, {"name": "yor_trace", "x-amazon-apigateway-tag-value": "abc"}]}`, tagged)
+	})
+
+	t.Run("update the value of the tag", func(t *testing.T) {
+		src := "{\n  \"tags\": [\n    {\n      \"name\": \"yor_trace\"\n    }\n  ]\n}"
+		tagged, err := setJSONTag(src, "yor_trace", "abc")
+		assert.Nil(t, err)
+		assert.Equal(t, "{\n  \"tags\": [\n    {\n      \"name\": \"yor_trace\",\n      \"x-amazon-apigateway-tag-value\": \"abc\"\n    }\n  ]\n}", tagged)
+
+		updated, err := setJSONTag(tagged, "yor_trace", "def")
+		assert.Nil(t, err)
+		assert.Equal(t, "{\n  \"tags\": [\n    {\n      \"name\": \"yor_trace\",\n      \"x-amazon-apigateway-tag-value\": \"def\"\n    }\n  ]\n}", updated)
+	})
+}
+
+func TestSetTag(t *testing.T) {
+	traceTag := &tags.Tag{Key: "yor_trace", Value: "abc"}
+	tagged, err := SetTag("openapi.yaml", []byte("{\"openapi\": \"3.0.1\"}"), traceTag)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"openapi": "3.0.1", "tags": [{"name": "yor_trace", "x-amazon-apigateway-tag-value": "abc"}]}`, string(tagged))
+
+	tagged, err = SetTag("openapi.yaml", []byte("openapi: 3.0.1\n"), traceTag)
+	assert.Nil(t, err)
+	assert.Equal(t, "openapi: 3.0.1\ntags:\n  - name: \"yor_trace\"\n    x-amazon-apigateway-tag-value: \"abc\"\n", string(tagged))
+
+	_, err = SetTag("openapi.json", []byte("openapi: 3.0.1\n"), traceTag)
+	assert.NotNil(t, err)
+}
@@ -1,6 +1,10 @@
 package common
 
-import "github.com/bridgecrewio/yor/src/common/structure"
+import (
+	"context"
+
+	"github.com/bridgecrewio/yor/src/common/structure"
+)
 
 type IParser interface {
 	Init(rootDir string, args map[string]string)
@@ -12,3 +16,16 @@ type IParser interface {
 	GetSupportedFileExtensions() []string
 	Close()
 }
+
+// IBufferParser is implemented by parsers which can parse content which is not saved to disk yet, as the content of
+// filePath. Parsers resolving paths relative to the file (i.e. terraform modules) must implement it for the LSP to
+// compute the tags of unsaved buffers correctly.
+type IBufferParser interface {
+	ParseBuffer(filePath string, src []byte) ([]structure.IBlock, error)
+}
+
+// IContextParser is implemented by parsers with long-running operations, such as downloading terraform modules, which
+// should stop once the run is cancelled.
+type IContextParser interface {
+	SetContext(ctx context.Context)
+}
@@ -0,0 +1,10 @@
+package common
+
+// The styles of the file paths in the report and in the tags - native paths use the separator of the OS, while posix
+// paths always use forward slashes
+const (
+	NativePathStyle = "native"
+	PosixPathStyle  = "posix"
+)
+
+var PathStyles = []string{NativePathStyle, PosixPathStyle}
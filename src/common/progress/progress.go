@@ -0,0 +1,127 @@
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	CliMode  = "cli"
+	JSONMode = "json"
+
+	cliUpdateInterval = 500 * time.Millisecond
+)
+
+var AllowedModes = []string{CliMode, JSONMode}
+
+// Event is written as a single JSON line for every processed file in the json mode
+type Event struct {
+	Processed      int     `json:"processed"`
+	Total          int     `json:"total"`
+	CurrentDir     string  `json:"currentDir"`
+	ElapsedSeconds float64 `json:"elapsedSeconds"`
+	ETASeconds     float64 `json:"etaSeconds"`
+	Done           bool    `json:"done"`
+}
+
+// Tracker reports the progress of a scan. A nil Tracker reports nothing, so callers don't need to check if progress
+// reporting is enabled.
+type Tracker struct {
+	mode       string
+	out        io.Writer
+	total      int
+	processed  int
+	startTime  time.Time
+	lastUpdate time.Time
+	lock       sync.Mutex
+	now        func() time.Time
+}
+
+// NewTracker returns a tracker writing to out in the given mode, or nil if mode is empty
+func NewTracker(mode string, out io.Writer) *Tracker {
+	if mode == "" {
+		return nil
+	}
+	return &Tracker{mode: mode, out: out, now: time.Now}
+}
+
+func (t *Tracker) Start(total int) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.total = total
+	t.processed = 0
+	t.startTime = t.now()
+}
+
+// FileDone records that file was processed. It is safe for concurrent use.
+func (t *Tracker) FileDone(file string) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.processed++
+	now := t.now()
+	// the cli line is rewritten in place, so there is no point in updating it more often than it can be read
+	if t.mode == CliMode && now.Sub(t.lastUpdate) < cliUpdateInterval && t.processed < t.total {
+		return
+	}
+	t.lastUpdate = now
+	t.write(t.createEvent(filepath.Dir(file), now))
+}
+
+func (t *Tracker) Finish() {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	event := t.createEvent("", t.now())
+	event.Done = true
+	t.write(event)
+}
+
+func (t *Tracker) createEvent(currentDir string, now time.Time) Event {
+	elapsed := now.Sub(t.startTime)
+	var eta time.Duration
+	if t.processed > 0 && t.processed < t.total {
+		eta = elapsed / time.Duration(t.processed) * time.Duration(t.total-t.processed)
+	}
+	return Event{
+		Processed:      t.processed,
+		Total:          t.total,
+		CurrentDir:     currentDir,
+		ElapsedSeconds: elapsed.Seconds(),
+		ETASeconds:     eta.Seconds(),
+	}
+}
+
+func (t *Tracker) write(event Event) {
+	switch t.mode {
+	case JSONMode:
+		eventBytes, _ := json.Marshal(event)
+		_, _ = fmt.Fprintln(t.out, string(eventBytes))
+	case CliMode:
+		if event.Done {
+			_, _ = fmt.Fprintf(t.out, "\r\033[KScanned %d/%d files in %v\n", event.Processed, event.Total, secondsToDuration(event.ElapsedSeconds))
+			return
+		}
+		percent := 100
+		if event.Total > 0 {
+			percent = event.Processed * 100 / event.Total
+		}
+		_, _ = fmt.Fprintf(t.out, "\r\033[KScanning %d/%d files (%d%%), ETA %v - %s", event.Processed, event.Total, percent,
+			secondsToDuration(event.ETASeconds), event.CurrentDir)
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Second)
+}
@@ -0,0 +1,66 @@
+package progress
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracker(t *testing.T) {
+	mockClock := func() func() time.Time {
+		current := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+		return func() time.Time {
+			current = current.Add(time.Second)
+			return current
+		}
+	}
+
+	t.Run("json events", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		tracker := NewTracker(JSONMode, out)
+		tracker.now = mockClock()
+		tracker.Start(4)
+		tracker.FileDone("modules/s3/main.tf")
+		tracker.FileDone("modules/s3/variables.tf")
+		tracker.Finish()
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		assert.Equal(t, 3, len(lines))
+		var event Event
+		assert.Nil(t, json.Unmarshal([]byte(lines[0]), &event))
+		assert.Equal(t, Event{Processed: 1, Total: 4, CurrentDir: "modules/s3", ElapsedSeconds: 1, ETASeconds: 3}, event)
+		assert.Nil(t, json.Unmarshal([]byte(lines[2]), &event))
+		assert.True(t, event.Done)
+		assert.Equal(t, 2, event.Processed)
+	})
+
+	t.Run("cli updates are throttled", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		tracker := NewTracker(CliMode, out)
+		now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+		tracker.now = func() time.Time { return now }
+		tracker.Start(3)
+		tracker.FileDone("a/main.tf")
+		tracker.FileDone("b/main.tf")
+		tracker.FileDone("c/main.tf")
+		tracker.Finish()
+
+		output := out.String()
+		assert.Contains(t, output, "Scanning 1/3 files (33%)")
+		assert.NotContains(t, output, "Scanning 2/3 files")
+		assert.Contains(t, output, "Scanning 3/3 files (100%)")
+		assert.Contains(t, output, "Scanned 3/3 files")
+	})
+
+	t.Run("disabled tracker", func(t *testing.T) {
+		tracker := NewTracker("", &bytes.Buffer{})
+		assert.Nil(t, tracker)
+		tracker.Start(1)
+		tracker.FileDone("main.tf")
+		tracker.Finish()
+	})
+}
@@ -0,0 +1,106 @@
+package pullrequest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const requestTimeout = 30 * time.Second
+
+type GitHubProvider struct {
+	APIURL string
+	Token  string
+	client *http.Client
+}
+
+type GitLabProvider struct {
+	APIURL string
+	Token  string
+	client *http.Client
+}
+
+// NewGitHubProvider returns a provider for github.com, or for a GitHub Enterprise server on any other host
+func NewGitHubProvider(host string, token string) *GitHubProvider {
+	apiURL := "https://api.github.com"
+	if host != "" && host != "github.com" {
+		apiURL = fmt.Sprintf("https://%s/api/v3", host)
+	}
+	return &GitHubProvider{APIURL: apiURL, Token: token, client: &http.Client{Timeout: requestTimeout}}
+}
+
+func NewGitLabProvider(host string, token string) *GitLabProvider {
+	if host == "" {
+		host = "gitlab.com"
+	}
+	return &GitLabProvider{APIURL: fmt.Sprintf("https://%s/api/v4", host), Token: token, client: &http.Client{Timeout: requestTimeout}}
+}
+
+func (p *GitHubProvider) Name() string {
+	return GitHubProviderName
+}
+
+func (p *GitHubProvider) CreatePullRequest(pr *PullRequest) (string, error) {
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/pulls", p.APIURL, pr.Organization, pr.Repository)
+	payload := map[string]string{"title": pr.Title, "head": pr.Head, "base": pr.Base, "body": pr.Body}
+	var response struct {
+		HTMLURL string `json:"html_url"`
+	}
+	headers := map[string]string{"Authorization": "token " + p.Token, "Accept": "application/vnd.github.v3+json"}
+	if err := postJSON(p.client, endpoint, headers, payload, &response); err != nil {
+		return "", err
+	}
+	return response.HTMLURL, nil
+}
+
+func (p *GitLabProvider) Name() string {
+	return GitLabProviderName
+}
+
+func (p *GitLabProvider) CreatePullRequest(pr *PullRequest) (string, error) {
+	projectID := url.PathEscape(fmt.Sprintf("%s/%s", pr.Organization, pr.Repository))
+	endpoint := fmt.Sprintf("%s/projects/%s/merge_requests", p.APIURL, projectID)
+	payload := map[string]string{"title": pr.Title, "source_branch": pr.Head, "target_branch": pr.Base, "description": pr.Body}
+	var response struct {
+		WebURL string `json:"web_url"`
+	}
+	headers := map[string]string{"PRIVATE-TOKEN": p.Token}
+	if err := postJSON(p.client, endpoint, headers, payload, &response); err != nil {
+		return "", err
+	}
+	return response.WebURL, nil
+}
+
+func postJSON(client *http.Client, endpoint string, headers map[string]string, payload interface{}, response interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request to %s: %s", endpoint, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("request to %s failed with status %d: %s", endpoint, resp.StatusCode, string(respBody))
+	}
+	return json.Unmarshal(respBody, response)
+}
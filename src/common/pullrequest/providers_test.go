@@ -0,0 +1,78 @@
+package pullrequest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviders(t *testing.T) {
+	pr := &PullRequest{Organization: "bridgecrewio", Repository: "terragoat", Head: "yor/tags-1", Base: "main", Title: DefaultTitle, Body: "## Yor Findings Summary"}
+
+	t.Run("GitHub pull request", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/repos/bridgecrewio/terragoat/pulls", r.URL.Path)
+			assert.Equal(t, "token secret", r.Header.Get("Authorization"))
+			var payload map[string]string
+			_ = json.NewDecoder(r.Body).Decode(&payload)
+			assert.Equal(t, map[string]string{"title": DefaultTitle, "head": "yor/tags-1", "base": "main", "body": "## Yor Findings Summary"}, payload)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"html_url": "https://github.com/bridgecrewio/terragoat/pull/1"}`))
+		}))
+		defer server.Close()
+
+		provider := NewGitHubProvider("github.com", "secret")
+		provider.APIURL = server.URL
+		prURL, err := provider.CreatePullRequest(pr)
+		assert.Nil(t, err)
+		assert.Equal(t, "https://github.com/bridgecrewio/terragoat/pull/1", prURL)
+	})
+
+	t.Run("GitLab merge request", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/projects/bridgecrewio%2Fterragoat/merge_requests", r.URL.RawPath)
+			assert.Equal(t, "secret", r.Header.Get("PRIVATE-TOKEN"))
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"web_url": "https://gitlab.com/bridgecrewio/terragoat/-/merge_requests/1"}`))
+		}))
+		defer server.Close()
+
+		provider := NewGitLabProvider("gitlab.com", "secret")
+		provider.APIURL = server.URL
+		prURL, err := provider.CreatePullRequest(pr)
+		assert.Nil(t, err)
+		assert.Equal(t, "https://gitlab.com/bridgecrewio/terragoat/-/merge_requests/1", prURL)
+	})
+
+	t.Run("Failed request", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"message": "Validation Failed"}`))
+		}))
+		defer server.Close()
+
+		provider := NewGitHubProvider("github.com", "secret")
+		provider.APIURL = server.URL
+		_, err := provider.CreatePullRequest(pr)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Infer provider from host", func(t *testing.T) {
+		provider, err := NewProvider("", "github.example.com", "secret")
+		assert.Nil(t, err)
+		assert.Equal(t, GitHubProviderName, provider.Name())
+		assert.Equal(t, "https://github.example.com/api/v3", provider.(*GitHubProvider).APIURL)
+
+		provider, err = NewProvider("", "gitlab.example.com", "secret")
+		assert.Nil(t, err)
+		assert.Equal(t, GitLabProviderName, provider.Name())
+
+		_, err = NewProvider("", "bitbucket.org", "secret")
+		assert.NotNil(t, err)
+		_, err = NewProvider("github", "github.com", "")
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,231 @@
+package pullrequest
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bridgecrewio/yor/src/common/gitservice"
+	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const (
+	GitHubProviderName = "github"
+	GitLabProviderName = "gitlab"
+	DefaultTitle       = "Update tags (by Yor)"
+	DefaultAuthorName  = "yor"
+	DefaultAuthorEmail = "yor@bridgecrew.io"
+	branchPrefix       = "yor/tags-"
+)
+
+type Options struct {
+	Branch   string
+	Base     string
+	Title    string
+	Provider string
+}
+
+type PullRequest struct {
+	Organization string
+	Repository   string
+	Head         string
+	Base         string
+	Title        string
+	Body         string
+}
+
+// IProvider opens pull requests in a git hosting service
+type IProvider interface {
+	Name() string
+	CreatePullRequest(pr *PullRequest) (string, error)
+}
+
+// Create commits the files yor changed in the repository of dir to a new branch, pushes it to origin and opens a pull
+// request with the given body. Other changes in the worktree are left out of the pull request, and the original HEAD
+// is checked out again once the branch is pushed. It returns the URL of the pull request, or an empty string if there
+// were no changes.
+func Create(dir string, options Options, body string, changedFiles []string) (string, error) {
+	repository, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return "", fmt.Errorf("failed to open the git repository of %s: %s", dir, err)
+	}
+	remote, err := repository.Remote("origin")
+	if err != nil {
+		return "", fmt.Errorf("failed to find the origin remote: %s", err)
+	}
+	remoteURL := remote.Config().URLs[0]
+	host, org, repoName, err := gitservice.ParseRemoteURL(remoteURL)
+	if err != nil {
+		return "", err
+	}
+	provider, err := NewProvider(options.Provider, host, gitservice.GetGitToken())
+	if err != nil {
+		return "", err
+	}
+	auth, err := gitservice.GetRemoteAuthForURL(remoteURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the authentication for %s: %s", remoteURL, err)
+	}
+
+	head, err := repository.Head()
+	if err != nil {
+		return "", fmt.Errorf("failed to get repository HEAD: %s", err)
+	}
+	base := options.Base
+	if base == "" {
+		if !head.Name().IsBranch() {
+			return "", fmt.Errorf("HEAD is detached, please specify the base branch of the pull request")
+		}
+		base = head.Name().Short()
+	}
+	branch := options.Branch
+	if branch == "" {
+		branch = fmt.Sprintf("%s%d", branchPrefix, time.Now().Unix())
+	}
+
+	committed, err := commitChanges(repository, head, branch, options.titleOrDefault(), changedFiles)
+	if err != nil || !committed {
+		return "", err
+	}
+	logger.Info(fmt.Sprintf("Pushing branch %s to origin", branch))
+	refSpec := config.RefSpec(fmt.Sprintf("refs/heads/%s:refs/heads/%s", branch, branch))
+	err = repository.Push(&git.PushOptions{RemoteName: "origin", RefSpecs: []config.RefSpec{refSpec}, Auth: auth})
+	if err != nil {
+		return "", fmt.Errorf("failed to push branch %s: %s", branch, err)
+	}
+
+	return provider.CreatePullRequest(&PullRequest{
+		Organization: org,
+		Repository:   repoName,
+		Head:         branch,
+		Base:         base,
+		Title:        options.titleOrDefault(),
+		Body:         body,
+	})
+}
+
+func (o Options) titleOrDefault() string {
+	if o.Title == "" {
+		return DefaultTitle
+	}
+	return o.Title
+}
+
+// commitChanges commits the changed files to a new branch, and checks out head again, keeping the worktree as is
+func commitChanges(repository *git.Repository, head *plumbing.Reference, branch string, message string, changedFiles []string) (bool, error) {
+	worktree, err := repository.Worktree()
+	if err != nil {
+		return false, err
+	}
+	filesToCommit, err := getFilesToCommit(worktree, changedFiles)
+	if err != nil || len(filesToCommit) == 0 {
+		return false, err
+	}
+
+	err = worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(branch), Create: true, Keep: true})
+	if err != nil {
+		return false, fmt.Errorf("failed to create branch %s: %s", branch, err)
+	}
+	defer restoreHead(worktree, head)
+	for _, file := range filesToCommit {
+		if _, err = worktree.Add(file); err != nil {
+			return false, fmt.Errorf("failed to stage %s: %s", file, err)
+		}
+	}
+	email := gitservice.GetGitUserEmail()
+	if email == "" {
+		email = DefaultAuthorEmail
+	}
+	_, err = worktree.Commit(message, &git.CommitOptions{
+		Author: &object.Signature{Name: DefaultAuthorName, Email: email, When: time.Now()},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to commit the changes: %s", err)
+	}
+	logger.Info(fmt.Sprintf("Committed %d changed files to branch %s", len(filesToCommit), branch))
+	return true, nil
+}
+
+// getFilesToCommit returns the paths of the changed files relative to the worktree, leaving out the files which weren't
+// modified (i.e. in a dry run). It fails if other changes are staged, as they would be committed along.
+func getFilesToCommit(worktree *git.Worktree, changedFiles []string) ([]string, error) {
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the worktree status: %s", err)
+	}
+	root := worktree.Filesystem.Root()
+	filesToCommit := make([]string, 0)
+	isChangedFile := make(map[string]bool)
+	for _, file := range changedFiles {
+		absPath, err := filepath.Abs(file)
+		if err != nil {
+			return nil, err
+		}
+		relativePath, err := filepath.Rel(root, absPath)
+		if err != nil {
+			return nil, err
+		}
+		relativePath = filepath.ToSlash(relativePath)
+		isChangedFile[relativePath] = true
+		if fileStatus, ok := status[relativePath]; ok && fileStatus.Worktree == git.Modified {
+			filesToCommit = append(filesToCommit, relativePath)
+		}
+	}
+	for file, fileStatus := range status {
+		if !isChangedFile[file] && fileStatus.Staging != git.Unmodified && fileStatus.Staging != git.Untracked {
+			return nil, fmt.Errorf("%s has staged changes, please commit or unstage them before creating a pull request", file)
+		}
+	}
+	if len(filesToCommit) == 0 {
+		logger.Info("No files were changed, skipping the pull request creation")
+	}
+	sort.Strings(filesToCommit)
+	return filesToCommit, nil
+}
+
+// restoreHead checks out the original HEAD, and unstages the committed files so the worktree is left as it was
+func restoreHead(worktree *git.Worktree, head *plumbing.Reference) {
+	checkoutOptions := &git.CheckoutOptions{Keep: true}
+	if head.Name().IsBranch() {
+		checkoutOptions.Branch = head.Name()
+	} else {
+		checkoutOptions.Hash = head.Hash()
+	}
+	err := worktree.Checkout(checkoutOptions)
+	if err == nil {
+		err = worktree.Reset(&git.ResetOptions{Commit: head.Hash(), Mode: git.MixedReset})
+	}
+	if err != nil {
+		logger.Warning(fmt.Sprintf("Failed to check out %s again: %s", head.Name().Short(), err))
+	}
+}
+
+// NewProvider returns the provider by its name, or by the host of the remote if no name is given
+func NewProvider(name string, host string, token string) (IProvider, error) {
+	if token == "" {
+		return nil, fmt.Errorf("a token is required to create a pull request, please set %s", gitservice.GitTokenEnvKey)
+	}
+	if name == "" {
+		if strings.Contains(host, GitLabProviderName) {
+			name = GitLabProviderName
+		} else if strings.Contains(host, GitHubProviderName) {
+			name = GitHubProviderName
+		} else {
+			return nil, fmt.Errorf("could not infer the pull request provider of %s, please specify it explicitly", host)
+		}
+	}
+	switch strings.ToLower(name) {
+	case GitHubProviderName:
+		return NewGitHubProvider(host, token), nil
+	case GitLabProviderName:
+		return NewGitLabProvider(host, token), nil
+	default:
+		return nil, fmt.Errorf("unsupported pull request provider %s", name)
+	}
+}
@@ -0,0 +1,79 @@
+package pullrequest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommitChanges(t *testing.T) {
+	dir := t.TempDir()
+	repository, err := git.PlainInit(dir, false)
+	assert.Nil(t, err)
+	worktree, err := repository.Worktree()
+	assert.Nil(t, err)
+	writeFile := func(name string, content string) {
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+	writeFile("tagged.tf", "resource \"aws_s3_bucket\" \"a\" {\n}\n")
+	writeFile("user.tf", "resource \"aws_s3_bucket\" \"b\" {\n}\n")
+	_, err = worktree.Add("tagged.tf")
+	assert.Nil(t, err)
+	_, err = worktree.Add("user.tf")
+	assert.Nil(t, err)
+	_, err = worktree.Commit("initial", &git.CommitOptions{Author: &object.Signature{Name: "user", Email: "user@example.com", When: time.Now()}})
+	assert.Nil(t, err)
+	head, err := repository.Head()
+	assert.Nil(t, err)
+
+	// yor tagged tagged.tf, while user.tf and new.tf are unrelated changes of the user
+	writeFile("tagged.tf", "resource \"aws_s3_bucket\" \"a\" {\n  tags = {\n    yor_trace = \"123\"\n  }\n}\n")
+	writeFile("user.tf", "resource \"aws_s3_bucket\" \"b\" {\n  acl = \"private\"\n}\n")
+	writeFile("new.tf", "resource \"aws_s3_bucket\" \"c\" {\n}\n")
+
+	t.Run("Commit only the files yor changed", func(t *testing.T) {
+		committed, err := commitChanges(repository, head, "yor/tags-test", DefaultTitle, []string{filepath.Join(dir, "tagged.tf"), filepath.Join(dir, "user.tf.bak")})
+		assert.Nil(t, err)
+		assert.True(t, committed)
+
+		branch, err := repository.Reference("refs/heads/yor/tags-test", true)
+		assert.Nil(t, err)
+		commit, err := repository.CommitObject(branch.Hash())
+		assert.Nil(t, err)
+		stats, err := commit.Stats()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(stats))
+		assert.Equal(t, "tagged.tf", stats[0].Name)
+
+		// the original branch is checked out again, with the worktree left as it was
+		currentHead, err := repository.Head()
+		assert.Nil(t, err)
+		assert.Equal(t, head.Name(), currentHead.Name())
+		assert.Equal(t, head.Hash(), currentHead.Hash())
+		status, err := worktree.Status()
+		assert.Nil(t, err)
+		assert.Equal(t, git.Modified, status.File("tagged.tf").Worktree)
+		assert.Equal(t, git.Unmodified, status.File("tagged.tf").Staging)
+		assert.Equal(t, git.Modified, status.File("user.tf").Worktree)
+		assert.Equal(t, git.Untracked, status.File("new.tf").Worktree)
+	})
+
+	t.Run("Skip unchanged files", func(t *testing.T) {
+		committed, err := commitChanges(repository, head, "yor/tags-unchanged", DefaultTitle, []string{filepath.Join(dir, "new.tf")})
+		assert.Nil(t, err)
+		assert.False(t, committed)
+	})
+
+	t.Run("Fail on other staged changes", func(t *testing.T) {
+		_, err = worktree.Add("user.tf")
+		assert.Nil(t, err)
+		committed, err := commitChanges(repository, head, "yor/tags-staged", DefaultTitle, []string{filepath.Join(dir, "tagged.tf")})
+		assert.NotNil(t, err)
+		assert.False(t, committed)
+	})
+}
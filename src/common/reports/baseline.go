@@ -0,0 +1,80 @@
+package reports
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LoadBaseline reads the JSON report of a prior run, whose findings are suppressed from the reports of later runs
+func LoadBaseline(baselinePath string) (*Report, error) {
+	// #nosec G304 - file is from user
+	baselineBytes, err := os.ReadFile(baselinePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the baseline %s: %s", baselinePath, err)
+	}
+	baseline := &Report{}
+	if err = json.Unmarshal(baselineBytes, baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse the baseline %s, expected a JSON report of yor: %s", baselinePath, err)
+	}
+	return baseline, nil
+}
+
+// SetBaseline sets the report of a prior run. The new and updated tags, and the required tag violations, which were
+// already found in it are left out of the report, so only the resources which regressed since are reported. A finding
+// matches the baseline by its file, resource and tag key, so tag values which change from run to run (i.e. git
+// commits) don't break the match.
+func (r *ReportService) SetBaseline(baseline *Report) {
+	r.baseline = baseline
+}
+
+func findingKey(file string, resourceID string, tagKey string) string {
+	// the baseline may have been created on another OS, so paths are compared in posix style
+	return strings.Join([]string{strings.ReplaceAll(file, "\\", "/"), resourceID, tagKey}, "|")
+}
+
+func (r *ReportService) applyBaseline() {
+	if r.baseline == nil {
+		return
+	}
+	baselineFindings := make(map[string]bool)
+	for _, records := range [][]TagRecord{r.baseline.NewResourceTags, r.baseline.UpdatedResourceTags} {
+		for _, record := range records {
+			baselineFindings[findingKey(record.File, record.ResourceID, record.TagKey)] = true
+		}
+	}
+	baselineViolations := make(map[string]bool)
+	for _, violation := range r.baseline.RequiredTagViolations {
+		baselineViolations[findingKey(violation.File, violation.ResourceID, violation.TagKey)] = true
+	}
+
+	suppressed := 0
+	filterRecords := func(records []TagRecord) ([]TagRecord, int) {
+		filtered := []TagRecord{}
+		resources := make(map[string]bool)
+		for _, record := range records {
+			if baselineFindings[findingKey(record.File, record.ResourceID, record.TagKey)] {
+				suppressed++
+				continue
+			}
+			filtered = append(filtered, record)
+			resources[findingKey(record.File, record.ResourceID, "")] = true
+		}
+		return filtered, len(resources)
+	}
+	r.report.NewResourceTags, r.report.Summary.NewResources = filterRecords(r.report.NewResourceTags)
+	r.report.UpdatedResourceTags, r.report.Summary.UpdatedResources = filterRecords(r.report.UpdatedResourceTags)
+
+	var violations []RequiredTagViolation
+	for _, violation := range r.report.RequiredTagViolations {
+		if baselineViolations[findingKey(violation.File, violation.ResourceID, violation.TagKey)] {
+			suppressed++
+			continue
+		}
+		violations = append(violations, violation)
+	}
+	r.report.RequiredTagViolations = violations
+	r.report.Summary.RequiredTagViolations = len(violations)
+	r.report.Summary.SuppressedByBaseline = suppressed
+}
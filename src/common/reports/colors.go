@@ -0,0 +1,72 @@
+package reports
+
+import (
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+
+	// NoColorEnvKey disables the colors of the auto mode when it is set to any value, see https://no-color.org
+	NoColorEnvKey = "NO_COLOR"
+	// ColorForceEnvKey enables the colors of the auto mode when it is set to any value but 0, even if stdout isn't a
+	// terminal
+	ColorForceEnvKey = "CLICOLOR_FORCE"
+)
+
+var AllowedColorModes = []string{ColorAuto, ColorAlways, ColorNever}
+
+// ColorStruct holds the escape codes of the colors of the cli output, which are all empty when colors are disabled
+type ColorStruct struct {
+	Reset  string
+	Green  string
+	Yellow string
+	Blue   string
+	Purple string
+}
+
+var colors = newColorStruct(true)
+
+func newColorStruct(enabled bool) ColorStruct {
+	if !enabled {
+		return ColorStruct{}
+	}
+	return ColorStruct{
+		Reset:  "\033[0m",
+		Green:  "\033[32m",
+		Yellow: "\033[33m",
+		Blue:   "\033[34m",
+		Purple: "\033[35m",
+	}
+}
+
+func (c ColorStruct) Enabled() bool {
+	return c.Reset != ""
+}
+
+// SetColorMode sets whether the banner, the summary and the tables of the cli output are colored. The auto mode colors
+// them when stdout is a terminal, unless NO_COLOR is set, or when CLICOLOR_FORCE is set.
+func SetColorMode(mode string) {
+	colors = newColorStruct(isColorEnabled(strings.ToLower(mode), os.Stdout))
+}
+
+func isColorEnabled(mode string, out *os.File) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	if os.Getenv(NoColorEnvKey) != "" {
+		return false
+	}
+	if force := os.Getenv(ColorForceEnvKey); force != "" && force != "0" {
+		return true
+	}
+	return isatty.IsTerminal(out.Fd()) || isatty.IsCygwinTerminal(out.Fd())
+}
@@ -0,0 +1,51 @@
+package reports
+
+import (
+	"os"
+	"testing"
+
+	"github.com/bridgecrewio/yor/tests/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColorMode(t *testing.T) {
+	defer func() {
+		colors = newColorStruct(true)
+	}()
+	// the output of the tests is a pipe rather than a terminal
+	pipeReader, pipeWriter, err := os.Pipe()
+	assert.Nil(t, err)
+	defer pipeReader.Close()
+	defer pipeWriter.Close()
+
+	t.Run("Colors of the explicit modes", func(t *testing.T) {
+		t.Setenv(NoColorEnvKey, "1")
+		assert.True(t, isColorEnabled(ColorAlways, pipeWriter))
+		t.Setenv(NoColorEnvKey, "")
+		t.Setenv(ColorForceEnvKey, "1")
+		assert.False(t, isColorEnabled(ColorNever, pipeWriter))
+	})
+
+	t.Run("Colors of the auto mode", func(t *testing.T) {
+		t.Setenv(NoColorEnvKey, "")
+		t.Setenv(ColorForceEnvKey, "")
+		assert.False(t, isColorEnabled(ColorAuto, pipeWriter))
+		t.Setenv(ColorForceEnvKey, "0")
+		assert.False(t, isColorEnabled(ColorAuto, pipeWriter))
+		t.Setenv(ColorForceEnvKey, "1")
+		assert.True(t, isColorEnabled(ColorAuto, pipeWriter))
+		// NO_COLOR takes precedence over CLICOLOR_FORCE
+		t.Setenv(NoColorEnvKey, "1")
+		assert.False(t, isColorEnabled(ColorAuto, pipeWriter))
+	})
+
+	t.Run("No escape codes without colors", func(t *testing.T) {
+		SetColorMode(ColorNever)
+		assert.False(t, colors.Enabled())
+		reportService := NewReportService(NewTagChangeAccumulator())
+		reportService.CreateReport()
+		output := utils.CaptureOutput(reportService.PrintToStdout)
+		assert.Contains(t, output, "Yor Findings Summary")
+		assert.NotContains(t, output, "\033[")
+	})
+}
@@ -0,0 +1,101 @@
+package reports
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+)
+
+// Enrichment maps the yor_trace of each taggable resource to its location in the code and to its owners according to
+// git. The locations have the fields of the resources of Checkov results (file_path, file_line_range and resource),
+// with file paths relative to the tagged directory, so the results of a Checkov or Prisma Cloud scan of the same
+// directory can be linked to the owners of their resources by their yor_trace tag or by their location.
+type Enrichment struct {
+	// Repository is the git organization and repository of the resources, if the git tags were computed
+	Repository string                        `json:"repository,omitempty"`
+	Resources  map[string]EnrichmentResource `json:"resources"`
+}
+
+type EnrichmentResource struct {
+	FilePath      string   `json:"file_path"`
+	FileLineRange []int    `json:"file_line_range"`
+	Resource      string   `json:"resource"`
+	ResourceType  string   `json:"resource_type"`
+	CodeOwners    []string `json:"code_owners,omitempty"`
+	LastModifier  string   `json:"last_modified_by,omitempty"`
+	LastModified  string   `json:"last_modified_at,omitempty"`
+	Commit        string   `json:"commit,omitempty"`
+}
+
+// SetDirectory sets the tagged directory, which the file paths of the enrichment are relative to
+func (r *ReportService) SetDirectory(directory string) {
+	r.directory = directory
+}
+
+// SetKeyNames sets the names of the renamed built-in tags, which the git owners of the enrichment are read from
+func (r *ReportService) SetKeyNames(keyNames tags.KeyNames) {
+	r.keyNames = keyNames
+}
+
+// GetEnrichment returns the resources of the scanned blocks which have a yor_trace tag, after the run, by yor_trace
+func (r *ReportService) GetEnrichment() *Enrichment {
+	enrichment := &Enrichment{Resources: map[string]EnrichmentResource{}}
+	for _, block := range r.accumulator.GetScannedBlocks() {
+		if !block.IsBlockTaggable() {
+			continue
+		}
+		traceID := block.GetTraceID()
+		if traceID == "" {
+			continue
+		}
+		blockTags := map[string]string{}
+		for _, tag := range block.MergeTags() {
+			blockTags[tag.GetKey()] = tag.GetValue()
+		}
+		lines := block.GetLines()
+		resource := EnrichmentResource{
+			FilePath:      r.getEnrichmentPath(block.GetFilePath()),
+			FileLineRange: []int{lines.Start, lines.End},
+			Resource:      block.GetResourceID(),
+			ResourceType:  block.GetResourceType(),
+			LastModifier:  blockTags[r.keyNames.Get(tags.GitLastModifiedByTagKey)],
+			LastModified:  blockTags[r.keyNames.Get(tags.GitLastModifiedAtTagKey)],
+			Commit:        blockTags[r.keyNames.Get(tags.GitCommitTagKey)],
+		}
+		if modifiers := blockTags[r.keyNames.Get(tags.GitModifiersTagKey)]; modifiers != "" {
+			resource.CodeOwners = strings.Split(modifiers, "/")
+		}
+		enrichment.Resources[traceID] = resource
+		if enrichment.Repository == "" && blockTags[r.keyNames.Get(tags.GitRepoTagKey)] != "" {
+			enrichment.Repository = blockTags[r.keyNames.Get(tags.GitOrgTagKey)] + "/" + blockTags[r.keyNames.Get(tags.GitRepoTagKey)]
+		}
+	}
+	return enrichment
+}
+
+// getEnrichmentPath returns the path of the file relative to the tagged directory, with forward slashes and a leading
+// slash like the file paths of Checkov results
+func (r *ReportService) getEnrichmentPath(file string) string {
+	if r.directory != "" {
+		if relPath, err := filepath.Rel(r.directory, file); err == nil && !strings.HasPrefix(relPath, "..") {
+			file = relPath
+		}
+	}
+	return "/" + strings.TrimPrefix(filepath.ToSlash(file), "/")
+}
+
+func (r *ReportService) PrintEnrichmentToFile(file string) {
+	enrichmentBytes, err := json.MarshalIndent(r.GetEnrichment(), "", "    ")
+	if err != nil {
+		logger.Warning("Failed to create the enrichment as JSON")
+		return
+	}
+
+	err = writeOutputFile(file, enrichmentBytes, "application/json")
+	if err != nil {
+		logger.Warning("Failed to write to the enrichment file", err.Error())
+	}
+}
@@ -0,0 +1,190 @@
+package reports
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/bridgecrewio/yor/src/common/structure"
+)
+
+const (
+	ModuleNode   = "module"
+	FileNode     = "file"
+	ResourceNode = "resource"
+
+	// ContainsEdge relates a module to its files, and a file to its resources
+	ContainsEdge = "contains"
+	// CallsEdge relates a module call to the module it calls, or a nested stack to the template of its stack
+	CallsEdge = "calls"
+)
+
+// Graph relates the scanned resources to their files and modules, and the module calls to the modules they call, so
+// the propagation of tags through module hierarchies can be visualized
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+type GraphNode struct {
+	ID    string `json:"id"`
+	Kind  string `json:"kind"`
+	Label string `json:"label"`
+	// Tags are the final tags of taggable resources, after the run
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	Kind string `json:"kind"`
+}
+
+type graphBuilder struct {
+	graph      *Graph
+	nodeByID   map[string]bool
+	formatPath func(string) string
+}
+
+// GetGraph returns the graph of the taggable resources and the module calls which were scanned. The modules of the
+// graph are the directories of the files, and the sources of remote modules.
+func (r *ReportService) GetGraph() *Graph {
+	builder := &graphBuilder{graph: &Graph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}, nodeByID: make(map[string]bool), formatPath: r.formatPath}
+	for _, block := range r.accumulator.GetScannedBlocks() {
+		var moduleSource, templateFile string
+		if moduleCallBlock, ok := block.(structure.IModuleCallBlock); ok {
+			moduleSource = moduleCallBlock.GetModuleSource()
+		}
+		if nestedStackBlock, ok := block.(structure.INestedStackBlock); ok {
+			_, templateFile = nestedStackBlock.GetNestedTemplate()
+		}
+		if !block.IsBlockTaggable() && moduleSource == "" && templateFile == "" {
+			continue
+		}
+		fileID := builder.addFile(block.GetFilePath())
+		resourceNode := GraphNode{
+			ID:    fmt.Sprintf("%s:%s:%s", ResourceNode, r.formatPath(block.GetFilePath()), block.GetResourceID()),
+			Kind:  ResourceNode,
+			Label: block.GetResourceID(),
+		}
+		if block.IsBlockTaggable() {
+			resourceNode.Tags = make(map[string]string)
+			for _, tag := range block.MergeTags() {
+				resourceNode.Tags[tag.GetKey()] = tag.GetValue()
+			}
+		}
+		builder.addNode(resourceNode)
+		builder.addEdge(fileID, resourceNode.ID, ContainsEdge)
+		if moduleSource != "" {
+			builder.addEdge(resourceNode.ID, builder.addModule(moduleSource), CallsEdge)
+		}
+		if templateFile != "" {
+			builder.addEdge(resourceNode.ID, builder.addFile(templateFile), CallsEdge)
+		}
+	}
+	sort.SliceStable(builder.graph.Nodes, func(i, j int) bool {
+		return builder.graph.Nodes[i].ID < builder.graph.Nodes[j].ID
+	})
+	sort.SliceStable(builder.graph.Edges, func(i, j int) bool {
+		if builder.graph.Edges[i].From != builder.graph.Edges[j].From {
+			return builder.graph.Edges[i].From < builder.graph.Edges[j].From
+		}
+		return builder.graph.Edges[i].To < builder.graph.Edges[j].To
+	})
+	return builder.graph
+}
+
+func (b *graphBuilder) addNode(node GraphNode) {
+	if !b.nodeByID[node.ID] {
+		b.nodeByID[node.ID] = true
+		b.graph.Nodes = append(b.graph.Nodes, node)
+	}
+}
+
+func (b *graphBuilder) addEdge(from string, to string, kind string) {
+	b.graph.Edges = append(b.graph.Edges, GraphEdge{From: from, To: to, Kind: kind})
+}
+
+// addModule adds the node of the module in the given directory, or of the remote module with the given source, and
+// returns its id
+func (b *graphBuilder) addModule(module string) string {
+	label := b.formatPath(module)
+	id := fmt.Sprintf("%s:%s", ModuleNode, label)
+	b.addNode(GraphNode{ID: id, Kind: ModuleNode, Label: label})
+	return id
+}
+
+// addFile adds the node of the file, contained by the module of its directory, and returns its id
+func (b *graphBuilder) addFile(file string) string {
+	label := b.formatPath(file)
+	id := fmt.Sprintf("%s:%s", FileNode, label)
+	if !b.nodeByID[id] {
+		b.addNode(GraphNode{ID: id, Kind: FileNode, Label: label})
+		b.addEdge(b.addModule(filepath.Dir(file)), id, ContainsEdge)
+	}
+	return id
+}
+
+// AsDOT returns the graph in the DOT language of Graphviz, with the final tags of each resource in its label
+func (g *Graph) AsDOT() string {
+	shapes := map[string]string{ModuleNode: "folder", FileNode: "note", ResourceNode: "box"}
+	var sb strings.Builder
+	sb.WriteString("digraph yor {\n  rankdir=LR;\n")
+	for _, node := range g.Nodes {
+		lines := []string{escapeDOT(node.Label)}
+		var keys []string
+		for key := range node.Tags {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			lines = append(lines, escapeDOT(fmt.Sprintf("%s = %s", key, node.Tags[key])))
+		}
+		// \l ends each line left aligned, so the tags line up under the resource
+		label := strings.Join(lines, "\\l") + "\\l"
+		if len(lines) == 1 {
+			label = lines[0]
+		}
+		sb.WriteString(fmt.Sprintf("  \"%s\" [label=\"%s\", shape=%s];\n", escapeDOT(node.ID), label, shapes[node.Kind]))
+	}
+	for _, edge := range g.Edges {
+		style := ""
+		if edge.Kind == CallsEdge {
+			style = " [label=\"calls\", style=dashed]"
+		}
+		sb.WriteString(fmt.Sprintf("  \"%s\" -> \"%s\"%s;\n", escapeDOT(edge.From), escapeDOT(edge.To), style))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func escapeDOT(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n").Replace(s)
+}
+
+// PrintGraphToFile writes the graph of the run to the file, in DOT if its extension is .dot or .gv, and in JSON
+// otherwise
+func (r *ReportService) PrintGraphToFile(file string) {
+	graph := r.GetGraph()
+	var graphBytes []byte
+	contentType := "application/json"
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".dot", ".gv":
+		graphBytes = []byte(graph.AsDOT())
+		contentType = "text/vnd.graphviz"
+	default:
+		var err error
+		graphBytes, err = json.MarshalIndent(graph, "", "    ")
+		if err != nil {
+			logger.Warning("Failed to create the graph as JSON")
+			return
+		}
+	}
+
+	if err := writeOutputFile(file, graphBytes, contentType); err != nil {
+		logger.Warning("Failed to write to the graph file", err.Error())
+	}
+}
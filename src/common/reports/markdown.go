@@ -0,0 +1,103 @@
+package reports
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bridgecrewio/yor/src/common/i18n"
+)
+
+// AsMarkdown renders the Report as GitHub flavored markdown, i.e. to be used as a pull request description
+func (r *Report) AsMarkdown() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("## %s\n\n", i18n.T("Yor Findings Summary")))
+	sb.WriteString(markdownHeader("Scanned Resources", "New Resources Traced", "Updated Resources"))
+	sb.WriteString(fmt.Sprintf("| %d | %d | %d |\n", r.Summary.Scanned, r.Summary.NewResources, r.Summary.UpdatedResources))
+	if r.Summary.Interrupted {
+		sb.WriteString(fmt.Sprintf("\n> %s\n", i18n.T("The run was interrupted, the results are partial")))
+	}
+	if r.Summary.SuppressedByBaseline > 0 {
+		sb.WriteString(fmt.Sprintf("\n> "+i18n.T("%d findings of the baseline are not shown")+"\n", r.Summary.SuppressedByBaseline))
+	}
+	if len(r.NewResourceTags) > 0 {
+		sb.WriteString(fmt.Sprintf("\n### %s (%d)\n\n", i18n.T("New Resources Traced"), r.Summary.NewResources))
+		sb.WriteString(markdownHeader("File", "Resource", "Tag Key", "Tag Value", "Yor ID", "Source"))
+		for _, tr := range r.NewResourceTags {
+			writeMarkdownRow(&sb, tr.File, tr.ResourceID, tr.TagKey, tr.UpdatedValue, tr.YorTraceID, tr.Source)
+		}
+	}
+	if len(r.UpdatedResourceTags) > 0 {
+		sb.WriteString(fmt.Sprintf("\n### %s (%d)\n\n", i18n.T("Updated Resource Traces"), r.Summary.UpdatedResources))
+		sb.WriteString(markdownHeader("File", "Resource", "Tag Key", "Old Value", "Updated Value", "Yor ID", "Source"))
+		for _, tr := range r.UpdatedResourceTags {
+			writeMarkdownRow(&sb, tr.File, tr.ResourceID, tr.TagKey, tr.OldValue, tr.UpdatedValue, tr.YorTraceID, tr.Source)
+		}
+	}
+	if len(r.ImportedResourceTags) > 0 {
+		sb.WriteString(fmt.Sprintf("\n### %s (%d)\n\n", i18n.T("Imported Resources Traced"), r.Summary.ImportedResources))
+		sb.WriteString(markdownHeader("File", "Resource", "Tag Key", "Old Value", "Updated Value", "Yor ID", "Source"))
+		for _, tr := range r.ImportedResourceTags {
+			writeMarkdownRow(&sb, tr.File, tr.ResourceID, tr.TagKey, tr.OldValue, tr.UpdatedValue, tr.YorTraceID, tr.Source)
+		}
+	}
+	if len(r.NestedStacks) > 0 {
+		sb.WriteString(fmt.Sprintf("\n### %s (%d)\n\n", i18n.T("Nested Stacks"), len(r.NestedStacks)))
+		sb.WriteString(markdownHeader("File", "Resource", "Template URL", "Template File", "Yor ID"))
+		for _, nestedStack := range r.NestedStacks {
+			writeMarkdownRow(&sb, nestedStack.File, nestedStack.ResourceID, nestedStack.TemplateURL, nestedStack.TemplateFile, nestedStack.YorTraceID)
+		}
+	}
+	if len(r.APIDefinitions) > 0 {
+		sb.WriteString(fmt.Sprintf("\n### %s (%d)\n\n", i18n.T("API Definitions"), len(r.APIDefinitions)))
+		sb.WriteString(markdownHeader("File", "Resource", "Definition File", "Yor ID"))
+		for _, apiDefinition := range r.APIDefinitions {
+			writeMarkdownRow(&sb, apiDefinition.File, apiDefinition.ResourceID, apiDefinition.DefinitionFile, apiDefinition.YorTraceID)
+		}
+	}
+	if len(r.RequiredTagViolations) > 0 {
+		sb.WriteString(fmt.Sprintf("\n### %s (%d)\n\n", i18n.T("Required Tag Violations"), len(r.RequiredTagViolations)))
+		sb.WriteString(markdownHeader("File", "Resource", "Tag Key", "Reason", "Severity", "Yor ID"))
+		for _, violation := range r.RequiredTagViolations {
+			writeMarkdownRow(&sb, violation.File, violation.ResourceID, violation.TagKey, violation.Reason, violation.Severity, violation.YorTraceID)
+		}
+	}
+	if len(r.SkippedFiles) > 0 {
+		sb.WriteString(fmt.Sprintf("\n### %s (%d)\n\n", i18n.T("Skipped Files"), len(r.SkippedFiles)))
+		sb.WriteString(markdownHeader("File", "Reason"))
+		for _, skippedFile := range r.SkippedFiles {
+			writeMarkdownRow(&sb, skippedFile.File, skippedFile.Reason)
+		}
+	}
+	if len(r.SkippedResources) > 0 {
+		sb.WriteString(fmt.Sprintf("\n### %s (%d)\n\n", i18n.T("Skipped Resources"), len(r.SkippedResources)))
+		sb.WriteString(markdownHeader("File", "Resource", "Reason"))
+		for _, skippedResource := range r.SkippedResources {
+			writeMarkdownRow(&sb, skippedResource.File, skippedResource.ResourceID, skippedResource.Reason)
+		}
+	}
+	if len(r.Errors) > 0 {
+		sb.WriteString(fmt.Sprintf("\n### %s (%d)\n\n", i18n.T("Errors"), len(r.Errors)))
+		sb.WriteString(markdownHeader("Code", "File", "Message"))
+		for _, runError := range r.Errors {
+			writeMarkdownRow(&sb, string(runError.Code), runError.File, runError.Message)
+		}
+	}
+	return sb.String()
+}
+
+// markdownHeader returns the header of a markdown table with the columns, in the language of the locale
+func markdownHeader(columns ...string) string {
+	return fmt.Sprintf("| %s |\n|%s\n", strings.Join(i18n.Headers(columns...), " | "), strings.Repeat("---|", len(columns)))
+}
+
+func writeMarkdownRow(sb *strings.Builder, cells ...string) {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		escaped[i] = strings.ReplaceAll(strings.ReplaceAll(cell, "|", "\\|"), "\n", " ")
+	}
+	sb.WriteString(fmt.Sprintf("| %s |\n", strings.Join(escaped, " | ")))
+}
+
+func (r *ReportService) PrintMarkdownToStdout() {
+	fmt.Print(r.report.AsMarkdown())
+}
@@ -0,0 +1,66 @@
+package reports
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PathPrefix remaps the file paths under From to To in the report, e.g. the mount point of the repository in a
+// container (/tf) to its path on the host or in the CI workspace, so the links to the files of the report point to
+// the real files. An empty To strips the prefix, which leaves the paths relative to it.
+type PathPrefix struct {
+	From string
+	To   string
+}
+
+// ParsePathPrefixes parses the prefixes to strip from the file paths and the from=to pairs of the prefixes to remap
+func ParsePathPrefixes(strip []string, mappings []string) ([]PathPrefix, error) {
+	var prefixes []PathPrefix
+	for _, prefix := range strip {
+		prefix = strings.TrimSpace(prefix)
+		if prefix == "" {
+			return nil, fmt.Errorf("invalid path prefix to strip %#v", prefix)
+		}
+		prefixes = append(prefixes, PathPrefix{From: prefix})
+	}
+	for _, mapping := range mappings {
+		from, to, found := strings.Cut(mapping, "=")
+		from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+		if !found || from == "" || to == "" {
+			return nil, fmt.Errorf("invalid path prefix mapping %#v, expected a from=to pair", mapping)
+		}
+		prefixes = append(prefixes, PathPrefix{From: from, To: to})
+	}
+	return prefixes, nil
+}
+
+// SetPathPrefixes sets the prefixes remapped in the file paths of the report and of all its output formats
+func (r *ReportService) SetPathPrefixes(prefixes []PathPrefix) {
+	r.pathPrefixes = make([]PathPrefix, len(prefixes))
+	copy(r.pathPrefixes, prefixes)
+	// the longest prefix of a path is remapped, e.g. /tf/modules before /tf
+	sort.SliceStable(r.pathPrefixes, func(i, j int) bool {
+		return len(strings.TrimRight(r.pathPrefixes[i].From, "/\\")) > len(strings.TrimRight(r.pathPrefixes[j].From, "/\\"))
+	})
+}
+
+// remapPathPrefix replaces the first matching prefix of the path, which only matches whole path segments, so /tf
+// doesn't match /tfvars
+func (r *ReportService) remapPathPrefix(path string) string {
+	for _, prefix := range r.pathPrefixes {
+		from := strings.TrimRight(prefix.From, "/\\")
+		if from == "" || !strings.HasPrefix(path, from) {
+			continue
+		}
+		rest := path[len(from):]
+		if rest != "" && rest[0] != '/' && rest[0] != '\\' {
+			continue
+		}
+		if prefix.To == "" {
+			return strings.TrimLeft(rest, "/\\")
+		}
+		return strings.TrimRight(prefix.To, "/\\") + rest
+	}
+	return path
+}
@@ -0,0 +1,81 @@
+package reports
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// The schemas of the JSON report. The fields of a schema are never renamed or removed, so parsers of a schema keep
+// working with the reports of later releases, while new fields are only added to the latest schema.
+const (
+	// ReportSchemaV1 is the report of the first releases of yor: the summary of the scanned, new and updated resources,
+	// and the records of the new and updated tags, without their sources
+	ReportSchemaV1 = "v1"
+	// ReportSchemaV2 is the full report, with its schemaVersion and the versions of the tag groups of the run
+	ReportSchemaV2 = "v2"
+)
+
+var ReportSchemas = []string{ReportSchemaV1, ReportSchemaV2}
+
+type reportV1 struct {
+	Summary             reportSummaryV1 `json:"summary"`
+	NewResourceTags     []tagRecordV1   `json:"newResourceTags"`
+	UpdatedResourceTags []tagRecordV1   `json:"updatedResourceTags"`
+}
+
+type reportSummaryV1 struct {
+	Scanned          int `json:"scanned"`
+	NewResources     int `json:"newResources"`
+	UpdatedResources int `json:"updatedResources"`
+}
+
+type tagRecordV1 struct {
+	File         string `json:"file"`
+	ResourceID   string `json:"resourceId"`
+	TagKey       string `json:"key"`
+	OldValue     string `json:"oldValue"`
+	UpdatedValue string `json:"updatedValue"`
+	YorTraceID   string `json:"yorTraceId"`
+}
+
+func toTagRecordsV1(records []TagRecord) []tagRecordV1 {
+	recordsV1 := make([]tagRecordV1, 0, len(records))
+	for _, record := range records {
+		recordsV1 = append(recordsV1, tagRecordV1{
+			File:         record.File,
+			ResourceID:   record.ResourceID,
+			TagKey:       record.TagKey,
+			OldValue:     record.OldValue,
+			UpdatedValue: record.UpdatedValue,
+			YorTraceID:   record.YorTraceID,
+		})
+	}
+	return recordsV1
+}
+
+// SetReportSchema sets the schema of the JSON report, one of ReportSchemas, which is the latest schema by default
+func (r *ReportService) SetReportSchema(schema string) {
+	r.reportSchema = strings.ToLower(schema)
+}
+
+// SetTagGroupVersions sets the versions of the tag groups of the run, by their names, which are reported so the
+// consumers of the report can tell the tags they support
+func (r *ReportService) SetTagGroupVersions(versions map[string]string) {
+	r.tagGroupVersions = versions
+}
+
+// getReportJSONBytes returns the report as JSON in its schema
+func (r *ReportService) getReportJSONBytes() ([]byte, error) {
+	if r.reportSchema != ReportSchemaV1 {
+		return r.report.AsJSONBytes()
+	}
+	return json.MarshalIndent(reportV1{
+		Summary: reportSummaryV1{
+			Scanned:          r.report.Summary.Scanned,
+			NewResources:     r.report.Summary.NewResources,
+			UpdatedResources: r.report.Summary.UpdatedResources,
+		},
+		NewResourceTags:     toTagRecordsV1(r.report.NewResourceTags),
+		UpdatedResourceTags: toTagRecordsV1(r.report.UpdatedResourceTags),
+	}, "", "    ")
+}
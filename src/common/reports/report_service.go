@@ -5,29 +5,49 @@ import (
 	"fmt"
 	"os"
 	"sort"
+	"strings"
 
 	"github.com/bridgecrewio/yor/src/common"
+	"github.com/bridgecrewio/yor/src/common/i18n"
 	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/bridgecrewio/yor/src/common/objectstore"
+	"github.com/bridgecrewio/yor/src/common/structure"
 	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+	"github.com/bridgecrewio/yor/src/common/tagpolicy"
 	"github.com/olekukonko/tablewriter"
 )
 
 type ReportService struct {
-	report Report
+	report       Report
+	accumulator  *TagChangeAccumulator
+	interrupted  bool
+	pathStyle    string
+	pathPrefixes []PathPrefix
+	// reportSchema is the schema of the JSON report, see ReportSchemas
+	reportSchema     string
+	tagGroupVersions map[string]string
+	directory        string
+	requiredTags     *tagpolicy.RequiredTags
+	baseline         *Report
+	keyNames         tags.KeyNames
 }
 
 const (
-	colorReset  = "\033[0m"
-	colorGreen  = "\033[32m"
-	colorYellow = "\033[33m"
-	colorBlue   = "\033[34m"
-	colorPurple = "\033[35m"
+	NativePathStyle = common.NativePathStyle
+	PosixPathStyle  = common.PosixPathStyle
 )
 
+var PathStyles = common.PathStyles
+
 type ReportSummary struct {
-	Scanned          int `json:"scanned"`
-	NewResources     int `json:"newResources"`
-	UpdatedResources int `json:"updatedResources"`
+	Scanned               int  `json:"scanned"`
+	NewResources          int  `json:"newResources"`
+	UpdatedResources      int  `json:"updatedResources"`
+	ImportedResources     int  `json:"importedResources,omitempty"`
+	RequiredTagViolations int  `json:"requiredTagViolations,omitempty"`
+	Interrupted           bool `json:"interrupted,omitempty"`
+	// SuppressedByBaseline is the number of tags and violations left out of the report, since the baseline has them
+	SuppressedByBaseline int `json:"suppressedByBaseline,omitempty"`
 }
 
 type TagRecord struct {
@@ -37,12 +57,99 @@ type TagRecord struct {
 	OldValue     string `json:"oldValue"`
 	UpdatedValue string `json:"updatedValue"`
 	YorTraceID   string `json:"yorTraceId"`
+	// Source is the tag group or rule which produced the value, e.g. git, code2cloud, custom:<name> or external:<file>
+	Source string `json:"source,omitempty"`
+}
+
+// NestedStack relates a nested stack resource to the template of its stack, whose resources get the tags of the
+// nested stack resource in addition to their own
+type NestedStack struct {
+	File         string `json:"file"`
+	ResourceID   string `json:"resourceId"`
+	TemplateURL  string `json:"templateUrl"`
+	TemplateFile string `json:"templateFile,omitempty"`
+	YorTraceID   string `json:"yorTraceId"`
+}
+
+// APIDefinition relates an API resource to its local OpenAPI definition, whose root-level tags get the trace tag of the
+// API, so the API imported from the definition is traced too
+type APIDefinition struct {
+	File           string `json:"file"`
+	ResourceID     string `json:"resourceId"`
+	DefinitionFile string `json:"definitionFile"`
+	YorTraceID     string `json:"yorTraceId"`
+}
+
+// RequiredTagViolation is a required tag which a resource is missing after the run, or whose key or value doesn't
+// comply with its rule
+type RequiredTagViolation struct {
+	File       string `json:"file"`
+	ResourceID string `json:"resourceId"`
+	TagKey     string `json:"key"`
+	Reason     string `json:"reason"`
+	YorTraceID string `json:"yorTraceId"`
+	// Severity is the severity of the rule (error, warning or info), only the violations of error rules fail the run
+	Severity string `json:"severity"`
+}
+
+// RunError is an error which broke the run, or the tagging of a file, classified by its code
+type RunError struct {
+	Code    common.ErrorCode `json:"code"`
+	File    string           `json:"file,omitempty"`
+	Message string           `json:"message"`
+}
+
+type SkippedFile struct {
+	File   string `json:"file"`
+	Reason string `json:"reason"`
+}
+
+// SkippedResource is a resource which wasn't tagged, since tags can't be added to the expression of its tags safely
+type SkippedResource struct {
+	File       string `json:"file"`
+	ResourceID string `json:"resourceId"`
+	Reason     string `json:"reason"`
 }
 
 type Report struct {
-	Summary             ReportSummary `json:"summary"`
-	NewResourceTags     []TagRecord   `json:"newResourceTags"`
-	UpdatedResourceTags []TagRecord   `json:"updatedResourceTags"`
+	Summary               ReportSummary          `json:"summary"`
+	NewResourceTags       []TagRecord            `json:"newResourceTags"`
+	UpdatedResourceTags   []TagRecord            `json:"updatedResourceTags"`
+	ImportedResourceTags  []TagRecord            `json:"importedResourceTags,omitempty"`
+	NestedStacks          []NestedStack          `json:"nestedStacks,omitempty"`
+	APIDefinitions        []APIDefinition        `json:"apiDefinitions,omitempty"`
+	RequiredTagViolations []RequiredTagViolation `json:"requiredTagViolations,omitempty"`
+	SkippedFiles          []SkippedFile          `json:"skippedFiles,omitempty"`
+	SkippedResources      []SkippedResource      `json:"skippedResources,omitempty"`
+	// Errors is always set, so a run which had nothing to do can be told apart from a broken one by its empty errors
+	Errors []RunError `json:"errors"`
+	// SchemaVersion is the schema of the report, see ReportSchemas. Reports without it are of the v1 schema
+	SchemaVersion string `json:"schemaVersion"`
+	// TagGroups are the versions of the tag groups of the run, by their names
+	TagGroups map[string]string `json:"tagGroups,omitempty"`
+}
+
+// CountRequiredTagViolations returns the number of the required tag violations of the severity
+func (r *Report) CountRequiredTagViolations(severity string) int {
+	count := 0
+	for _, violation := range r.RequiredTagViolations {
+		if violation.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// GetErrorCode returns the code of the error which takes precedence among the errors of the run, if there are any
+func (r *Report) GetErrorCode() (common.ErrorCode, bool) {
+	for _, code := range common.ErrorCodes {
+		for _, runError := range r.Errors {
+			if runError.Code == code {
+				return code, true
+			}
+		}
+	}
+	return "", false
 }
 
 func (r *Report) AsJSONBytes() ([]byte, error) {
@@ -53,10 +160,50 @@ func (r *Report) AsJSONBytes() ([]byte, error) {
 	return jr, nil
 }
 
-var ReportServiceInst *ReportService
+// NewReportService returns a service which reports the changes collected by the given accumulator
+func NewReportService(accumulator *TagChangeAccumulator) *ReportService {
+	return &ReportService{accumulator: accumulator}
+}
+
+// SetInterrupted marks the report as partial, i.e. when the run was cancelled before all the files were tagged
+func (r *ReportService) SetInterrupted(interrupted bool) {
+	r.interrupted = interrupted
+}
+
+// SetPathStyle sets the style of the file paths in the report - native paths use the separator of the OS, while posix
+// paths always use forward slashes, so reports created on Windows and on Linux can be compared
+func (r *ReportService) SetPathStyle(pathStyle string) {
+	r.pathStyle = strings.ToLower(pathStyle)
+}
 
-func init() {
-	ReportServiceInst = &ReportService{}
+// SetRequiredTags sets the rules which the tags of the scanned resources are validated against
+func (r *ReportService) SetRequiredTags(requiredTags *tagpolicy.RequiredTags) {
+	r.requiredTags = requiredTags
+}
+
+func (r *ReportService) formatPath(path string) string {
+	path = r.remapPathPrefix(path)
+	if r.pathStyle == PosixPathStyle {
+		return strings.ReplaceAll(path, "\\", "/")
+	}
+	return path
+}
+
+// GetChangedFiles returns the files of the resources whose tags were added or updated, sorted
+func (r *ReportService) GetChangedFiles() []string {
+	newBlocks, updatedBlocks := r.accumulator.GetBlockChanges()
+	isChangedFile := make(map[string]bool)
+	changedFiles := make([]string, 0)
+	for _, blocks := range [][]structure.IBlock{newBlocks, updatedBlocks, r.accumulator.GetImportedBlocks()} {
+		for _, block := range blocks {
+			if !isChangedFile[block.GetFilePath()] {
+				isChangedFile[block.GetFilePath()] = true
+				changedFiles = append(changedFiles, block.GetFilePath())
+			}
+		}
+	}
+	sort.Strings(changedFiles)
+	return changedFiles
 }
 
 func (r *ReportService) GetReport() *Report {
@@ -64,60 +211,181 @@ func (r *ReportService) GetReport() *Report {
 }
 
 func (r *ReportService) CreateReport() *Report {
-	changesAccumulator := TagChangeAccumulatorInstance
+	scannedBlocks := r.accumulator.GetScannedBlocks()
+	newBlockTraces, updatedBlockTraces := r.accumulator.GetBlockChanges()
+	importedBlocks := r.accumulator.GetImportedBlocks()
+	r.report.SchemaVersion = ReportSchemaV2
+	r.report.TagGroups = r.tagGroupVersions
 	r.report.Summary = ReportSummary{
-		Scanned:          len(changesAccumulator.ScannedBlocks),
-		NewResources:     len(changesAccumulator.NewBlockTraces),
-		UpdatedResources: len(changesAccumulator.UpdatedBlockTraces),
+		Scanned:           len(scannedBlocks),
+		NewResources:      len(newBlockTraces),
+		UpdatedResources:  len(updatedBlockTraces),
+		ImportedResources: len(importedBlocks),
+		Interrupted:       r.interrupted,
 	}
 	r.report.NewResourceTags = []TagRecord{}
-	for _, block := range changesAccumulator.NewBlockTraces {
+	for _, block := range newBlockTraces {
 		for _, tag := range block.GetNewTags() {
 			r.report.NewResourceTags = append(r.report.NewResourceTags, TagRecord{
-				File:         block.GetFilePath(),
+				File:         r.formatPath(block.GetFilePath()),
 				ResourceID:   block.GetResourceID(),
 				TagKey:       tag.GetKey(),
 				OldValue:     "",
 				UpdatedValue: tag.GetValue(),
 				YorTraceID:   block.GetTraceID(),
+				Source:       tags.GetSource(tag),
 			})
 		}
 	}
 	r.report.UpdatedResourceTags = []TagRecord{}
-	for _, block := range changesAccumulator.UpdatedBlockTraces {
-		diff := block.CalculateTagsDiff()
-
-		sort.SliceStable(diff.Added, func(i, j int) bool {
-			return diff.Added[i].GetKey() < diff.Added[j].GetKey()
-		})
-		for _, val := range diff.Added {
-			r.report.UpdatedResourceTags = append(r.report.UpdatedResourceTags, TagRecord{
-				File:         block.GetFilePath(),
+	for _, block := range updatedBlockTraces {
+		r.report.UpdatedResourceTags = append(r.report.UpdatedResourceTags, r.getDiffRecords(block)...)
+	}
+	r.report.ImportedResourceTags = nil
+	for _, block := range importedBlocks {
+		r.report.ImportedResourceTags = append(r.report.ImportedResourceTags, r.getDiffRecords(block)...)
+	}
+	r.report.NestedStacks = nil
+	for _, block := range scannedBlocks {
+		nestedStackBlock, ok := block.(structure.INestedStackBlock)
+		if !ok {
+			continue
+		}
+		if templateURL, templateFile := nestedStackBlock.GetNestedTemplate(); templateURL != "" {
+			if templateFile != "" {
+				templateFile = r.formatPath(templateFile)
+			}
+			r.report.NestedStacks = append(r.report.NestedStacks, NestedStack{
+				File:         r.formatPath(block.GetFilePath()),
 				ResourceID:   block.GetResourceID(),
-				TagKey:       val.GetKey(),
-				OldValue:     "",
-				UpdatedValue: val.GetValue(),
+				TemplateURL:  templateURL,
+				TemplateFile: templateFile,
 				YorTraceID:   block.GetTraceID(),
 			})
 		}
-
-		sort.SliceStable(diff.Updated, func(i, j int) bool {
-			return diff.Updated[i].Key < diff.Updated[j].Key
-		})
-		for _, val := range diff.Updated {
-			r.report.UpdatedResourceTags = append(r.report.UpdatedResourceTags, TagRecord{
-				File:         block.GetFilePath(),
-				ResourceID:   block.GetResourceID(),
-				TagKey:       val.Key,
-				OldValue:     val.PrevValue,
-				UpdatedValue: val.NewValue,
-				YorTraceID:   block.GetTraceID(),
+	}
+	sort.SliceStable(r.report.NestedStacks, func(i, j int) bool {
+		if r.report.NestedStacks[i].File != r.report.NestedStacks[j].File {
+			return r.report.NestedStacks[i].File < r.report.NestedStacks[j].File
+		}
+		return r.report.NestedStacks[i].ResourceID < r.report.NestedStacks[j].ResourceID
+	})
+	r.report.APIDefinitions = nil
+	for _, block := range scannedBlocks {
+		apiBlock, ok := block.(structure.IAPIDefinitionBlock)
+		if !ok {
+			continue
+		}
+		if definitionFile := apiBlock.GetAPIDefinitionFile(); definitionFile != "" {
+			r.report.APIDefinitions = append(r.report.APIDefinitions, APIDefinition{
+				File:           r.formatPath(block.GetFilePath()),
+				ResourceID:     block.GetResourceID(),
+				DefinitionFile: r.formatPath(definitionFile),
+				YorTraceID:     block.GetTraceID(),
+			})
+		}
+	}
+	sort.SliceStable(r.report.APIDefinitions, func(i, j int) bool {
+		if r.report.APIDefinitions[i].File != r.report.APIDefinitions[j].File {
+			return r.report.APIDefinitions[i].File < r.report.APIDefinitions[j].File
+		}
+		return r.report.APIDefinitions[i].ResourceID < r.report.APIDefinitions[j].ResourceID
+	})
+	r.report.RequiredTagViolations = nil
+	for _, block := range scannedBlocks {
+		for _, violation := range r.requiredTags.Validate(block) {
+			r.report.RequiredTagViolations = append(r.report.RequiredTagViolations, RequiredTagViolation{
+				File:       r.formatPath(block.GetFilePath()),
+				ResourceID: block.GetResourceID(),
+				TagKey:     violation.TagKey,
+				Reason:     violation.Reason,
+				YorTraceID: block.GetTraceID(),
+				Severity:   violation.Severity,
 			})
 		}
 	}
+	sort.SliceStable(r.report.RequiredTagViolations, func(i, j int) bool {
+		if r.report.RequiredTagViolations[i].File != r.report.RequiredTagViolations[j].File {
+			return r.report.RequiredTagViolations[i].File < r.report.RequiredTagViolations[j].File
+		}
+		return r.report.RequiredTagViolations[i].ResourceID < r.report.RequiredTagViolations[j].ResourceID
+	})
+	r.report.Summary.RequiredTagViolations = len(r.report.RequiredTagViolations)
+	r.applyBaseline()
+	r.report.SkippedFiles = nil
+	for _, skippedFile := range r.accumulator.GetSkippedFiles() {
+		r.report.SkippedFiles = append(r.report.SkippedFiles, SkippedFile{File: r.formatPath(skippedFile.File), Reason: skippedFile.Reason})
+	}
+	sort.SliceStable(r.report.SkippedFiles, func(i, j int) bool {
+		return r.report.SkippedFiles[i].File < r.report.SkippedFiles[j].File
+	})
+	r.report.SkippedResources = nil
+	for _, block := range scannedBlocks {
+		if uneditableBlock, ok := block.(structure.IUneditableTagsBlock); ok && uneditableBlock.GetUneditableTagsReason() != "" {
+			r.report.SkippedResources = append(r.report.SkippedResources, SkippedResource{
+				File:       r.formatPath(block.GetFilePath()),
+				ResourceID: block.GetResourceID(),
+				Reason:     uneditableBlock.GetUneditableTagsReason(),
+			})
+		}
+	}
+	sort.SliceStable(r.report.SkippedResources, func(i, j int) bool {
+		if r.report.SkippedResources[i].File != r.report.SkippedResources[j].File {
+			return r.report.SkippedResources[i].File < r.report.SkippedResources[j].File
+		}
+		return r.report.SkippedResources[i].ResourceID < r.report.SkippedResources[j].ResourceID
+	})
+	r.report.Errors = []RunError{}
+	for _, runError := range r.accumulator.GetErrors() {
+		if runError.File != "" {
+			runError.File = r.formatPath(runError.File)
+		}
+		r.report.Errors = append(r.report.Errors, runError)
+	}
+	sort.SliceStable(r.report.Errors, func(i, j int) bool {
+		return r.report.Errors[i].File < r.report.Errors[j].File
+	})
 	return &r.report
 }
 
+// getDiffRecords returns the records of the tags added to the block followed by the records of its updated tags, each
+// sorted by key
+func (r *ReportService) getDiffRecords(block structure.IBlock) []TagRecord {
+	records := make([]TagRecord, 0)
+	diff := block.CalculateTagsDiff()
+
+	sort.SliceStable(diff.Added, func(i, j int) bool {
+		return diff.Added[i].GetKey() < diff.Added[j].GetKey()
+	})
+	for _, val := range diff.Added {
+		records = append(records, TagRecord{
+			File:         r.formatPath(block.GetFilePath()),
+			ResourceID:   block.GetResourceID(),
+			TagKey:       val.GetKey(),
+			OldValue:     "",
+			UpdatedValue: val.GetValue(),
+			YorTraceID:   block.GetTraceID(),
+			Source:       tags.GetSource(val),
+		})
+	}
+
+	sort.SliceStable(diff.Updated, func(i, j int) bool {
+		return diff.Updated[i].Key < diff.Updated[j].Key
+	})
+	for _, val := range diff.Updated {
+		records = append(records, TagRecord{
+			File:         r.formatPath(block.GetFilePath()),
+			ResourceID:   block.GetResourceID(),
+			TagKey:       val.Key,
+			OldValue:     val.PrevValue,
+			UpdatedValue: val.NewValue,
+			YorTraceID:   block.GetTraceID(),
+			Source:       val.Source,
+		})
+	}
+	return records
+}
+
 // PrintToStdout prints the Report to the normal std::out. The structure:
 // <Banner>
 // Scanned Resources: <int>
@@ -125,12 +393,31 @@ func (r *ReportService) CreateReport() *Report {
 // Updated Resources: <int>
 // <New Resources Table> as generated by printNewResourcesToStdout, if not empty
 // <Updated Resources Table> as generated by printUpdatedResourcesToStdout, if not empty
+// <Imported Resources Table> as generated by printImportedResourcesToStdout, if not empty
+// <Nested Stacks Table> as generated by printNestedStacksToStdout, if not empty
+// <API Definitions Table> as generated by printAPIDefinitionsToStdout, if not empty
+// <Required Tag Violations Table> as generated by printRequiredTagViolationsToStdout, if not empty
+// <Skipped Files Table> as generated by printSkippedFilesToStdout, if not empty
+// <Skipped Resources Table> as generated by printSkippedResourcesToStdout, if not empty
+// <Errors Table> as generated by printErrorsToStdout, if not empty
 func (r *ReportService) PrintToStdout() {
 	PrintBanner()
-	fmt.Println(colorReset, "Yor Findings Summary")
-	fmt.Println(colorReset, "Scanned Resources:\t", colorBlue, r.report.Summary.Scanned)
-	fmt.Println(colorReset, "New Resources Traced: \t", colorYellow, r.report.Summary.NewResources)
-	fmt.Println(colorReset, "Updated Resources:\t", colorGreen, r.report.Summary.UpdatedResources)
+	fmt.Println(colors.Reset, i18n.T("Yor Findings Summary"))
+	fmt.Println(colors.Reset, i18n.T("Scanned Resources")+":\t", colors.Blue, r.report.Summary.Scanned)
+	fmt.Println(colors.Reset, i18n.T("New Resources Traced")+": \t", colors.Yellow, r.report.Summary.NewResources)
+	fmt.Println(colors.Reset, i18n.T("Updated Resources")+":\t", colors.Green, r.report.Summary.UpdatedResources)
+	if r.report.Summary.ImportedResources > 0 {
+		fmt.Println(colors.Reset, i18n.T("Imported Resources")+":\t", colors.Blue, r.report.Summary.ImportedResources)
+	}
+	if r.report.Summary.RequiredTagViolations > 0 {
+		fmt.Println(colors.Reset, i18n.T("Required Tag Violations")+":", colors.Yellow, r.report.Summary.RequiredTagViolations)
+	}
+	if r.report.Summary.SuppressedByBaseline > 0 {
+		fmt.Println(colors.Reset, i18n.T("Suppressed by Baseline")+":\t", colors.Reset, r.report.Summary.SuppressedByBaseline)
+	}
+	if r.report.Summary.Interrupted {
+		fmt.Println(colors.Reset, i18n.T("The run was interrupted, the results are partial"))
+	}
 	fmt.Println()
 	if r.report.Summary.NewResources > 0 {
 		r.printNewResourcesToStdout()
@@ -139,69 +426,188 @@ func (r *ReportService) PrintToStdout() {
 	if r.report.Summary.UpdatedResources > 0 {
 		r.printUpdatedResourcesToStdout()
 	}
+	if r.report.Summary.ImportedResources > 0 {
+		fmt.Println()
+		r.printImportedResourcesToStdout()
+	}
+	if len(r.report.NestedStacks) > 0 {
+		fmt.Println()
+		r.printNestedStacksToStdout()
+	}
+	if len(r.report.APIDefinitions) > 0 {
+		fmt.Println()
+		r.printAPIDefinitionsToStdout()
+	}
+	if len(r.report.RequiredTagViolations) > 0 {
+		fmt.Println()
+		r.printRequiredTagViolationsToStdout()
+	}
+	if len(r.report.SkippedFiles) > 0 {
+		fmt.Println()
+		r.printSkippedFilesToStdout()
+	}
+	if len(r.report.SkippedResources) > 0 {
+		fmt.Println()
+		r.printSkippedResourcesToStdout()
+	}
+	if len(r.report.Errors) > 0 {
+		fmt.Println()
+		r.printErrorsToStdout()
+	}
+}
+
+func (r *ReportService) printErrorsToStdout() {
+	fmt.Print(colors.Yellow, fmt.Sprintf("%v (%v):\n", i18n.T("Errors"), len(r.report.Errors)), colors.Reset)
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(i18n.Headers("Code", "File", "Message"))
+	for _, runError := range r.report.Errors {
+		table.Append([]string{string(runError.Code), runError.File, runError.Message})
+	}
+	table.Render()
+}
+
+func (r *ReportService) printRequiredTagViolationsToStdout() {
+	fmt.Print(colors.Yellow, fmt.Sprintf("%v (%v):\n", i18n.T("Required Tag Violations"), len(r.report.RequiredTagViolations)), colors.Reset)
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(i18n.Headers("File", "Resource", "Tag Key", "Reason", "Severity", "Yor ID"))
+	table.SetRowLine(true)
+	table.SetRowSeparator("-")
+	for _, violation := range r.report.RequiredTagViolations {
+		table.Append([]string{violation.File, violation.ResourceID, violation.TagKey, violation.Reason, violation.Severity, violation.YorTraceID})
+	}
+	table.SetAutoMergeCellsByColumnIndex([]int{0, 1, 5})
+	table.Render()
+}
+
+func (r *ReportService) printNestedStacksToStdout() {
+	fmt.Print(colors.Blue, fmt.Sprintf("%v (%v):\n", i18n.T("Nested Stacks"), len(r.report.NestedStacks)), colors.Reset)
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(i18n.Headers("File", "Resource", "Template", "Yor ID"))
+	for _, nestedStack := range r.report.NestedStacks {
+		template := nestedStack.TemplateURL
+		if nestedStack.TemplateFile != "" {
+			template = nestedStack.TemplateFile
+		}
+		table.Append([]string{nestedStack.File, nestedStack.ResourceID, template, nestedStack.YorTraceID})
+	}
+	table.Render()
+}
+
+func (r *ReportService) printAPIDefinitionsToStdout() {
+	fmt.Print(colors.Blue, fmt.Sprintf("%v (%v):\n", i18n.T("API Definitions"), len(r.report.APIDefinitions)), colors.Reset)
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(i18n.Headers("File", "Resource", "Definition File", "Yor ID"))
+	for _, apiDefinition := range r.report.APIDefinitions {
+		table.Append([]string{apiDefinition.File, apiDefinition.ResourceID, apiDefinition.DefinitionFile, apiDefinition.YorTraceID})
+	}
+	table.Render()
+}
+
+func (r *ReportService) printSkippedFilesToStdout() {
+	fmt.Print(colors.Yellow, fmt.Sprintf("%v (%v):\n", i18n.T("Skipped Files"), len(r.report.SkippedFiles)), colors.Reset)
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(i18n.Headers("File", "Reason"))
+	for _, skippedFile := range r.report.SkippedFiles {
+		table.Append([]string{skippedFile.File, skippedFile.Reason})
+	}
+	table.Render()
+}
+
+func (r *ReportService) printSkippedResourcesToStdout() {
+	fmt.Print(colors.Yellow, fmt.Sprintf("%v (%v):\n", i18n.T("Skipped Resources"), len(r.report.SkippedResources)), colors.Reset)
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader(i18n.Headers("File", "Resource", "Reason"))
+	for _, skippedResource := range r.report.SkippedResources {
+		table.Append([]string{skippedResource.File, skippedResource.ResourceID, skippedResource.Reason})
+	}
+	table.Render()
 }
 
 func PrintBanner() {
-	fmt.Printf("%v%vv%v\n", common.YorLogo, colorPurple, common.Version)
+	fmt.Printf("%v%vv%v\n", common.YorLogo, colors.Purple, common.Version)
 }
 
 func (r *ReportService) printUpdatedResourcesToStdout() {
-	fmt.Print(colorGreen, fmt.Sprintf("Updated Resource Traces (%v):\n", r.report.Summary.UpdatedResources), colorReset)
+	fmt.Print(colors.Green, fmt.Sprintf("%v (%v):\n", i18n.T("Updated Resource Traces"), r.report.Summary.UpdatedResources), colors.Reset)
+	printDiffRecordsTable(r.report.UpdatedResourceTags)
+}
+
+func (r *ReportService) printImportedResourcesToStdout() {
+	fmt.Print(colors.Blue, fmt.Sprintf("%v (%v):\n", i18n.T("Imported Resources Traced"), r.report.Summary.ImportedResources), colors.Reset)
+	printDiffRecordsTable(r.report.ImportedResourceTags)
+}
+
+func printDiffRecordsTable(records []TagRecord) {
 	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"File", "Resource", "Tag Key", "Old Value", "Updated Value", "Yor ID"})
-	table.SetColumnColor(
-		tablewriter.Colors{},
-		tablewriter.Colors{},
-		tablewriter.Colors{tablewriter.Bold},
-		tablewriter.Colors{tablewriter.Normal, tablewriter.FgRedColor},
-		tablewriter.Colors{tablewriter.Normal, tablewriter.FgGreenColor},
-		tablewriter.Colors{},
-	)
+	table.SetHeader(i18n.Headers("File", "Resource", "Tag Key", "Old Value", "Updated Value", "Yor ID", "Source"))
+	if colors.Enabled() {
+		table.SetColumnColor(
+			tablewriter.Colors{},
+			tablewriter.Colors{},
+			tablewriter.Colors{tablewriter.Bold},
+			tablewriter.Colors{tablewriter.Normal, tablewriter.FgRedColor},
+			tablewriter.Colors{tablewriter.Normal, tablewriter.FgGreenColor},
+			tablewriter.Colors{},
+			tablewriter.Colors{},
+		)
+	}
 
 	table.SetRowLine(true)
 	table.SetRowSeparator("-")
 
-	for _, tr := range r.report.UpdatedResourceTags {
-		table.Append([]string{tr.File, tr.ResourceID, tr.TagKey, tr.OldValue, tr.UpdatedValue, tr.YorTraceID})
+	for _, tr := range records {
+		table.Append([]string{tr.File, tr.ResourceID, tr.TagKey, tr.OldValue, tr.UpdatedValue, tr.YorTraceID, tr.Source})
 	}
 	table.SetAutoMergeCellsByColumnIndex([]int{0, 1, 5})
 	table.Render()
 }
 
 func (r *ReportService) printNewResourcesToStdout() {
-	fmt.Print(colorYellow, fmt.Sprintf("New Resources Traced (%v):\n", r.report.Summary.NewResources), colorReset)
+	fmt.Print(colors.Yellow, fmt.Sprintf("%v (%v):\n", i18n.T("New Resources Traced"), r.report.Summary.NewResources), colors.Reset)
 	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"File", "Resource", "Tag Key", "Tag Value", "Yor ID"})
+	table.SetHeader(i18n.Headers("File", "Resource", "Tag Key", "Tag Value", "Yor ID", "Source"))
 	table.SetRowLine(true)
 	table.SetRowSeparator("-")
-	table.SetColumnColor(
-		tablewriter.Colors{},
-		tablewriter.Colors{},
-		tablewriter.Colors{tablewriter.Bold},
-		tablewriter.Colors{tablewriter.Normal, tablewriter.FgGreenColor},
-		tablewriter.Colors{},
-	)
+	if colors.Enabled() {
+		table.SetColumnColor(
+			tablewriter.Colors{},
+			tablewriter.Colors{},
+			tablewriter.Colors{tablewriter.Bold},
+			tablewriter.Colors{tablewriter.Normal, tablewriter.FgGreenColor},
+			tablewriter.Colors{},
+			tablewriter.Colors{},
+		)
+	}
 	for _, tr := range r.report.NewResourceTags {
-		table.Append([]string{tr.File, tr.ResourceID, tr.TagKey, tr.UpdatedValue, tr.YorTraceID})
+		table.Append([]string{tr.File, tr.ResourceID, tr.TagKey, tr.UpdatedValue, tr.YorTraceID, tr.Source})
 	}
 	table.SetAutoMergeCellsByColumnIndex([]int{0, 1, 4})
 	table.Render()
 }
 
 func (r *ReportService) PrintJSONToFile(file string) {
-	jr, err := r.report.AsJSONBytes()
+	jr, err := r.getReportJSONBytes()
 	if err != nil {
 		logger.Warning("Failed to create report as JSON")
 	}
 
-	err = os.WriteFile(file, jr, 0600)
+	err = writeOutputFile(file, jr, "application/json")
 	if err != nil {
 		logger.Warning("Failed to write to JSON file", err.Error())
 	}
 }
 
+// writeOutputFile writes the JSON output to a local file, or uploads it when the file is an object url, i.e.
+// s3://bucket/key
+func writeOutputFile(file string, data []byte, contentType string) error {
+	if objectstore.IsURL(file) {
+		return objectstore.Upload(file, data, contentType)
+	}
+	return os.WriteFile(file, data, 0600)
+}
+
 func (r *ReportService) PrintJSONToStdout() {
-	jr, err := r.report.AsJSONBytes()
+	jr, err := r.getReportJSONBytes()
 	if err != nil {
 		logger.Error("couldn't parse report to JSON")
 	}
@@ -210,7 +616,7 @@ func (r *ReportService) PrintJSONToStdout() {
 
 func (r *ReportService) PrintTagGroupTags(tagsByGroup map[string][]tags.ITag) {
 	table := tablewriter.NewWriter(os.Stdout)
-	table.SetHeader([]string{"Group", "Tag Key", "Description"})
+	table.SetHeader(i18n.Headers("Group", "Tag Key", "Description"))
 	table.SetRowLine(true)
 	table.SetRowSeparator("-")
 	for group, groupTags := range tagsByGroup {
@@ -4,15 +4,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"testing"
 
+	cfnStructure "github.com/bridgecrewio/yor/src/cloudformation/structure"
 	"github.com/bridgecrewio/yor/src/common"
 	"github.com/bridgecrewio/yor/src/common/structure"
 	"github.com/bridgecrewio/yor/src/common/tagging/code2cloud"
 	"github.com/bridgecrewio/yor/src/common/tagging/gittag"
 	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+	"github.com/bridgecrewio/yor/src/common/tagpolicy"
 	tfStructure "github.com/bridgecrewio/yor/src/terraform/structure"
 	"github.com/bridgecrewio/yor/tests/utils"
 	"github.com/hashicorp/hcl/v2"
@@ -22,6 +25,7 @@ import (
 
 func TestResultsGeneration(t *testing.T) {
 	accumulator := setupAccumulator()
+	reportService := NewReportService(accumulator)
 	t.Run("Test change accumulator", func(t *testing.T) {
 		assert.Equal(t, 5, len(accumulator.GetScannedBlocks()))
 		newBlocks, updatedBlocks := accumulator.GetBlockChanges()
@@ -30,9 +34,9 @@ func TestResultsGeneration(t *testing.T) {
 	})
 
 	t.Run("Test report JSON stdout", func(t *testing.T) {
-		ReportServiceInst.CreateReport()
-		_, _ = ReportServiceInst.report.AsJSONBytes()
-		output := utils.CaptureOutput(ReportServiceInst.PrintJSONToStdout)
+		reportService.CreateReport()
+		_, _ = reportService.report.AsJSONBytes()
+		output := utils.CaptureOutput(reportService.PrintJSONToStdout)
 		lines := strings.Split(output, "\n")
 		assert.NotNil(t, output)
 		assert.LessOrEqual(t, 100, len(lines))
@@ -67,7 +71,7 @@ func TestResultsGeneration(t *testing.T) {
 	})
 
 	t.Run("Test report JSON file", func(t *testing.T) {
-		ReportServiceInst.CreateReport()
+		reportService.CreateReport()
 		reportFileName := "test.json"
 		defer func() {
 			err := os.Remove(reportFileName)
@@ -76,8 +80,8 @@ func TestResultsGeneration(t *testing.T) {
 			}
 		}()
 
-		_, _ = ReportServiceInst.report.AsJSONBytes()
-		ReportServiceInst.PrintJSONToFile(reportFileName)
+		_, _ = reportService.report.AsJSONBytes()
+		reportService.PrintJSONToFile(reportFileName)
 		content, _ := os.ReadFile(reportFileName)
 		result := Report{}
 		_ = json.Unmarshal(content, &result)
@@ -89,9 +93,61 @@ func TestResultsGeneration(t *testing.T) {
 		assert.LessOrEqual(t, 4, len(result.UpdatedResourceTags))
 	})
 
+	t.Run("Test report JSON file schemas", func(t *testing.T) {
+		reportFileName := filepath.Join(t.TempDir(), "report.json")
+		readReport := func() map[string]interface{} {
+			content, err := os.ReadFile(reportFileName)
+			assert.Nil(t, err)
+			result := map[string]interface{}{}
+			assert.Nil(t, json.Unmarshal(content, &result))
+			return result
+		}
+		reportService.SetTagGroupVersions(map[string]string{"code2cloud": "1"})
+		defer reportService.SetTagGroupVersions(nil)
+		reportService.CreateReport()
+		reportService.PrintJSONToFile(reportFileName)
+		result := readReport()
+		assert.Equal(t, ReportSchemaV2, result["schemaVersion"])
+		assert.Equal(t, map[string]interface{}{"code2cloud": "1"}, result["tagGroups"])
+		assert.Contains(t, result, "errors")
+
+		reportService.SetReportSchema(ReportSchemaV1)
+		defer reportService.SetReportSchema(ReportSchemaV2)
+		reportService.PrintJSONToFile(reportFileName)
+		result = readReport()
+		assert.Equal(t, 3, len(result))
+		assert.Equal(t, map[string]interface{}{"scanned": 5.0, "newResources": 2.0, "updatedResources": 2.0}, result["summary"])
+		var recordKeys []string
+		for key := range result["newResourceTags"].([]interface{})[0].(map[string]interface{}) {
+			recordKeys = append(recordKeys, key)
+		}
+		assert.ElementsMatch(t, []string{"file", "resourceId", "key", "oldValue", "updatedValue", "yorTraceId"}, recordKeys)
+	})
+
+	t.Run("Test signed report JSON file is verified with its key only", func(t *testing.T) {
+		reportService.CreateReport()
+		reportFileName := filepath.Join(t.TempDir(), "signed.json")
+		key := []byte("signing-key")
+		assert.Nil(t, reportService.PrintSignedJSONToFile(reportFileName, key))
+
+		content, err := os.ReadFile(reportFileName)
+		assert.Nil(t, err)
+		signature, err := LoadReportSignature(reportFileName + SignatureSuffix)
+		assert.Nil(t, err)
+		assert.Equal(t, HMACSHA256, signature.Algorithm)
+		assert.Nil(t, VerifyReport(content, signature, key))
+
+		assert.EqualError(t, VerifyReport(content, signature, []byte("another-key")), "the report was altered since it was signed, or wasn't signed with the signing key")
+		altered := []byte(strings.Replace(string(content), "\"scanned\": 5", "\"scanned\": 6", 1))
+		assert.NotEqual(t, content, altered)
+		assert.EqualError(t, VerifyReport(altered, signature, key), "the report was altered since it was signed, or wasn't signed with the signing key")
+
+		assert.NotNil(t, reportService.PrintSignedJSONToFile(filepath.Join(t.TempDir(), "missing", "signed.json"), key))
+	})
+
 	t.Run("Test report structure", func(t *testing.T) {
-		ReportServiceInst.CreateReport()
-		report := ReportServiceInst.report
+		reportService.CreateReport()
+		report := reportService.report
 		assert.Equal(t, len(accumulator.GetScannedBlocks()), report.Summary.Scanned)
 		assert.Equal(t, 2, report.Summary.NewResources)
 		for _, tr := range report.NewResourceTags {
@@ -114,12 +170,12 @@ func TestResultsGeneration(t *testing.T) {
 	})
 
 	t.Run("Test CLI output structure", func(t *testing.T) {
-		ReportServiceInst.CreateReport()
+		reportService.CreateReport()
 
-		output := utils.CaptureOutput(ReportServiceInst.PrintToStdout)
+		output := utils.CaptureOutput(reportService.PrintToStdout)
 		lines := strings.Split(output, "\n")
 		// Verify banner
-		assert.Equal(t, fmt.Sprintf("%v%vv%v", common.YorLogo, colorPurple, common.Version), strings.Join(lines[0:6], "\n"))
+		assert.Equal(t, fmt.Sprintf("%v%vv%v", common.YorLogo, colors.Purple, common.Version), strings.Join(lines[0:6], "\n"))
 
 		// Verify counts
 		lines = lines[7:]
@@ -154,6 +210,393 @@ func TestResultsGeneration(t *testing.T) {
 		assert.True(t, matched)
 	})
 
+	t.Run("Test markdown output structure", func(t *testing.T) {
+		reportService.CreateReport()
+
+		output := utils.CaptureOutput(reportService.PrintMarkdownToStdout)
+		lines := strings.Split(output, "\n")
+		assert.Equal(t, "## Yor Findings Summary", lines[0])
+		assert.Equal(t, "| 5 | 2 | 2 |", lines[4])
+		assert.Contains(t, output, "### New Resources Traced (2)")
+		assert.Contains(t, output, "### Updated Resource Traces (2)")
+		assert.Contains(t, output, "| File | Resource | Tag Key | Old Value | Updated Value | Yor ID |")
+	})
+
+	t.Run("Test posix path style", func(t *testing.T) {
+		windowsAccumulator := NewTagChangeAccumulator()
+		windowsAccumulator.AccumulateChanges(&tfStructure.TerraformBlock{
+			Block: structure.Block{
+				FilePath:   "C:\\module\\mock.tf",
+				NewTags:    []tags.ITag{&code2cloud.YorTraceTag{Tag: tags.Tag{Key: "yor_trace", Value: "mock-uuid"}}},
+				IsTaggable: true,
+			},
+			HclSyntaxBlock: &hclsyntax.Block{Labels: []string{"aws_s3_bucket", "windows_bucket"}},
+		})
+		windowsReportService := NewReportService(windowsAccumulator)
+		assert.Equal(t, "C:\\module\\mock.tf", windowsReportService.CreateReport().NewResourceTags[0].File)
+		windowsReportService.SetPathStyle(PosixPathStyle)
+		assert.Equal(t, "C:/module/mock.tf", windowsReportService.CreateReport().NewResourceTags[0].File)
+	})
+
+	t.Run("Test path prefixes are remapped in the report and the tags export", func(t *testing.T) {
+		containerAccumulator := NewTagChangeAccumulator()
+		for _, file := range []string{"/tf/main.tf", "/tf/modules/vpc/main.tf", "/tfvars/main.tf"} {
+			containerAccumulator.AccumulateChanges(&tfStructure.TerraformBlock{
+				Block: structure.Block{
+					FilePath:   file,
+					NewTags:    []tags.ITag{&code2cloud.YorTraceTag{Tag: tags.Tag{Key: "yor_trace", Value: file}}},
+					IsTaggable: true,
+				},
+				HclSyntaxBlock: &hclsyntax.Block{Labels: []string{"aws_s3_bucket", "bucket"}},
+			})
+		}
+		prefixes, err := ParsePathPrefixes([]string{"/tf/modules"}, []string{"/tf=/home/runner/work/repo/"})
+		assert.Nil(t, err)
+		containerReportService := NewReportService(containerAccumulator)
+		containerReportService.SetPathPrefixes(prefixes)
+
+		var reportFiles []string
+		for _, record := range containerReportService.CreateReport().NewResourceTags {
+			reportFiles = append(reportFiles, record.File)
+		}
+		expectedFiles := []string{"/home/runner/work/repo/main.tf", "vpc/main.tf", "/tfvars/main.tf"}
+		assert.ElementsMatch(t, expectedFiles, reportFiles)
+		var exportFiles []string
+		for _, resource := range containerReportService.GetTagsExport().Resources {
+			exportFiles = append(exportFiles, resource.File)
+		}
+		assert.ElementsMatch(t, expectedFiles, exportFiles)
+
+		_, err = ParsePathPrefixes(nil, []string{"/tf"})
+		assert.EqualError(t, err, "invalid path prefix mapping \"/tf\", expected a from=to pair")
+	})
+
+	t.Run("Test imported resources are reported separately", func(t *testing.T) {
+		importAccumulator := NewTagChangeAccumulator()
+		importAccumulator.AccumulateChanges(&tfStructure.TerraformBlock{
+			Block: structure.Block{
+				FilePath:   "/module/main.tf",
+				NewTags:    []tags.ITag{&code2cloud.YorTraceTag{Tag: tags.Tag{Key: "yor_trace", Value: "imported-uuid"}}},
+				IsTaggable: true,
+			},
+			HclSyntaxBlock: &hclsyntax.Block{Labels: []string{"aws_s3_bucket", "imported_bucket"}},
+			Imported:       true,
+		})
+		importAccumulator.AccumulateChanges(&tfStructure.TerraformBlock{
+			Block: structure.Block{
+				FilePath:   "/module/main.tf",
+				NewTags:    []tags.ITag{&code2cloud.YorTraceTag{Tag: tags.Tag{Key: "yor_trace", Value: "new-uuid"}}},
+				IsTaggable: true,
+			},
+			HclSyntaxBlock: &hclsyntax.Block{Labels: []string{"aws_s3_bucket", "new_bucket"}},
+		})
+		importReportService := NewReportService(importAccumulator)
+		importReport := importReportService.CreateReport()
+		assert.Equal(t, 2, importReport.Summary.Scanned)
+		assert.Equal(t, 1, importReport.Summary.NewResources)
+		assert.Equal(t, 1, importReport.Summary.ImportedResources)
+		assert.Equal(t, []TagRecord{{File: "/module/main.tf", ResourceID: "aws_s3_bucket.imported_bucket", TagKey: "yor_trace", UpdatedValue: "imported-uuid", YorTraceID: "imported-uuid"}},
+			importReport.ImportedResourceTags)
+		assert.Equal(t, "aws_s3_bucket.new_bucket", importReport.NewResourceTags[0].ResourceID)
+		assert.Equal(t, []string{"/module/main.tf"}, importReportService.GetChangedFiles())
+		assert.Contains(t, importReport.AsMarkdown(), "### Imported Resources Traced (1)")
+	})
+
+	t.Run("Test tag records are attributed to their sources", func(t *testing.T) {
+		sourceAccumulator := NewTagChangeAccumulator()
+		sourceAccumulator.AccumulateChanges(&tfStructure.TerraformBlock{
+			Block: structure.Block{
+				FilePath:    "/module/main.tf",
+				ExitingTags: []tags.ITag{&tags.Tag{Key: "yor_trace", Value: "trace-uuid"}, &tags.Tag{Key: "git_commit", Value: "old-commit"}},
+				NewTags: []tags.ITag{
+					&tags.Tag{Key: "git_commit", Value: "new-commit", Source: tags.GitSource},
+					&tags.Tag{Key: "team", Value: "platform", Source: tags.ExternalSourcePrefix + "tags.yml"},
+				},
+				IsTaggable: true,
+			},
+			HclSyntaxBlock: &hclsyntax.Block{Labels: []string{"aws_s3_bucket", "bucket"}},
+		})
+		sourceReport := NewReportService(sourceAccumulator).CreateReport()
+		assert.Equal(t, []TagRecord{
+			{File: "/module/main.tf", ResourceID: "aws_s3_bucket.bucket", TagKey: "team", UpdatedValue: "platform", YorTraceID: "trace-uuid", Source: "external:tags.yml"},
+			{File: "/module/main.tf", ResourceID: "aws_s3_bucket.bucket", TagKey: "git_commit", OldValue: "old-commit", UpdatedValue: "new-commit", YorTraceID: "trace-uuid", Source: "git"},
+		}, sourceReport.UpdatedResourceTags)
+		assert.Contains(t, sourceReport.AsMarkdown(), "| new-commit | trace-uuid | git |")
+	})
+
+	t.Run("Test required tag violations are reported", func(t *testing.T) {
+		violationsAccumulator := NewTagChangeAccumulator()
+		violationsAccumulator.AccumulateChanges(&tfStructure.TerraformBlock{
+			Block: structure.Block{
+				FilePath:    "/module/main.tf",
+				Type:        "aws_s3_bucket",
+				ExitingTags: []tags.ITag{&tags.Tag{Key: "yor_trace", Value: "trace-uuid"}, &tags.Tag{Key: "costcenter", Value: "100"}},
+				NewTags:     []tags.ITag{&tags.Tag{Key: "Environment", Value: "staging"}},
+				IsTaggable:  true,
+			},
+			HclSyntaxBlock: &hclsyntax.Block{Labels: []string{"aws_s3_bucket", "bucket"}},
+		})
+		violationsService := NewReportService(violationsAccumulator)
+		violationsService.SetRequiredTags(&tagpolicy.RequiredTags{Tags: []tagpolicy.RequiredTag{
+			{Key: "CostCenter"},
+			{Key: "Environment", AllowedValues: []string{"prod", "dev*"}, Severity: "Warning"},
+			{Key: "Owner", ResourceTypes: []string{"aws_instance"}},
+		}})
+		violationsReport := violationsService.CreateReport()
+		assert.Equal(t, 2, violationsReport.Summary.RequiredTagViolations)
+		assert.Equal(t, []RequiredTagViolation{
+			{File: "/module/main.tf", ResourceID: "aws_s3_bucket.bucket", TagKey: "CostCenter", Reason: "key is capitalized as costcenter", YorTraceID: "trace-uuid", Severity: "error"},
+			{File: "/module/main.tf", ResourceID: "aws_s3_bucket.bucket", TagKey: "Environment", Reason: "value staging is not one of prod, dev*", YorTraceID: "trace-uuid", Severity: "warning"},
+		}, violationsReport.RequiredTagViolations)
+		assert.Equal(t, 1, violationsReport.CountRequiredTagViolations(tagpolicy.SeverityError))
+		assert.Equal(t, 1, violationsReport.CountRequiredTagViolations(tagpolicy.SeverityWarning))
+		assert.Contains(t, violationsReport.AsMarkdown(), "### Required Tag Violations (2)")
+		assert.Contains(t, violationsReport.AsMarkdown(), "| Environment | value staging is not one of prod, dev* | warning |")
+	})
+
+	t.Run("Test findings of the baseline are suppressed", func(t *testing.T) {
+		baselineAccumulator := NewTagChangeAccumulator()
+		for _, name := range []string{"legacy", "added"} {
+			baselineAccumulator.AccumulateChanges(&tfStructure.TerraformBlock{
+				Block: structure.Block{
+					FilePath:   "/module/main.tf",
+					Type:       "aws_s3_bucket",
+					NewTags:    []tags.ITag{&tags.Tag{Key: "yor_trace", Value: name + "-uuid"}, &tags.Tag{Key: "git_commit", Value: "new-commit"}},
+					IsTaggable: true,
+				},
+				HclSyntaxBlock: &hclsyntax.Block{Labels: []string{"aws_s3_bucket", name}},
+			})
+		}
+		baselineService := NewReportService(baselineAccumulator)
+		baselineService.SetRequiredTags(&tagpolicy.RequiredTags{Tags: []tagpolicy.RequiredTag{{Key: "Owner"}}})
+		baselineFile := filepath.Join(t.TempDir(), "baseline.json")
+		// the baseline was created on windows, with an older commit
+		assert.Nil(t, os.WriteFile(baselineFile, []byte(`{
+			"newResourceTags": [
+				{"file": "\\module\\main.tf", "resourceId": "aws_s3_bucket.legacy", "key": "yor_trace", "updatedValue": "legacy-uuid"},
+				{"file": "\\module\\main.tf", "resourceId": "aws_s3_bucket.legacy", "key": "git_commit", "updatedValue": "old-commit"}
+			],
+			"requiredTagViolations": [{"file": "/module/main.tf", "resourceId": "aws_s3_bucket.legacy", "key": "Owner", "reason": "missing"}]
+		}`), 0600))
+		baseline, err := LoadBaseline(baselineFile)
+		assert.Nil(t, err)
+		baselineService.SetBaseline(baseline)
+
+		baselineReport := baselineService.CreateReport()
+		assert.Equal(t, 1, baselineReport.Summary.NewResources)
+		assert.Equal(t, 1, baselineReport.Summary.RequiredTagViolations)
+		assert.Equal(t, 3, baselineReport.Summary.SuppressedByBaseline)
+		for _, record := range baselineReport.NewResourceTags {
+			assert.Equal(t, "aws_s3_bucket.added", record.ResourceID)
+		}
+		assert.Equal(t, "aws_s3_bucket.added", baselineReport.RequiredTagViolations[0].ResourceID)
+		assert.Contains(t, baselineReport.AsMarkdown(), "3 findings of the baseline are not shown")
+
+		_, err = LoadBaseline(filepath.Join(t.TempDir(), "missing.json"))
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Test tags export holds the final tags of each resource", func(t *testing.T) {
+		exportAccumulator := NewTagChangeAccumulator()
+		exportAccumulator.AccumulateChanges(&tfStructure.TerraformBlock{
+			Block: structure.Block{
+				FilePath:    "/module/main.tf",
+				Type:        "aws_s3_bucket",
+				ExitingTags: []tags.ITag{&tags.Tag{Key: "yor_trace", Value: "trace-uuid"}, &tags.Tag{Key: "git_commit", Value: "old-commit"}},
+				NewTags:     []tags.ITag{&tags.Tag{Key: "git_commit", Value: "new-commit"}, &tags.Tag{Key: "team", Value: "platform"}},
+				IsTaggable:  true,
+			},
+			HclSyntaxBlock: &hclsyntax.Block{Labels: []string{"aws_s3_bucket", "bucket"}},
+		})
+		exportAccumulator.AccumulateChanges(&tfStructure.TerraformBlock{
+			Block:          structure.Block{FilePath: "/module/main.tf", Type: "aws_iam_policy_document", IsTaggable: false},
+			HclSyntaxBlock: &hclsyntax.Block{Labels: []string{"aws_iam_policy_document", "policy"}},
+		})
+		exportService := NewReportService(exportAccumulator)
+		exportFile := filepath.Join(t.TempDir(), "tags.json")
+		exportService.PrintTagsExportToFile(exportFile)
+		content, err := os.ReadFile(exportFile)
+		assert.Nil(t, err)
+		export := TagsExport{}
+		assert.Nil(t, json.Unmarshal(content, &export))
+		assert.Equal(t, TagsExport{Resources: []ResourceTags{{
+			File:         "/module/main.tf",
+			ResourceID:   "aws_s3_bucket.bucket",
+			ResourceType: "aws_s3_bucket",
+			YorTraceID:   "trace-uuid",
+			Tags:         map[string]string{"yor_trace": "trace-uuid", "git_commit": "new-commit", "team": "platform"},
+		}}}, export)
+	})
+
+	t.Run("Test enrichment maps the yor traces to the locations and owners of the resources", func(t *testing.T) {
+		enrichmentAccumulator := NewTagChangeAccumulator()
+		enrichmentAccumulator.AccumulateChanges(&structure.Block{
+			FilePath: filepath.Join("module", "network", "main.tf"),
+			Name:     "aws_vpc.main",
+			Type:     "aws_vpc",
+			Lines:    structure.Lines{Start: 3, End: 9},
+			ExitingTags: []tags.ITag{
+				&tags.Tag{Key: "yor_trace", Value: "trace-uuid"},
+				&tags.Tag{Key: "git_org", Value: "bridgecrewio"},
+				&tags.Tag{Key: "git_repo", Value: "yor"},
+			},
+			NewTags: []tags.ITag{
+				&tags.Tag{Key: "git_modifiers", Value: "alice/bob"},
+				&tags.Tag{Key: "git_last_modified_by", Value: "bob@example.com"},
+			},
+			IsTaggable: true,
+		})
+		enrichmentAccumulator.AccumulateChanges(&structure.Block{FilePath: filepath.Join("module", "main.tf"), Name: "aws_s3_bucket.untraced", IsTaggable: true})
+		enrichmentService := NewReportService(enrichmentAccumulator)
+		enrichmentService.SetDirectory("module")
+		enrichmentFile := filepath.Join(t.TempDir(), "enrichment.json")
+		enrichmentService.PrintEnrichmentToFile(enrichmentFile)
+		content, err := os.ReadFile(enrichmentFile)
+		assert.Nil(t, err)
+		enrichment := Enrichment{}
+		assert.Nil(t, json.Unmarshal(content, &enrichment))
+		assert.Equal(t, Enrichment{
+			Repository: "bridgecrewio/yor",
+			Resources: map[string]EnrichmentResource{"trace-uuid": {
+				FilePath:      "/network/main.tf",
+				FileLineRange: []int{3, 9},
+				Resource:      "aws_vpc.main",
+				ResourceType:  "aws_vpc",
+				CodeOwners:    []string{"alice", "bob"},
+				LastModifier:  "bob@example.com",
+			}},
+		}, enrichment)
+	})
+
+	t.Run("Test nested stacks are related to their templates", func(t *testing.T) {
+		stackAccumulator := NewTagChangeAccumulator()
+		stackAccumulator.AccumulateChanges(&cfnStructure.CloudformationBlock{
+			Block: structure.Block{
+				FilePath:   "/stacks/parent.yaml",
+				Name:       "NetworkStack",
+				NewTags:    []tags.ITag{&code2cloud.YorTraceTag{Tag: tags.Tag{Key: "yor_trace", Value: "stack-uuid"}}},
+				IsTaggable: true,
+			},
+			NestedTemplateURL:  "network.yaml",
+			NestedTemplateFile: "/stacks/network.yaml",
+		})
+		stackAccumulator.AccumulateChanges(&cfnStructure.CloudformationBlock{
+			Block: structure.Block{
+				FilePath:   "/stacks/parent.yaml",
+				Name:       "Bucket",
+				IsTaggable: true,
+			},
+		})
+		stackReport := NewReportService(stackAccumulator).CreateReport()
+		assert.Equal(t, []NestedStack{{File: "/stacks/parent.yaml", ResourceID: "NetworkStack", TemplateURL: "network.yaml", TemplateFile: "/stacks/network.yaml", YorTraceID: "stack-uuid"}},
+			stackReport.NestedStacks)
+		assert.Contains(t, stackReport.AsMarkdown(), "### Nested Stacks (1)")
+	})
+
+	t.Run("Test APIs are related to their OpenAPI definitions", func(t *testing.T) {
+		apiAccumulator := NewTagChangeAccumulator()
+		apiAccumulator.AccumulateChanges(&tfStructure.TerraformBlock{
+			Block: structure.Block{
+				FilePath:   "/api/main.tf",
+				NewTags:    []tags.ITag{&code2cloud.YorTraceTag{Tag: tags.Tag{Key: "yor_trace", Value: "api-uuid"}}},
+				IsTaggable: true,
+			},
+			HclSyntaxBlock:    &hclsyntax.Block{Labels: []string{"aws_apigatewayv2_api", "pets"}},
+			APIDefinitionFile: "/api/openapi.yaml",
+		})
+		apiReport := NewReportService(apiAccumulator).CreateReport()
+		assert.Equal(t, []APIDefinition{{File: "/api/main.tf", ResourceID: "aws_apigatewayv2_api.pets", DefinitionFile: "/api/openapi.yaml", YorTraceID: "api-uuid"}},
+			apiReport.APIDefinitions)
+		assert.Contains(t, apiReport.AsMarkdown(), "### API Definitions (1)")
+	})
+
+	t.Run("Test resources with uneditable tags are reported as skipped", func(t *testing.T) {
+		uneditableAccumulator := NewTagChangeAccumulator()
+		uneditableAccumulator.AccumulateChanges(&tfStructure.TerraformBlock{
+			Block: structure.Block{
+				FilePath:          "/module/main.tf",
+				TagsAttributeName: "tags",
+			},
+			HclSyntaxBlock:       &hclsyntax.Block{Labels: []string{"aws_s3_bucket", "conditional_tags"}},
+			UneditableTagsReason: "the expression of tags has no literal map to add the tags to",
+		})
+		uneditableAccumulator.AccumulateChanges(&tfStructure.TerraformBlock{
+			Block: structure.Block{
+				FilePath:   "/module/main.tf",
+				NewTags:    []tags.ITag{&code2cloud.YorTraceTag{Tag: tags.Tag{Key: "yor_trace", Value: "new-uuid"}}},
+				IsTaggable: true,
+			},
+			HclSyntaxBlock: &hclsyntax.Block{Labels: []string{"aws_s3_bucket", "new_bucket"}},
+		})
+		uneditableReport := NewReportService(uneditableAccumulator).CreateReport()
+		assert.Equal(t, 1, uneditableReport.Summary.NewResources)
+		assert.Equal(t, []SkippedResource{{File: "/module/main.tf", ResourceID: "aws_s3_bucket.conditional_tags", Reason: "the expression of tags has no literal map to add the tags to"}},
+			uneditableReport.SkippedResources)
+		assert.Contains(t, uneditableReport.AsMarkdown(), "### Skipped Resources (1)")
+	})
+
+	t.Run("Test graph relates module calls to the resources of their modules", func(t *testing.T) {
+		graphAccumulator := NewTagChangeAccumulator()
+		graphAccumulator.AccumulateChanges(&tfStructure.TerraformBlock{
+			Block: structure.Block{
+				FilePath:    "/estate/main.tf",
+				ExitingTags: []tags.ITag{&tags.Tag{Key: "team", Value: "network"}},
+				IsTaggable:  true,
+			},
+			HclSyntaxBlock: &hclsyntax.Block{Labels: []string{"vpc"}},
+			ModuleSource:   "./modules/vpc",
+		})
+		graphAccumulator.AccumulateChanges(&tfStructure.TerraformBlock{
+			Block: structure.Block{
+				FilePath:   "/estate/modules/vpc/main.tf",
+				NewTags:    []tags.ITag{&code2cloud.YorTraceTag{Tag: tags.Tag{Key: "yor_trace", Value: "vpc-uuid"}}},
+				IsTaggable: true,
+			},
+			HclSyntaxBlock: &hclsyntax.Block{Labels: []string{"aws_vpc", "this"}},
+		})
+		graphAccumulator.AccumulateChanges(&tfStructure.TerraformBlock{
+			Block:          structure.Block{FilePath: "/estate/modules/vpc/variables.tf"},
+			HclSyntaxBlock: &hclsyntax.Block{Labels: []string{"tags"}},
+		})
+		graphReportService := NewReportService(graphAccumulator)
+		graphReportService.SetPathStyle(PosixPathStyle)
+		graph := graphReportService.GetGraph()
+		assert.Equal(t, []GraphNode{
+			{ID: "file:/estate/main.tf", Kind: FileNode, Label: "/estate/main.tf"},
+			{ID: "file:/estate/modules/vpc/main.tf", Kind: FileNode, Label: "/estate/modules/vpc/main.tf"},
+			{ID: "module:/estate", Kind: ModuleNode, Label: "/estate"},
+			{ID: "module:/estate/modules/vpc", Kind: ModuleNode, Label: "/estate/modules/vpc"},
+			{ID: "resource:/estate/main.tf:vpc", Kind: ResourceNode, Label: "vpc", Tags: map[string]string{"team": "network"}},
+			{ID: "resource:/estate/modules/vpc/main.tf:aws_vpc.this", Kind: ResourceNode, Label: "aws_vpc.this", Tags: map[string]string{"yor_trace": "vpc-uuid"}},
+		}, graph.Nodes)
+		assert.Equal(t, []GraphEdge{
+			{From: "file:/estate/main.tf", To: "resource:/estate/main.tf:vpc", Kind: ContainsEdge},
+			{From: "file:/estate/modules/vpc/main.tf", To: "resource:/estate/modules/vpc/main.tf:aws_vpc.this", Kind: ContainsEdge},
+			{From: "module:/estate", To: "file:/estate/main.tf", Kind: ContainsEdge},
+			{From: "module:/estate/modules/vpc", To: "file:/estate/modules/vpc/main.tf", Kind: ContainsEdge},
+			{From: "resource:/estate/main.tf:vpc", To: "module:/estate/modules/vpc", Kind: CallsEdge},
+		}, graph.Edges)
+
+		dot := graph.AsDOT()
+		assert.Contains(t, dot, `"resource:/estate/modules/vpc/main.tf:aws_vpc.this" [label="aws_vpc.this\lyor_trace = vpc-uuid\l", shape=box];`)
+		assert.Contains(t, dot, `"resource:/estate/main.tf:vpc" -> "module:/estate/modules/vpc" [label="calls", style=dashed];`)
+
+		graphFile := filepath.Join(t.TempDir(), "graph.json")
+		graphReportService.PrintGraphToFile(graphFile)
+		graphBytes, err := os.ReadFile(graphFile)
+		assert.Nil(t, err)
+		var writtenGraph Graph
+		assert.Nil(t, json.Unmarshal(graphBytes, &writtenGraph))
+		assert.Equal(t, *graph, writtenGraph)
+	})
+
+	t.Run("Test reports of different accumulators are isolated", func(t *testing.T) {
+		otherReportService := NewReportService(NewTagChangeAccumulator())
+		otherReport := otherReportService.CreateReport()
+		assert.Equal(t, 0, otherReport.Summary.Scanned)
+		assert.Equal(t, 5, reportService.CreateReport().Summary.Scanned)
+	})
+
 	t.Run("Test list-tags result", func(t *testing.T) {
 		grt := &gittag.GitRepoTag{}
 		grt.Init()
@@ -165,7 +608,7 @@ func TestResultsGeneration(t *testing.T) {
 		ytt.Init()
 
 		o := utils.CaptureOutput(func() {
-			ReportServiceInst.PrintTagGroupTags(map[string][]tags.ITag{
+			reportService.PrintTagGroupTags(map[string][]tags.ITag{
 				"git": {
 					grt,
 					got,
@@ -185,7 +628,7 @@ func TestResultsGeneration(t *testing.T) {
 }
 
 func setupAccumulator() *TagChangeAccumulator {
-	accumulator := TagChangeAccumulatorInstance
+	accumulator := NewTagChangeAccumulator()
 	accumulator.AccumulateChanges(&tfStructure.TerraformBlock{
 		Block: structure.Block{
 			FilePath:    "/module/regional/mock.tf",
@@ -0,0 +1,84 @@
+package reports
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+const (
+	// SigningKeyEnvKey holds the secret key the JSON report is signed and verified with, which is kept out of the
+	// arguments so it doesn't show up in the process list or the run manifest
+	SigningKeyEnvKey = "YOR_REPORT_SIGNING_KEY"
+	// SignatureSuffix is appended to the path of the JSON report for the path of its signature
+	SignatureSuffix = ".sig"
+	HMACSHA256      = "hmac-sha256"
+)
+
+// ReportSignature is the signature of a JSON report, which is written next to it, so compliance systems can verify the
+// report wasn't altered since it was created
+type ReportSignature struct {
+	Algorithm string `json:"algorithm"`
+	Signature string `json:"signature"`
+}
+
+// SignReport signs the report with the key, as the HMAC-SHA256 of its bytes
+func SignReport(report []byte, key []byte) *ReportSignature {
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(report)
+	return &ReportSignature{
+		Algorithm: HMACSHA256,
+		Signature: hex.EncodeToString(mac.Sum(nil)),
+	}
+}
+
+// VerifyReport checks the signature was made for the report with the key
+func VerifyReport(report []byte, signature *ReportSignature, key []byte) error {
+	if signature.Algorithm != HMACSHA256 {
+		return fmt.Errorf("unsupported signature algorithm %s, expected %s", signature.Algorithm, HMACSHA256)
+	}
+	expected := SignReport(report, key)
+	if !hmac.Equal([]byte(signature.Signature), []byte(expected.Signature)) {
+		return errors.New("the report was altered since it was signed, or wasn't signed with the signing key")
+	}
+	return nil
+}
+
+// LoadReportSignature reads the signature of a report
+func LoadReportSignature(signaturePath string) (*ReportSignature, error) {
+	// #nosec G304 - file is from user
+	content, err := os.ReadFile(signaturePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the signature %s: %s", signaturePath, err)
+	}
+	signature := &ReportSignature{}
+	if err = json.Unmarshal(content, signature); err != nil {
+		return nil, fmt.Errorf("failed to parse the signature %s: %s", signaturePath, err)
+	}
+	return signature, nil
+}
+
+// PrintSignedJSONToFile writes the JSON report to the file like PrintJSONToFile, and its signature with the key to the
+// file with the SignatureSuffix. Unlike the other outputs, a report which can't be signed fails the run, since it
+// can't be verified later.
+func (r *ReportService) PrintSignedJSONToFile(file string, key []byte) error {
+	jr, err := r.getReportJSONBytes()
+	if err != nil {
+		return fmt.Errorf("failed to create report as JSON: %s", err)
+	}
+	if err = writeOutputFile(file, jr, "application/json"); err != nil {
+		return fmt.Errorf("failed to write the report to %s: %s", file, err)
+	}
+	signatureBytes, err := json.MarshalIndent(SignReport(jr, key), "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to create the signature of the report as JSON: %s", err)
+	}
+	if err = writeOutputFile(file+SignatureSuffix, signatureBytes, "application/json"); err != nil {
+		return fmt.Errorf("failed to write the signature of the report to %s: %s", file+SignatureSuffix, err)
+	}
+	return nil
+}
@@ -3,30 +3,41 @@ package reports
 import (
 	"sync"
 
+	"github.com/bridgecrewio/yor/src/common"
 	"github.com/bridgecrewio/yor/src/common/structure"
 )
 
+// TagChangeAccumulator collects the scanned blocks of a single run. It is safe for concurrent use, so the workers of a
+// runner can share it, while every runner has its own.
 type TagChangeAccumulator struct {
 	ScannedBlocks      []structure.IBlock
 	NewBlockTraces     []structure.IBlock
 	UpdatedBlockTraces []structure.IBlock
+	ImportedBlocks     []structure.IBlock
+	SkippedFiles       []SkippedFile
+	Errors             []RunError
+	lock               sync.Mutex
 }
 
-var TagChangeAccumulatorInstance *TagChangeAccumulator
-var accumulatorLock sync.Mutex
-
-func init() {
-	TagChangeAccumulatorInstance = &TagChangeAccumulator{}
+func NewTagChangeAccumulator() *TagChangeAccumulator {
+	return &TagChangeAccumulator{}
 }
 
 // AccumulateChanges saves the results of the scan of each block.
 // If a block has no changes, it will be saved only to ScannedBlocks
-// Otherwise it will be saved to NewBlockTraces if it is new or to UpdatedBlockTraces otherwise
+// Otherwise it will be saved to ImportedBlocks if its resource is imported by the code, to NewBlockTraces if it is new
+// or to UpdatedBlockTraces otherwise
 func (a *TagChangeAccumulator) AccumulateChanges(block structure.IBlock) {
-	accumulatorLock.Lock()
-	defer accumulatorLock.Unlock()
+	a.lock.Lock()
+	defer a.lock.Unlock()
 	a.ScannedBlocks = append(a.ScannedBlocks, block)
 	diff := block.CalculateTagsDiff()
+	if importedBlock, ok := block.(structure.IImportedBlock); ok && importedBlock.IsImported() {
+		if len(diff.Added) > 0 || len(diff.Updated) > 0 {
+			a.ImportedBlocks = append(a.ImportedBlocks, block)
+		}
+		return
+	}
 	// If only tags are new, add to newly traced. If some updates - add to updated. Otherwise will be added to
 	// ScannedBlocks.
 	if len(diff.Updated) == 0 && len(diff.Added) > 0 {
@@ -36,11 +47,48 @@ func (a *TagChangeAccumulator) AccumulateChanges(block structure.IBlock) {
 	}
 }
 
+// AccumulateSkippedFile saves a file which wasn't tagged, with the reason it was skipped
+func (a *TagChangeAccumulator) AccumulateSkippedFile(file string, reason string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.SkippedFiles = append(a.SkippedFiles, SkippedFile{File: file, Reason: reason})
+}
+
+// AccumulateError saves an error which broke the run, or the tagging of a file if file is set
+func (a *TagChangeAccumulator) AccumulateError(code common.ErrorCode, file string, message string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.Errors = append(a.Errors, RunError{Code: code, File: file, Message: message})
+}
+
+func (a *TagChangeAccumulator) GetErrors() []RunError {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.Errors
+}
+
+func (a *TagChangeAccumulator) GetSkippedFiles() []SkippedFile {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.SkippedFiles
+}
+
 // GetBlockChanges returns both the NewBlockTraces and the UpdatedBlockTraces that were found by the parsers
 func (a *TagChangeAccumulator) GetBlockChanges() ([]structure.IBlock, []structure.IBlock) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
 	return a.NewBlockTraces, a.UpdatedBlockTraces
 }
 
+// GetImportedBlocks returns the changed blocks of the resources which are imported by the code
+func (a *TagChangeAccumulator) GetImportedBlocks() []structure.IBlock {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.ImportedBlocks
+}
+
 func (a *TagChangeAccumulator) GetScannedBlocks() []structure.IBlock {
+	a.lock.Lock()
+	defer a.lock.Unlock()
 	return a.ScannedBlocks
 }
@@ -0,0 +1,63 @@
+package reports
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/bridgecrewio/yor/src/common/logger"
+)
+
+// TagsExport holds the final tags of each taggable resource, after the run. It is shaped to be the input of policies
+// in the plan stage, i.e. input.resources[_].tags in Conftest (Rego) or the resources of a Sentinel mock.
+type TagsExport struct {
+	Resources []ResourceTags `json:"resources"`
+}
+
+type ResourceTags struct {
+	File         string            `json:"file"`
+	ResourceID   string            `json:"resourceId"`
+	ResourceType string            `json:"resourceType"`
+	YorTraceID   string            `json:"yorTraceId"`
+	Tags         map[string]string `json:"tags"`
+}
+
+// GetTagsExport returns the existing and new tags of the scanned resources, sorted by file and resource
+func (r *ReportService) GetTagsExport() *TagsExport {
+	export := &TagsExport{Resources: []ResourceTags{}}
+	for _, block := range r.accumulator.GetScannedBlocks() {
+		if !block.IsBlockTaggable() {
+			continue
+		}
+		resourceTags := make(map[string]string)
+		for _, tag := range block.MergeTags() {
+			resourceTags[tag.GetKey()] = tag.GetValue()
+		}
+		export.Resources = append(export.Resources, ResourceTags{
+			File:         r.formatPath(block.GetFilePath()),
+			ResourceID:   block.GetResourceID(),
+			ResourceType: block.GetResourceType(),
+			YorTraceID:   block.GetTraceID(),
+			Tags:         resourceTags,
+		})
+	}
+	sort.SliceStable(export.Resources, func(i, j int) bool {
+		if export.Resources[i].File != export.Resources[j].File {
+			return export.Resources[i].File < export.Resources[j].File
+		}
+		return export.Resources[i].ResourceID < export.Resources[j].ResourceID
+	})
+	return export
+}
+
+func (r *ReportService) PrintTagsExportToFile(file string) {
+	exportBytes, err := json.MarshalIndent(r.GetTagsExport(), "", "    ")
+	if err != nil {
+		logger.Warning("Failed to create the tags export as JSON")
+		return
+	}
+
+	err = writeOutputFile(file, exportBytes, "application/json")
+	if err != nil {
+		logger.Warning("Failed to write to the tags export file", err.Error())
+	}
+}
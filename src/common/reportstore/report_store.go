@@ -0,0 +1,147 @@
+package reportstore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bridgecrewio/yor/src/common"
+	"github.com/bridgecrewio/yor/src/common/i18n"
+	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/bridgecrewio/yor/src/common/reports"
+	"github.com/olekukonko/tablewriter"
+)
+
+const (
+	reportFilePrefix = "yor-report-"
+	timestampLayout  = "20060102T150405.000000000Z"
+	chartWidth       = 20
+)
+
+// StoredReport is the report of a run, kept in the report store with the directory it tagged
+type StoredReport struct {
+	Timestamp time.Time       `json:"timestamp"`
+	Version   string          `json:"version"`
+	Directory string          `json:"directory"`
+	DryRun    bool            `json:"dryRun"`
+	Report    *reports.Report `json:"report"`
+}
+
+// TrendPoint is the summary of a stored report. Coverage is the percentage of the scanned resources which were
+// already tagged by yor before the run.
+type TrendPoint struct {
+	Timestamp        time.Time `json:"timestamp"`
+	Directory        string    `json:"directory"`
+	DryRun           bool      `json:"dryRun"`
+	Scanned          int       `json:"scanned"`
+	NewResources     int       `json:"newResources"`
+	UpdatedResources int       `json:"updatedResources"`
+	Coverage         float64   `json:"coverage"`
+}
+
+// Save writes the report to the store, a directory of timestamped reports which is created if it doesn't exist
+func Save(storeDir string, directory string, dryRun bool, report *reports.Report) (string, error) {
+	if err := os.MkdirAll(storeDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create the report store %s: %s", storeDir, err)
+	}
+	storedReport := StoredReport{
+		Timestamp: time.Now().UTC(),
+		Version:   common.Version,
+		Directory: directory,
+		DryRun:    dryRun,
+		Report:    report,
+	}
+	reportBytes, err := json.MarshalIndent(storedReport, "", "    ")
+	if err != nil {
+		return "", err
+	}
+	reportPath := filepath.Join(storeDir, reportFilePrefix+storedReport.Timestamp.Format(timestampLayout)+".json")
+	logger.Info(fmt.Sprintf("Storing the report in %s", reportPath))
+	return reportPath, os.WriteFile(reportPath, reportBytes, 0600)
+}
+
+// Load returns the stored reports of the directory, oldest first. Reports of all the directories are returned if
+// directory is empty.
+func Load(storeDir string, directory string) ([]StoredReport, error) {
+	entries, err := os.ReadDir(storeDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the report store %s: %s", storeDir, err)
+	}
+	var storedReports []StoredReport
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), reportFilePrefix) || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		reportPath := filepath.Join(storeDir, entry.Name())
+		// #nosec G304 - the store is given by the user
+		reportBytes, err := os.ReadFile(reportPath)
+		if err != nil {
+			return nil, err
+		}
+		var storedReport StoredReport
+		if err = json.Unmarshal(reportBytes, &storedReport); err != nil || storedReport.Report == nil {
+			logger.Warning(fmt.Sprintf("Skipping %s, which isn't a stored report", reportPath))
+			continue
+		}
+		if directory == "" || filepath.Clean(storedReport.Directory) == filepath.Clean(directory) {
+			storedReports = append(storedReports, storedReport)
+		}
+	}
+	sort.SliceStable(storedReports, func(i, j int) bool {
+		return storedReports[i].Timestamp.Before(storedReports[j].Timestamp)
+	})
+	return storedReports, nil
+}
+
+func GetTrend(storedReports []StoredReport) []TrendPoint {
+	trend := make([]TrendPoint, 0, len(storedReports))
+	for _, storedReport := range storedReports {
+		summary := storedReport.Report.Summary
+		coverage := 100.0
+		if summary.Scanned > 0 {
+			coverage = float64(summary.Scanned-summary.NewResources) * 100 / float64(summary.Scanned)
+		}
+		trend = append(trend, TrendPoint{
+			Timestamp:        storedReport.Timestamp,
+			Directory:        storedReport.Directory,
+			DryRun:           storedReport.DryRun,
+			Scanned:          summary.Scanned,
+			NewResources:     summary.NewResources,
+			UpdatedResources: summary.UpdatedResources,
+			Coverage:         coverage,
+		})
+	}
+	return trend
+}
+
+// PrintTrend charts the coverage and the changes of each run
+func PrintTrend(w io.Writer, trend []TrendPoint) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(i18n.Headers("Time", "Directory", "Scanned", "New", "Updated", "Coverage"))
+	table.SetAutoWrapText(false)
+	for _, point := range trend {
+		directory := point.Directory
+		if point.DryRun {
+			directory += " (" + i18n.T("dry run") + ")"
+		}
+		table.Append([]string{
+			point.Timestamp.Local().Format("2006-01-02 15:04"),
+			directory,
+			fmt.Sprint(point.Scanned),
+			fmt.Sprint(point.NewResources),
+			fmt.Sprint(point.UpdatedResources),
+			fmt.Sprintf("%s %5.1f%%", coverageBar(point.Coverage), point.Coverage),
+		})
+	}
+	table.Render()
+}
+
+func coverageBar(coverage float64) string {
+	filled := int(coverage*chartWidth/100 + 0.5)
+	return strings.Repeat("█", filled) + strings.Repeat("░", chartWidth-filled)
+}
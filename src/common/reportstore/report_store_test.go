@@ -0,0 +1,57 @@
+package reportstore
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bridgecrewio/yor/src/common/reports"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportStore(t *testing.T) {
+	storeDir := filepath.Join(t.TempDir(), "reports")
+	firstReport := &reports.Report{Summary: reports.ReportSummary{Scanned: 4, NewResources: 4}}
+	secondReport := &reports.Report{Summary: reports.ReportSummary{Scanned: 5, NewResources: 1, UpdatedResources: 2}}
+
+	t.Run("store the reports of the runs", func(t *testing.T) {
+		_, err := Save(storeDir, "terraform", false, firstReport)
+		assert.Nil(t, err)
+		_, err = Save(storeDir, "cloudformation", true, &reports.Report{})
+		assert.Nil(t, err)
+		_, err = Save(storeDir, "terraform/", false, secondReport)
+		assert.Nil(t, err)
+		// other files in the store are ignored
+		assert.Nil(t, os.WriteFile(filepath.Join(storeDir, "README.md"), []byte("reports"), 0600))
+
+		storedReports, err := Load(storeDir, "")
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(storedReports))
+		storedReports, err = Load(storeDir, "terraform")
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(storedReports))
+		assert.Equal(t, firstReport.Summary, storedReports[0].Report.Summary)
+		assert.Equal(t, secondReport.Summary, storedReports[1].Report.Summary)
+	})
+
+	t.Run("trend of the coverage", func(t *testing.T) {
+		storedReports, err := Load(storeDir, "terraform")
+		assert.Nil(t, err)
+		trend := GetTrend(storedReports)
+		assert.Equal(t, 2, len(trend))
+		assert.Equal(t, 0.0, trend[0].Coverage)
+		assert.Equal(t, 80.0, trend[1].Coverage)
+		assert.Equal(t, 2, trend[1].UpdatedResources)
+
+		output := new(bytes.Buffer)
+		PrintTrend(output, trend)
+		assert.Contains(t, output.String(), "░░░░░░░░░░░░░░░░░░░░   0.0%")
+		assert.Contains(t, output.String(), "████████████████░░░░  80.0%")
+	})
+
+	t.Run("missing store", func(t *testing.T) {
+		_, err := Load(filepath.Join(storeDir, "missing"), "")
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,125 @@
+package runlock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultLockFileName is the lock file created in the tagged directory, unless another lock file is given
+const DefaultLockFileName = ".yor.lock"
+
+const (
+	// StaleLockAge is the age after which the lock of a run is considered abandoned, i.e. when yor was killed. Running
+	// yor processes refresh their lock well before it.
+	StaleLockAge = 2 * time.Minute
+
+	refreshInterval = StaleLockAge / 4
+	pollInterval    = 200 * time.Millisecond
+)
+
+// lockInfo is written to the lock file, so a blocked run can tell which process holds the lock
+type lockInfo struct {
+	PID       int       `json:"pid"`
+	Hostname  string    `json:"hostname"`
+	StartedAt time.Time `json:"startedAt"`
+}
+
+// Lock is an advisory lock, which keeps concurrent yor runs (i.e. a pre-commit hook and an IDE plugin) from tagging the
+// same directory. It is held as long as its lock file exists. A nil Lock holds nothing, so it can always be released.
+type Lock struct {
+	path string
+	stop chan struct{}
+	done sync.WaitGroup
+}
+
+// Acquire creates the lock file, waiting up to the given duration for the run which holds it to release it. A lock
+// file which wasn't refreshed for StaleLockAge is taken over.
+func Acquire(ctx context.Context, lockPath string, wait time.Duration) (*Lock, error) {
+	deadline := time.Now().Add(wait)
+	for {
+		err := create(lockPath)
+		if err == nil {
+			lock := &Lock{path: lockPath, stop: make(chan struct{})}
+			lock.done.Add(1)
+			go lock.refresh()
+			return lock, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create the lock file %s: %s", lockPath, err)
+		}
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > StaleLockAge {
+			// the run which created the lock stopped refreshing it
+			_ = os.Remove(lockPath)
+			continue
+		}
+		if !time.Now().Before(deadline) {
+			return nil, fmt.Errorf("%s, use --wait to wait for it to finish", describeHolder(lockPath))
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("stopped waiting for the lock: %w", ctx.Err())
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+func create(lockPath string) error {
+	// #nosec G304
+	lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	hostname, _ := os.Hostname()
+	infoBytes, _ := json.Marshal(lockInfo{PID: os.Getpid(), Hostname: hostname, StartedAt: time.Now().UTC()})
+	_, err = lockFile.Write(infoBytes)
+	if closeErr := lockFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(lockPath)
+	}
+	return err
+}
+
+func describeHolder(lockPath string) string {
+	// #nosec G304
+	infoBytes, err := os.ReadFile(lockPath)
+	var info lockInfo
+	if err != nil || json.Unmarshal(infoBytes, &info) != nil {
+		return fmt.Sprintf("the directory is locked by another yor run (lock file %s)", lockPath)
+	}
+	return fmt.Sprintf("the directory is locked by yor process %d on %s since %s (lock file %s)", info.PID, info.Hostname, info.StartedAt.Format(time.RFC3339), lockPath)
+}
+
+func (l *Lock) refresh() {
+	defer l.done.Done()
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			now := time.Now()
+			_ = os.Chtimes(l.path, now, now)
+		}
+	}
+}
+
+// Release removes the lock file
+func (l *Lock) Release() error {
+	if l == nil {
+		return nil
+	}
+	close(l.stop)
+	l.done.Wait()
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove the lock file %s: %s", l.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package runlock
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLock(t *testing.T) {
+	t.Run("concurrent runs are locked out", func(t *testing.T) {
+		lockPath := filepath.Join(t.TempDir(), DefaultLockFileName)
+		lock, err := Acquire(context.Background(), lockPath, 0)
+		assert.Nil(t, err)
+		_, err = Acquire(context.Background(), lockPath, 0)
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "the directory is locked by yor process")
+		assert.Nil(t, lock.Release())
+		_, err = os.Stat(lockPath)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("wait for the lock to be released", func(t *testing.T) {
+		lockPath := filepath.Join(t.TempDir(), DefaultLockFileName)
+		lock, err := Acquire(context.Background(), lockPath, 0)
+		assert.Nil(t, err)
+		go func() {
+			time.Sleep(3 * pollInterval)
+			_ = lock.Release()
+		}()
+		waitingLock, err := Acquire(context.Background(), lockPath, time.Minute)
+		assert.Nil(t, err)
+		assert.Nil(t, waitingLock.Release())
+	})
+
+	t.Run("stop waiting when the context is cancelled", func(t *testing.T) {
+		lockPath := filepath.Join(t.TempDir(), DefaultLockFileName)
+		lock, err := Acquire(context.Background(), lockPath, 0)
+		assert.Nil(t, err)
+		defer func() {
+			_ = lock.Release()
+		}()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = Acquire(ctx, lockPath, time.Minute)
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
+
+	t.Run("take over stale locks", func(t *testing.T) {
+		lockPath := filepath.Join(t.TempDir(), DefaultLockFileName)
+		assert.Nil(t, os.WriteFile(lockPath, []byte("{}"), 0600))
+		staleTime := time.Now().Add(-2 * StaleLockAge)
+		assert.Nil(t, os.Chtimes(lockPath, staleTime, staleTime))
+		lock, err := Acquire(context.Background(), lockPath, 0)
+		assert.Nil(t, err)
+		assert.Nil(t, lock.Release())
+	})
+
+	t.Run("nil locks are released", func(t *testing.T) {
+		var lock *Lock
+		assert.Nil(t, lock.Release())
+	})
+}
@@ -0,0 +1,236 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bridgecrewio/yor/src/common/i18n"
+	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/bridgecrewio/yor/src/common/tagging"
+	"github.com/bridgecrewio/yor/src/common/tagging/gittag"
+	"github.com/olekukonko/tablewriter"
+)
+
+// Benchmark holds the durations of the phases of a run. The walk and report phases are timed once, while the parse,
+// tags, git and write phases are summed over the files, which are tagged by concurrent workers, so they may add up to
+// more than the total. Durations are in nanoseconds in JSON.
+type Benchmark struct {
+	Directory  string               `json:"directory"`
+	Workers    int                  `json:"workers"`
+	Files      int                  `json:"files"`
+	Resources  int                  `json:"resources"`
+	Total      time.Duration        `json:"total"`
+	Phases     PhaseDurations       `json:"phases"`
+	Frameworks []FrameworkBenchmark `json:"frameworks"`
+}
+
+type PhaseDurations struct {
+	Walk  time.Duration `json:"walk"`
+	Parse time.Duration `json:"parse"`
+	// Tags is the duration of the tag groups other than git, whose blame is timed on its own
+	Tags   time.Duration `json:"tags"`
+	Git    time.Duration `json:"git"`
+	Write  time.Duration `json:"write"`
+	Report time.Duration `json:"report"`
+}
+
+// FrameworkBenchmark holds the durations of the phases of the files parsed by a framework
+type FrameworkBenchmark struct {
+	Framework string        `json:"framework"`
+	Files     int           `json:"files"`
+	Resources int           `json:"resources"`
+	Parse     time.Duration `json:"parse"`
+	Tags      time.Duration `json:"tags"`
+	Git       time.Duration `json:"git"`
+	Write     time.Duration `json:"write"`
+}
+
+// benchmarkRecorder collects the durations of the workers by framework. A nil recorder records nothing, so runs which
+// aren't benchmarked aren't slowed down by it.
+type benchmarkRecorder struct {
+	lock       sync.Mutex
+	frameworks map[string]*FrameworkBenchmark
+}
+
+func newBenchmarkRecorder() *benchmarkRecorder {
+	return &benchmarkRecorder{frameworks: map[string]*FrameworkBenchmark{}}
+}
+
+func (b *benchmarkRecorder) add(framework string, update func(framework *FrameworkBenchmark)) {
+	if b == nil {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if _, ok := b.frameworks[framework]; !ok {
+		b.frameworks[framework] = &FrameworkBenchmark{Framework: framework}
+	}
+	update(b.frameworks[framework])
+}
+
+func (b *benchmarkRecorder) addTagGroup(framework string, tagGroup tagging.ITagGroup, duration time.Duration) {
+	_, isGit := tagGroup.(*gittag.TagGroup)
+	b.add(framework, func(framework *FrameworkBenchmark) {
+		if isGit {
+			framework.Git += duration
+		} else {
+			framework.Tags += duration
+		}
+	})
+}
+
+// Benchmark tags the files of the directory like a dry run, and times the phases of the run. The tagged files are
+// written to a temporary directory rather than over the files, so writing them is timed without changing them.
+func (r *Runner) Benchmark() (*Benchmark, error) {
+	r.benchmark = newBenchmarkRecorder()
+	start := time.Now()
+	files := r.listFiles()
+	walkDuration := time.Since(start)
+
+	writeDir, err := os.MkdirTemp("", "yor-benchmark-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a temporary directory for the written files: %s", err)
+	}
+	defer func() {
+		_ = os.RemoveAll(writeDir)
+	}()
+	var writtenFiles int64
+	var wg sync.WaitGroup
+	fileChan := make(chan string)
+	for i := 0; i < r.workersNum; i++ {
+		go func() {
+			for file := range fileChan {
+				// every written file gets its own directory, which keeps its name as some parsers rely on it
+				fileWriteDir := filepath.Join(writeDir, fmt.Sprint(atomic.AddInt64(&writtenFiles, 1)))
+				r.benchmarkFile(file, fileWriteDir)
+				wg.Done()
+			}
+		}()
+	}
+	for _, file := range files {
+		if r.ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		fileChan <- file
+	}
+	close(fileChan)
+	wg.Wait()
+	for _, parser := range r.parsers {
+		parser.Close()
+	}
+	if err = r.ctx.Err(); err != nil {
+		return nil, fmt.Errorf("the benchmark of %s was interrupted: %w", r.dir, err)
+	}
+
+	reportStart := time.Now()
+	r.reportingService.CreateReport()
+	benchmark := &Benchmark{
+		Directory:  r.dir,
+		Workers:    r.workersNum,
+		Files:      len(files),
+		Phases:     PhaseDurations{Walk: walkDuration, Report: time.Since(reportStart)},
+		Frameworks: []FrameworkBenchmark{},
+	}
+	benchmark.Total = time.Since(start)
+	for _, framework := range r.benchmark.frameworks {
+		benchmark.Resources += framework.Resources
+		benchmark.Phases.Parse += framework.Parse
+		benchmark.Phases.Tags += framework.Tags
+		benchmark.Phases.Git += framework.Git
+		benchmark.Phases.Write += framework.Write
+		benchmark.Frameworks = append(benchmark.Frameworks, *framework)
+	}
+	sort.Slice(benchmark.Frameworks, func(i, j int) bool {
+		return benchmark.Frameworks[i].Framework < benchmark.Frameworks[j].Framework
+	})
+	return benchmark, nil
+}
+
+// benchmarkFile tags the file like TagFile in a dry run, and writes the tagged file to writeDir
+func (r *Runner) benchmarkFile(file string, writeDir string) {
+	if reason := r.getFileLimitViolation(file); reason != "" {
+		r.skipFile(file, reason)
+		return
+	}
+	for _, parser := range r.parsers {
+		blocks, isFileTaggable, err := r.tagFileWithParser(parser, file)
+		if err != nil {
+			continue
+		}
+		for _, block := range blocks {
+			if !r.isBlockSkipped(block) {
+				r.ChangeAccumulator.AccumulateChanges(block)
+			}
+		}
+		if !isFileTaggable || !r.hasTagChanges(blocks) {
+			continue
+		}
+		if err = os.MkdirAll(writeDir, 0700); err != nil {
+			logger.Warning(fmt.Sprintf("Failed to create %s: %s", writeDir, err))
+			continue
+		}
+		start := time.Now()
+		err = parser.WriteFile(file, blocks, filepath.Join(writeDir, filepath.Base(file)))
+		r.benchmark.add(parser.Name(), func(framework *FrameworkBenchmark) {
+			framework.Write += time.Since(start)
+		})
+		if err != nil {
+			logger.Warning(fmt.Sprintf("Failed writing tags of file %s, because %v", file, err))
+		}
+	}
+}
+
+// Print writes the durations of the phases and of the frameworks as tables
+func (b *Benchmark) Print(w io.Writer) {
+	_, _ = fmt.Fprintf(w, i18n.T("Benchmark of %s: %d files, %d resources, %d workers")+"\n", b.Directory, b.Files, b.Resources, b.Workers)
+	_, _ = fmt.Fprintln(w, i18n.T("The parse, tags, git and write durations are summed over the concurrent workers"))
+	phasesTable := tablewriter.NewWriter(w)
+	phasesTable.SetHeader(i18n.Headers("Phase", "Duration"))
+	phasesTable.SetAutoWrapText(false)
+	phases := []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"Walk", b.Phases.Walk},
+		{"Parse", b.Phases.Parse},
+		{"Tags", b.Phases.Tags},
+		{"Git", b.Phases.Git},
+		{"Write", b.Phases.Write},
+		{"Report", b.Phases.Report},
+		{"Total", b.Total},
+	}
+	for _, phase := range phases {
+		phasesTable.Append([]string{i18n.T(phase.name), formatDuration(phase.duration)})
+	}
+	phasesTable.Render()
+
+	frameworksTable := tablewriter.NewWriter(w)
+	frameworksTable.SetHeader(i18n.Headers("Framework", "Files", "Resources", "Parse", "Tags", "Git", "Write"))
+	frameworksTable.SetAutoWrapText(false)
+	for _, framework := range b.Frameworks {
+		frameworksTable.Append([]string{
+			framework.Framework,
+			fmt.Sprint(framework.Files),
+			fmt.Sprint(framework.Resources),
+			formatDuration(framework.Parse),
+			formatDuration(framework.Tags),
+			formatDuration(framework.Git),
+			formatDuration(framework.Write),
+		})
+	}
+	frameworksTable.Render()
+}
+
+func formatDuration(duration time.Duration) string {
+	if duration < time.Millisecond {
+		return duration.Round(time.Microsecond).String()
+	}
+	return duration.Round(time.Millisecond).String()
+}
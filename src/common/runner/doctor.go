@@ -0,0 +1,180 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/bridgecrewio/yor/src/common"
+	"github.com/bridgecrewio/yor/src/common/i18n"
+	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/bridgecrewio/yor/src/common/structure"
+	"github.com/bridgecrewio/yor/src/common/tagging/code2cloud"
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+	"github.com/olekukonko/tablewriter"
+)
+
+// Diagnosis holds the problems yor doctor found in the tags of the directory, which are repaired with --fix
+type Diagnosis struct {
+	Directory       string `json:"directory"`
+	TracedResources int    `json:"tracedResources"`
+	// DuplicateTraces are the yor_trace values set on several resources, mostly by copy-pasted blocks, which break
+	// the tracing of the resources to their code
+	DuplicateTraces []DuplicateTrace `json:"duplicateTraces"`
+	Repaired        int              `json:"repaired"`
+}
+
+// DuplicateTrace is a yor_trace value and the resources which have it. The first resource, by file and line, keeps it,
+// and the others are given new traces when they are repaired.
+type DuplicateTrace struct {
+	TraceID   string           `json:"traceId"`
+	Resources []TracedResource `json:"resources"`
+}
+
+type TracedResource struct {
+	File       string `json:"file"`
+	Line       int    `json:"line"`
+	ResourceID string `json:"resourceId"`
+	// NewTraceID is the trace the resource was repaired with, if it was
+	NewTraceID string `json:"newTraceId,omitempty"`
+	block      structure.IBlock
+}
+
+type parsedFile struct {
+	file   string
+	parser common.IParser
+	blocks []structure.IBlock
+}
+
+// Doctor checks the tags of the files of the directory, without computing new tags. If fix is set, the resources with
+// duplicate yor_trace values, other than the first resource of each, are given new traces and their files are written.
+func (r *Runner) Doctor(fix bool) (*Diagnosis, error) {
+	diagnosis := &Diagnosis{Directory: r.dir, DuplicateTraces: []DuplicateTrace{}}
+	var parsedFiles []parsedFile
+	resourcesByTrace := map[string][]TracedResource{}
+	for _, file := range r.listFiles() {
+		if r.ctx.Err() != nil {
+			break
+		}
+		for _, parser := range r.parsers {
+			if r.isFileSkipped(parser, file) {
+				continue
+			}
+			blocks, err := parser.ParseFile(file)
+			if err != nil {
+				logger.Info(fmt.Sprintf("Failed to parse file %v with parser %v", file, parser.Name()))
+				continue
+			}
+			structure.SetTraceKey(blocks, r.keyNames.Get(tags.YorTraceTagKey))
+			parsedFiles = append(parsedFiles, parsedFile{file: file, parser: parser, blocks: blocks})
+			for _, block := range blocks {
+				if !block.IsBlockTaggable() || r.isBlockSkipped(block) {
+					continue
+				}
+				traceID := block.GetTraceID()
+				if traceID == "" {
+					continue
+				}
+				diagnosis.TracedResources++
+				resourcesByTrace[traceID] = append(resourcesByTrace[traceID], TracedResource{
+					File:       file,
+					Line:       block.GetLines().Start,
+					ResourceID: block.GetResourceID(),
+					block:      block,
+				})
+			}
+		}
+	}
+	for _, parser := range r.parsers {
+		parser.Close()
+	}
+	if err := r.ctx.Err(); err != nil {
+		return nil, fmt.Errorf("the diagnosis of %s was interrupted: %w", r.dir, err)
+	}
+
+	for traceID, resources := range resourcesByTrace {
+		if len(resources) < 2 {
+			continue
+		}
+		sort.Slice(resources, func(i, j int) bool {
+			return isBefore(resources[i], resources[j])
+		})
+		diagnosis.DuplicateTraces = append(diagnosis.DuplicateTraces, DuplicateTrace{TraceID: traceID, Resources: resources})
+	}
+	sort.Slice(diagnosis.DuplicateTraces, func(i, j int) bool {
+		return isBefore(diagnosis.DuplicateTraces[i].Resources[0], diagnosis.DuplicateTraces[j].Resources[0])
+	})
+	if fix {
+		r.repairDuplicateTraces(diagnosis, parsedFiles)
+	}
+	return diagnosis, nil
+}
+
+func isBefore(resource TracedResource, other TracedResource) bool {
+	if resource.File != other.File {
+		return resource.File < other.File
+	}
+	return resource.Line < other.Line
+}
+
+// repairDuplicateTraces gives new traces to the resources which duplicate the trace of another resource, and writes
+// the files of the repaired resources
+func (r *Runner) repairDuplicateTraces(diagnosis *Diagnosis, parsedFiles []parsedFile) {
+	repairedResources := map[structure.IBlock]*TracedResource{}
+	for i := range diagnosis.DuplicateTraces {
+		resources := diagnosis.DuplicateTraces[i].Resources
+		for j := 1; j < len(resources); j++ {
+			traceTag := &code2cloud.YorTraceTag{}
+			traceTag.Init()
+			r.keyNames.Rename(traceTag)
+			newTag, err := traceTag.CalculateValue(struct{}{})
+			if err != nil {
+				logger.Warning(fmt.Sprintf("Failed to create a new trace for %s: %s", resources[j].ResourceID, err))
+				continue
+			}
+			tags.SetSource(newTag, tags.Code2CloudSource)
+			resources[j].block.AddNewTags([]tags.ITag{newTag})
+			resources[j].NewTraceID = newTag.GetValue()
+			repairedResources[resources[j].block] = &resources[j]
+		}
+	}
+	for _, parsed := range parsedFiles {
+		var fileResources []*TracedResource
+		for _, block := range parsed.blocks {
+			if resource, ok := repairedResources[block]; ok {
+				fileResources = append(fileResources, resource)
+			}
+		}
+		if len(fileResources) == 0 {
+			continue
+		}
+		if err := parsed.parser.WriteFile(parsed.file, parsed.blocks, parsed.file); err != nil {
+			logger.Warning(fmt.Sprintf("Failed writing the repaired traces to file %s, because %v", parsed.file, err))
+			// the resources of the file keep their duplicate traces
+			for _, resource := range fileResources {
+				resource.NewTraceID = ""
+			}
+			continue
+		}
+		diagnosis.Repaired += len(fileResources)
+	}
+}
+
+// Print writes the summary of the diagnosis and the resources of the duplicate traces as a table
+func (d *Diagnosis) Print(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "%s: %d\n", i18n.T("Traced Resources"), d.TracedResources)
+	_, _ = fmt.Fprintf(w, "%s: %d\n", i18n.T("Duplicate Yor IDs"), len(d.DuplicateTraces))
+	_, _ = fmt.Fprintf(w, "%s: %d\n", i18n.T("Repaired Resources"), d.Repaired)
+	if len(d.DuplicateTraces) == 0 {
+		return
+	}
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(i18n.Headers("Yor ID", "Resource", "File", "Updated Value"))
+	table.SetAutoWrapText(false)
+	for _, duplicate := range d.DuplicateTraces {
+		for _, resource := range duplicate.Resources {
+			table.Append([]string{duplicate.TraceID, resource.ResourceID, fmt.Sprintf("%s:%d", resource.File, resource.Line), resource.NewTraceID})
+		}
+	}
+	table.Render()
+}
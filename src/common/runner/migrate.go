@@ -0,0 +1,213 @@
+package runner
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bridgecrewio/yor/src/common/i18n"
+	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/bridgecrewio/yor/src/common/structure"
+	"github.com/bridgecrewio/yor/src/common/utils"
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	keyNotWrittenReason = "the key isn't written in the tags of the resource, e.g. it is set by a variable"
+	keyExistsReason     = "the resource already has a tag with the new key"
+)
+
+// Migration holds the renames of the tag keys of the resources of the directory. Renames which couldn't be made are
+// reported with their reason.
+type Migration struct {
+	Directory string      `json:"directory"`
+	DryRun    bool        `json:"dryRun"`
+	Renames   []TagRename `json:"renames"`
+}
+
+type TagRename struct {
+	File       string `json:"file"`
+	Line       int    `json:"line"`
+	ResourceID string `json:"resourceId"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	// Reason is why the key wasn't renamed, if it wasn't
+	Reason string `json:"reason,omitempty"`
+}
+
+// LoadTagKeyMapping reads the renames of the tag keys from a YAML or JSON file of old_key: new_key pairs
+func LoadTagKeyMapping(mappingPath string) (map[string]string, error) {
+	// #nosec G304 - file is from user
+	content, err := os.ReadFile(mappingPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the tag key mapping %s: %s", mappingPath, err)
+	}
+	mapping := map[string]string{}
+	if err = yaml.Unmarshal(content, &mapping); err != nil {
+		return nil, fmt.Errorf("failed to parse the tag key mapping %s, expected old_key: new_key pairs: %s", mappingPath, err)
+	}
+	return mapping, nil
+}
+
+// MigrateTags renames the keys of the tags of the resources of the directory by the mapping, in a single pass over the
+// files. The keys are renamed in the text of the tags of each resource, so the files are otherwise left untouched, and
+// keys which aren't written in the tags, e.g. which are set by variables, are reported and left for manual migration.
+func (r *Runner) MigrateTags(mapping map[string]string, dryRun bool) (*Migration, error) {
+	migration := &Migration{Directory: r.dir, DryRun: dryRun, Renames: []TagRename{}}
+	for _, file := range r.listFiles() {
+		if r.ctx.Err() != nil {
+			break
+		}
+		migration.Renames = append(migration.Renames, r.migrateFileTags(file, mapping, dryRun)...)
+	}
+	for _, parser := range r.parsers {
+		parser.Close()
+	}
+	if err := r.ctx.Err(); err != nil {
+		return migration, fmt.Errorf("the migration of %s was interrupted: %w", r.dir, err)
+	}
+	return migration, nil
+}
+
+func (r *Runner) migrateFileTags(file string, mapping map[string]string, dryRun bool) []TagRename {
+	var blocks []structure.IBlock
+	for _, parser := range r.parsers {
+		if r.isFileSkipped(parser, file) {
+			continue
+		}
+		parsedBlocks, err := parser.ParseFile(file)
+		if err != nil {
+			logger.Info(fmt.Sprintf("Failed to parse file %v with parser %v", file, parser.Name()))
+			continue
+		}
+		// several parsers may support the file, e.g. yaml files, so the blocks of the first which finds any are renamed
+		if len(parsedBlocks) > 0 {
+			blocks = parsedBlocks
+			break
+		}
+	}
+	var renames []TagRename
+	var edits []utils.TextEdit
+	var lines []string
+	var lineOffsets []int
+	for _, block := range blocks {
+		if !block.IsBlockTaggable() || r.isBlockSkipped(block) {
+			continue
+		}
+		existingKeys := map[string]bool{}
+		for _, tag := range block.GetExistingTags() {
+			existingKeys[tag.GetKey()] = true
+		}
+		for _, tag := range block.GetExistingTags() {
+			to, ok := mapping[tag.GetKey()]
+			if !ok || to == tag.GetKey() {
+				continue
+			}
+			rename := TagRename{File: file, Line: block.GetLines().Start, ResourceID: block.GetResourceID(), From: tag.GetKey(), To: to}
+			if existingKeys[to] {
+				rename.Reason = keyExistsReason
+				renames = append(renames, rename)
+				continue
+			}
+			if lines == nil {
+				content, err := utils.ReadFile(file)
+				if err != nil {
+					logger.Warning(fmt.Sprintf("Failed to read %s: %s", file, err))
+					return nil
+				}
+				lines, lineOffsets = splitLines(string(content))
+			}
+			edit, line, found := findTagKey(lines, lineOffsets, block.GetTagsLines(), tag.GetKey(), to)
+			if !found {
+				rename.Reason = keyNotWrittenReason
+			} else {
+				rename.Line = line
+				edits = append(edits, edit)
+			}
+			renames = append(renames, rename)
+		}
+	}
+	if len(edits) == 0 || dryRun {
+		return renames
+	}
+	if err := utils.WriteFile(file, []byte(utils.ApplyTextEdits(strings.Join(lines, ""), edits))); err != nil {
+		logger.Warning(fmt.Sprintf("Failed writing the renamed tags to file %s, because %v", file, err))
+		for i := range renames {
+			if renames[i].Reason == "" {
+				renames[i].Reason = fmt.Sprintf("failed to write the file: %s", err)
+			}
+		}
+	}
+	return renames
+}
+
+// splitLines splits the content into its lines, which keep their line endings, and returns the offset of each line
+func splitLines(content string) ([]string, []int) {
+	lines := strings.SplitAfter(content, "\n")
+	offsets := make([]int, len(lines))
+	offset := 0
+	for i, line := range lines {
+		offsets[i] = offset
+		offset += len(line)
+	}
+	return lines, offsets
+}
+
+// findTagKey finds the first occurrence of the key in the tags lines of a block, either as a key of a map (key = value,
+// "key": value, key: value or - key=value) or as the value of a Key of a list of tags (Key: key), and returns the
+// edit which renames it and its line
+func findTagKey(lines []string, lineOffsets []int, tagsLines structure.Lines, from string, to string) (utils.TextEdit, int, bool) {
+	if tagsLines.Start < 1 || tagsLines.End < tagsLines.Start {
+		return utils.TextEdit{}, 0, false
+	}
+	quotedKey := regexp.QuoteMeta(from)
+	keyPatterns := []*regexp.Regexp{
+		regexp.MustCompile(`(?:^\s*|[{,(]\s*)(?:-\s*)?["']?(` + quotedKey + `)["']?\s*[:=]`),
+		regexp.MustCompile(`\bKey["']?\s*:\s*["']?(` + quotedKey + `)["']?\s*(?:,|}|$)`),
+	}
+	for lineNum := tagsLines.Start; lineNum <= tagsLines.End && lineNum <= len(lines); lineNum++ {
+		line := strings.TrimRight(lines[lineNum-1], "\r\n")
+		for _, pattern := range keyPatterns {
+			if match := pattern.FindStringSubmatchIndex(line); match != nil {
+				start := lineOffsets[lineNum-1] + match[2]
+				return utils.TextEdit{Start: start, End: start + len(from), Text: to}, lineNum, true
+			}
+		}
+	}
+	return utils.TextEdit{}, 0, false
+}
+
+// Print writes the renames of the migration as a table
+func (m *Migration) Print(w io.Writer) {
+	renamed := 0
+	for _, rename := range m.Renames {
+		if rename.Reason == "" {
+			renamed++
+		}
+	}
+	title := i18n.T("Renamed Tags")
+	if m.DryRun {
+		title += " (" + i18n.T("dry run") + ")"
+	}
+	_, _ = fmt.Fprintf(w, "%s: %d\n", title, renamed)
+	_, _ = fmt.Fprintf(w, "%s: %d\n", i18n.T("Tags Not Renamed"), len(m.Renames)-renamed)
+	if len(m.Renames) == 0 {
+		return
+	}
+	renames := make([]TagRename, len(m.Renames))
+	copy(renames, m.Renames)
+	sort.SliceStable(renames, func(i, j int) bool {
+		return renames[i].File < renames[j].File
+	})
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(i18n.Headers("Resource", "File", "Old Key", "New Key", "Reason"))
+	table.SetAutoWrapText(false)
+	for _, rename := range renames {
+		table.Append([]string{rename.ResourceID, fmt.Sprintf("%s:%d", rename.File, rename.Line), rename.From, rename.To, rename.Reason})
+	}
+	table.Render()
+}
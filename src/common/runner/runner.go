@@ -1,7 +1,11 @@
 package runner
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"plugin"
@@ -9,18 +13,30 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
+	armStructure "github.com/bridgecrewio/yor/src/arm/structure"
 	cfnStructure "github.com/bridgecrewio/yor/src/cloudformation/structure"
 	"github.com/bridgecrewio/yor/src/common"
+	"github.com/bridgecrewio/yor/src/common/checkpoint"
 	"github.com/bridgecrewio/yor/src/common/clioptions"
+	"github.com/bridgecrewio/yor/src/common/gitservice"
+	"github.com/bridgecrewio/yor/src/common/hooks"
 	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/bridgecrewio/yor/src/common/openapi"
+	"github.com/bridgecrewio/yor/src/common/progress"
 	"github.com/bridgecrewio/yor/src/common/reports"
+	"github.com/bridgecrewio/yor/src/common/structure"
 	"github.com/bridgecrewio/yor/src/common/tagging"
 	"github.com/bridgecrewio/yor/src/common/tagging/external"
+	"github.com/bridgecrewio/yor/src/common/tagging/gittag"
 	"github.com/bridgecrewio/yor/src/common/tagging/simple"
 	"github.com/bridgecrewio/yor/src/common/tagging/tags"
 	taggingUtils "github.com/bridgecrewio/yor/src/common/tagging/utils"
+	"github.com/bridgecrewio/yor/src/common/tagpolicy"
 	"github.com/bridgecrewio/yor/src/common/utils"
+	composeStructure "github.com/bridgecrewio/yor/src/compose/structure"
+	packerStructure "github.com/bridgecrewio/yor/src/packer/structure"
 	slsStructure "github.com/bridgecrewio/yor/src/serverless/structure"
 	tfStructure "github.com/bridgecrewio/yor/src/terraform/structure"
 )
@@ -38,47 +54,163 @@ type Runner struct {
 	skippedResources     []string
 	workersNum           int
 	dryRun               bool
+	backup               bool
 	localModuleTag       bool
+	ctx                  context.Context
+	progress             *progress.Tracker
+	followSymlinks       bool
+	skipSubmodules       bool
+	maxFileSize          int64
+	maxResourcesPerFile  int
+	identityAnonymizer   *gittag.IdentityAnonymizer
+	hooks                *hooks.Hooks
+	ignoredValueChanges  []string
+	checkpoint           *checkpoint.Checkpoint
+	checkpointPath       string
+	checkpointLock       sync.Mutex
+	checkpointSavedAt    time.Time
+	remainingFiles       map[string]int
+	benchmark            *benchmarkRecorder
+	apiDefinitionTraces  map[string]string
+	apiDefinitionsLock   sync.Mutex
+	skipAPIDefinitions   bool
+	// keyNames rename the built-in tags of the run
+	keyNames tags.KeyNames
+	// workerPanic is the first panic of the workers, which TagDirectory raises again in its own goroutine
+	workerPanic     interface{}
+	workerPanicLock sync.Mutex
+}
+
+// skippedFileError is returned for files which are skipped because they exceed the configured limits
+type skippedFileError struct {
+	reason string
+}
+
+func (e *skippedFileError) Error() string {
+	return e.reason
 }
 
 const WorkersNumEnvKey = "YOR_WORKER_NUM"
 
+// CheckpointInterval is the minimal interval between the saves of the checkpoint of a run, which serializes the results
+// of all the completed directories, so large directories aren't slowed down by saving it after every directory
+const CheckpointInterval = 10 * time.Second
+
+// AnonymizationSaltEnvKey sets the salt of the hashed git identities, so it doesn't show in the logged command line
+const AnonymizationSaltEnvKey = "YOR_ANONYMIZATION_SALT"
+
 func (r *Runner) Init(commands *clioptions.TagOptions) error {
+	return r.InitWithContext(context.Background(), commands)
+}
+
+// InitWithContext initializes the runner with a context, which stops TagDirectory from tagging further files once it is
+// done. The files which were already tagged are still written and reported.
+func (r *Runner) InitWithContext(ctx context.Context, commands *clioptions.TagOptions) error {
+	r.ctx = ctx
 	dir := commands.Directory
 	extraTags, extraTagGroups, err := loadExternalResources(commands.CustomTagging)
 	if err != nil {
 		logger.Warning(fmt.Sprintf("failed to load extenal tags from plugins due to error: %s", err))
 	}
+	// the built-in tags take their renamed keys when their tag groups are initialized
+	r.keyNames, err = tags.ParseKeyNames(commands.TagKeyNames)
+	if err != nil {
+		return err
+	}
+	anonymizationSalt := commands.AnonymizationSalt
+	if anonymizationSalt == "" {
+		anonymizationSalt = os.Getenv(AnonymizationSaltEnvKey)
+	}
+	if strings.ToLower(commands.AnonymizeGitIdentities) == gittag.HashAnonymization && anonymizationSalt == "" {
+		logger.Warning(fmt.Sprintf("Hashing git identities without a salt, set %v so they can't be recovered by hashing known emails", AnonymizationSaltEnvKey))
+	}
+	r.identityAnonymizer = gittag.NewIdentityAnonymizer(strings.ToLower(commands.AnonymizeGitIdentities), anonymizationSalt, r.keyNames)
 	for _, group := range commands.TagGroups {
+		if len(commands.OnlyGroups) > 0 && !utils.InSlice(commands.OnlyGroups, group) {
+			continue
+		}
 		tagGroup := taggingUtils.TagGroupsByName(taggingUtils.TagGroupName(group))
 		r.TagGroups = append(r.TagGroups, tagGroup)
 	}
-	r.TagGroups = append(r.TagGroups, extraTagGroups...)
+	// the tag groups of plugins have no name to select them by
+	if len(commands.OnlyGroups) == 0 {
+		r.TagGroups = append(r.TagGroups, extraTagGroups...)
+	} else {
+		for _, tagGroup := range extraTagGroups {
+			logger.Warning(fmt.Sprintf("Skipping the tag group %s of --custom-tagging, which can't be selected by --only-groups", getCustomSource(tagGroup)))
+		}
+	}
 	if commands.ConfigFile == "" {
 		logger.Info("Did not get an external config file")
 	}
 	for _, tagGroup := range r.TagGroups {
-		tagGroup.InitTagGroup(dir, commands.SkipTags, commands.Tag, tagging.WithTagPrefix(commands.TagPrefix))
+		skipTags := commands.SkipTags
+		if _, ok := tagGroup.(*gittag.TagGroup); ok && utils.InSlice(commands.TagGroups, string(taggingUtils.GitRemote)) {
+			// the organization and the repository of the remote rules replace the ones of the git tags
+			skipTags = append([]string{r.keyNames.Get(tags.GitOrgTagKey), r.keyNames.Get(tags.GitRepoTagKey)}, skipTags...)
+		}
+		tagGroup.InitTagGroup(dir, skipTags, commands.Tag, tagging.WithTagPrefix(commands.TagPrefix), tagging.WithContext(ctx), tagging.WithPathStyle(commands.PathStyle), tagging.WithKeyNames(r.keyNames))
 		if simpleTagGroup, ok := tagGroup.(*simple.TagGroup); ok {
 			simpleTagGroup.SetTags(extraTags)
 		} else if externalTagGroup, ok := tagGroup.(*external.TagGroup); ok && commands.ConfigFile != "" {
 			externalTagGroup.InitExternalTagGroups(commands.ConfigFile)
+		} else if provenanceTagGroup, ok := tagGroup.(*gittag.ProvenanceTagGroup); ok {
+			provenanceTagGroup.SetFormat(strings.ToLower(commands.ProvenanceFormat), commands.ProvenanceDelimiter)
+			if commands.Ref != "" && commands.Remote == "" {
+				if err = provenanceTagGroup.SetRef(commands.Ref); err != nil {
+					return err
+				}
+			}
+		} else if remoteTagGroup, ok := tagGroup.(*gittag.RemoteTagGroup); ok {
+			var rules []*gitservice.RemoteRule
+			if rules, err = gitservice.ParseRemoteRules(commands.GitRemoteRules); err != nil {
+				return err
+			}
+			remoteTagGroup.SetRules(rules)
+		} else if gitTagGroup, ok := tagGroup.(*gittag.TagGroup); ok {
+			gitTagGroup.SetIdentityAnonymizer(r.identityAnonymizer)
+			gitTagGroup.SetFreeze(commands.FreezeGitTags)
+			// the clone of a remote is checked out at its ref
+			if commands.Ref != "" && commands.Remote == "" {
+				if err = gitTagGroup.SetRef(commands.Ref); err != nil {
+					return err
+				}
+			}
 		}
 	}
+	if len(commands.OnlyKeys) > 0 {
+		r.TagGroups = filterTagGroups(r.TagGroups, commands.OnlyKeys)
+		if len(r.TagGroups) == 0 {
+			logger.Warning(fmt.Sprintf("None of the tag groups creates the tags of --only-keys %s - expect an empty result", strings.Join(commands.OnlyKeys, ",")))
+		}
+	}
+	// the traces of the OpenAPI definitions are left untouched by the runs which don't refresh yor_trace
+	if len(commands.OnlyKeys) > 0 || len(commands.OnlyGroups) > 0 {
+		r.skipAPIDefinitions = !hasTagKey(r.TagGroups, r.keyNames.Get(tags.YorTraceTagKey))
+	}
 	processedParsers := map[string]struct{}{}
+	var unsupportedParsers []string
 	for _, p := range commands.Parsers {
+		p = strings.ToLower(p)
 		if _, exists := processedParsers[p]; exists {
 			continue
 		}
 		switch p {
-		case "Terraform":
+		case "terraform":
 			r.parsers = append(r.parsers, &tfStructure.TerraformParser{})
-		case "CloudFormation":
+		case "cloudformation":
 			r.parsers = append(r.parsers, &cfnStructure.CloudformationParser{})
-		case "Serverless":
+		case "serverless":
 			r.parsers = append(r.parsers, &slsStructure.ServerlessParser{})
+		case "arm":
+			r.parsers = append(r.parsers, &armStructure.ArmParser{})
+		case "dockercompose":
+			r.parsers = append(r.parsers, &composeStructure.ComposeParser{})
+		case "packer":
+			r.parsers = append(r.parsers, &packerStructure.PackerParser{})
 		default:
-			logger.Warning(fmt.Sprintf("ignoring unknown parser %#v", err))
+			logger.Warning(fmt.Sprintf("ignoring unknown parser %#v", p))
+			unsupportedParsers = append(unsupportedParsers, p)
 		}
 		processedParsers[p] = struct{}{}
 	}
@@ -86,20 +218,75 @@ func (r *Runner) Init(commands *clioptions.TagOptions) error {
 		"tag-local-modules": strconv.FormatBool(commands.TagLocalModules)}
 	for _, parser := range r.parsers {
 		parser.Init(dir, options)
+		if contextParser, ok := parser.(common.IContextParser); ok {
+			contextParser.SetContext(ctx)
+		}
 	}
 
-	r.ChangeAccumulator = reports.TagChangeAccumulatorInstance
-	r.reportingService = reports.ReportServiceInst
+	r.ChangeAccumulator = reports.NewTagChangeAccumulator()
+	r.reportingService = reports.NewReportService(r.ChangeAccumulator)
+	for _, p := range unsupportedParsers {
+		r.ChangeAccumulator.AccumulateError(common.UnsupportedFramework, "", fmt.Sprintf("unknown parser %s", p))
+	}
+	r.reportingService.SetPathStyle(commands.PathStyle)
+	r.reportingService.SetKeyNames(r.keyNames)
+	pathPrefixes, err := reports.ParsePathPrefixes(commands.PathPrefixStrip, commands.PathPrefixMap)
+	if err != nil {
+		return err
+	}
+	r.reportingService.SetPathPrefixes(pathPrefixes)
+	r.reportingService.SetReportSchema(commands.ReportSchema)
+	tagGroupVersions := map[string]string{}
+	for _, group := range commands.TagGroups {
+		if len(commands.OnlyGroups) > 0 && !utils.InSlice(commands.OnlyGroups, group) {
+			continue
+		}
+		if version := taggingUtils.GetTagGroupVersion(taggingUtils.TagGroupName(group)); version != "" {
+			tagGroupVersions[group] = version
+		}
+	}
+	r.reportingService.SetTagGroupVersions(tagGroupVersions)
+	r.reportingService.SetDirectory(commands.Directory)
+	if commands.RequiredTagsFile != "" {
+		requiredTags, err := tagpolicy.LoadRequiredTags(commands.RequiredTagsFile)
+		if err != nil {
+			return err
+		}
+		r.reportingService.SetRequiredTags(requiredTags)
+	}
+	if commands.BaselineFile != "" {
+		baseline, err := reports.LoadBaseline(commands.BaselineFile)
+		if err != nil {
+			return err
+		}
+		r.reportingService.SetBaseline(baseline)
+	}
+	if commands.Checkpoint != "" {
+		r.checkpoint, err = checkpoint.Load(commands.Checkpoint, commands.Directory, commands.DryRun)
+		if err != nil {
+			return err
+		}
+		r.checkpoint.Restore(r.ChangeAccumulator, r.keyNames.Get(tags.YorTraceTagKey))
+		r.checkpointPath = commands.Checkpoint
+	}
 	r.dir = commands.Directory
 	r.skippedTags = commands.SkipTags
 	r.skipDirs = append(commands.SkipDirs, ".git")
 	r.configFilePath = commands.ConfigFile
 	r.dryRun = commands.DryRun
+	r.backup = commands.Backup
+	r.followSymlinks = commands.FollowSymlinks
+	r.skipSubmodules = commands.SkipSubmodules
+	r.maxFileSize = int64(commands.MaxFileSizeMB) * 1024 * 1024
+	r.maxResourcesPerFile = commands.MaxResourcesPerFile
+	r.progress = progress.NewTracker(strings.ToLower(commands.Progress), os.Stderr)
+	r.hooks = hooks.New(commands.PreScanHook, commands.PostFileWriteHook, commands.PostRunHook)
 	if utils.InSlice(r.skipDirs, r.dir) {
 		logger.Warning(fmt.Sprintf("Selected dir, %s, is skipped - expect an empty result", r.dir))
 	}
 	r.skippedResourceTypes = commands.SkipResourceTypes
 	r.skippedResources = commands.SkipResources
+	r.ignoredValueChanges = commands.IgnoreValueChanges
 	var convErr error
 	r.workersNum, convErr = strconv.Atoi(utils.GetEnv(WorkersNumEnvKey, "10"))
 	if convErr != nil {
@@ -110,47 +297,228 @@ func (r *Runner) Init(commands *clioptions.TagOptions) error {
 
 func (r *Runner) worker(fileChan chan string, wg *sync.WaitGroup) {
 	for file := range fileChan {
-		r.TagFile(file)
+		r.tagWorkerFile(file)
 		wg.Done()
 	}
 }
 
-func (r *Runner) TagDirectory() (*reports.ReportService, error) {
-	var files []string
-	err := filepath.Walk(r.dir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			logger.Error("Failed to scan dir", path)
-		}
-		if !info.IsDir() {
-			files = append(files, path)
+// tagWorkerFile tags a file of the worker. A panic, such as an error ending yor where logger.SetExitFunc panics, is
+// kept for TagDirectory to raise again, so callers which recover from it (i.e. the gRPC server) aren't ended by a panic
+// in a worker. The files after it are skipped.
+func (r *Runner) tagWorkerFile(file string) {
+	defer func() {
+		if p := recover(); p != nil {
+			r.workerPanicLock.Lock()
+			defer r.workerPanicLock.Unlock()
+			if r.workerPanic == nil {
+				r.workerPanic = p
+			}
 		}
-		return nil
-	})
+	}()
+	if r.getWorkerPanic() != nil {
+		return
+	}
+	r.TagFile(file)
+	r.progress.FileDone(file)
+	r.completeFile(file)
+}
+
+func (r *Runner) getWorkerPanic() interface{} {
+	r.workerPanicLock.Lock()
+	defer r.workerPanicLock.Unlock()
+	return r.workerPanic
+}
+
+func (r *Runner) TagDirectory() (*reports.ReportService, error) {
+	files := r.skipCompletedDirs(r.listFiles())
+	err := r.hooks.Run(r.ctx, hooks.Context{Event: hooks.PreScan, Directory: r.dir, DryRun: r.dryRun, Files: files})
 	if err != nil {
-		logger.Error("Failed to run Walk() on root dir", r.dir)
+		return nil, err
 	}
 
+	r.progress.Start(len(files))
 	var wg sync.WaitGroup
-	wg.Add(len(files))
 	fileChan := make(chan string)
 
 	for i := 0; i < r.workersNum; i++ {
 		go r.worker(fileChan, &wg)
 	}
 
+	sentFiles := 0
+sendFiles:
 	for _, file := range files {
-		fileChan <- file
+		if r.ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		select {
+		case fileChan <- file:
+			sentFiles++
+		case <-r.ctx.Done():
+			wg.Done()
+			break sendFiles
+		}
 	}
 	close(fileChan)
 	wg.Wait()
+	r.progress.Finish()
 
 	for _, parser := range r.parsers {
 		parser.Close()
 	}
+	if workerPanic := r.getWorkerPanic(); workerPanic != nil {
+		panic(workerPanic)
+	}
 
+	if err := r.ctx.Err(); err != nil {
+		r.reportingService.SetInterrupted(true)
+		r.saveCheckpoint()
+		return r.reportingService, fmt.Errorf("tagging of %s was interrupted after %d out of %d files: %w", r.dir, sentFiles, len(files), err)
+	}
+	if r.checkpoint != nil {
+		if err := checkpoint.Remove(r.checkpointPath); err != nil {
+			logger.Warning(err.Error())
+		}
+	}
 	return r.reportingService, nil
 }
 
+// skipCompletedDirs returns the files which aren't in the directories completed by the resumed run, nor the checkpoint
+// itself, and counts the files of each directory, so the directory is completed once they are tagged
+func (r *Runner) skipCompletedDirs(files []string) []string {
+	if r.checkpoint == nil {
+		return files
+	}
+	checkpointPath, _ := filepath.Abs(r.checkpointPath)
+	r.remainingFiles = map[string]int{}
+	var remaining []string
+	skipped := 0
+	for _, file := range files {
+		if absPath, err := filepath.Abs(file); err == nil && absPath == checkpointPath {
+			continue
+		}
+		dir := filepath.Dir(file)
+		if r.checkpoint.IsCompleted(dir) {
+			skipped++
+			continue
+		}
+		r.remainingFiles[dir]++
+		remaining = append(remaining, file)
+	}
+	if skipped > 0 {
+		logger.Info(fmt.Sprintf("Skipping %d files of the directories completed before the checkpoint", skipped))
+	}
+	return remaining
+}
+
+// completeFile completes the directory of the file in the checkpoint once all of its files are tagged, and saves the
+// checkpoint if it wasn't saved for CheckpointInterval. The files of an interrupted run may be partially tagged, so they
+// don't complete their directories.
+func (r *Runner) completeFile(file string) {
+	if r.checkpoint == nil || r.ctx.Err() != nil {
+		return
+	}
+	r.checkpointLock.Lock()
+	defer r.checkpointLock.Unlock()
+	dir := filepath.Dir(file)
+	r.remainingFiles[dir]--
+	if r.remainingFiles[dir] > 0 {
+		return
+	}
+	r.checkpoint.Complete(dir)
+	if time.Since(r.checkpointSavedAt) >= CheckpointInterval {
+		r.saveCheckpointLocked()
+	}
+}
+
+func (r *Runner) saveCheckpoint() {
+	if r.checkpoint == nil {
+		return
+	}
+	r.checkpointLock.Lock()
+	defer r.checkpointLock.Unlock()
+	r.saveCheckpointLocked()
+}
+
+func (r *Runner) saveCheckpointLocked() {
+	if err := r.checkpoint.Save(r.checkpointPath, r.ChangeAccumulator); err != nil {
+		// the run goes on, it just can't be resumed from the directories completed since the last save
+		logger.Warning(err.Error())
+	}
+	r.checkpointSavedAt = time.Now()
+}
+
+// listFiles returns the files in the runner's directory. Symlinks to files are listed, while symlinks to directories
+// are walked only if followSymlinks is set. Files are listed by their real path, so a file which is linked from several
+// places is tagged once, and is blamed by its own path rather than the link's. Git submodules are skipped if
+// skipSubmodules is set.
+func (r *Runner) listFiles() []string {
+	var files []string
+	listedFiles := map[string]bool{}
+	visitedDirs := map[string]bool{}
+	realDir := utils.RealPath(r.dir)
+	visitedDirs[realDir] = true
+	addFile := func(path string) {
+		filePath := utils.RealPath(path)
+		if filePath == "" {
+			logger.Warning(fmt.Sprintf("Failed to resolve the path of %s", path))
+			return
+		}
+		// files under the directory keep the directory as given, i.e. relative, in their path
+		if relPath, err := filepath.Rel(realDir, filePath); err == nil && relPath != ".." && !strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+			filePath = filepath.Join(r.dir, relPath)
+		}
+		if !listedFiles[filePath] {
+			listedFiles[filePath] = true
+			files = append(files, filePath)
+		}
+	}
+	var walk func(root string)
+	walk = func(root string) {
+		err := utils.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				logger.Error("Failed to scan dir", path)
+			}
+			if entry.IsDir() {
+				if r.skipSubmodules && filepath.Clean(path) != filepath.Clean(r.dir) && gitservice.IsRepositoryRoot(path) {
+					logger.Info(fmt.Sprintf("Skipping git submodule %s", path))
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if entry.Type()&fs.ModeSymlink == 0 {
+				addFile(path)
+				return nil
+			}
+			targetInfo, err := utils.Stat(path)
+			if err != nil {
+				logger.Warning(fmt.Sprintf("Failed to resolve symlink %s: %s", path, err))
+				return nil
+			}
+			if !targetInfo.IsDir() {
+				addFile(path)
+				return nil
+			}
+			if !r.followSymlinks {
+				logger.Debug(fmt.Sprintf("Skipping symlink %s", path))
+				return nil
+			}
+			if target := utils.RealPath(path); !visitedDirs[target] {
+				// links to directories may form cycles, so every directory is walked once
+				visitedDirs[target] = true
+				// the trailing separator makes WalkDir resolve the link instead of reporting it as a file
+				walk(path + string(filepath.Separator))
+			}
+			return nil
+		})
+		if err != nil {
+			logger.Error("Failed to run Walk() on root dir", root)
+		}
+	}
+	walk(r.dir)
+	return files
+}
+
 func (r *Runner) isSkippedResourceType(resourceType string) bool {
 	for _, skippedResourceType := range r.skippedResourceTypes {
 		if resourceType == skippedResourceType {
@@ -169,50 +537,305 @@ func (r *Runner) isSkippedResource(resource string) bool {
 	return false
 }
 
+func (r *Runner) isBlockSkipped(block structure.IBlock) bool {
+	return r.isSkippedResourceType(block.GetResourceType()) || r.isSkippedResource(block.GetResourceID())
+}
+
 func (r *Runner) TagFile(file string) {
+	if reason := r.getFileLimitViolation(file); reason != "" {
+		r.skipFile(file, reason)
+		return
+	}
+	backedUp := false
 	for _, parser := range r.parsers {
-		if r.isFileSkipped(parser, file) {
-			logger.Debug(fmt.Sprintf("%v parser Skipping %v", parser.Name(), file))
+		blocks, isFileTaggable, err := r.tagFileWithParser(parser, file)
+		if err != nil {
+			var skippedErr *skippedFileError
+			if errors.As(err, &skippedErr) {
+				r.skipFile(file, skippedErr.reason)
+			} else if r.ctx.Err() == nil {
+				r.ChangeAccumulator.AccumulateError(common.ParseFailure, file, err.Error())
+			}
 			continue
 		}
-		logger.Info(fmt.Sprintf("Tagging %v\n", file))
-		blocks, err := parser.ParseFile(file)
+		if isFileTaggable && r.ctx.Err() != nil {
+			// the git tags of the blocks are missing once blame is cancelled, so the file is left untouched
+			r.skipFile(file, "tagging was interrupted")
+			return
+		}
+		for _, block := range blocks {
+			if r.isBlockSkipped(block) {
+				continue
+			}
+			r.ChangeAccumulator.AccumulateChanges(block)
+		}
+		if isFileTaggable && !r.dryRun && !r.skipAPIDefinitions {
+			r.tagAPIDefinitions(blocks)
+		}
+		// files whose tags didn't change aren't rewritten, so their formatting is left untouched
+		if isFileTaggable && !r.dryRun && r.hasTagChanges(blocks) {
+			if r.backup && !backedUp {
+				// the file isn't written without its backup
+				if err = utils.BackupFile(file); err != nil {
+					logger.Warning(fmt.Sprintf("Failed writing tags to file %s, because %v", file, err))
+					r.ChangeAccumulator.AccumulateError(common.WriteFailure, file, err.Error())
+					continue
+				}
+				backedUp = true
+			}
+			err = parser.WriteFile(file, blocks, file)
+			if err != nil {
+				logger.Warning(fmt.Sprintf("Failed writing tags to file %s, because %v", file, err))
+				r.ChangeAccumulator.AccumulateError(common.WriteFailure, file, err.Error())
+				continue
+			}
+			r.runPostFileWriteHook(file, blocks)
+		}
+	}
+}
+
+// tagAPIDefinitions writes the trace tags of the APIs of the blocks to their local OpenAPI definitions. A definition
+// shared by APIs of different traces keeps the trace of the first one, since an API imported from it gets its tags.
+func (r *Runner) tagAPIDefinitions(blocks []structure.IBlock) {
+	for _, block := range blocks {
+		apiBlock, ok := block.(structure.IAPIDefinitionBlock)
+		if !ok || !block.IsBlockTaggable() || r.isBlockSkipped(block) {
+			continue
+		}
+		definitionFile, traceID := apiBlock.GetAPIDefinitionFile(), block.GetTraceID()
+		if definitionFile == "" || traceID == "" {
+			continue
+		}
+		r.tagAPIDefinition(block, definitionFile, traceID)
+	}
+}
+
+func (r *Runner) tagAPIDefinition(block structure.IBlock, definitionFile string, traceID string) {
+	r.apiDefinitionsLock.Lock()
+	defer r.apiDefinitionsLock.Unlock()
+	if r.apiDefinitionTraces == nil {
+		r.apiDefinitionTraces = make(map[string]string)
+	}
+	if taggedTraceID, tagged := r.apiDefinitionTraces[definitionFile]; tagged {
+		if taggedTraceID != traceID {
+			logger.Warning(fmt.Sprintf("Skipping the OpenAPI definition %s of %s, it was tagged with the trace of another API", definitionFile, block.GetResourceID()))
+		}
+		return
+	}
+	r.apiDefinitionTraces[definitionFile] = traceID
+	src, err := utils.ReadFile(definitionFile)
+	if err == nil {
+		var tagged []byte
+		tagged, err = openapi.SetTag(definitionFile, src, &tags.Tag{Key: r.keyNames.Get(tags.YorTraceTagKey), Value: traceID})
+		if err == nil && !bytes.Equal(src, tagged) {
+			if r.backup {
+				err = utils.BackupFile(definitionFile)
+			}
+			if err == nil {
+				err = utils.WriteFile(definitionFile, tagged)
+			}
+		}
+	}
+	if err != nil {
+		logger.Warning(fmt.Sprintf("Failed writing tags to file %s, because %v", definitionFile, err))
+		r.ChangeAccumulator.AccumulateError(common.WriteFailure, definitionFile, err.Error())
+	}
+}
+
+func (r *Runner) runPostFileWriteHook(file string, blocks []structure.IBlock) {
+	var resources []string
+	for _, block := range blocks {
+		if block.IsBlockTaggable() && !r.isBlockSkipped(block) {
+			resources = append(resources, block.GetResourceID())
+		}
+	}
+	hookContext := hooks.Context{Event: hooks.PostFileWrite, Directory: r.dir, File: file, Resources: resources}
+	if err := r.hooks.Run(r.ctx, hookContext); err != nil {
+		logger.Warning(err.Error())
+	}
+}
+
+// RunPostRunHook runs the post-run hook with the report of the run. A failing hook is only warned about, since the
+// files were already tagged.
+func (r *Runner) RunPostRunHook(report *reports.Report) {
+	hookContext := hooks.Context{Event: hooks.PostRun, Directory: r.dir, DryRun: r.dryRun, Report: report}
+	if err := r.hooks.Run(r.ctx, hookContext); err != nil {
+		logger.Warning(err.Error())
+	}
+}
+
+// WriteIdentitiesMapping writes the git identities replaced by each anonymized value in the tags of the run
+func (r *Runner) WriteIdentitiesMapping(mappingPath string) error {
+	return r.identityAnonymizer.WriteMapping(mappingPath)
+}
+
+// ComputeTagsForFile calculates the tags of every block in the given file, using all the parsers which support it.
+// Unlike TagFile, the changes are neither accumulated nor written back to the file.
+func (r *Runner) ComputeTagsForFile(file string) []structure.IBlock {
+	var allBlocks []structure.IBlock
+	for _, parser := range r.parsers {
+		blocks, _, err := r.tagFileWithParser(parser, file)
 		if err != nil {
-			logger.Info(fmt.Sprintf("Failed to parse file %v with parser %v", file, reflect.TypeOf(parser)))
 			continue
 		}
-		isFileTaggable := false
 		for _, block := range blocks {
-			if r.isSkippedResourceType(block.GetResourceType()) {
-				continue
+			if !r.isBlockSkipped(block) {
+				allBlocks = append(allBlocks, block)
 			}
-			if r.isSkippedResource(block.GetResourceID()) {
+		}
+	}
+	return allBlocks
+}
+
+// ComputeTagsForBuffer returns the tags yor would set for the resources of file if its content was src, which may not be
+// saved yet. Git tags are computed by mapping src to the blame of file.
+func (r *Runner) ComputeTagsForBuffer(file string, src []byte) ([]structure.IBlock, error) {
+	for _, tagGroup := range r.TagGroups {
+		if gitTagGroup, ok := tagGroup.(*gittag.TagGroup); ok {
+			gitTagGroup.SetBuffer(file, src)
+			defer gitTagGroup.RemoveBuffer(file)
+		}
+	}
+	// parsers which can only parse files read a temporary copy, which keeps the file's name as some parsers
+	// (i.e. serverless) rely on it
+	tempFile := ""
+	var allBlocks []structure.IBlock
+	for _, parser := range r.parsers {
+		if r.isFileExcluded(parser, file) {
+			continue
+		}
+		var blocks []structure.IBlock
+		var err error
+		if bufferParser, ok := parser.(common.IBufferParser); ok {
+			blocks, err = bufferParser.ParseBuffer(file, src)
+		} else {
+			if tempFile == "" {
+				tempDir, err := os.MkdirTemp("", "yor-buffer-*")
+				if err != nil {
+					return nil, fmt.Errorf("failed to create a temporary directory for %v: %s", file, err)
+				}
+				defer func() {
+					_ = os.RemoveAll(tempDir)
+				}()
+				tempFile = filepath.Join(tempDir, filepath.Base(file))
+				if err = os.WriteFile(tempFile, src, 0600); err != nil {
+					return nil, fmt.Errorf("failed to write buffer of %v: %s", file, err)
+				}
+			}
+			if !parser.ValidFile(tempFile) {
 				continue
 			}
-			if block.IsBlockTaggable() {
-				logger.Debug(fmt.Sprintf("Tagging %v:%v", file, block.GetResourceID()))
-				isFileTaggable = true
-				for _, tagGroup := range r.TagGroups {
-					err := tagGroup.CreateTagsForBlock(block)
-					if err != nil {
-						logger.Warning(fmt.Sprintf("Failed to tag %v in %v due to %v", block.GetResourceID(), block.GetFilePath(), err.Error()))
-						continue
+			blocks, err = parser.ParseFile(tempFile)
+			for _, block := range blocks {
+				block.Init(file, block.GetRawBlock())
+			}
+		}
+		if err != nil {
+			logger.Info(fmt.Sprintf("Failed to parse buffer of %v with parser %v", file, reflect.TypeOf(parser)))
+			continue
+		}
+		r.tagBlocks(parser.Name(), file, blocks)
+		for _, block := range blocks {
+			if !r.isBlockSkipped(block) {
+				allBlocks = append(allBlocks, block)
+			}
+		}
+	}
+	return allBlocks, nil
+}
+
+func (r *Runner) skipFile(file string, reason string) {
+	logger.Warning(fmt.Sprintf("Skipping %s: %s", file, reason))
+	r.ChangeAccumulator.AccumulateSkippedFile(file, reason)
+}
+
+func (r *Runner) tagFileWithParser(parser common.IParser, file string) ([]structure.IBlock, bool, error) {
+	if r.isFileSkipped(parser, file) {
+		logger.Debug(fmt.Sprintf("%v parser Skipping %v", parser.Name(), file))
+		return nil, false, nil
+	}
+	logger.Info(fmt.Sprintf("Tagging %v\n", file))
+	start := time.Now()
+	blocks, err := parser.ParseFile(file)
+	r.benchmark.add(parser.Name(), func(framework *FrameworkBenchmark) {
+		framework.Files++
+		framework.Resources += len(blocks)
+		framework.Parse += time.Since(start)
+	})
+	if err != nil {
+		logger.Info(fmt.Sprintf("Failed to parse file %v with parser %v", file, reflect.TypeOf(parser)))
+		return nil, false, err
+	}
+	if r.maxResourcesPerFile > 0 && len(blocks) > r.maxResourcesPerFile {
+		return nil, false, &skippedFileError{reason: fmt.Sprintf("%d resources exceed the limit of %d resources per file", len(blocks), r.maxResourcesPerFile)}
+	}
+	return blocks, r.tagBlocks(parser.Name(), file, blocks), nil
+}
+
+// tagBlocks creates the tags of the taggable blocks of the file, and returns whether any of them is taggable
+func (r *Runner) tagBlocks(framework string, file string, blocks []structure.IBlock) bool {
+	isFileTaggable := false
+	structure.SetTraceKey(blocks, r.keyNames.Get(tags.YorTraceTagKey))
+	for _, block := range blocks {
+		if r.isBlockSkipped(block) {
+			continue
+		}
+		if block.IsBlockTaggable() {
+			logger.Debug(fmt.Sprintf("Tagging %v:%v", file, block.GetResourceID()))
+			isFileTaggable = true
+			for _, tagGroup := range r.TagGroups {
+				start := time.Now()
+				err := tagGroup.CreateTagsForBlock(block)
+				r.benchmark.addTagGroup(framework, tagGroup, time.Since(start))
+				if err != nil {
+					logger.Warning(fmt.Sprintf("Failed to tag %v in %v due to %v", block.GetResourceID(), block.GetFilePath(), err.Error()))
+					continue
+				}
+				// the tag groups of plugins may not record the sources of their tags
+				for _, tag := range block.GetNewTags() {
+					if tags.GetSource(tag) == "" {
+						tags.SetSource(tag, getCustomSource(tagGroup))
 					}
 				}
-			} else {
-				logger.Debug(fmt.Sprintf("Block %v:%v is not taggable, skipping", file, block.GetResourceID()))
 			}
-			r.ChangeAccumulator.AccumulateChanges(block)
+			r.keepEquivalentValues(block)
+		} else {
+			logger.Debug(fmt.Sprintf("Block %v:%v is not taggable, skipping", file, block.GetResourceID()))
 		}
-		if isFileTaggable && !r.dryRun {
-			err = parser.WriteFile(file, blocks, file)
-			if err != nil {
-				logger.Warning(fmt.Sprintf("Failed writing tags to file %s, because %v", file, err))
+	}
+	return isFileTaggable
+}
+
+// keepEquivalentValues sets the new tags whose values differ from the existing ones only by the ignored kinds of
+// changes back to the existing values, so they aren't written or reported as updated
+func (r *Runner) keepEquivalentValues(block structure.IBlock) {
+	if len(r.ignoredValueChanges) == 0 {
+		return
+	}
+	for _, newTag := range block.GetNewTags() {
+		for _, existingTag := range block.GetExistingTags() {
+			if newTag.GetKey() == existingTag.GetKey() && newTag.GetValue() != existingTag.GetValue() &&
+				tags.IsEquivalentValue(newTag.GetValue(), existingTag.GetValue(), r.ignoredValueChanges) {
+				newTag.SetValue(existingTag.GetValue())
 			}
 		}
 	}
 }
 
+// hasTagChanges returns whether tags are added to, or updated in, any of the tagged blocks
+func (r *Runner) hasTagChanges(blocks []structure.IBlock) bool {
+	for _, block := range blocks {
+		if !block.IsBlockTaggable() || r.isBlockSkipped(block) {
+			continue
+		}
+		diff := block.CalculateTagsDiff()
+		if len(diff.Added) > 0 || len(diff.Updated) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func loadExternalResources(externalPaths []string) ([]tags.ITag, []tagging.ITagGroup, error) {
 	var extraTags []tags.ITag
 	var extraTagGroups []tagging.ITagGroup
@@ -248,6 +871,7 @@ func loadExternalResources(externalPaths []string) ([]tags.ITag, []tagging.ITagG
 				if !ok {
 					return nil, nil, fmt.Errorf("unexpected type from module symbol")
 				}
+				tags.SetSource(tag, getCustomSource(tag))
 				extraTags = append(extraTags, tag)
 			}
 			iPtrs, err = extractExternalResources(plug, "ExtraTagGroups")
@@ -267,6 +891,41 @@ func loadExternalResources(externalPaths []string) ([]tags.ITag, []tagging.ITagG
 	return extraTags, extraTagGroups, nil
 }
 
+// filterTagGroups keeps the tags of the tag groups whose keys match one of the glob patterns of onlyKeys, and returns
+// the tag groups which may still create tags, so the others aren't run at all (e.g. git blame isn't computed for a run
+// which only refreshes yor_trace). The tag groups which can't be filtered can't be restricted to the keys either.
+func filterTagGroups(tagGroups []tagging.ITagGroup, onlyKeys []string) []tagging.ITagGroup {
+	var filteredTagGroups []tagging.ITagGroup
+	for _, tagGroup := range tagGroups {
+		filterableTagGroup, ok := tagGroup.(tagging.IFilterableTagGroup)
+		if !ok {
+			logger.Warning(fmt.Sprintf("Skipping the tag group %s, whose tags can't be filtered by --only-keys", getCustomSource(tagGroup)))
+			continue
+		}
+		if filterableTagGroup.FilterTags(onlyKeys) {
+			filteredTagGroups = append(filteredTagGroups, tagGroup)
+		}
+	}
+	return filteredTagGroups
+}
+
+// hasTagKey returns whether any of the tag groups creates the tag of the key
+func hasTagKey(tagGroups []tagging.ITagGroup, key string) bool {
+	for _, tagGroup := range tagGroups {
+		for _, tag := range tagGroup.GetTags() {
+			if tag.GetKey() == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getCustomSource returns the source of the tags of a plugin's tag or tag group, named after its type
+func getCustomSource(resource interface{}) string {
+	return tags.CustomSourcePrefix + reflect.Indirect(reflect.ValueOf(resource)).Type().Name()
+}
+
 func extractExternalResources(plug *plugin.Plugin, symbol string) ([]interface{}, error) {
 	symExtraTags, err := plug.Lookup(symbol)
 	if err != nil {
@@ -284,9 +943,16 @@ func extractExternalResources(plug *plugin.Plugin, symbol string) ([]interface{}
 }
 
 func (r *Runner) isFileSkipped(p common.IParser, file string) bool {
+	return r.isFileExcluded(p, file) || !p.ValidFile(file)
+}
+
+// isFileExcluded checks if the file is skipped by the parser without reading it
+func (r *Runner) isFileExcluded(p common.IParser, file string) bool {
 	relPath, _ := filepath.Rel(r.dir, file)
+	// skipped dirs may be given with either separator on Windows
+	slashPath := filepath.ToSlash(r.dir + "/" + relPath)
 	for _, sp := range r.skipDirs {
-		if strings.HasPrefix(r.dir+"/"+relPath, sp) {
+		if strings.HasPrefix(slashPath, filepath.ToSlash(sp)) {
 			return true
 		}
 	}
@@ -305,5 +971,31 @@ func (r *Runner) isFileSkipped(p common.IParser, file string) bool {
 			return true
 		}
 	}
-	return !p.ValidFile(file)
+	return false
+}
+
+// getFileLimitViolation returns the reason to skip a file some parser would parse, or an empty string if it is within
+// the configured limits and is not binary
+func (r *Runner) getFileLimitViolation(file string) string {
+	parsed := false
+	for _, parser := range r.parsers {
+		if !r.isFileExcluded(parser, file) {
+			parsed = true
+			break
+		}
+	}
+	if !parsed {
+		return ""
+	}
+	info, err := utils.Stat(file)
+	if err != nil {
+		return ""
+	}
+	if r.maxFileSize > 0 && info.Size() > r.maxFileSize {
+		return fmt.Sprintf("file size %d bytes exceeds the limit of %d bytes", info.Size(), r.maxFileSize)
+	}
+	if utils.IsBinaryFile(file) {
+		return "file is binary"
+	}
+	return ""
 }
@@ -1,18 +1,27 @@
 package runner
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
 	"time"
 
 	cloudformationStructure "github.com/bridgecrewio/yor/src/cloudformation/structure"
+	"github.com/bridgecrewio/yor/src/common"
+	"github.com/bridgecrewio/yor/src/common/checkpoint"
 	"github.com/bridgecrewio/yor/src/common/clioptions"
 	"github.com/bridgecrewio/yor/src/common/gitservice"
+	"github.com/bridgecrewio/yor/src/common/hooks"
 	"github.com/bridgecrewio/yor/src/common/structure"
+	"github.com/bridgecrewio/yor/src/common/tagging"
 	"github.com/bridgecrewio/yor/src/common/tagging/gittag"
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
 	taggingUtils "github.com/bridgecrewio/yor/src/common/tagging/utils"
 	"github.com/bridgecrewio/yor/src/common/utils"
 	terraformStructure "github.com/bridgecrewio/yor/src/terraform/structure"
@@ -145,6 +154,537 @@ func Test_TagCFNDir(t *testing.T) {
 		tg := runner.TagGroups
 		assert.Equal(t, len(allTagGroups)-1, len(tg))
 	})
+
+	t.Run("List files with symlinks and submodules", func(t *testing.T) {
+		dir := t.TempDir()
+		external := t.TempDir()
+		for _, subDir := range []string{"modules", "submodule", "outside"} {
+			assert.Nil(t, os.Mkdir(filepath.Join(dir, subDir), 0700))
+		}
+		for _, file := range []string{"main.tf", "modules/s3.tf", "submodule/main.tf", "outside/vpc.tf"} {
+			assert.Nil(t, os.WriteFile(filepath.Join(dir, file), []byte(""), 0600))
+		}
+		for _, file := range []string{"ext.tf", "lib.tf"} {
+			assert.Nil(t, os.WriteFile(filepath.Join(external, file), []byte(""), 0600))
+		}
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "submodule", ".git"), []byte("gitdir: ../.git/modules/submodule"), 0600))
+		assert.Nil(t, os.Symlink(filepath.Join(dir, "main.tf"), filepath.Join(dir, "modules", "link.tf")))
+		assert.Nil(t, os.Symlink(filepath.Join(external, "ext.tf"), filepath.Join(dir, "modules", "ext.tf")))
+		assert.Nil(t, os.Symlink(filepath.Join(dir, "outside"), filepath.Join(dir, "modules", "linked")))
+		assert.Nil(t, os.Symlink(external, filepath.Join(dir, "modules", "external")))
+		// a link to an ancestor must not be followed forever
+		assert.Nil(t, os.Symlink(dir, filepath.Join(dir, "modules", "cycle")))
+
+		realExternal, err := filepath.EvalSymlinks(external)
+		assert.Nil(t, err)
+		relativeFiles := func(runner *Runner) []string {
+			var files []string
+			for _, file := range runner.listFiles() {
+				if strings.HasPrefix(file, realExternal) {
+					files = append(files, "external/"+filepath.Base(file))
+					continue
+				}
+				relPath, _ := filepath.Rel(dir, file)
+				files = append(files, filepath.ToSlash(relPath))
+			}
+			return files
+		}
+
+		// links to files are listed by the path of the linked file, once, while links to directories are skipped
+		runner := Runner{dir: dir}
+		assert.ElementsMatch(t, []string{"main.tf", "modules/s3.tf", "outside/vpc.tf", "submodule/.git", "submodule/main.tf", "external/ext.tf"}, relativeFiles(&runner))
+
+		runner = Runner{dir: dir, followSymlinks: true, skipSubmodules: true}
+		assert.ElementsMatch(t, []string{"main.tf", "modules/s3.tf", "outside/vpc.tf", "external/ext.tf", "external/lib.tf"}, relativeFiles(&runner))
+	})
+
+	t.Run("Skip files exceeding the limits", func(t *testing.T) {
+		dir := t.TempDir()
+		twoResources := "resource \"aws_s3_bucket\" \"a\" {\n}\n\nresource \"aws_s3_bucket\" \"b\" {\n}\n"
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(twoResources), 0600))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "binary.tf"), []byte{0x7f, 'E', 'L', 'F', 0, 0}, 0600))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "image.png"), []byte{0x89, 'P', 'N', 'G', 0, 0}, 0600))
+		runner := Runner{}
+		err := runner.Init(&clioptions.TagOptions{
+			Directory:           dir,
+			TagGroups:           []string{"code2cloud"},
+			Parsers:             []string{"Terraform"},
+			DryRun:              true,
+			MaxResourcesPerFile: 1,
+		})
+		assert.Nil(t, err)
+		reportService, err := runner.TagDirectory()
+		assert.Nil(t, err)
+		report := reportService.CreateReport()
+		assert.Equal(t, 0, report.Summary.Scanned)
+		assert.Equal(t, 2, len(report.SkippedFiles))
+		assert.Equal(t, filepath.Join(dir, "binary.tf"), report.SkippedFiles[0].File)
+		assert.Equal(t, "file is binary", report.SkippedFiles[0].Reason)
+		assert.Equal(t, filepath.Join(dir, "main.tf"), report.SkippedFiles[1].File)
+	})
+
+	t.Run("Back up the written files", func(t *testing.T) {
+		dir := t.TempDir()
+		src := "resource \"aws_s3_bucket\" \"a\" {\n}\n"
+		file := filepath.Join(dir, "main.tf")
+		assert.Nil(t, os.WriteFile(file, []byte(src), 0644))
+		runner := Runner{}
+		err := runner.Init(&clioptions.TagOptions{
+			Directory: dir,
+			TagGroups: []string{"code2cloud"},
+			Parsers:   []string{"Terraform"},
+			Backup:    true,
+		})
+		assert.Nil(t, err)
+		runner.TagFile(file)
+		backup, err := os.ReadFile(file + utils.BackupFileSuffix)
+		assert.Nil(t, err)
+		assert.Equal(t, src, string(backup))
+		content, err := os.ReadFile(file)
+		assert.Nil(t, err)
+		assert.Contains(t, string(content), "yor_trace")
+		// the file keeps its permissions, and no temporary files are left
+		info, err := os.Stat(file)
+		assert.Nil(t, err)
+		assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+		entries, err := os.ReadDir(dir)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(entries))
+	})
+
+	t.Run("Leave files without tag changes untouched", func(t *testing.T) {
+		t.Setenv("YOR_SIMPLE_TAGS", "{\"team\": \"platform team\"}")
+		dir := t.TempDir()
+		src := "resource \"aws_s3_bucket\" \"a\" {\n  tags = {\n    yor_trace   =   \"4b0e4a36-5e0f-4bd4-9d2c-0b4ad4cf1a3e\"\n    team = \"Platform  Team\"\n  }\n}\n"
+		file := filepath.Join(dir, "main.tf")
+		assert.Nil(t, os.WriteFile(file, []byte(src), 0600))
+		runner := Runner{}
+		err := runner.Init(&clioptions.TagOptions{
+			Directory:          dir,
+			TagGroups:          []string{"simple", "code2cloud"},
+			Parsers:            []string{"Terraform"},
+			IgnoreValueChanges: []string{"case", "whitespace"},
+		})
+		assert.Nil(t, err)
+		runner.TagFile(file)
+		content, err := os.ReadFile(file)
+		assert.Nil(t, err)
+		assert.Equal(t, src, string(content))
+		_, updatedBlocks := runner.ChangeAccumulator.GetBlockChanges()
+		assert.Empty(t, updatedBlocks)
+
+		runner = Runner{}
+		err = runner.Init(&clioptions.TagOptions{
+			Directory: dir,
+			TagGroups: []string{"simple", "code2cloud"},
+			Parsers:   []string{"Terraform"},
+		})
+		assert.Nil(t, err)
+		runner.TagFile(file)
+		content, err = os.ReadFile(file)
+		assert.Nil(t, err)
+		assert.Contains(t, string(content), "platform team")
+	})
+
+	t.Run("Refresh only the tags of the selected keys and tag groups", func(t *testing.T) {
+		t.Setenv("YOR_SIMPLE_TAGS", "{\"team\": \"platform\", \"env\": \"dev\"}")
+		dir := t.TempDir()
+		file := filepath.Join(dir, "main.tf")
+		assert.Nil(t, os.WriteFile(file, []byte("resource \"aws_s3_bucket\" \"a\" {\n  tags = {\n    team = \"infra\"\n  }\n}\n"), 0600))
+		runner := Runner{}
+		err := runner.Init(&clioptions.TagOptions{
+			Directory: dir,
+			TagGroups: []string{"simple", "code2cloud"},
+			OnlyKeys:  []string{"te*"},
+			Parsers:   []string{"Terraform"},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(runner.TagGroups))
+		runner.TagFile(file)
+		content, err := os.ReadFile(file)
+		assert.Nil(t, err)
+		assert.Contains(t, string(content), "\"platform\"")
+		assert.NotContains(t, string(content), "env")
+		assert.NotContains(t, string(content), "yor_trace")
+
+		runner = Runner{}
+		err = runner.Init(&clioptions.TagOptions{
+			Directory:  dir,
+			TagGroups:  []string{"simple", "code2cloud"},
+			OnlyGroups: []string{"code2cloud"},
+			Parsers:    []string{"Terraform"},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(runner.TagGroups))
+		runner.TagFile(file)
+		content, err = os.ReadFile(file)
+		assert.Nil(t, err)
+		assert.Contains(t, string(content), "yor_trace")
+		assert.NotContains(t, string(content), "env")
+	})
+
+	t.Run("Tag the OpenAPI definitions of the APIs", func(t *testing.T) {
+		dir := t.TempDir()
+		file := filepath.Join(dir, "main.tf")
+		src := "resource \"aws_apigatewayv2_api\" \"pets\" {\n  name = \"pets\"\n  body = file(\"${path.module}/openapi.yaml\")\n}\n"
+		assert.Nil(t, os.WriteFile(file, []byte(src), 0600))
+		definitionFile := filepath.Join(dir, "openapi.yaml")
+		assert.Nil(t, os.WriteFile(definitionFile, []byte("openapi: 3.0.1\ninfo:\n  title: pets\n"), 0600))
+		runner := Runner{}
+		err := runner.Init(&clioptions.TagOptions{
+			Directory: dir,
+			TagGroups: []string{"code2cloud"},
+			Parsers:   []string{"Terraform"},
+		})
+		assert.Nil(t, err)
+		runner.TagFile(file)
+		report := runner.reportingService.CreateReport()
+		assert.Equal(t, 1, len(report.APIDefinitions))
+		assert.Equal(t, definitionFile, report.APIDefinitions[0].DefinitionFile)
+		traceID := report.APIDefinitions[0].YorTraceID
+		assert.NotEmpty(t, traceID)
+		definition, err := os.ReadFile(definitionFile)
+		assert.Nil(t, err)
+		assert.Equal(t, "openapi: 3.0.1\ninfo:\n  title: pets\ntags:\n  - name: \"yor_trace\"\n    x-amazon-apigateway-tag-value: \""+traceID+"\"\n", string(definition))
+	})
+
+	t.Run("Report the errors of the run", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "broken.tf"), []byte("resource \"aws_s3_bucket\" \"a\" {\n"), 0600))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte("resource \"aws_s3_bucket\" \"b\" {\n}\n"), 0600))
+		runner := Runner{}
+		err := runner.Init(&clioptions.TagOptions{
+			Directory: dir,
+			TagGroups: []string{"code2cloud"},
+			Parsers:   []string{"Terraform", "Kubernetes"},
+			DryRun:    true,
+		})
+		assert.Nil(t, err)
+		reportService, err := runner.TagDirectory()
+		assert.Nil(t, err)
+		report := reportService.CreateReport()
+		assert.Equal(t, 1, report.Summary.Scanned)
+		assert.Equal(t, 2, len(report.Errors))
+		assert.Equal(t, common.UnsupportedFramework, report.Errors[0].Code)
+		assert.Equal(t, common.ParseFailure, report.Errors[1].Code)
+		assert.Equal(t, filepath.Join(dir, "broken.tf"), report.Errors[1].File)
+		code, ok := report.GetErrorCode()
+		assert.True(t, ok)
+		assert.Equal(t, common.UnsupportedFramework, code)
+	})
+
+	t.Run("Run the hooks", func(t *testing.T) {
+		dir := t.TempDir()
+		src := "resource \"aws_s3_bucket\" \"a\" {\n}\n"
+		file := filepath.Join(dir, "main.tf")
+		assert.Nil(t, os.WriteFile(file, []byte(src), 0600))
+		hookOutput := filepath.Join(t.TempDir(), "post-file-write.json")
+		runner := Runner{}
+		err := runner.Init(&clioptions.TagOptions{
+			Directory:         dir,
+			TagGroups:         []string{"code2cloud"},
+			Parsers:           []string{"Terraform"},
+			PreScanHook:       "exit 1",
+			PostFileWriteHook: fmt.Sprintf("cat > %s", hookOutput),
+		})
+		assert.Nil(t, err)
+		// a failing pre-scan hook aborts the run before any file is tagged
+		reportService, err := runner.TagDirectory()
+		assert.Nil(t, reportService)
+		assert.NotNil(t, err)
+		content, err := os.ReadFile(file)
+		assert.Nil(t, err)
+		assert.Equal(t, src, string(content))
+
+		runner.hooks = hooks.New("", fmt.Sprintf("cat > %s", hookOutput), "")
+		_, err = runner.TagDirectory()
+		assert.Nil(t, err)
+		hookContext, err := os.ReadFile(hookOutput)
+		assert.Nil(t, err)
+		assert.Contains(t, string(hookContext), `"event":"post-file-write"`)
+		assert.Contains(t, string(hookContext), `"resources":["aws_s3_bucket.a"]`)
+	})
+
+	t.Run("Resume from the checkpoint", func(t *testing.T) {
+		dir := t.TempDir()
+		for _, subDir := range []string{"a", "b"} {
+			assert.Nil(t, os.Mkdir(filepath.Join(dir, subDir), 0700))
+			src := fmt.Sprintf("resource \"aws_s3_bucket\" \"%s\" {\n}\n", subDir)
+			assert.Nil(t, os.WriteFile(filepath.Join(dir, subDir, "main.tf"), []byte(src), 0600))
+		}
+		checkpointPath := filepath.Join(t.TempDir(), "checkpoint.json")
+		options := clioptions.TagOptions{
+			Directory:  dir,
+			TagGroups:  []string{"code2cloud"},
+			Parsers:    []string{"Terraform"},
+			Checkpoint: checkpointPath,
+		}
+		// the interrupted run tagged the files of a
+		interruptedRunner := Runner{}
+		assert.Nil(t, interruptedRunner.Init(&options))
+		interruptedRunner.TagFile(filepath.Join(dir, "a", "main.tf"))
+		savedCheckpoint := checkpoint.New(dir, false)
+		savedCheckpoint.Complete(filepath.Join(dir, "a"))
+		assert.Nil(t, savedCheckpoint.Save(checkpointPath, interruptedRunner.ChangeAccumulator))
+
+		runner := Runner{}
+		assert.Nil(t, runner.Init(&options))
+		reportService, err := runner.TagDirectory()
+		assert.Nil(t, err)
+		report := reportService.CreateReport()
+		// a isn't tagged again, so its resource is reported as new rather than as unchanged
+		assert.Equal(t, 2, report.Summary.Scanned)
+		assert.Equal(t, 2, report.Summary.NewResources)
+		content, err := os.ReadFile(filepath.Join(dir, "b", "main.tf"))
+		assert.Nil(t, err)
+		assert.Contains(t, string(content), "yor_trace")
+		_, err = os.Stat(checkpointPath)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("Resume from the checkpoint with a renamed yor_trace", func(t *testing.T) {
+		dir := t.TempDir()
+		for _, subDir := range []string{"a", "b"} {
+			assert.Nil(t, os.Mkdir(filepath.Join(dir, subDir), 0700))
+			src := fmt.Sprintf("resource \"aws_s3_bucket\" \"%s\" {\n}\n", subDir)
+			assert.Nil(t, os.WriteFile(filepath.Join(dir, subDir, "main.tf"), []byte(src), 0600))
+		}
+		checkpointPath := filepath.Join(t.TempDir(), "checkpoint.json")
+		options := clioptions.TagOptions{
+			Directory:   dir,
+			TagGroups:   []string{"code2cloud"},
+			Parsers:     []string{"Terraform"},
+			Checkpoint:  checkpointPath,
+			TagKeyNames: []string{"yor_trace=corp:trace-id"},
+		}
+		interruptedRunner := Runner{}
+		assert.Nil(t, interruptedRunner.Init(&options))
+		interruptedRunner.TagFile(filepath.Join(dir, "a", "main.tf"))
+		savedCheckpoint := checkpoint.New(dir, false)
+		savedCheckpoint.Complete(filepath.Join(dir, "a"))
+		assert.Nil(t, savedCheckpoint.Save(checkpointPath, interruptedRunner.ChangeAccumulator))
+
+		runner := Runner{}
+		assert.Nil(t, runner.Init(&options))
+		reportService, err := runner.TagDirectory()
+		assert.Nil(t, err)
+		report := reportService.CreateReport()
+		assert.Equal(t, 2, len(report.NewResourceTags))
+		for _, newResourceTag := range report.NewResourceTags {
+			assert.NotEmpty(t, newResourceTag.YorTraceID, newResourceTag.ResourceID)
+		}
+	})
+
+	t.Run("Raise the panic of a worker in TagDirectory", func(t *testing.T) {
+		dir := t.TempDir()
+		src := "resource \"aws_s3_bucket\" \"a\" {\n}\n"
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte(src), 0600))
+		runner := Runner{}
+		assert.Nil(t, runner.Init(&clioptions.TagOptions{
+			Directory: dir,
+			TagGroups: []string{"code2cloud"},
+			Parsers:   []string{"Terraform"},
+			DryRun:    true,
+		}))
+		runner.TagGroups = append(runner.TagGroups, &panickingTagGroup{})
+		assert.PanicsWithValue(t, "failed to tag", func() {
+			_, _ = runner.TagDirectory()
+		})
+	})
+
+	t.Run("Benchmark the phases of the run", func(t *testing.T) {
+		dir := t.TempDir()
+		src := "resource \"aws_s3_bucket\" \"a\" {\n}\n"
+		file := filepath.Join(dir, "main.tf")
+		assert.Nil(t, os.WriteFile(file, []byte(src), 0600))
+		runner := Runner{}
+		err := runner.Init(&clioptions.TagOptions{
+			Directory: dir,
+			TagGroups: []string{"code2cloud"},
+			Parsers:   []string{"Terraform", "CloudFormation"},
+			DryRun:    true,
+		})
+		assert.Nil(t, err)
+		benchmark, err := runner.Benchmark()
+		assert.Nil(t, err)
+		assert.Equal(t, 1, benchmark.Files)
+		assert.Equal(t, 1, benchmark.Resources)
+		assert.Equal(t, 1, len(benchmark.Frameworks))
+		assert.Equal(t, "Terraform", benchmark.Frameworks[0].Framework)
+		assert.True(t, benchmark.Frameworks[0].Parse > 0)
+		assert.True(t, benchmark.Frameworks[0].Write > 0)
+		assert.Equal(t, benchmark.Frameworks[0].Write, benchmark.Phases.Write)
+		assert.True(t, benchmark.Total >= benchmark.Phases.Walk)
+		// the tagged file is written elsewhere
+		content, err := os.ReadFile(file)
+		assert.Nil(t, err)
+		assert.Equal(t, src, string(content))
+
+		output := testingUtils.CaptureOutput(func() {
+			benchmark.Print(os.Stdout)
+		})
+		assert.Contains(t, output, "Walk")
+		assert.Contains(t, output, "Terraform")
+	})
+
+	t.Run("Repair the duplicate traces of copy-pasted blocks", func(t *testing.T) {
+		dir := t.TempDir()
+		bucket := func(name string, trace string) string {
+			return fmt.Sprintf("resource \"aws_s3_bucket\" %q {\n  tags = {\n    yor_trace = %q\n  }\n}\n", name, trace)
+		}
+		src := bucket("a", "trace-a") + bucket("b", "trace-a") + bucket("c", "trace-c")
+		file := filepath.Join(dir, "main.tf")
+		assert.Nil(t, os.WriteFile(file, []byte(src), 0600))
+		assert.Nil(t, os.WriteFile(filepath.Join(dir, "copy.tf"), []byte(bucket("d", "trace-c")), 0600))
+		runner := Runner{}
+		err := runner.Init(&clioptions.TagOptions{Directory: dir, Parsers: []string{"Terraform"}})
+		assert.Nil(t, err)
+		diagnosis, err := runner.Doctor(false)
+		assert.Nil(t, err)
+		assert.Equal(t, 4, diagnosis.TracedResources)
+		assert.Equal(t, 2, len(diagnosis.DuplicateTraces))
+		// the copied resource of another file comes first by its file
+		assert.Equal(t, "trace-c", diagnosis.DuplicateTraces[0].TraceID)
+		assert.Equal(t, "aws_s3_bucket.d", diagnosis.DuplicateTraces[0].Resources[0].ResourceID)
+		assert.Equal(t, "aws_s3_bucket.b", diagnosis.DuplicateTraces[1].Resources[1].ResourceID)
+		assert.Equal(t, 0, diagnosis.Repaired)
+
+		runner = Runner{}
+		assert.Nil(t, runner.Init(&clioptions.TagOptions{Directory: dir, Parsers: []string{"Terraform"}}))
+		diagnosis, err = runner.Doctor(true)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, diagnosis.Repaired)
+		repairedTrace := diagnosis.DuplicateTraces[1].Resources[1].NewTraceID
+		assert.NotEqual(t, "", repairedTrace)
+		content, err := os.ReadFile(file)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, strings.Count(string(content), "trace-a"))
+		assert.Contains(t, string(content), repairedTrace)
+
+		runner = Runner{}
+		assert.Nil(t, runner.Init(&clioptions.TagOptions{Directory: dir, Parsers: []string{"Terraform"}}))
+		diagnosis, err = runner.Doctor(false)
+		assert.Nil(t, err)
+		assert.Empty(t, diagnosis.DuplicateTraces)
+	})
+
+	t.Run("Migrate the tag keys of the resources", func(t *testing.T) {
+		dir := t.TempDir()
+		tfFile := filepath.Join(dir, "main.tf")
+		tfSrc := "resource \"aws_s3_bucket\" \"a\" {\n  tags = {\n    team = \"payments\"\n    \"cost-center\" = \"team\"\n  }\n}\n" +
+			"resource \"aws_s3_bucket\" \"b\" {\n  tags = {\n    team  = \"payments\"\n    owner = \"payments\"\n  }\n}\n"
+		assert.Nil(t, os.WriteFile(tfFile, []byte(tfSrc), 0600))
+		cfnFile := filepath.Join(dir, "template.yaml")
+		cfnSrc := "AWSTemplateFormatVersion: '2010-09-09'\nResources:\n  Bucket:\n    Type: AWS::S3::Bucket\n    Properties:\n      Tags:\n        - Key: team\n          Value: payments\n"
+		assert.Nil(t, os.WriteFile(cfnFile, []byte(cfnSrc), 0600))
+		mapping := map[string]string{"team": "owner", "cost-center": "cost_center"}
+		migrate := func(dryRun bool) *Migration {
+			runner := Runner{}
+			assert.Nil(t, runner.Init(&clioptions.TagOptions{Directory: dir, Parsers: []string{"Terraform", "CloudFormation"}}))
+			migration, err := runner.MigrateTags(mapping, dryRun)
+			assert.Nil(t, err)
+			return migration
+		}
+
+		migration := migrate(true)
+		assert.Equal(t, 4, len(migration.Renames))
+		content, _ := os.ReadFile(tfFile)
+		assert.Equal(t, tfSrc, string(content))
+
+		migration = migrate(false)
+		reasons := map[string]string{}
+		for _, rename := range migration.Renames {
+			reasons[rename.ResourceID+":"+rename.From] = rename.Reason
+		}
+		assert.Equal(t, map[string]string{
+			"aws_s3_bucket.a:team":        "",
+			"aws_s3_bucket.a:cost-center": "",
+			"aws_s3_bucket.b:team":        keyExistsReason,
+			"Bucket:team":                 "",
+		}, reasons)
+		content, _ = os.ReadFile(tfFile)
+		assert.Equal(t, strings.Replace(strings.Replace(tfSrc, "team =", "owner =", 1), "\"cost-center\"", "\"cost_center\"", 1), string(content))
+		content, _ = os.ReadFile(cfnFile)
+		assert.Equal(t, strings.Replace(cfnSrc, "Key: team", "Key: owner", 1), string(content))
+	})
+
+	t.Run("Tag files of in-memory file systems concurrently without writing them", func(t *testing.T) {
+		tfSrc := "resource \"aws_s3_bucket\" \"a\" {\n  bucket = \"a\"\n}\n"
+		fsys := fstest.MapFS{
+			"infra/main.tf":    &fstest.MapFile{Data: []byte(tfSrc)},
+			"infra/network.tf": &fstest.MapFile{Data: []byte(strings.Replace(tfSrc, "\"a\"", "\"b\"", 2))},
+		}
+		paths := []string{"infra/main.tf", "infra/network.tf"}
+		changeSets := make([]*FileChangeSet, len(paths))
+		var wg sync.WaitGroup
+		for i := range paths {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				changeSet, err := TagFile(fsys, paths[i], &clioptions.TagOptions{TagGroups: []string{"code2cloud", "git"}, Parsers: []string{"Terraform"}})
+				assert.Nil(t, err)
+				changeSets[i] = changeSet
+			}(i)
+		}
+		wg.Wait()
+
+		for i, changeSet := range changeSets {
+			assert.Equal(t, paths[i], changeSet.Path)
+			assert.True(t, changeSet.Changed)
+			assert.Contains(t, string(changeSet.Content), "yor_trace")
+			assert.NotContains(t, string(changeSet.Content), "git_commit")
+			assert.Equal(t, 1, len(changeSet.NewResourceTags))
+			assert.Equal(t, paths[i], changeSet.NewResourceTags[0].File)
+			assert.Equal(t, "yor_trace", changeSet.NewResourceTags[0].TagKey)
+		}
+		assert.Equal(t, tfSrc, string(fsys["infra/main.tf"].Data))
+
+		_, err := TagFile(fsys, "/infra/main.tf", nil)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Stop tagging when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		runner := Runner{}
+		err := runner.InitWithContext(ctx, &clioptions.TagOptions{
+			Directory: "../../../tests/cloudformation/resources/ebs",
+			TagGroups: []string{"code2cloud"},
+			Parsers:   []string{"CloudFormation"},
+			DryRun:    true,
+		})
+		assert.Nil(t, err)
+		reportService, err := runner.TagDirectory()
+		assert.True(t, errors.Is(err, context.Canceled))
+		report := reportService.CreateReport()
+		assert.True(t, report.Summary.Interrupted)
+		assert.Equal(t, 0, report.Summary.Scanned)
+	})
+
+	t.Run("Skip writing files which are in flight when the context is cancelled", func(t *testing.T) {
+		dir := t.TempDir()
+		src, err := os.ReadFile("../../../tests/cloudformation/resources/ebs/ebs.yaml")
+		assert.Nil(t, err)
+		file := filepath.Join(dir, "ebs.yaml")
+		assert.Nil(t, os.WriteFile(file, src, 0600))
+		ctx, cancel := context.WithCancel(context.Background())
+		runner := Runner{}
+		err = runner.InitWithContext(ctx, &clioptions.TagOptions{
+			Directory: dir,
+			TagGroups: []string{"code2cloud"},
+			Parsers:   []string{"CloudFormation"},
+		})
+		assert.Nil(t, err)
+		cancel()
+		runner.TagFile(file)
+		content, err := os.ReadFile(file)
+		assert.Nil(t, err)
+		assert.Equal(t, string(src), string(content))
+		report := runner.reportingService.CreateReport()
+		assert.Equal(t, 0, report.Summary.Scanned)
+		assert.Equal(t, 1, len(report.SkippedFiles))
+		assert.Equal(t, "tagging was interrupted", report.SkippedFiles[0].Reason)
+	})
 }
 
 func TestRunnerInternals(t *testing.T) {
@@ -274,3 +814,19 @@ func initMockGitTagGroup(rootDir string, filesToBlames map[string]string) *gitta
 	gitTagGroup.GitService = gitService
 	return &gitTagGroup
 }
+
+// panickingTagGroup panics while tagging, as the errors of yor do where logger.SetExitFunc panics
+type panickingTagGroup struct {
+	tagging.TagGroup
+}
+
+func (t *panickingTagGroup) InitTagGroup(_ string, _ []string, _ []string, _ ...tagging.InitTagGroupOption) {
+}
+
+func (t *panickingTagGroup) CreateTagsForBlock(_ structure.IBlock) error {
+	panic("failed to tag")
+}
+
+func (t *panickingTagGroup) GetDefaultTags() []tags.ITag {
+	return nil
+}
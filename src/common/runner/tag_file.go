@@ -0,0 +1,120 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/bridgecrewio/yor/src/common/clioptions"
+	"github.com/bridgecrewio/yor/src/common/reports"
+	taggingUtils "github.com/bridgecrewio/yor/src/common/tagging/utils"
+	"github.com/bridgecrewio/yor/src/common/utils"
+)
+
+// mountedFileSystems numbers the directories the file systems of TagFile are mounted at, so concurrent calls never
+// share one
+var mountedFileSystems int64
+
+// FileChangeSet is a file tagged by TagFile, with its tagged content and the tags which were added and updated
+type FileChangeSet struct {
+	// Path is the path of the file in the file system
+	Path string `json:"path"`
+	// Content is the tagged content of the file, which is its original content if its tags didn't change
+	Content             []byte              `json:"-"`
+	Changed             bool                `json:"changed"`
+	NewResourceTags     []reports.TagRecord `json:"newResourceTags"`
+	UpdatedResourceTags []reports.TagRecord `json:"updatedResourceTags"`
+}
+
+// TagFile tags the file of the file system like yor tag, but returns its tagged content instead of writing it, so
+// embedders can compose the tags with their own code generation in memory. The path is an unrooted, slash separated
+// path of the file system, whose other files are read as the neighbours of the file, e.g. the local modules of
+// terraform files. The files of the file system are never written, and TagFile can be called concurrently for the
+// files of the same or of different file systems.
+// The options are the options of yor tag, of which the ones of the run itself, such as the remote, the hooks or the
+// checkpoint, are ignored. The git tags need the repository and the history of the file, so the git tag groups are left
+// out.
+func TagFile(fsys fs.FS, path string, options *clioptions.TagOptions) (*FileChangeSet, error) {
+	if !fs.ValidPath(path) {
+		return nil, fmt.Errorf("invalid path %s, expected an unrooted, slash separated path of the file system", path)
+	}
+	original, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	// the file system is mounted at a directory of its own, whose files are written to memory
+	mountDir := filepath.Join(os.TempDir(), fmt.Sprintf("yor-tag-file-%d-%d", os.Getpid(), atomic.AddInt64(&mountedFileSystems, 1)))
+	unmount := utils.Mount(mountDir, utils.NewOverlayFileSystem(utils.NewIOFileSystem(fsys)))
+	defer unmount()
+	file := filepath.Join(mountDir, filepath.FromSlash(path))
+
+	yorRunner := new(Runner)
+	if err = yorRunner.InitWithContext(context.Background(), getTagFileOptions(options, mountDir)); err != nil {
+		return nil, err
+	}
+	yorRunner.TagFile(file)
+	for _, parser := range yorRunner.parsers {
+		parser.Close()
+	}
+
+	report := yorRunner.reportingService.CreateReport()
+	if len(report.SkippedFiles) > 0 {
+		return nil, fmt.Errorf("%s was skipped: %s", path, report.SkippedFiles[0].Reason)
+	}
+	if len(report.Errors) > 0 {
+		return nil, fmt.Errorf("failed to tag %s: %s", path, report.Errors[0].Message)
+	}
+	content, err := utils.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the tagged %s: %w", path, err)
+	}
+	return &FileChangeSet{
+		Path:                path,
+		Content:             content,
+		Changed:             !bytes.Equal(original, content),
+		NewResourceTags:     report.NewResourceTags,
+		UpdatedResourceTags: report.UpdatedResourceTags,
+	}, nil
+}
+
+// getTagFileOptions returns the options of the runner of TagFile, whose directory is the mounted file system, and
+// whose report has the paths of the file system
+func getTagFileOptions(options *clioptions.TagOptions, mountDir string) *clioptions.TagOptions {
+	tagOptions := clioptions.TagOptions{}
+	if options != nil {
+		tagOptions = *options
+	}
+	tagOptions.Directory = mountDir
+	tagOptions.Remote = ""
+	tagOptions.Ref = ""
+	tagOptions.DryRun = false
+	tagOptions.Backup = false
+	tagOptions.CreatePR = false
+	tagOptions.Checkpoint = ""
+	tagOptions.BaselineFile = ""
+	tagOptions.PreScanHook = ""
+	tagOptions.PostFileWriteHook = ""
+	tagOptions.PostRunHook = ""
+	tagOptions.Progress = ""
+	tagOptions.PathStyle = reports.PosixPathStyle
+	tagOptions.PathPrefixStrip = []string{mountDir}
+	tagOptions.PathPrefixMap = nil
+	if len(tagOptions.Parsers) == 0 {
+		tagOptions.Parsers = append([]string{}, clioptions.AllowedParsers...)
+	}
+	tagGroups := tagOptions.TagGroups
+	if len(tagGroups) == 0 {
+		tagGroups = taggingUtils.GetAllTagGroupsNames()
+	}
+	tagOptions.TagGroups = nil
+	for _, group := range tagGroups {
+		if group != string(taggingUtils.GitTagGroupName) && group != string(taggingUtils.Provenance) && group != string(taggingUtils.GitRemote) {
+			tagOptions.TagGroups = append(tagOptions.TagGroups, group)
+		}
+	}
+	return &tagOptions
+}
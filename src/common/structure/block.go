@@ -41,6 +41,61 @@ type IBlock interface {
 	GetResourceType() string
 }
 
+// IImportedBlock is implemented by blocks of frameworks which can import existing resources into the state, i.e.
+// terraform import blocks. Imported resources are reported separately from the resources created by the code.
+type IImportedBlock interface {
+	IsImported() bool
+}
+
+// IUneditableTagsBlock is implemented by blocks of frameworks whose tags can be set by any expression, i.e. terraform
+// resources. Blocks whose expression can't be edited safely aren't tagged, and are reported as skipped.
+type IUneditableTagsBlock interface {
+	// GetUneditableTagsReason returns why tags can't be added to the expression of the block's tags, or an empty
+	// string if they can
+	GetUneditableTagsReason() string
+}
+
+// IModuleCallBlock is implemented by blocks of frameworks which can call a module, i.e. terraform module blocks. The
+// tags of a module call may be passed on to the resources of the module by its variables.
+type IModuleCallBlock interface {
+	// GetModuleSource returns the directory of the called module if it's local, or its source otherwise, or an empty
+	// string for blocks which don't call a module
+	GetModuleSource() string
+}
+
+// INestedStackBlock is implemented by blocks of frameworks which can create nested stacks from another template, i.e.
+// AWS::CloudFormation::Stack resources. The tags of a nested stack are applied to the resources of its template.
+type INestedStackBlock interface {
+	// GetNestedTemplate returns the URL of the template of the nested stack, and the path of the template if it's a
+	// local file, or an empty string for blocks which aren't nested stacks
+	GetNestedTemplate() (string, string)
+}
+
+// IAPIDefinitionBlock is implemented by blocks of frameworks which can define an API by an OpenAPI definition in another
+// file, i.e. API Gateway APIs. The trace tag of the API is written to the tags of its definition.
+type IAPIDefinitionBlock interface {
+	// GetAPIDefinitionFile returns the path of the local OpenAPI definition of the API, or an empty string for blocks
+	// whose API isn't defined by a local file
+	GetAPIDefinitionFile() string
+}
+
+// ITraceKeyBlock is implemented by the blocks whose trace tag can be renamed, such as Block and the blocks which embed it
+type ITraceKeyBlock interface {
+	SetTraceKey(key string)
+}
+
+// SetTraceKey sets the key of the trace tag of the blocks, when yor_trace is renamed, so their existing trace is kept
+func SetTraceKey(blocks []IBlock, key string) {
+	if key == tags.YorTraceTagKey {
+		return
+	}
+	for _, block := range blocks {
+		if traceKeyBlock, ok := block.(ITraceKeyBlock); ok {
+			traceKeyBlock.SetTraceKey(key)
+		}
+	}
+}
+
 type Block struct {
 	FilePath          string
 	ExitingTags       []tags.ITag
@@ -52,6 +107,8 @@ type Block struct {
 	TagLines          Lines
 	Name              string
 	Type              string
+	// TraceKey is the key of the trace tag, when yor_trace is renamed
+	TraceKey string
 }
 
 func (b *Block) Init(filePath string, rawBlock interface{}) {
@@ -59,6 +116,17 @@ func (b *Block) Init(filePath string, rawBlock interface{}) {
 	b.RawBlock = rawBlock
 }
 
+func (b *Block) SetTraceKey(key string) {
+	b.TraceKey = key
+}
+
+func (b *Block) getTraceKey() string {
+	if b.TraceKey == "" {
+		return tags.YorTraceTagKey
+	}
+	return b.TraceKey
+}
+
 func (b *Block) GetLines(_ ...bool) Lines {
 	return b.Lines
 }
@@ -84,7 +152,7 @@ func (b *Block) AddNewTags(newTags []tags.ITag) {
 		return
 	}
 	isTraced := false
-	yorTagKey := tags.YorTraceTagKey
+	yorTagKey := b.getTraceKey()
 	for _, tag := range b.ExitingTags {
 		match := tags.IsTagKeyMatch(tag, yorTagKey)
 		if match {
@@ -127,7 +195,7 @@ func (b *Block) MergeTags() []tags.ITag {
 	}
 
 	var mergedTags []tags.ITag
-	yorTagKeyName := tags.YorTraceTagKey
+	yorTagKeyName := b.getTraceKey()
 	for _, existingTag := range b.ExitingTags {
 		if newTag, ok := newTagsByKey[existingTag.GetKey()]; ok {
 			match := tags.IsTagKeyMatch(existingTag, yorTagKeyName)
@@ -163,6 +231,7 @@ func (b *Block) CalculateTagsDiff() *TagDiff {
 						Key:       newTag.GetKey(),
 						PrevValue: existingTag.GetValue(),
 						NewValue:  newTag.GetValue(),
+						Source:    tags.GetSource(newTag),
 					})
 					break
 				}
@@ -197,7 +266,7 @@ func (b *Block) GetFilePath() string {
 
 func (b *Block) GetTraceID() string {
 	for _, tag := range b.MergeTags() {
-		if tag.GetKey() == tags.YorTraceTagKey {
+		if tag.GetKey() == b.getTraceKey() {
 			return tag.GetValue()
 		}
 	}
@@ -80,6 +80,24 @@ func TestTagsUpdatedResource(t *testing.T) {
 	})
 }
 
+func TestTagsRenamedTraceKey(t *testing.T) {
+	var block = Block{
+		FilePath:          "/mock.tf",
+		TraceKey:          "corp:trace-id",
+		ExitingTags:       []tags.ITag{&tags.Tag{Key: "corp:trace-id", Value: "123456789"}},
+		IsTaggable:        true,
+		TagsAttributeName: "tags",
+	}
+
+	t.Run("Test add new tags - skip renamed trace tag", func(t *testing.T) {
+		block.AddNewTags([]tags.ITag{&tags.Tag{Key: "corp:trace-id", Value: "987654321"}})
+
+		assert.Equal(t, 0, len(block.NewTags))
+		assert.Equal(t, "123456789", block.GetTraceID())
+		block.NewTags = nil
+	})
+}
+
 func TestTagsNewResource(t *testing.T) {
 	var existingTags = []tags.ITag{
 		&tags.Tag{
@@ -0,0 +1,141 @@
+package backstage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/bridgecrewio/yor/src/common/structure"
+	"github.com/bridgecrewio/yor/src/common/tagging"
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+	"gopkg.in/yaml.v2"
+)
+
+// CatalogInfoFileNames are the names of the Backstage descriptor files, which are looked up from the tagged directory
+// up to the root of its repository
+var CatalogInfoFileNames = []string{"catalog-info.yaml", "catalog-info.yml"}
+
+const componentKind = "component"
+
+// CatalogEntity is the part of a Backstage entity which the tags are taken from
+type CatalogEntity struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Name string `yaml:"name"`
+	} `yaml:"metadata"`
+	Spec struct {
+		Owner     string `yaml:"owner"`
+		System    string `yaml:"system"`
+		Lifecycle string `yaml:"lifecycle"`
+	} `yaml:"spec"`
+}
+
+type TagGroup struct {
+	tagging.TagGroup
+	entity *CatalogEntity
+}
+
+func (t *TagGroup) InitTagGroup(path string, skippedTags []string, explicitlySpecifiedTags []string, options ...tagging.InitTagGroupOption) {
+	for _, fn := range options {
+		fn(&t.Options)
+	}
+	t.SkippedTags = skippedTags
+	t.SpecifiedTags = explicitlySpecifiedTags
+	t.Source = tags.BackstageSource
+	t.Dir = path
+	if path != "" {
+		catalogInfoPath := FindCatalogInfo(path)
+		if catalogInfoPath == "" {
+			logger.Info(fmt.Sprintf("Did not find a Backstage catalog-info.yaml for %s", path))
+		} else {
+			entity, err := LoadCatalogEntity(catalogInfoPath)
+			if err != nil {
+				logger.Warning(fmt.Sprintf("Failed to load the Backstage entity of %s: %s", catalogInfoPath, err))
+			}
+			t.entity = entity
+		}
+	}
+	t.SetBuiltInTags(t.GetDefaultTags())
+}
+
+func (t *TagGroup) GetDefaultTags() []tags.ITag {
+	return []tags.ITag{
+		&OwnerTag{},
+		&SystemTag{},
+		&LifecycleTag{},
+	}
+}
+
+// CreateTagsForBlock tags the block with the owner, system and lifecycle of the entity, which are the same for all the
+// resources of the repository. Blocks aren't tagged when the repository has no catalog-info.yaml.
+func (t *TagGroup) CreateTagsForBlock(block structure.IBlock) error {
+	if t.entity == nil {
+		return nil
+	}
+	return t.UpdateBlockTags(block, t.entity)
+}
+
+// FindCatalogInfo returns the path of the catalog-info.yaml of the directory, or of the closest of its parents up to
+// the root of its repository, or an empty string if there is none
+func FindCatalogInfo(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	if info, err := os.Stat(dir); err == nil && !info.IsDir() {
+		dir = filepath.Dir(dir)
+	}
+	for {
+		for _, name := range CatalogInfoFileNames {
+			if info, err := os.Stat(filepath.Join(dir, name)); err == nil && !info.IsDir() {
+				return filepath.Join(dir, name)
+			}
+		}
+		// .git is a directory in repositories and a file in submodules and worktrees
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// LoadCatalogEntity reads the entity of the descriptor file, which may hold several entities as YAML documents. The
+// first Component is used, as it describes the service the resources belong to, or else the first entity with an owner.
+func LoadCatalogEntity(catalogInfoPath string) (*CatalogEntity, error) {
+	// #nosec G304 - file is from the scanned repository
+	content, err := os.ReadFile(catalogInfoPath)
+	if err != nil {
+		return nil, err
+	}
+	var entity *CatalogEntity
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for {
+		document := &CatalogEntity{}
+		err = decoder.Decode(document)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(document.Kind, componentKind) {
+			return document, nil
+		}
+		if entity == nil && document.Spec.Owner != "" {
+			entity = document
+		}
+	}
+	if entity == nil {
+		return nil, fmt.Errorf("no Component or entity with an owner")
+	}
+	return entity, nil
+}
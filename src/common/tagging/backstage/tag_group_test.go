@@ -0,0 +1,76 @@
+package backstage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bridgecrewio/yor/src/common/structure"
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+	"github.com/stretchr/testify/assert"
+)
+
+const catalogInfo = `apiVersion: backstage.io/v1alpha1
+kind: API
+metadata:
+  name: payments-api
+spec:
+  owner: team-api
+---
+apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  name: payments
+spec:
+  type: service
+  owner: group:default/team-payments
+  system: billing
+  lifecycle: production
+`
+
+func TestBackstageTagGroup(t *testing.T) {
+	repo := t.TempDir()
+	assert.Nil(t, os.Mkdir(filepath.Join(repo, ".git"), 0700))
+	assert.Nil(t, os.WriteFile(filepath.Join(repo, "catalog-info.yaml"), []byte(catalogInfo), 0600))
+	dir := filepath.Join(repo, "terraform", "network")
+	assert.Nil(t, os.MkdirAll(dir, 0700))
+
+	t.Run("Tag the blocks with the owner, system and lifecycle of the component", func(t *testing.T) {
+		tagGroup := TagGroup{}
+		tagGroup.InitTagGroup(dir, nil, nil)
+		block := &structure.Block{FilePath: filepath.Join(dir, "main.tf"), IsTaggable: true}
+		assert.Nil(t, tagGroup.CreateTagsForBlock(block))
+		assert.ElementsMatch(t, []tags.ITag{
+			&tags.Tag{Key: "owner", Value: "group:default/team-payments", Source: tags.BackstageSource},
+			&tags.Tag{Key: "system", Value: "billing", Source: tags.BackstageSource},
+			&tags.Tag{Key: "lifecycle", Value: "production", Source: tags.BackstageSource},
+		}, block.GetNewTags())
+	})
+
+	t.Run("Skip the tags which the entity doesn't set", func(t *testing.T) {
+		entityPath := filepath.Join(t.TempDir(), "catalog-info.yml")
+		assert.Nil(t, os.WriteFile(entityPath, []byte("kind: Resource\nspec:\n  owner: team-data\n"), 0600))
+		entity, err := LoadCatalogEntity(entityPath)
+		assert.Nil(t, err)
+		tagGroup := TagGroup{entity: entity}
+		tagGroup.SetTags(tagGroup.GetDefaultTags())
+		block := &structure.Block{IsTaggable: true}
+		assert.Nil(t, tagGroup.CreateTagsForBlock(block))
+		assert.Equal(t, 1, len(block.GetNewTags()))
+		assert.Equal(t, "team-data", block.GetNewTags()[0].GetValue())
+	})
+
+	t.Run("Look up the catalog info only up to the root of the repository", func(t *testing.T) {
+		assert.Equal(t, filepath.Join(repo, "catalog-info.yaml"), FindCatalogInfo(dir))
+		nestedRepo := filepath.Join(repo, "vendor", "module")
+		assert.Nil(t, os.MkdirAll(filepath.Join(nestedRepo, ".git"), 0700))
+		assert.Equal(t, "", FindCatalogInfo(nestedRepo))
+
+		tagGroup := TagGroup{}
+		tagGroup.InitTagGroup(nestedRepo, nil, nil)
+		block := &structure.Block{IsTaggable: true}
+		assert.Nil(t, tagGroup.CreateTagsForBlock(block))
+		assert.Empty(t, block.GetNewTags())
+		assert.Equal(t, 3, len(tagGroup.GetTags()))
+	})
+}
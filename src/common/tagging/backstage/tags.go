@@ -0,0 +1,68 @@
+package backstage
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+)
+
+type OwnerTag struct {
+	tags.Tag
+}
+
+func (t *OwnerTag) Init() {
+	t.Key = tags.BackstageOwnerTagKey
+}
+
+func (t *OwnerTag) CalculateValue(data interface{}) (tags.ITag, error) {
+	entity, ok := data.(*CatalogEntity)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert data to *CatalogEntity, which is required to calculte tag value. Type of data: %s", reflect.TypeOf(data))
+	}
+	return &tags.Tag{Key: t.Key, Value: entity.Spec.Owner}, nil
+}
+
+func (t *OwnerTag) GetDescription() string {
+	return "The owner of the service of the resource in the Backstage catalog"
+}
+
+type SystemTag struct {
+	tags.Tag
+}
+
+func (t *SystemTag) Init() {
+	t.Key = tags.BackstageSystemTagKey
+}
+
+func (t *SystemTag) CalculateValue(data interface{}) (tags.ITag, error) {
+	entity, ok := data.(*CatalogEntity)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert data to *CatalogEntity, which is required to calculte tag value. Type of data: %s", reflect.TypeOf(data))
+	}
+	return &tags.Tag{Key: t.Key, Value: entity.Spec.System}, nil
+}
+
+func (t *SystemTag) GetDescription() string {
+	return "The system which the service of the resource belongs to in the Backstage catalog"
+}
+
+type LifecycleTag struct {
+	tags.Tag
+}
+
+func (t *LifecycleTag) Init() {
+	t.Key = tags.BackstageLifecycleTagKey
+}
+
+func (t *LifecycleTag) CalculateValue(data interface{}) (tags.ITag, error) {
+	entity, ok := data.(*CatalogEntity)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert data to *CatalogEntity, which is required to calculte tag value. Type of data: %s", reflect.TypeOf(data))
+	}
+	return &tags.Tag{Key: t.Key, Value: entity.Spec.Lifecycle}, nil
+}
+
+func (t *LifecycleTag) GetDescription() string {
+	return "The lifecycle stage of the service of the resource in the Backstage catalog, e.g. production"
+}
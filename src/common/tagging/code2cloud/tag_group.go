@@ -16,7 +16,8 @@ func (t *TagGroup) InitTagGroup(_ string, skippedTags []string, explicitlySpecif
 	}
 	t.SkippedTags = skippedTags
 	t.SpecifiedTags = explicitlySpecifiedTags
-	t.SetTags([]tags.ITag{&YorTraceTag{}})
+	t.Source = tags.Code2CloudSource
+	t.SetBuiltInTags([]tags.ITag{&YorTraceTag{}})
 }
 
 func (t *TagGroup) GetDefaultTags() []tags.ITag {
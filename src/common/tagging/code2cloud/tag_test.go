@@ -26,6 +26,13 @@ func TestTagCreation(t *testing.T) {
 		assert.Equal(t, tagPrefix+"yor_trace", valueTag.GetKey())
 		assert.Equal(t, 36, len(valueTag.GetValue()))
 	})
+	t.Run("BcTraceTagCreationWithRenamedKey", func(t *testing.T) {
+		tagGroup := TagGroup{}
+		tagGroup.InitTagGroup("", nil, nil, tagging.WithKeyNames(tags.KeyNames{tags.YorTraceTagKey: "corp:trace-id"}))
+		valueTag, err := tagGroup.GetTags()[0].CalculateValue(struct{}{})
+		assert.Nil(t, err)
+		assert.Equal(t, "corp:trace-id", valueTag.GetKey())
+	})
 }
 
 func EvaluateTag(t *testing.T, tag tags.ITag) tags.ITag {
@@ -0,0 +1,259 @@
+package external
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/bridgecrewio/yor/src/common/structure"
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+)
+
+const defaultLookupTimeout = 10 * time.Second
+
+// LookupVariableRegex matches the variables of a lookup's url and key: ${tag:<key>}, ${resource:<type|id|file>} and
+// ${env:<name>}
+var LookupVariableRegex = regexp.MustCompile(`\${(tag|resource|env):([^}\s]+)}`)
+
+// LookupConfig fetches the value of a tag at scan time, from an HTTP endpoint or from a local JSON file, keyed by the
+// attributes of the resource. i.e. the cost center of the resource's team from a CMDB.
+type LookupConfig struct {
+	// URL is requested with GET and must respond with JSON. The values of its tag and resource variables are escaped.
+	URL string `yaml:"url"`
+	// File is a JSON object whose members are looked up by Key. It is relative to the configuration file.
+	File    string            `yaml:"file"`
+	Key     string            `yaml:"key"`
+	Field   string            `yaml:"field"`
+	Headers map[string]string `yaml:"headers"`
+	Timeout string            `yaml:"timeout"`
+	// CacheTTL expires the cached responses, which are otherwise kept until yor exits
+	CacheTTL string `yaml:"cache_ttl"`
+	NoCache  bool   `yaml:"no_cache"`
+}
+
+type lookup struct {
+	config   LookupConfig
+	file     string
+	cacheTTL time.Duration
+	client   *http.Client
+	// responses by the requested urls
+	cache     map[string]*cachedResponse
+	cacheLock sync.Mutex
+	fileData  map[string]interface{}
+	fileErr   error
+	fileOnce  sync.Once
+}
+
+type cachedResponse struct {
+	lock      sync.Mutex
+	fetched   bool
+	fetchedAt time.Time
+	value     string
+	found     bool
+}
+
+func newLookup(config *LookupConfig, configDir string) (*lookup, error) {
+	if config == nil {
+		return nil, nil
+	}
+	if (config.URL == "") == (config.File == "") {
+		return nil, fmt.Errorf("a lookup must specify either a url or a file")
+	}
+	if config.File != "" && config.Key == "" {
+		return nil, fmt.Errorf("a file lookup must specify the key of the resource's entry")
+	}
+	l := &lookup{config: *config, cache: make(map[string]*cachedResponse)}
+	if config.File != "" {
+		l.file = evaluateTemplateVariable(config.File)
+		if !filepath.IsAbs(l.file) {
+			l.file = filepath.Join(configDir, l.file)
+		}
+	}
+	timeout := defaultLookupTimeout
+	if config.Timeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(config.Timeout); err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid lookup timeout %v, expected a positive duration (e.g. 5s)", config.Timeout)
+		}
+	}
+	l.client = &http.Client{Timeout: timeout}
+	if config.CacheTTL != "" {
+		var err error
+		if l.cacheTTL, err = time.ParseDuration(config.CacheTTL); err != nil || l.cacheTTL <= 0 {
+			return nil, fmt.Errorf("invalid lookup cache ttl %v, expected a positive duration (e.g. 10m)", config.CacheTTL)
+		}
+	}
+	return l, nil
+}
+
+// Resolve returns the value looked up for the block, and false if there is none, including when the resource doesn't
+// have one of the attributes the lookup is keyed by
+func (l *lookup) Resolve(block structure.IBlock, blockTags []tags.ITag) (string, bool) {
+	if l.file != "" {
+		key, ok := evaluateLookupVariables(l.config.Key, block, blockTags, nil)
+		if !ok {
+			return "", false
+		}
+		return l.resolveFromFile(key)
+	}
+	requestURL, ok := evaluateLookupVariables(l.config.URL, block, blockTags, url.PathEscape)
+	if !ok {
+		return "", false
+	}
+	if l.config.NoCache {
+		value, found, _ := l.fetch(requestURL, block, blockTags)
+		return value, found
+	}
+	l.cacheLock.Lock()
+	response, ok := l.cache[requestURL]
+	if !ok {
+		response = &cachedResponse{}
+		l.cache[requestURL] = response
+	}
+	l.cacheLock.Unlock()
+
+	// concurrent lookups of the same url wait for a single request
+	response.lock.Lock()
+	defer response.lock.Unlock()
+	if response.fetched && (l.cacheTTL == 0 || time.Since(response.fetchedAt) < l.cacheTTL) {
+		return response.value, response.found
+	}
+	value, found, err := l.fetch(requestURL, block, blockTags)
+	if err == nil {
+		response.fetched, response.fetchedAt, response.value, response.found = true, time.Now(), value, found
+	}
+	return value, found
+}
+
+func (l *lookup) resolveFromFile(key string) (string, bool) {
+	l.fileOnce.Do(func() {
+		// #nosec G304
+		fileBytes, err := os.ReadFile(l.file)
+		if err != nil {
+			l.fileErr = err
+			return
+		}
+		l.fileErr = json.Unmarshal(fileBytes, &l.fileData)
+	})
+	if l.fileErr != nil {
+		logger.Warning(fmt.Sprintf("failed to read the lookup file %s: %s", l.file, l.fileErr))
+		return "", false
+	}
+	entry, ok := l.fileData[key]
+	if !ok {
+		return "", false
+	}
+	return getLookupField(entry, l.config.Field)
+}
+
+// fetch requests the url, which is not found if it responds with 404. Other failures are returned as errors, so they
+// aren't cached.
+func (l *lookup) fetch(requestURL string, block structure.IBlock, blockTags []tags.ITag) (string, bool, error) {
+	req, err := http.NewRequest(http.MethodGet, requestURL, nil)
+	if err != nil {
+		logger.Warning(fmt.Sprintf("invalid lookup url %s: %s", requestURL, err))
+		return "", false, err
+	}
+	req.Header.Set("Accept", "application/json")
+	for key, value := range l.config.Headers {
+		headerValue, _ := evaluateLookupVariables(value, block, blockTags, nil)
+		req.Header.Set(key, headerValue)
+	}
+	resp, err := l.client.Do(req)
+	if err != nil {
+		logger.Warning(fmt.Sprintf("failed to look up %s: %s", requestURL, err))
+		return "", false, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", false, nil
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger.Warning(fmt.Sprintf("failed to read the response of %s: %s", requestURL, err))
+		return "", false, err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		err = fmt.Errorf("request to %s failed with status %d", requestURL, resp.StatusCode)
+		logger.Warning(err.Error())
+		return "", false, err
+	}
+	var response interface{}
+	if err = json.Unmarshal(respBody, &response); err != nil {
+		logger.Warning(fmt.Sprintf("the response of %s is not valid JSON: %s", requestURL, err))
+		return "", false, err
+	}
+	value, found := getLookupField(response, l.config.Field)
+	return value, found, nil
+}
+
+// getLookupField returns the value at the dot separated path of field, which must be a string, a number or a boolean
+func getLookupField(data interface{}, field string) (string, bool) {
+	if field != "" {
+		for _, fieldName := range strings.Split(field, ".") {
+			object, ok := data.(map[string]interface{})
+			if !ok {
+				return "", false
+			}
+			if data, ok = object[fieldName]; !ok {
+				return "", false
+			}
+		}
+	}
+	switch value := data.(type) {
+	case string:
+		return value, value != ""
+	case float64, bool:
+		return fmt.Sprint(value), true
+	}
+	return "", false
+}
+
+// evaluateLookupVariables replaces the variables of the template by the attributes of the resource, whose values are
+// escaped by escape if it is given. It returns false if one of them isn't set.
+func evaluateLookupVariables(template string, block structure.IBlock, blockTags []tags.ITag, escape func(string) string) (string, bool) {
+	allSet := true
+	evaluated := LookupVariableRegex.ReplaceAllStringFunc(template, func(variable string) string {
+		match := LookupVariableRegex.FindStringSubmatch(variable)
+		value := ""
+		switch match[1] {
+		case "tag":
+			for _, tag := range blockTags {
+				if tag.GetKey() == match[2] {
+					value = tag.GetValue()
+				}
+			}
+		case "resource":
+			switch match[2] {
+			case "type":
+				value = block.GetResourceType()
+			case "id":
+				value = block.GetResourceID()
+			case "file":
+				value = block.GetFilePath()
+			}
+		case "env":
+			value = os.Getenv(match[2])
+		}
+		if value == "" {
+			allSet = false
+		}
+		// environment variables may hold a part of the url, such as the address of the endpoint
+		if escape != nil && match[1] != "env" {
+			return escape(value)
+		}
+		return value
+	})
+	return evaluated, allSet
+}
@@ -3,6 +3,7 @@ package external
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -29,6 +30,7 @@ type Tag struct {
 	defaultValue string
 	filters      map[string]interface{}
 	matches      MatchesConfig
+	lookup       *lookup
 }
 
 type Config struct {
@@ -47,6 +49,7 @@ type TagsConfig []struct {
 type TagConfigValue struct {
 	Default string        `yaml:"default"`
 	Matches MatchesConfig `yaml:"matches"`
+	Lookup  *LookupConfig `yaml:"lookup"`
 }
 
 type MatchesConfig []map[string]interface{}
@@ -109,6 +112,12 @@ func (t *TagGroup) InitExternalTagGroups(configFilePath string) {
 }
 
 func (t *TagGroup) InitTagGroup(dir string, skippedTags []string, explicitlySpecifiedTags []string, options ...tagging.InitTagGroupOption) {
+	opt := tagging.InitTagGroupOptions{}
+	for _, fn := range options {
+		fn(&opt)
+	}
+	// the key names match the renamed git tags of the filters
+	t.Options.KeyNames = opt.KeyNames
 	t.SkippedTags = skippedTags
 	t.SpecifiedTags = explicitlySpecifiedTags
 	t.Dir = dir
@@ -138,6 +147,23 @@ func (t *TagGroup) extractExternalTags() {
 	}
 }
 
+// FilterTags keeps the tags of the config whose keys match one of the glob patterns of onlyKeys
+func (t *TagGroup) FilterTags(onlyKeys []string) bool {
+	onlyKeyPatterns := tagging.CompileKeyPatterns(onlyKeys)
+	hasTags := false
+	for tagGroupName, groupTags := range t.tagGroupsByName {
+		var filteredTags []Tag
+		for _, groupTag := range groupTags {
+			if t.IsTagKeySelected(groupTag.GetKey(), onlyKeyPatterns) {
+				filteredTags = append(filteredTags, groupTag)
+			}
+		}
+		t.tagGroupsByName[tagGroupName] = filteredTags
+		hasTags = hasTags || len(filteredTags) > 0
+	}
+	return hasTags
+}
+
 func (t *TagGroup) GetDefaultTags() []tags.ITag {
 	return []tags.ITag{}
 }
@@ -185,7 +211,13 @@ func (t *TagGroup) CalculateTagValue(block structure.IBlock, tag Tag) (tags.ITag
 	}
 	retTag.Key = tag.GetKey()
 	retTag.Value = evaluateTemplateVariable(tag.defaultValue)
+	retTag.Source = tags.ExternalSourcePrefix + t.configFilePath
 	blockTags := append(block.GetExistingTags(), block.GetNewTags()...)
+	if tag.lookup != nil {
+		if value, found := tag.lookup.Resolve(block, blockTags); found {
+			retTag.Value = value
+		}
+	}
 	if len(tag.matches) > 0 {
 		for _, matchEntry := range tag.matches {
 			for matchValue, matchObj := range matchEntry {
@@ -210,7 +242,7 @@ func (t *TagGroup) CalculateTagValue(block structure.IBlock, tag Tag) (tags.ITag
 							for _, blockTag := range blockTags {
 								blockTagKey, blockTagValue := blockTag.GetKey(), blockTag.GetValue()
 								if blockTagKey == tagName {
-									if blockTagKey == tags.GitModifiersTagKey {
+									if blockTagKey == t.Options.KeyNames.Get(tags.GitModifiersTagKey) {
 										for _, val := range strings.Split(blockTagValue, "/") {
 											if utils.InSlice(tagMatch, val) {
 												foundTag = true
@@ -236,6 +268,12 @@ func (t *TagGroup) CalculateTagValue(block structure.IBlock, tag Tag) (tags.ITag
 		return retTag, nil
 	} else if tag.defaultValue != "" {
 		return retTag, nil
+	} else if tag.lookup != nil {
+		// the resource isn't tagged if nothing was found for it and there is no default
+		if retTag.Value == "" {
+			return nil, nil
+		}
+		return retTag, nil
 	}
 	return Tag{}, fmt.Errorf("could not compute external tag %s", tag.GetKey())
 }
@@ -250,6 +288,13 @@ func (t *TagGroup) ExtractExternalGroupsTags(tagsConfig TagsConfig) []Tag {
 		if err != nil {
 			logger.Error(err.Error())
 		}
+		computedTag.lookup, err = newLookup(tagValueObj.Lookup, filepath.Dir(t.configFilePath))
+		if err != nil {
+			logger.Error(fmt.Sprintf("invalid lookup of tag %s: %s", tagKey, err))
+		}
+		if t.IsTagSkipped(computedTag) {
+			continue
+		}
 		groupTags = append(groupTags, computedTag)
 	}
 	return groupTags
@@ -270,8 +315,8 @@ func evaluateTemplateVariable(val string) string {
 
 func parseExternalTag(tagValueObj TagConfigValue, tagKey string, groupFilters map[string]interface{}) (Tag, error) {
 	var parsedTag = Tag{filters: groupFilters}
-	if tagValueObj.Matches == nil && tagValueObj.Default == "" {
-		return Tag{}, fmt.Errorf("please specify either a default tag value and/or a computed tag value")
+	if tagValueObj.Matches == nil && tagValueObj.Default == "" && tagValueObj.Lookup == nil {
+		return Tag{}, fmt.Errorf("please specify either a default tag value, a computed tag value and/or a lookup")
 	}
 	parsedTag.defaultValue = tagValueObj.Default
 	parsedTag.ITag = &tags.Tag{Key: tagKey, Value: tagValueObj.Default}
@@ -1,6 +1,8 @@
 package external
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"testing"
@@ -48,6 +50,53 @@ func TestExternalTagGroup(t *testing.T) {
 		assert.Equal(t, 1, len(block.NewTags))
 	})
 
+	t.Run("test tagGroup CreateTagsForBlock skipped tag", func(t *testing.T) {
+		_ = os.Setenv("GIT_BRANCH", "master")
+		confPath, _ := filepath.Abs("../../../../tests/external_tags/external_tag_group.yml")
+		tagGroup := TagGroup{}
+		tagGroup.InitTagGroup("", []string{"e?v"}, nil)
+		tagGroup.InitExternalTagGroups(confPath)
+		block := &MockTestBlock{
+			Block: structure.Block{
+				FilePath:   "",
+				IsTaggable: true,
+			},
+		}
+		err := tagGroup.CreateTagsForBlock(block)
+		if err != nil {
+			logger.Warning(err.Error())
+			t.Fail()
+		}
+		assert.Equal(t, 0, len(block.NewTags))
+	})
+
+	t.Run("test tagGroup CreateTagsForBlock only keys", func(t *testing.T) {
+		_ = os.Setenv("GIT_BRANCH", "master")
+		confPath, _ := filepath.Abs("../../../../tests/external_tags/external_tag_group.yml")
+		tagGroup := TagGroup{}
+		tagGroup.InitTagGroup("", nil, nil)
+		tagGroup.InitExternalTagGroups(confPath)
+		assert.True(t, tagGroup.FilterTags([]string{"e*"}))
+		block := &MockTestBlock{
+			Block: structure.Block{
+				FilePath:   "",
+				IsTaggable: true,
+				ExitingTags: []tags.ITag{
+					&tags.Tag{Key: "git_commit", Value: "00193660c248483862c06e2ae96111adfcb683af"},
+				},
+			},
+		}
+		err := tagGroup.CreateTagsForBlock(block)
+		if err != nil {
+			logger.Warning(err.Error())
+			t.Fail()
+		}
+		assert.Equal(t, 1, len(block.NewTags))
+		assert.Equal(t, "env", block.NewTags[0].GetKey())
+
+		assert.False(t, tagGroup.FilterTags([]string{"owner"}))
+	})
+
 	t.Run("test tagGroup CreateTagsForBlock matches", func(t *testing.T) {
 		confPath, _ := filepath.Abs("../../../../tests/external_tags/external_tag_group.yml")
 		tagGroup := TagGroup{}
@@ -211,6 +260,79 @@ func TestExternalTagGroup(t *testing.T) {
 	})
 }
 
+func TestExternalTagGroupLookup(t *testing.T) {
+	getTagValues := func(block structure.IBlock) map[string]string {
+		values := make(map[string]string)
+		for _, tag := range block.GetNewTags() {
+			values[tag.GetKey()] = tag.GetValue()
+		}
+		return values
+	}
+	newTeamBlock := func(team string) *MockTestBlock {
+		block := &MockTestBlock{Block: structure.Block{FilePath: "main.tf", Name: "aws_s3_bucket.b", Type: "aws_s3_bucket", IsTaggable: true}}
+		if team != "" {
+			block.ExitingTags = []tags.ITag{&tags.Tag{Key: "team", Value: team}}
+		}
+		return block
+	}
+
+	t.Run("test tagGroup CreateTagsForBlock file lookup", func(t *testing.T) {
+		confPath, _ := filepath.Abs("../../../../tests/external_tags/lookup/external_tag_group_lookup.yml")
+		tagGroup := TagGroup{}
+		tagGroup.InitTagGroup("", nil, nil)
+		tagGroup.InitExternalTagGroups(confPath)
+
+		platformBlock, dataBlock, unknownBlock := newTeamBlock("platform"), newTeamBlock("data"), newTeamBlock("")
+		for _, block := range []*MockTestBlock{platformBlock, dataBlock, unknownBlock} {
+			assert.Nil(t, tagGroup.CreateTagsForBlock(block))
+		}
+		assert.Equal(t, map[string]string{"cost_center": "CC-1001", "owner": "platform@example.com"}, getTagValues(platformBlock))
+		assert.Equal(t, map[string]string{"cost_center": "CC-2002", "owner": "unowned"}, getTagValues(dataBlock))
+		assert.Equal(t, map[string]string{"owner": "unowned"}, getTagValues(unknownBlock))
+	})
+
+	t.Run("test tagGroup CreateTagsForBlock url lookup is cached", func(t *testing.T) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			if r.URL.Path != "/teams/platform" || r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(`{"costCenter": "CC-1001"}`))
+		}))
+		defer server.Close()
+		_ = os.Setenv("CMDB_URL", server.URL)
+		defer os.Unsetenv("CMDB_URL")
+		confPath := filepath.Join(t.TempDir(), "lookup.yml")
+		conf := `tag_groups:
+  - name: cmdb
+    tags:
+      - name: cost_center
+        value:
+          lookup:
+            url: ${env:CMDB_URL}/teams/${tag:team}
+            field: costCenter
+            headers:
+              Authorization: Bearer token
+            timeout: 5s
+`
+		assert.Nil(t, os.WriteFile(confPath, []byte(conf), 0600))
+		tagGroup := TagGroup{}
+		tagGroup.InitTagGroup("", nil, nil)
+		tagGroup.InitExternalTagGroups(confPath)
+
+		firstBlock, secondBlock, otherBlock := newTeamBlock("platform"), newTeamBlock("platform"), newTeamBlock("data")
+		for _, block := range []*MockTestBlock{firstBlock, secondBlock, otherBlock} {
+			assert.Nil(t, tagGroup.CreateTagsForBlock(block))
+		}
+		assert.Equal(t, map[string]string{"cost_center": "CC-1001"}, getTagValues(firstBlock))
+		assert.Equal(t, map[string]string{"cost_center": "CC-1001"}, getTagValues(secondBlock))
+		assert.Equal(t, map[string]string{}, getTagValues(otherBlock))
+		assert.Equal(t, 2, requests)
+	})
+}
+
 type MockTestBlock struct {
 	structure.Block
 }
@@ -15,7 +15,7 @@ type GitCommitTag struct {
 }
 
 func (t *GitCommitTag) Init() {
-	t.Key = "git_commit"
+	t.Key = tags.GitCommitTagKey
 }
 
 func (t *GitCommitTag) CalculateValue(data interface{}) (tags.ITag, error) {
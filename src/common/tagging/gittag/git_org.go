@@ -13,7 +13,7 @@ type GitOrgTag struct {
 }
 
 func (t *GitOrgTag) Init() {
-	t.Key = "git_org"
+	t.Key = tags.GitOrgTagKey
 }
 
 func (t *GitOrgTag) CalculateValue(data interface{}) (tags.ITag, error) {
@@ -0,0 +1,76 @@
+package gittag
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/bridgecrewio/yor/src/common/gitservice"
+	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/bridgecrewio/yor/src/common/structure"
+	"github.com/bridgecrewio/yor/src/common/tagging"
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+)
+
+// RemoteTagGroup tags the resources with the organization and the name of the repository, parsed from the origin remote
+// of their repository by the rules of SetRules, so the remotes of self-hosted providers (GitHub Enterprise, GitLab,
+// Bitbucket and Azure DevOps servers) get the right git_org and git_repo. Its tags don't need the blame of the
+// resources, and replace the git_org and git_repo of the git tag group when both are selected.
+type RemoteTagGroup struct {
+	TagGroup
+	rules []*gitservice.RemoteRule
+	// the organizations and repository names of the remotes, by their URLs
+	remotes sync.Map
+}
+
+func (t *RemoteTagGroup) InitTagGroup(path string, skippedTags []string, explicitlySpecifiedTags []string, options ...tagging.InitTagGroupOption) {
+	t.initGitTagGroup(path, skippedTags, explicitlySpecifiedTags, options...)
+	t.Source = tags.GitRemoteSource
+	t.SetBuiltInTags(t.GetDefaultTags())
+}
+
+func (t *RemoteTagGroup) GetDefaultTags() []tags.ITag {
+	return []tags.ITag{
+		&GitOrgTag{},
+		&GitRepoTag{},
+	}
+}
+
+// SetRules sets the rules which parse the remotes before the gitservice.DefaultRemoteRules
+func (t *RemoteTagGroup) SetRules(rules []*gitservice.RemoteRule) {
+	t.rules = rules
+}
+
+func (t *RemoteTagGroup) CreateTagsForBlock(block structure.IBlock) error {
+	gitService := t.getGitService(block.GetFilePath())
+	if gitService == nil || gitService.GetRemoteURL() == "" {
+		return nil
+	}
+	remote := t.getRemote(gitService.GetRemoteURL())
+	if remote == nil {
+		return nil
+	}
+	err := t.UpdateBlockTags(block, remote)
+	if block.IsGCPBlock() {
+		for _, tag := range block.GetNewTags() {
+			t.cleanGCPTagValue(tag)
+		}
+	}
+	return err
+}
+
+// getRemote returns the organization and the repository name of the remote as the blame the git tags are computed
+// from, or nil if no rule matches the remote
+func (t *RemoteTagGroup) getRemote(remoteURL string) *gitservice.GitBlame {
+	if remote, ok := t.remotes.Load(remoteURL); ok {
+		return remote.(*gitservice.GitBlame)
+	}
+	var remote *gitservice.GitBlame
+	org, repo, err := gitservice.ParseRemote(remoteURL, t.rules)
+	if err != nil {
+		logger.Warning(fmt.Sprintf("Failed to parse the organization and the repository of the git remote: %s", err))
+	} else {
+		remote = &gitservice.GitBlame{GitOrg: org, GitRepository: repo}
+	}
+	actual, _ := t.remotes.LoadOrStore(remoteURL, remote)
+	return actual.(*gitservice.GitBlame)
+}
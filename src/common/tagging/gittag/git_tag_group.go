@@ -1,12 +1,14 @@
 package gittag
 
 import (
+	"context"
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/bridgecrewio/yor/src/common"
 	"github.com/bridgecrewio/yor/src/common/gitservice"
 	"github.com/bridgecrewio/yor/src/common/logger"
 	"github.com/bridgecrewio/yor/src/common/structure"
@@ -21,6 +23,15 @@ import (
 type TagGroup struct {
 	tagging.TagGroup
 	GitService *gitservice.GitService
+	ctx        context.Context
+	pathStyle  string
+	// git services of the submodules in the scanned directory, by the directories of their files
+	servicesByDir sync.Map
+	// content of unsaved buffers by their paths, which is mapped to the blame of the file instead of its content on disk
+	buffers    sync.Map
+	anonymizer *IdentityAnonymizer
+	// freeze keeps the git tags of resources whose lines, other than their tags, weren't changed since their git_commit
+	freeze bool
 }
 
 type fileLineMapper struct {
@@ -29,22 +40,36 @@ type fileLineMapper struct {
 }
 
 func (t *TagGroup) InitTagGroup(path string, skippedTags []string, explicitlySpecifiedTags []string, options ...tagging.InitTagGroupOption) {
+	t.initGitTagGroup(path, skippedTags, explicitlySpecifiedTags, options...)
+	t.SetBuiltInTags(t.GetDefaultTags())
+}
+
+// initGitTagGroup initializes the git service of the tag group, without setting its tags, which are set by the tag
+// groups which compute their tags from the blame too, such as ProvenanceTagGroup
+func (t *TagGroup) initGitTagGroup(path string, skippedTags []string, explicitlySpecifiedTags []string, options ...tagging.InitTagGroupOption) {
 	opt := tagging.InitTagGroupOptions{}
 	for _, fn := range options {
 		fn(&opt)
 	}
 	t.SkippedTags = skippedTags
 	t.SpecifiedTags = explicitlySpecifiedTags
+	t.Source = tags.GitSource
+	// the git tags are renamed, but not prefixed
+	t.Options.KeyNames = opt.KeyNames
 	if path != "" {
 		gitService, err := gitservice.NewGitService(path)
 		if err != nil {
-			logger.Error(fmt.Sprintf("Failed to initialize git service for path \"%s\". Please ensure the provided root directory is initialized via the git init command: %q", path, err), "SILENT")
+			logger.ErrorWithExitCode(common.GitUnavailable.ExitCode(), fmt.Sprintf("Failed to initialize git service for path \"%s\". Please ensure the provided root directory is initialized via the git init command: %q", path, err), "SILENT")
+		}
+		t.ctx = opt.Context
+		t.pathStyle = opt.PathStyle
+		if gitService != nil {
+			t.initGitService(gitService)
 		}
 		t.GitService = gitService
 	} else {
 		logger.Debug("Path was passed as \"\", not initializing git service")
 	}
-	t.SetTags(t.GetDefaultTags())
 }
 
 func (t *TagGroup) GetDefaultTags() []tags.ITag {
@@ -59,8 +84,70 @@ func (t *TagGroup) GetDefaultTags() []tags.ITag {
 	}
 }
 
-func (t *TagGroup) initFileMapping(path string) fileLineMapper {
-	fileBlame, err := t.GitService.GetFileBlame(path)
+// getGitService returns the git service of the repository of the file, which is a submodule's if the file is in one
+func (t *TagGroup) getGitService(filePath string) *gitservice.GitService {
+	if t.GitService == nil || t.GitService.GetRepositoryRoot() == "" {
+		return t.GitService
+	}
+	dir := filepath.Dir(filePath)
+	if service, ok := t.servicesByDir.Load(dir); ok {
+		return service.(*gitservice.GitService)
+	}
+	service := t.GitService
+	repoRoot := gitservice.FindRepositoryRoot(dir)
+	if repoRoot != "" && repoRoot != t.GitService.GetRepositoryRoot() {
+		if submoduleService, err := gitservice.NewGitService(repoRoot); submoduleService != nil {
+			if err != nil {
+				logger.Debug(fmt.Sprintf("Failed to get the remote of submodule %s: %s", repoRoot, err))
+			}
+			t.initGitService(submoduleService)
+			service = submoduleService
+		} else {
+			logger.Warning(fmt.Sprintf("Failed to open the git repository of submodule %s: %s", repoRoot, err))
+		}
+	}
+	actual, _ := t.servicesByDir.LoadOrStore(dir, service)
+	return actual.(*gitservice.GitService)
+}
+
+func (t *TagGroup) initGitService(gitService *gitservice.GitService) {
+	if t.ctx != nil {
+		gitService.SetContext(t.ctx)
+	}
+	gitService.SetPathStyle(t.pathStyle)
+}
+
+// SetIdentityAnonymizer sets the anonymizer which replaces the emails and user names in the tags
+func (t *TagGroup) SetIdentityAnonymizer(anonymizer *IdentityAnonymizer) {
+	t.anonymizer = anonymizer
+}
+
+// SetFreeze sets whether the git tags of resources which weren't changed since the commit of their git_commit tag are
+// kept, rather than refreshed by the blame of their lines
+func (t *TagGroup) SetFreeze(freeze bool) {
+	t.freeze = freeze
+}
+
+// SetRef sets the ref (a branch, a tag or a commit) whose blame is used instead of the blame of HEAD, when the files
+// of the ref are read from the git object database rather than from the working tree
+func (t *TagGroup) SetRef(ref string) error {
+	if t.GitService == nil {
+		return nil
+	}
+	return t.GitService.SetRef(ref)
+}
+
+// SetBuffer sets the unsaved content of the file, whose lines are mapped to the lines of the file in git
+func (t *TagGroup) SetBuffer(filePath string, src []byte) {
+	t.buffers.Store(filePath, src)
+}
+
+func (t *TagGroup) RemoveBuffer(filePath string) {
+	t.buffers.Delete(filePath)
+}
+
+func (t *TagGroup) initFileMapping(gitService *gitservice.GitService, path string) fileLineMapper {
+	fileBlame, err := gitService.GetFileBlame(path)
 	if err != nil {
 		logger.Warning(fmt.Sprintf("Unable to get git blame for file %s: %s", path, err))
 		return fileLineMapper{}
@@ -70,12 +157,13 @@ func (t *TagGroup) initFileMapping(path string) fileLineMapper {
 }
 
 func (t *TagGroup) CreateTagsForBlock(block structure.IBlock) error {
-	fileLinesMap := t.initFileMapping(block.GetFilePath())
+	gitService := t.getGitService(block.GetFilePath())
+	fileLinesMap := t.initFileMapping(gitService, block.GetFilePath())
 	linesInGit := t.getBlockLinesInGit(block, fileLinesMap)
 	if linesInGit.Start < 0 || linesInGit.End < 0 {
 		return nil
 	}
-	blame, err := t.GitService.GetBlameForFileLines(block.GetFilePath(), linesInGit)
+	blame, err := gitService.GetBlameForFileLines(block.GetFilePath(), linesInGit)
 	if err != nil {
 		logger.Warning(fmt.Sprintf("Failed to tag %v with git tags, err: %v", block.GetResourceID(), err.Error()))
 		return nil
@@ -88,10 +176,16 @@ func (t *TagGroup) CreateTagsForBlock(block structure.IBlock) error {
 	if !t.hasNonTagChanges(blame, block) {
 		return nil
 	}
+	if t.freeze && t.isUnchangedSinceTagged(gitService, blame, block) {
+		return nil
+	}
 	err = t.UpdateBlockTags(block, blame)
 	if err != nil {
 		return err
 	}
+	for _, tag := range block.GetNewTags() {
+		t.anonymizer.AnonymizeTag(tag)
+	}
 	if block.IsGCPBlock() {
 		for _, tag := range block.GetNewTags() {
 			t.cleanGCPTagValue(tag)
@@ -135,9 +229,15 @@ func (t *TagGroup) mapOriginFileToGitFile(path string, fileBlame *git.BlameResul
 		gitLines = append(gitLines, line.Text)
 	}
 
-	originFileText, err := os.ReadFile(filepath.Clean(path))
-	if err != nil {
-		return fileLineMapper{}
+	var originFileText []byte
+	if buffer, ok := t.buffers.Load(path); ok {
+		originFileText = buffer.([]byte)
+	} else {
+		var err error
+		originFileText, err = utils.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return fileLineMapper{}
+		}
 	}
 
 	originLines := utils.GetLinesFromBytes(originFileText)
@@ -204,25 +304,64 @@ func (t *TagGroup) hasNonTagChanges(blame *gitservice.GitBlame, block structure.
 	return false
 }
 
+// isUnchangedSinceTagged returns whether all the lines of the block, other than its tags, were committed at or before the
+// commit of its git_commit tag
+func (t *TagGroup) isUnchangedSinceTagged(gitService *gitservice.GitService, blame *gitservice.GitBlame, block structure.IBlock) bool {
+	var taggedCommit string
+	for _, tag := range t.GetTags() {
+		if _, ok := tag.(*GitCommitTag); !ok {
+			continue
+		}
+		for _, existingTag := range block.GetExistingTags() {
+			if existingTag.GetKey() == tag.GetKey() {
+				taggedCommit = existingTag.GetValue()
+			}
+		}
+	}
+	if taggedCommit == "" || taggedCommit == CommitUnavailable {
+		return false
+	}
+	taggedDate, err := gitService.GetCommitDate(taggedCommit)
+	if err != nil {
+		logger.Debug(fmt.Sprintf("Refreshing the git tags of %s, since the commit of its tags is unknown: %s", block.GetResourceID(), err))
+		return false
+	}
+	tagsLines := block.GetTagsLines()
+	for lineNum, line := range blame.BlamesByLine {
+		if tagsLines.Start != -1 && lineNum >= tagsLines.Start && lineNum <= tagsLines.End {
+			continue
+		}
+		if line == nil || line.Hash.IsZero() {
+			// uncommitted changes
+			return false
+		}
+		if line.Hash.String() != taggedCommit && line.Date.After(taggedDate) {
+			return false
+		}
+	}
+	return true
+}
+
 func (t *TagGroup) cleanGCPTagValue(val tags.ITag) {
 	updated := val.GetValue()
 	switch val.GetKey() {
-	case tags.GitModifiersTagKey:
+	case t.Options.KeyNames.Get(tags.GitModifiersTagKey):
 		modifiers := strings.Split(updated, "/")
 		for i, m := range modifiers {
 			modifiers[i] = utils.RemoveGcpInvalidChars.ReplaceAllString(m, "")
 		}
 		updated = strings.Join(modifiers, "__")
-	case tags.GitLastModifiedAtTagKey:
+	case t.Options.KeyNames.Get(tags.GitLastModifiedAtTagKey):
 		updated = strings.ReplaceAll(updated, " ", "-")
 		updated = strings.ReplaceAll(updated, ":", "-")
-	case tags.GitFileTagKey:
+	case t.Options.KeyNames.Get(tags.GitFileTagKey):
 		updated = strings.ReplaceAll(updated, "/", "__")
 		updated = strings.ReplaceAll(updated, ".", "_")
-	case tags.GitLastModifiedByTagKey:
+	case t.Options.KeyNames.Get(tags.GitLastModifiedByTagKey):
 		updated = strings.Split(updated, "@")[0]
 		updated = utils.RemoveGcpInvalidChars.ReplaceAllString(updated, "")
-	case tags.GitRepoTagKey:
+	case t.Options.KeyNames.Get(tags.GitRepoTagKey), t.Options.KeyNames.Get(tags.GitOrgTagKey):
+		// the organizations of the remote rules may have subgroups
 		updated = strings.ReplaceAll(updated, "/", "__")
 		updated = strings.ReplaceAll(updated, ".", "_")
 	}
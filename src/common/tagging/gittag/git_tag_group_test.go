@@ -2,14 +2,20 @@ package gittag
 
 import (
 	"os"
+	"path/filepath"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/bridgecrewio/yor/src/common/gitservice"
 	"github.com/bridgecrewio/yor/src/common/structure"
 	"github.com/bridgecrewio/yor/src/common/tagging/tags"
 	"github.com/bridgecrewio/yor/src/common/utils"
 	"github.com/bridgecrewio/yor/tests/utils/blameutils"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -91,6 +97,90 @@ var ExpectedFileMappingDeleted = map[string]map[int]int{
 	"gitToOrigin": {1: 1, 2: 2, 3: 3, 4: 4, 5: -1, 6: 5, 7: 6, 8: 7, 9: 8, 10: 9, 11: 10, 12: 11},
 }
 
+func TestGitTagGroupFreeze(t *testing.T) {
+	dir := t.TempDir()
+	repository, err := git.PlainInit(dir, false)
+	assert.Nil(t, err)
+	worktree, err := repository.Worktree()
+	assert.Nil(t, err)
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "main.tf"), []byte("resource \"aws_s3_bucket\" \"a\" {\n}\n"), 0600))
+	_, err = worktree.Add("main.tf")
+	assert.Nil(t, err)
+	taggedDate := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	taggedCommit, err := worktree.Commit("add bucket", &git.CommitOptions{Author: &object.Signature{Name: "author", Email: "author@example.com", When: taggedDate}})
+	assert.Nil(t, err)
+	gitService, _ := gitservice.NewGitService(dir)
+
+	tagGroup := TagGroup{}
+	tagGroup.InitTagGroup("", nil, nil)
+	tagGroup.SetFreeze(true)
+	blameOf := func(lines ...*git.Line) *gitservice.GitBlame {
+		blame := &gitservice.GitBlame{BlamesByLine: map[int]*git.Line{}}
+		for i, line := range lines {
+			blame.BlamesByLine[i+1] = line
+		}
+		return blame
+	}
+	taggedLine := &git.Line{Hash: taggedCommit, Date: taggedDate}
+	blockTaggedAt := func(commit string) *MockTestBlock {
+		return &MockTestBlock{Block: structure.Block{
+			IsTaggable:  true,
+			ExitingTags: []tags.ITag{&tags.Tag{Key: tags.GitCommitTagKey, Value: commit}},
+		}}
+	}
+
+	t.Run("resources which weren't changed since their git_commit are frozen", func(t *testing.T) {
+		olderLine := &git.Line{Hash: plumbing.NewHash("1111111111111111111111111111111111111111"), Date: taggedDate.Add(-time.Hour)}
+		block := blockTaggedAt(taggedCommit.String())
+		assert.True(t, tagGroup.isUnchangedSinceTagged(gitService, blameOf(taggedLine, olderLine, taggedLine), block))
+	})
+
+	t.Run("changed resources are refreshed", func(t *testing.T) {
+		newerLine := &git.Line{Hash: plumbing.NewHash("2222222222222222222222222222222222222222"), Date: taggedDate.Add(time.Hour)}
+		uncommittedLine := &git.Line{Hash: plumbing.ZeroHash, Date: time.Now()}
+		block := blockTaggedAt(taggedCommit.String())
+		assert.False(t, tagGroup.isUnchangedSinceTagged(gitService, blameOf(taggedLine, newerLine, taggedLine), block))
+		assert.False(t, tagGroup.isUnchangedSinceTagged(gitService, blameOf(taggedLine, uncommittedLine, taggedLine), block))
+	})
+
+	t.Run("resources without a known git_commit are refreshed", func(t *testing.T) {
+		assert.False(t, tagGroup.isUnchangedSinceTagged(gitService, blameOf(taggedLine), blockTaggedAt(CommitUnavailable)))
+		assert.False(t, tagGroup.isUnchangedSinceTagged(gitService, blameOf(taggedLine), blockTaggedAt("3333333333333333333333333333333333333333")))
+		assert.False(t, tagGroup.isUnchangedSinceTagged(gitService, blameOf(taggedLine), &MockTestBlock{Block: structure.Block{IsTaggable: true}}))
+	})
+}
+
+func TestRemoteTagGroup(t *testing.T) {
+	dir := t.TempDir()
+	repository, err := git.PlainInit(dir, false)
+	assert.Nil(t, err)
+	_, err = repository.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{"git@gitlab.example.com:group/subgroup/project.git"}})
+	assert.Nil(t, err)
+
+	t.Run("tag the organization and the repository of the remote", func(t *testing.T) {
+		tagGroup := RemoteTagGroup{}
+		tagGroup.InitTagGroup(dir, nil, nil)
+		block := &MockTestBlock{Block: structure.Block{FilePath: filepath.Join(dir, "main.tf"), IsTaggable: true}}
+		assert.Nil(t, tagGroup.CreateTagsForBlock(block))
+		assert.Equal(t, []tags.ITag{
+			&tags.Tag{Key: tags.GitRepoTagKey, Value: "project", Source: tags.GitRemoteSource},
+			&tags.Tag{Key: tags.GitOrgTagKey, Value: "group/subgroup", Source: tags.GitRemoteSource},
+		}, block.NewTags)
+	})
+
+	t.Run("tag by the rules", func(t *testing.T) {
+		tagGroup := RemoteTagGroup{}
+		tagGroup.InitTagGroup(dir, nil, nil)
+		rules, err := gitservice.ParseRemoteRules([]string{`^gitlab\.example\.com/(?P<org>[^/]+)/(?P<repo>.+)$`})
+		assert.Nil(t, err)
+		tagGroup.SetRules(rules)
+		block := &MockTestBlock{Block: structure.Block{FilePath: filepath.Join(dir, "main.tf"), IsTaggable: true}}
+		assert.Nil(t, tagGroup.CreateTagsForBlock(block))
+		assert.Equal(t, "group", block.NewTags[1].GetValue())
+		assert.Equal(t, "subgroup/project", block.NewTags[0].GetValue())
+	})
+}
+
 type MockTestBlock struct {
 	structure.Block
 }
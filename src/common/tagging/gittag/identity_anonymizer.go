@@ -0,0 +1,118 @@
+package gittag
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+)
+
+const (
+	HashAnonymization   = "hash"
+	RedactAnonymization = "redact"
+
+	redactedIdentity     = "redacted"
+	hashedIdentityLength = 16
+)
+
+var AnonymizationModes = []string{HashAnonymization, RedactAnonymization}
+
+// IdentityAnonymizer replaces the emails and user names in the values of the git tags, so they can't identify people.
+// Hashed identities are keyed by the salt, so they are stable between runs but can't be recovered by hashing known
+// emails. A nil IdentityAnonymizer keeps the identities as they are.
+type IdentityAnonymizer struct {
+	mode string
+	salt string
+	// keyNames are the names of the git tags which hold the identities
+	keyNames tags.KeyNames
+	// identities by their anonymized values, which are written to the mapping file
+	identities map[string]map[string]bool
+	lock       sync.Mutex
+}
+
+func NewIdentityAnonymizer(mode string, salt string, keyNames tags.KeyNames) *IdentityAnonymizer {
+	if mode == "" {
+		return nil
+	}
+	return &IdentityAnonymizer{
+		mode:       mode,
+		salt:       salt,
+		keyNames:   keyNames,
+		identities: make(map[string]map[string]bool),
+	}
+}
+
+// Anonymize returns the value which replaces the identity in the tags
+func (a *IdentityAnonymizer) Anonymize(identity string) string {
+	if a == nil || identity == "" {
+		return identity
+	}
+	anonymized := redactedIdentity
+	if a.mode == HashAnonymization {
+		mac := hmac.New(sha256.New, []byte(a.salt))
+		_, _ = mac.Write([]byte(identity))
+		anonymized = hex.EncodeToString(mac.Sum(nil))[:hashedIdentityLength]
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.identities[anonymized] == nil {
+		a.identities[anonymized] = make(map[string]bool)
+	}
+	a.identities[anonymized][identity] = true
+	return anonymized
+}
+
+// AnonymizeTag replaces the identities in the value of the tag, if it is one of the git tags which hold them
+func (a *IdentityAnonymizer) AnonymizeTag(tag tags.ITag) {
+	if a == nil {
+		return
+	}
+	switch tag.GetKey() {
+	case a.keyNames.Get(tags.GitLastModifiedByTagKey):
+		tag.SetValue(a.Anonymize(tag.GetValue()))
+	case a.keyNames.Get(tags.GitModifiersTagKey):
+		modifiers := strings.Split(tag.GetValue(), "/")
+		anonymizedModifiers := make([]string, 0, len(modifiers))
+		found := make(map[string]bool)
+		for _, modifier := range modifiers {
+			anonymized := a.Anonymize(modifier)
+			if !found[anonymized] {
+				anonymizedModifiers = append(anonymizedModifiers, anonymized)
+				found[anonymized] = true
+			}
+		}
+		sort.Strings(anonymizedModifiers)
+		tag.SetValue(strings.Join(anonymizedModifiers, "/"))
+	}
+}
+
+// WriteMapping writes the identities replaced by each anonymized value to a JSON file, which should be kept apart
+// from the tagged code since it holds the identities
+func (a *IdentityAnonymizer) WriteMapping(mappingPath string) error {
+	mapping := make(map[string][]string)
+	if a != nil {
+		a.lock.Lock()
+		for anonymized, identities := range a.identities {
+			for identity := range identities {
+				mapping[anonymized] = append(mapping[anonymized], identity)
+			}
+			sort.Strings(mapping[anonymized])
+		}
+		a.lock.Unlock()
+	}
+	mappingBytes, err := json.MarshalIndent(mapping, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(mappingPath, mappingBytes, 0600); err != nil {
+		return fmt.Errorf("failed to write the identities mapping to %s: %s", mappingPath, err)
+	}
+	return nil
+}
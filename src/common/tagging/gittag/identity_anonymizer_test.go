@@ -0,0 +1,67 @@
+package gittag
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentityAnonymizer(t *testing.T) {
+	t.Run("hashed identities are stable and keyed by the salt", func(t *testing.T) {
+		anonymizer := NewIdentityAnonymizer(HashAnonymization, "salt", nil)
+		hashed := anonymizer.Anonymize("user@example.com")
+		assert.Equal(t, hashedIdentityLength, len(hashed))
+		assert.NotContains(t, hashed, "user")
+		assert.Equal(t, hashed, anonymizer.Anonymize("user@example.com"))
+		assert.NotEqual(t, hashed, anonymizer.Anonymize("other@example.com"))
+		assert.NotEqual(t, hashed, NewIdentityAnonymizer(HashAnonymization, "other salt", nil).Anonymize("user@example.com"))
+	})
+
+	t.Run("redacted identities", func(t *testing.T) {
+		anonymizer := NewIdentityAnonymizer(RedactAnonymization, "", nil)
+		lastModifiedBy := &tags.Tag{Key: tags.GitLastModifiedByTagKey, Value: "user@example.com"}
+		modifiers := &tags.Tag{Key: tags.GitModifiersTagKey, Value: "other/user"}
+		commit := &tags.Tag{Key: tags.GitCommitTagKey, Value: "00193660c248483862c06e2ae96111adfcb683af"}
+		for _, tag := range []tags.ITag{lastModifiedBy, modifiers, commit} {
+			anonymizer.AnonymizeTag(tag)
+		}
+		assert.Equal(t, "redacted", lastModifiedBy.GetValue())
+		assert.Equal(t, "redacted", modifiers.GetValue())
+		assert.Equal(t, "00193660c248483862c06e2ae96111adfcb683af", commit.GetValue())
+	})
+
+	t.Run("hashed modifiers and the mapping file", func(t *testing.T) {
+		anonymizer := NewIdentityAnonymizer(HashAnonymization, "salt", nil)
+		modifiers := &tags.Tag{Key: tags.GitModifiersTagKey, Value: "other/user"}
+		anonymizer.AnonymizeTag(modifiers)
+		expectedModifiers := []string{anonymizer.Anonymize("other"), anonymizer.Anonymize("user")}
+		if expectedModifiers[0] > expectedModifiers[1] {
+			expectedModifiers[0], expectedModifiers[1] = expectedModifiers[1], expectedModifiers[0]
+		}
+		assert.Equal(t, expectedModifiers[0]+"/"+expectedModifiers[1], modifiers.GetValue())
+
+		mappingPath := filepath.Join(t.TempDir(), "identities.json")
+		assert.Nil(t, anonymizer.WriteMapping(mappingPath))
+		mappingBytes, err := os.ReadFile(mappingPath)
+		assert.Nil(t, err)
+		var mapping map[string][]string
+		assert.Nil(t, json.Unmarshal(mappingBytes, &mapping))
+		assert.Equal(t, map[string][]string{
+			anonymizer.Anonymize("other"): {"other"},
+			anonymizer.Anonymize("user"):  {"user"},
+		}, mapping)
+	})
+
+	t.Run("identities are kept without an anonymization mode", func(t *testing.T) {
+		anonymizer := NewIdentityAnonymizer("", "", nil)
+		assert.Nil(t, anonymizer)
+		lastModifiedBy := &tags.Tag{Key: tags.GitLastModifiedByTagKey, Value: "user@example.com"}
+		anonymizer.AnonymizeTag(lastModifiedBy)
+		assert.Equal(t, "user@example.com", lastModifiedBy.GetValue())
+	})
+}
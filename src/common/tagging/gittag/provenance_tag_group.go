@@ -0,0 +1,96 @@
+package gittag
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/bridgecrewio/yor/src/common/gitservice"
+	"github.com/bridgecrewio/yor/src/common/tagging"
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+)
+
+const (
+	// ProvenanceJSONFormat writes the provenance as a JSON object, e.g. {"repo":"...","commit":"...","file":"..."}
+	ProvenanceJSONFormat = "json"
+	// ProvenanceKeyValueFormat writes the provenance as key=value pairs, e.g. repo=...;commit=...;file=...
+	ProvenanceKeyValueFormat   = "kv"
+	DefaultProvenanceDelimiter = ";"
+)
+
+var ProvenanceFormats = []string{ProvenanceJSONFormat, ProvenanceKeyValueFormat}
+
+// ProvenanceTagGroup records the repository URL, the latest commit and the file of the resource in a single tag, for
+// regulators which require the provenance of the resources in one tag because of the limits on the number of tags. Its
+// value is computed from the blame of the resource like the git tags, so it only changes with the resource.
+type ProvenanceTagGroup struct {
+	TagGroup
+	provenanceTag *ProvenanceTag
+}
+
+func (t *ProvenanceTagGroup) InitTagGroup(path string, skippedTags []string, explicitlySpecifiedTags []string, options ...tagging.InitTagGroupOption) {
+	t.initGitTagGroup(path, skippedTags, explicitlySpecifiedTags, options...)
+	t.Source = tags.ProvenanceSource
+	t.provenanceTag = &ProvenanceTag{Format: ProvenanceKeyValueFormat, Delimiter: DefaultProvenanceDelimiter}
+	t.SetBuiltInTags([]tags.ITag{t.provenanceTag})
+}
+
+func (t *ProvenanceTagGroup) GetDefaultTags() []tags.ITag {
+	return []tags.ITag{
+		&ProvenanceTag{Format: ProvenanceKeyValueFormat, Delimiter: DefaultProvenanceDelimiter},
+	}
+}
+
+// SetFormat sets the format of the provenance, one of ProvenanceFormats, and the delimiter of its key=value pairs
+func (t *ProvenanceTagGroup) SetFormat(format string, delimiter string) {
+	if t.provenanceTag == nil {
+		return
+	}
+	if format != "" {
+		t.provenanceTag.Format = format
+	}
+	if delimiter != "" {
+		t.provenanceTag.Delimiter = delimiter
+	}
+}
+
+type ProvenanceTag struct {
+	tags.Tag
+	Format    string
+	Delimiter string
+}
+
+type provenance struct {
+	Repo   string `json:"repo"`
+	Commit string `json:"commit"`
+	File   string `json:"file"`
+}
+
+func (t *ProvenanceTag) Init() {
+	t.Key = tags.ProvenanceTagKey
+}
+
+func (t *ProvenanceTag) CalculateValue(data interface{}) (tags.ITag, error) {
+	gitBlame, ok := data.(*gitservice.GitBlame)
+	if !ok {
+		return nil, fmt.Errorf("failed to convert data to *GitBlame, which is required to calculte tag value. Type of data: %s", reflect.TypeOf(data))
+	}
+	value := provenance{Repo: gitBlame.RepositoryURL, Commit: CommitUnavailable, File: gitBlame.FilePath}
+	if latestCommit := gitBlame.GetLatestCommit(); latestCommit != nil && !latestCommit.Hash.IsZero() {
+		value.Commit = latestCommit.Hash.String()
+	}
+	if t.Format == ProvenanceJSONFormat {
+		valueBytes, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		return &tags.Tag{Key: t.Key, Value: string(valueBytes)}, nil
+	}
+	pairs := []string{"repo=" + value.Repo, "commit=" + value.Commit, "file=" + value.File}
+	return &tags.Tag{Key: t.Key, Value: strings.Join(pairs, t.Delimiter)}, nil
+}
+
+func (t *ProvenanceTag) GetDescription() string {
+	return "The repository URL, the latest commit and the file of this resource in a single tag, for compliance"
+}
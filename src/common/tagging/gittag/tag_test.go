@@ -62,6 +62,19 @@ func TestTagCreation(t *testing.T) {
 		assert.Equal(t, "jonjozwiak/schosterbarak", valueTag.GetValue())
 	})
 
+	t.Run("ProvenanceCreation", func(t *testing.T) {
+		provenanceBlame := blame
+		provenanceBlame.RepositoryURL = "https://github.com/" + blameutils.Org + "/" + blameutils.Repository
+		tag := ProvenanceTag{Format: ProvenanceKeyValueFormat, Delimiter: "|"}
+		valueTag := EvaluateTag(t, &tag, provenanceBlame)
+		assert.Equal(t, "yor_provenance", valueTag.GetKey())
+		assert.Equal(t, "repo="+provenanceBlame.RepositoryURL+"|commit="+blameutils.CommitHash1+"|file="+blameutils.FilePath, valueTag.GetValue())
+
+		tag = ProvenanceTag{Format: ProvenanceJSONFormat}
+		valueTag = EvaluateTag(t, &tag, provenanceBlame)
+		assert.JSONEq(t, `{"repo": "`+provenanceBlame.RepositoryURL+`", "commit": "`+blameutils.CommitHash1+`", "file": "`+blameutils.FilePath+`"}`, valueTag.GetValue())
+	})
+
 	t.Run("Tag description tests", func(t *testing.T) {
 		tag := tags.Tag{}
 		defaultDescription := tag.GetDescription()
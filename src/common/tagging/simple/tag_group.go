@@ -19,6 +19,7 @@ type TagGroup struct {
 func (t *TagGroup) InitTagGroup(_ string, skippedTags []string, explicitlySpecifiedTags []string, options ...tagging.InitTagGroupOption) {
 	t.SkippedTags = skippedTags
 	t.SpecifiedTags = explicitlySpecifiedTags
+	t.Source = tags.SimpleSource
 	envTagsStr := os.Getenv("YOR_SIMPLE_TAGS")
 	if envTagsStr == "" {
 		return
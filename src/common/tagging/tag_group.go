@@ -1,6 +1,7 @@
 package tagging
 
 import (
+	"context"
 	"fmt"
 	"regexp"
 	"strings"
@@ -18,6 +19,18 @@ type TagGroup struct {
 	Dir           string
 	SpecifiedTags []string
 	Options       InitTagGroupOptions
+	// Source is recorded on the values of the tags, unless their definitions have a source of their own
+	Source string
+	// skippedTagPatterns are compiled from SkippedTags on first use, so each pattern is compiled once per tag group
+	skippedTagPatterns KeyPatterns
+}
+
+// globPattern is a glob pattern of whole keys, compiled to a regex. legacyRegex is the unanchored regex the pattern
+// was matched as before, kept only to warn about the keys which the pattern no longer matches.
+type globPattern struct {
+	pattern     string
+	regex       *regexp.Regexp
+	legacyRegex *regexp.Regexp
 }
 
 var IgnoredDirs = []string{".git", ".DS_Store", ".idea"}
@@ -26,6 +39,10 @@ type InitTagGroupOption func(opt *InitTagGroupOptions)
 
 type InitTagGroupOptions struct {
 	TagPrefix string
+	Context   context.Context
+	PathStyle string
+	// KeyNames rename the built-in tags of the tag group
+	KeyNames tags.KeyNames
 }
 
 func WithTagPrefix(s string) InitTagGroupOption {
@@ -34,6 +51,27 @@ func WithTagPrefix(s string) InitTagGroupOption {
 	}
 }
 
+// WithContext sets the context which cancels long-running operations of the tag group, such as git blame
+func WithContext(ctx context.Context) InitTagGroupOption {
+	return func(opt *InitTagGroupOptions) {
+		opt.Context = ctx
+	}
+}
+
+// WithPathStyle sets the style of the file paths in the tags, such as yor_file
+func WithPathStyle(pathStyle string) InitTagGroupOption {
+	return func(opt *InitTagGroupOptions) {
+		opt.PathStyle = pathStyle
+	}
+}
+
+// WithKeyNames sets the names the built-in tags of the tag group are written with, see tags.ParseKeyNames
+func WithKeyNames(keyNames tags.KeyNames) InitTagGroupOption {
+	return func(opt *InitTagGroupOptions) {
+		opt.KeyNames = keyNames
+	}
+}
+
 type ITagGroup interface {
 	InitTagGroup(path string, skippedTags []string, explicitlySpecifiedTags []string, options ...InitTagGroupOption)
 	CreateTagsForBlock(block structure.IBlock) error
@@ -41,13 +79,33 @@ type ITagGroup interface {
 	GetDefaultTags() []tags.ITag
 }
 
+// IFilterableTagGroup is a tag group which can be restricted to the tags of some keys, so a run refreshes them only
+type IFilterableTagGroup interface {
+	// FilterTags keeps the tags whose keys match one of the glob patterns of onlyKeys, and returns whether the tag group
+	// may still create tags
+	FilterTags(onlyKeys []string) bool
+}
+
 func (t *TagGroup) GetSkippedDirs() []string {
 	return IgnoredDirs
 }
 
 func (t *TagGroup) SetTags(tags []tags.ITag) {
+	t.setTags(tags, false)
+}
+
+// SetBuiltInTags sets the tags of the built-in tag groups, which are renamed by the key names of the options, unlike
+// the tags of SetTags which are set by the users
+func (t *TagGroup) SetBuiltInTags(tags []tags.ITag) {
+	t.setTags(tags, true)
+}
+
+func (t *TagGroup) setTags(tags []tags.ITag, isBuiltIn bool) {
 	for _, tag := range tags {
 		tag.Init()
+		if isBuiltIn {
+			t.Options.KeyNames.Rename(tag)
+		}
 		tag.SetTagPrefix(t.Options.TagPrefix)
 		if !t.IsTagSkipped(tag) && (t.SpecifiedTags == nil || len(t.SpecifiedTags) == 0 || utils.InSlice(t.SpecifiedTags, tag.GetKey())) {
 			t.tags = append(t.tags, tag)
@@ -59,17 +117,87 @@ func (t *TagGroup) GetTags() []tags.ITag {
 	return t.tags
 }
 
+// IsTagSkipped checks if the key of the tag matches one of the skipped tags. These are glob patterns of whole keys,
+// where * matches any sequence of characters and ? a single one, e.g. git_last_modified_*. The tag prefix is ignored,
+// so the built-in tags are skipped by the same patterns with and without it.
+// The skipped tags used to be unanchored regexes, so trace also skipped yor_trace. The keys which only the old matching
+// skips are warned about, since the same --skip-tags now keeps them.
 func (t *TagGroup) IsTagSkipped(tag tags.ITag) bool {
-	for _, st := range t.SkippedTags {
-		stRegex := strings.ReplaceAll(st, "*", ".*")
-		if match, err := regexp.Match(stRegex, []byte(tag.GetKey())); match || err != nil {
-			logger.Info(fmt.Sprintf("Skipping %v due to skip-tag constraint %v", tag.GetKey(), st))
+	if t.skippedTagPatterns == nil {
+		t.skippedTagPatterns = CompileKeyPatterns(t.SkippedTags)
+	}
+	key := tag.GetKey()
+	for _, st := range t.skippedTagPatterns {
+		if t.isKeyMatch(st, key) {
+			logger.Info(fmt.Sprintf("Skipping %v due to skip-tag constraint %v", key, st.pattern))
+			return true
+		}
+	}
+	for _, st := range t.skippedTagPatterns {
+		if st.legacyRegex != nil && st.legacyRegex.MatchString(key) {
+			logger.Warning(fmt.Sprintf("Not skipping %v, since skip-tag constraint %v matches whole keys only, use *%v* to skip the keys which contain it", key, st.pattern, st.pattern))
+		}
+	}
+	return false
+}
+
+func (t *TagGroup) FilterTags(onlyKeys []string) bool {
+	onlyKeyPatterns := CompileKeyPatterns(onlyKeys)
+	var filteredTags []tags.ITag
+	for _, tag := range t.tags {
+		if t.IsTagKeySelected(tag.GetKey(), onlyKeyPatterns) {
+			filteredTags = append(filteredTags, tag)
+		}
+	}
+	t.tags = filteredTags
+	return len(t.tags) > 0
+}
+
+// IsTagKeySelected checks if the key matches one of the glob patterns of onlyKeys, the same way IsTagSkipped does
+func (t *TagGroup) IsTagKeySelected(key string, onlyKeys KeyPatterns) bool {
+	for _, pattern := range onlyKeys {
+		if t.isKeyMatch(pattern, key) {
 			return true
 		}
 	}
 	return false
 }
 
+func (t *TagGroup) isKeyMatch(pattern globPattern, key string) bool {
+	return pattern.regex.MatchString(key) || pattern.regex.MatchString(strings.TrimPrefix(key, t.Options.TagPrefix))
+}
+
+// KeyPatterns are the compiled glob patterns of whole keys of --skip-tags and --only-keys
+type KeyPatterns []globPattern
+
+// CompileKeyPatterns compiles the glob patterns once, so they aren't compiled again for each of the keys they match
+func CompileKeyPatterns(patterns []string) KeyPatterns {
+	compiled := make(KeyPatterns, 0, len(patterns))
+	for _, pattern := range patterns {
+		var patternRegex strings.Builder
+		patternRegex.WriteString("^")
+		for _, char := range pattern {
+			switch char {
+			case '*':
+				patternRegex.WriteString(".*")
+			case '?':
+				patternRegex.WriteString(".")
+			default:
+				patternRegex.WriteString(regexp.QuoteMeta(string(char)))
+			}
+		}
+		patternRegex.WriteString("$")
+		// the old patterns may not be valid regexes, in which case there is nothing to warn about
+		legacyRegex, _ := regexp.Compile(strings.ReplaceAll(pattern, "*", ".*"))
+		compiled = append(compiled, globPattern{
+			pattern:     pattern,
+			regex:       regexp.MustCompile(patternRegex.String()),
+			legacyRegex: legacyRegex,
+		})
+	}
+	return compiled
+}
+
 func (t *TagGroup) UpdateBlockTags(block structure.IBlock, data interface{}) error {
 	var newTags []tags.ITag
 	var err error
@@ -80,6 +208,11 @@ func (t *TagGroup) UpdateBlockTags(block structure.IBlock, data interface{}) err
 			logger.Error(fmt.Sprintf("Failed to create %v tag for block %v", tag.GetKey(), block.GetResourceID()))
 		}
 		if tagVal != nil && tagVal.GetValue() != "" {
+			source := tags.GetSource(tag)
+			if source == "" {
+				source = t.Source
+			}
+			tags.SetSource(tagVal, source)
 			newTags = append(newTags, tagVal)
 		}
 	}
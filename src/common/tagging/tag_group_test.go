@@ -3,6 +3,7 @@ package tagging
 import (
 	"testing"
 
+	"github.com/bridgecrewio/yor/src/common/structure"
 	"github.com/bridgecrewio/yor/src/common/tagging/tags"
 
 	"github.com/stretchr/testify/assert"
@@ -40,6 +41,50 @@ func TestTagGroup(t *testing.T) {
 		assert.Equal(t, 0, len(tgs))
 	})
 
+	t.Run("Test tagGroup skip glob tags match the whole key", func(t *testing.T) {
+		tagGroup := TagGroup{SkippedTags: []string{"git_last_modified_*", "git_modifier?", "trace"}}
+		tagGroup.SetTags([]tags.ITag{
+			&tags.Tag{Key: "yor_trace"},
+			&tags.Tag{Key: "git_commit"},
+			&tags.Tag{Key: "git_modifiers"},
+			&tags.Tag{Key: "git_last_modified_at"},
+			&tags.Tag{Key: "git_last_modified_by"},
+			&tags.Tag{Key: "team.git_modifiers"},
+		})
+		tgs := tagGroup.GetTags()
+		assert.Equal(t, 3, len(tgs))
+		assert.Equal(t, "yor_trace", tgs[0].GetKey())
+		assert.Equal(t, "git_commit", tgs[1].GetKey())
+		assert.Equal(t, "team.git_modifiers", tgs[2].GetKey())
+	})
+
+	t.Run("Test tagGroup compiles the skipped tags once", func(t *testing.T) {
+		tagGroup := TagGroup{SkippedTags: []string{"git_*", "trace"}}
+		tagGroup.SetTags([]tags.ITag{&tags.Tag{Key: "yor_trace"}, &tags.Tag{Key: "git_commit"}})
+		patterns := tagGroup.skippedTagPatterns
+		assert.Equal(t, 2, len(patterns))
+		tagGroup.SetTags([]tags.ITag{&tags.Tag{Key: "git_modifiers"}})
+		assert.Same(t, &patterns[0], &tagGroup.skippedTagPatterns[0])
+		assert.Equal(t, 1, len(tagGroup.GetTags()))
+		assert.Equal(t, "yor_trace", tagGroup.GetTags()[0].GetKey())
+		// trace skipped yor_trace before the skipped tags matched whole keys, which is warned about
+		assert.True(t, patterns[1].legacyRegex.MatchString("yor_trace"))
+		assert.False(t, patterns[1].regex.MatchString("yor_trace"))
+	})
+
+	t.Run("Test tagGroup records the sources of the tag values", func(t *testing.T) {
+		tagGroup := TagGroup{Source: tags.SimpleSource}
+		pluginTag := &tags.Tag{Key: "owner", Value: "platform", Source: tags.CustomSourcePrefix + "OwnerTag"}
+		tagGroup.SetTags([]tags.ITag{&tags.Tag{Key: "env", Value: "dev"}, pluginTag})
+		block := &structure.Block{IsTaggable: true}
+		assert.Nil(t, tagGroup.UpdateBlockTags(block, nil))
+		sources := make(map[string]string)
+		for _, tag := range block.GetNewTags() {
+			sources[tag.GetKey()] = tags.GetSource(tag)
+		}
+		assert.Equal(t, map[string]string{"env": "simple", "owner": "custom:OwnerTag"}, sources)
+	})
+
 	t.Run("Test tag prefix not broke tagGroup skip multi", func(t *testing.T) {
 		tagGroup := TagGroup{SkippedTags: []string{"git*", "yor_trace"}, Options: InitTagGroupOptions{
 			TagPrefix: "prefix_",
@@ -52,4 +97,23 @@ func TestTagGroup(t *testing.T) {
 		tgs := tagGroup.GetTags()
 		assert.Equal(t, 0, len(tgs))
 	})
+
+	t.Run("Test tagGroup filter tags by only keys", func(t *testing.T) {
+		tagGroup := TagGroup{Options: InitTagGroupOptions{TagPrefix: "prefix_"}}
+		tagGroup.SetTags([]tags.ITag{
+			&tags.Tag{Key: "yor_trace"},
+			&tags.Tag{Key: "git_commit"},
+			&tags.Tag{Key: "git_last_modified_at"},
+			&tags.Tag{Key: "git_last_modified_by"},
+		})
+		assert.True(t, tagGroup.FilterTags([]string{"git_commit", "git_last_modified_*"}))
+		tgs := tagGroup.GetTags()
+		assert.Equal(t, 3, len(tgs))
+		assert.Equal(t, "prefix_git_commit", tgs[0].GetKey())
+		assert.Equal(t, "prefix_git_last_modified_at", tgs[1].GetKey())
+		assert.Equal(t, "prefix_git_last_modified_by", tgs[2].GetKey())
+
+		assert.False(t, tagGroup.FilterTags([]string{"yor_trace"}))
+		assert.Equal(t, 0, len(tagGroup.GetTags()))
+	})
 }
@@ -3,11 +3,15 @@ package tags
 import (
 	"fmt"
 	"regexp"
+	"strings"
 )
 
 type Tag struct {
 	Key   string
 	Value string
+	// Source is the tag group or rule which produced the value, see ISourcedTag. It is only reported, so it is kept
+	// out of the tags written to the files.
+	Source string `json:"-" yaml:"-"`
 }
 
 const YorTraceTagKey = "yor_trace"
@@ -16,6 +20,31 @@ const GitModifiersTagKey = "git_modifiers"
 const GitLastModifiedAtTagKey = "git_last_modified_at"
 const GitLastModifiedByTagKey = "git_last_modified_by"
 const GitRepoTagKey = "git_repo"
+const GitCommitTagKey = "git_commit"
+const GitOrgTagKey = "git_org"
+const BackstageOwnerTagKey = "owner"
+const BackstageSystemTagKey = "system"
+const BackstageLifecycleTagKey = "lifecycle"
+const ProvenanceTagKey = "yor_provenance"
+
+// The sources of the tag values, which are reported so reviewers can audit where each value came from
+const GitSource = "git"
+const Code2CloudSource = "code2cloud"
+const SimpleSource = "simple"
+const BackstageSource = "backstage"
+const ProvenanceSource = "provenance"
+const GitRemoteSource = "git_remote"
+const CustomSourcePrefix = "custom:"
+const ExternalSourcePrefix = "external:"
+
+// BuiltInTagKeys are the keys of the tags yor calculates itself, which can be renamed with KeyNames
+var BuiltInTagKeys = []string{YorTraceTagKey, GitCommitTagKey, GitFileTagKey, GitLastModifiedAtTagKey,
+	GitLastModifiedByTagKey, GitModifiersTagKey, GitOrgTagKey, GitRepoTagKey, BackstageOwnerTagKey, BackstageSystemTagKey,
+	BackstageLifecycleTagKey, ProvenanceTagKey}
+
+// KeyNames maps the keys of the built-in tags to the names they are written with, when they are renamed. Each run has
+// its own, so runs which rename the tags differently can run at the same time.
+type KeyNames map[string]string
 
 type ITag interface {
 	Init()
@@ -32,6 +61,19 @@ type TagDiff struct {
 	Key       string
 	PrevValue string
 	NewValue  string
+	Source    string
+}
+
+// IRenamableTag is implemented by the tags whose key can be renamed, such as Tag and the built-in tags which embed it
+type IRenamableTag interface {
+	SetKey(key string)
+}
+
+// ISourcedTag is implemented by the tags which record the source of their value, such as Tag. Tags of plugins which
+// don't implement it are attributed to the tag group which computed them.
+type ISourcedTag interface {
+	GetSource() string
+	SetSource(source string)
 }
 
 func Init(key string, value string) ITag {
@@ -66,6 +108,10 @@ func (t *Tag) GetKey() string {
 	return t.Key
 }
 
+func (t *Tag) SetKey(key string) {
+	t.Key = key
+}
+
 func (t *Tag) SetValue(val string) {
 	t.Value = val
 }
@@ -74,8 +120,79 @@ func (t *Tag) GetValue() string {
 	return t.Value
 }
 
+func (t *Tag) GetSource() string {
+	return t.Source
+}
+
+func (t *Tag) SetSource(source string) {
+	t.Source = source
+}
+
+// GetSource returns the source of the tag's value, or an empty string if it isn't recorded
+func GetSource(tag ITag) string {
+	if sourcedTag, ok := tag.(ISourcedTag); ok {
+		return sourcedTag.GetSource()
+	}
+	return ""
+}
+
+// SetSource records the source of the tag's value, if the tag can record it
+func SetSource(tag ITag, source string) {
+	if sourcedTag, ok := tag.(ISourcedTag); ok {
+		sourcedTag.SetSource(source)
+	}
+}
+
 // IsTagKeyMatch Try to match the tag's key name with a potentially quoted string
 func IsTagKeyMatch(tag ITag, keyName string) bool {
 	match, _ := regexp.Match(fmt.Sprintf(`\b"?%s"?\b`, regexp.QuoteMeta(keyName)), []byte(tag.GetKey()))
 	return match
 }
+
+// ParseKeyNames parses the renames of the built-in tags, given as key=name pairs such as yor_trace=corp:trace-id
+func ParseKeyNames(pairs []string) (KeyNames, error) {
+	names := make(KeyNames)
+	renamedKeys := make(map[string]string)
+	for _, pair := range pairs {
+		key, name, found := strings.Cut(pair, "=")
+		key, name = strings.TrimSpace(key), strings.TrimSpace(name)
+		if !found || key == "" || name == "" {
+			return nil, fmt.Errorf("invalid tag key name %#v, expected a key=name pair", pair)
+		}
+		isBuiltIn := false
+		for _, builtInKey := range BuiltInTagKeys {
+			if key == builtInKey {
+				isBuiltIn = true
+				break
+			}
+		}
+		if !isBuiltIn {
+			return nil, fmt.Errorf("tag %s can't be renamed, only the built-in tags can: %v", key, BuiltInTagKeys)
+		}
+		if otherKey, ok := renamedKeys[name]; ok && otherKey != key {
+			return nil, fmt.Errorf("both %s and %s are renamed to %s", otherKey, key, name)
+		}
+		names[key] = name
+		renamedKeys[name] = key
+	}
+	return names, nil
+}
+
+// Get returns the name the built-in tag with the given key is written with
+func (n KeyNames) Get(key string) string {
+	if name, ok := n[key]; ok {
+		return name
+	}
+	return key
+}
+
+// Rename renames the tag to the name of its key, if it is a renamed built-in tag
+func (n KeyNames) Rename(tag ITag) {
+	renamableTag, ok := tag.(IRenamableTag)
+	if !ok {
+		return
+	}
+	if name := n.Get(tag.GetKey()); name != tag.GetKey() {
+		renamableTag.SetKey(name)
+	}
+}
@@ -0,0 +1,25 @@
+package tags
+
+import "strings"
+
+const (
+	// CaseChanges are changes of a tag value in letter case only, i.e. Platform and platform
+	CaseChanges = "case"
+	// WhitespaceChanges are changes of a tag value in whitespace only, i.e. leading, trailing or repeated spaces
+	WhitespaceChanges = "whitespace"
+)
+
+var ValueChangeKinds = []string{CaseChanges, WhitespaceChanges}
+
+// IsEquivalentValue returns whether the values differ only by the given kinds of changes
+func IsEquivalentValue(value string, otherValue string, ignoredChanges []string) bool {
+	for _, ignoredChange := range ignoredChanges {
+		switch strings.ToLower(ignoredChange) {
+		case CaseChanges:
+			value, otherValue = strings.ToLower(value), strings.ToLower(otherValue)
+		case WhitespaceChanges:
+			value, otherValue = strings.Join(strings.Fields(value), " "), strings.Join(strings.Fields(otherValue), " ")
+		}
+	}
+	return value == otherValue
+}
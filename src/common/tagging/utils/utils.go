@@ -4,6 +4,7 @@ import (
 	"sort"
 
 	"github.com/bridgecrewio/yor/src/common/tagging"
+	"github.com/bridgecrewio/yor/src/common/tagging/backstage"
 	"github.com/bridgecrewio/yor/src/common/tagging/code2cloud"
 	"github.com/bridgecrewio/yor/src/common/tagging/external"
 	"github.com/bridgecrewio/yor/src/common/tagging/gittag"
@@ -17,12 +18,40 @@ const (
 	GitTagGroupName    TagGroupName = "git"
 	Code2Cloud         TagGroupName = "code2cloud"
 	ExternalTagName    TagGroupName = "external"
+	Backstage          TagGroupName = "backstage"
+	Provenance         TagGroupName = "provenance"
+	GitRemote          TagGroupName = "git_remote"
 )
 
 var tagGroupsByName = map[TagGroupName]tagging.ITagGroup{
 	SimpleTagGroupName: &simple.TagGroup{},
 	GitTagGroupName:    &gittag.TagGroup{},
 	Code2Cloud:         &code2cloud.TagGroup{},
+	Backstage:          &backstage.TagGroup{},
+}
+
+// optionalTagGroupsByName are the tag groups which only run when they are selected with --tag-groups
+var optionalTagGroupsByName = map[TagGroupName]tagging.ITagGroup{
+	Provenance: &gittag.ProvenanceTagGroup{},
+	GitRemote:  &gittag.RemoteTagGroup{},
+}
+
+// tagGroupVersions are the versions of the tag groups, which are bumped whenever a tag of the group is renamed or
+// removed, or the format of its value changes, so the consumers of the tags can tell the tags they support. Adding a
+// tag to a group keeps its version.
+var tagGroupVersions = map[TagGroupName]string{
+	SimpleTagGroupName: "1",
+	GitTagGroupName:    "1",
+	Code2Cloud:         "1",
+	ExternalTagName:    "1",
+	Backstage:          "1",
+	Provenance:         "1",
+	GitRemote:          "1",
+}
+
+// GetTagGroupVersion returns the version of the tag group, or an empty string if it isn't a tag group of yor
+func GetTagGroupVersion(name TagGroupName) string {
+	return tagGroupVersions[name]
 }
 
 func TagGroupsByName(name TagGroupName) tagging.ITagGroup {
@@ -34,6 +63,12 @@ func TagGroupsByName(name TagGroupName) tagging.ITagGroup {
 		tagGroup = &gittag.TagGroup{}
 	case Code2Cloud:
 		tagGroup = &code2cloud.TagGroup{}
+	case Backstage:
+		tagGroup = &backstage.TagGroup{}
+	case Provenance:
+		tagGroup = &gittag.ProvenanceTagGroup{}
+	case GitRemote:
+		tagGroup = &gittag.RemoteTagGroup{}
 	case ExternalTagName:
 		tagGroup = &external.TagGroup{}
 	}
@@ -50,3 +85,14 @@ func GetAllTagGroupsNames() []string {
 	tagGroupNames = append(tagGroupNames, string(ExternalTagName)) // Add the external tag name as the last tag group
 	return tagGroupNames
 }
+
+// GetSupportedTagGroupsNames returns the names of all the tag groups, including the optional tag groups which aren't
+// among the default tag groups of GetAllTagGroupsNames
+func GetSupportedTagGroupsNames() []string {
+	var optionalTagGroupNames []string
+	for name := range optionalTagGroupsByName {
+		optionalTagGroupNames = append(optionalTagGroupNames, string(name))
+	}
+	sort.Strings(optionalTagGroupNames)
+	return append(GetAllTagGroupsNames(), optionalTagGroupNames...)
+}
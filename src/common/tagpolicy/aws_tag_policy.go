@@ -0,0 +1,198 @@
+package tagpolicy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bridgecrewio/yor/src/common/logger"
+)
+
+const allSupportedResourceTypes = "ALL_SUPPORTED"
+
+// awsResourceTypes are the Terraform and CloudFormation types of the resource types of the tag policies' enforced_for
+var awsResourceTypes = map[string][]string{
+	"apigateway:restapis":                {"aws_api_gateway_rest_api", "AWS::ApiGateway::RestApi"},
+	"cloudformation:stack":               {"aws_cloudformation_stack", "AWS::CloudFormation::Stack"},
+	"cloudfront:distribution":            {"aws_cloudfront_distribution", "AWS::CloudFront::Distribution"},
+	"dynamodb:table":                     {"aws_dynamodb_table", "AWS::DynamoDB::Table"},
+	"ec2:ALL_SUPPORTED":                  {"aws_instance", "aws_ebs_volume", "aws_vpc*", "aws_subnet", "aws_security_group", "aws_*_gateway", "aws_launch_template", "aws_ec2_*", "AWS::EC2::*"},
+	"ec2:instance":                       {"aws_instance", "AWS::EC2::Instance"},
+	"ec2:internet-gateway":               {"aws_internet_gateway", "AWS::EC2::InternetGateway"},
+	"ec2:launch-template":                {"aws_launch_template", "AWS::EC2::LaunchTemplate"},
+	"ec2:natgateway":                     {"aws_nat_gateway", "AWS::EC2::NatGateway"},
+	"ec2:security-group":                 {"aws_security_group", "AWS::EC2::SecurityGroup"},
+	"ec2:subnet":                         {"aws_subnet", "AWS::EC2::Subnet"},
+	"ec2:volume":                         {"aws_ebs_volume", "AWS::EC2::Volume"},
+	"ec2:vpc":                            {"aws_vpc", "AWS::EC2::VPC"},
+	"ecr:repository":                     {"aws_ecr_repository", "AWS::ECR::Repository"},
+	"ecs:cluster":                        {"aws_ecs_cluster", "AWS::ECS::Cluster"},
+	"ecs:service":                        {"aws_ecs_service", "AWS::ECS::Service"},
+	"ecs:task-definition":                {"aws_ecs_task_definition", "AWS::ECS::TaskDefinition"},
+	"eks:cluster":                        {"aws_eks_cluster", "AWS::EKS::Cluster"},
+	"elasticache:cluster":                {"aws_elasticache_cluster", "AWS::ElastiCache::CacheCluster"},
+	"elasticfilesystem:file-system":      {"aws_efs_file_system", "AWS::EFS::FileSystem"},
+	"elasticloadbalancing:ALL_SUPPORTED": {"aws_lb*", "aws_alb*", "aws_elb", "AWS::ElasticLoadBalancing*"},
+	"elasticloadbalancing:loadbalancer":  {"aws_lb", "aws_alb", "aws_elb", "AWS::ElasticLoadBalancingV2::LoadBalancer", "AWS::ElasticLoadBalancing::LoadBalancer"},
+	"elasticloadbalancing:targetgroup":   {"aws_lb_target_group", "aws_alb_target_group", "AWS::ElasticLoadBalancingV2::TargetGroup"},
+	"iam:role":                           {"aws_iam_role", "AWS::IAM::Role"},
+	"kms:key":                            {"aws_kms_key", "AWS::KMS::Key"},
+	"lambda:function":                    {"aws_lambda_function", "AWS::Lambda::Function"},
+	"logs:ALL_SUPPORTED":                 {"aws_cloudwatch_log_*", "AWS::Logs::*"},
+	"logs:log-group":                     {"aws_cloudwatch_log_group", "AWS::Logs::LogGroup"},
+	"rds:ALL_SUPPORTED":                  {"aws_db_*", "aws_rds_*", "AWS::RDS::*"},
+	"rds:cluster":                        {"aws_rds_cluster", "AWS::RDS::DBCluster"},
+	"rds:db":                             {"aws_db_instance", "AWS::RDS::DBInstance"},
+	"s3:bucket":                          {"aws_s3_bucket", "AWS::S3::Bucket"},
+	"secretsmanager:secret":              {"aws_secretsmanager_secret", "AWS::SecretsManager::Secret"},
+	"sns:topic":                          {"aws_sns_topic", "AWS::SNS::Topic"},
+	"sqs:queue":                          {"aws_sqs_queue", "AWS::SQS::Queue"},
+	"states:stateMachine":                {"aws_sfn_state_machine", "AWS::StepFunctions::StateMachine"},
+}
+
+// awsServiceNamespaces are the CloudFormation namespaces of the services whose Terraform types are aws_<service>_*, for
+// the ALL_SUPPORTED resource types which aren't in awsResourceTypes
+var awsServiceNamespaces = map[string]string{
+	"dynamodb": "DynamoDB",
+	"ecr":      "ECR",
+	"ecs":      "ECS",
+	"eks":      "EKS",
+	"iam":      "IAM",
+	"kms":      "KMS",
+	"lambda":   "Lambda",
+	"s3":       "S3",
+	"sns":      "SNS",
+	"sqs":      "SQS",
+}
+
+// awsTagPolicy is the document of an AWS Organizations tag policy. Its values are either plain, as in effective policies,
+// or set by inheritance operators, as in the policies attached to the organization
+type awsTagPolicy struct {
+	Tags map[string]struct {
+		TagKey      json.RawMessage `json:"tag_key"`
+		TagValue    json.RawMessage `json:"tag_value"`
+		EnforcedFor json.RawMessage `json:"enforced_for"`
+	} `json:"tags"`
+}
+
+// awsPolicyOutput is the output of aws organizations describe-effective-policy or describe-policy, whose content is the
+// policy document as a string
+type awsPolicyOutput struct {
+	EffectivePolicy *struct {
+		PolicyContent string `json:"PolicyContent"`
+	} `json:"EffectivePolicy"`
+	Policy *struct {
+		Content string `json:"Content"`
+	} `json:"Policy"`
+}
+
+// ImportAWSTagPolicy translates an AWS Organizations tag policy to required tags rules. Each tag of the policy requires
+// its key, with the policy's capitalization, and its allowed values on the Terraform and CloudFormation resources of
+// the policy's enforced_for types, or on all the AWS resources if the tag isn't enforced for specific types.
+func ImportAWSTagPolicy(policyPath string) (*RequiredTags, error) {
+	// #nosec G304
+	policyBytes, err := os.ReadFile(policyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the tag policy %s: %s", policyPath, err)
+	}
+	var output awsPolicyOutput
+	if err = json.Unmarshal(policyBytes, &output); err != nil {
+		return nil, fmt.Errorf("failed to parse the tag policy %s: %s", policyPath, err)
+	}
+	if output.EffectivePolicy != nil {
+		policyBytes = []byte(output.EffectivePolicy.PolicyContent)
+	} else if output.Policy != nil {
+		policyBytes = []byte(output.Policy.Content)
+	}
+	var policy awsTagPolicy
+	if err = json.Unmarshal(policyBytes, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse the tag policy %s: %s", policyPath, err)
+	}
+	if len(policy.Tags) == 0 {
+		return nil, fmt.Errorf("the tag policy %s has no tags", policyPath)
+	}
+
+	policyKeys := make([]string, 0, len(policy.Tags))
+	for policyKey := range policy.Tags {
+		policyKeys = append(policyKeys, policyKey)
+	}
+	sort.Strings(policyKeys)
+	requiredTags := &RequiredTags{}
+	for _, policyKey := range policyKeys {
+		policyTag := policy.Tags[policyKey]
+		rule := RequiredTag{Key: policyKey}
+		tagKeys, err := getPolicyValues(policyTag.TagKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag_key of tag %s in the tag policy %s: %s", policyKey, policyPath, err)
+		}
+		if len(tagKeys) > 0 {
+			rule.Key = tagKeys[0]
+		}
+		if rule.AllowedValues, err = getPolicyValues(policyTag.TagValue); err != nil {
+			return nil, fmt.Errorf("invalid tag_value of tag %s in the tag policy %s: %s", policyKey, policyPath, err)
+		}
+		enforcedFor, err := getPolicyValues(policyTag.EnforcedFor)
+		if err != nil {
+			return nil, fmt.Errorf("invalid enforced_for of tag %s in the tag policy %s: %s", policyKey, policyPath, err)
+		}
+		rule.ResourceTypes = translateAWSResourceTypes(rule.Key, enforcedFor)
+		requiredTags.Tags = append(requiredTags.Tags, rule)
+	}
+	return requiredTags, nil
+}
+
+// getPolicyValues returns the string or list of strings of a policy field. Fields set by operators take the values of
+// @@assign and @@append, while the operators controlling the child policies are ignored.
+func getPolicyValues(field json.RawMessage) ([]string, error) {
+	if len(field) == 0 {
+		return nil, nil
+	}
+	var value string
+	if err := json.Unmarshal(field, &value); err == nil {
+		return []string{value}, nil
+	}
+	var values []string
+	if err := json.Unmarshal(field, &values); err == nil {
+		return values, nil
+	}
+	var operators map[string]json.RawMessage
+	if err := json.Unmarshal(field, &operators); err != nil {
+		return nil, fmt.Errorf("expected a string, a list of strings or inheritance operators")
+	}
+	for _, operator := range []string{"@@assign", "@@append"} {
+		if operatorField, ok := operators[operator]; ok {
+			operatorValues, err := getPolicyValues(operatorField)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, operatorValues...)
+		}
+	}
+	return values, nil
+}
+
+// translateAWSResourceTypes returns the Terraform and CloudFormation types of the enforced_for resource types. The
+// types which can't be translated are logged and kept as they are, so they can be replaced in the rules by hand.
+func translateAWSResourceTypes(key string, enforcedFor []string) []string {
+	if len(enforcedFor) == 0 {
+		return []string{"aws_*", "AWS::*"}
+	}
+	var resourceTypes []string
+	for _, awsType := range enforcedFor {
+		if iacTypes, ok := awsResourceTypes[awsType]; ok {
+			resourceTypes = append(resourceTypes, iacTypes...)
+			continue
+		}
+		service, resource, _ := strings.Cut(awsType, ":")
+		if namespace, ok := awsServiceNamespaces[service]; ok && resource == allSupportedResourceTypes {
+			resourceTypes = append(resourceTypes, fmt.Sprintf("aws_%s_*", service), fmt.Sprintf("AWS::%s::*", namespace))
+			continue
+		}
+		logger.Warning(fmt.Sprintf("Tag %s is enforced for %s, which has no known Terraform or CloudFormation types, please replace it in the resource_types of the rule", key, awsType))
+		// an untranslated type matches no resources, rather than dropping the rule or applying it to all the resources
+		resourceTypes = append(resourceTypes, awsType)
+	}
+	return resourceTypes
+}
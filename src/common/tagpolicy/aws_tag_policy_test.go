@@ -0,0 +1,41 @@
+package tagpolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImportAWSTagPolicy(t *testing.T) {
+	t.Run("policy with inheritance operators", func(t *testing.T) {
+		requiredTags, err := ImportAWSTagPolicy("../../../tests/tag_policy/aws_tag_policy.json")
+		assert.Nil(t, err)
+		assert.Equal(t, []RequiredTag{
+			{
+				Key:           "CostCenter",
+				AllowedValues: []string{"100", "200", "300*"},
+				ResourceTypes: []string{"aws_instance", "AWS::EC2::Instance", "aws_s3_bucket", "AWS::S3::Bucket", "ec2:dhcp-options"},
+			},
+			{
+				Key:           "Environment",
+				AllowedValues: []string{"prod", "dev"},
+				ResourceTypes: []string{"aws_*", "AWS::*"},
+			},
+		}, requiredTags.Tags)
+	})
+
+	t.Run("effective policy", func(t *testing.T) {
+		requiredTags, err := ImportAWSTagPolicy("../../../tests/tag_policy/aws_effective_tag_policy.json")
+		assert.Nil(t, err)
+		assert.Equal(t, []RequiredTag{
+			{Key: "Owner", ResourceTypes: []string{"aws_lambda_*", "AWS::Lambda::*"}},
+		}, requiredTags.Tags)
+	})
+
+	t.Run("policy without tags", func(t *testing.T) {
+		_, err := ImportAWSTagPolicy("../../../tests/tag_policy/missing.json")
+		assert.NotNil(t, err)
+		_, err = ImportAWSTagPolicy("../../../tests/external_tags/lookup/cmdb.json")
+		assert.EqualError(t, err, "the tag policy ../../../tests/external_tags/lookup/cmdb.json has no tags")
+	})
+}
@@ -0,0 +1,149 @@
+package tagpolicy
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/bridgecrewio/yor/src/common/structure"
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+	"github.com/bridgecrewio/yor/src/common/utils"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+var allowedSeverities = []string{SeverityError, SeverityWarning, SeverityInfo}
+
+// RequiredTag requires the resources of its types to have a tag, whose value is one of the allowed values if any are
+// set. The allowed values and resource types may use * as a wildcard, and a rule without resource types applies to all
+// the taggable resources. Only the violations of rules with the error severity, the default one, fail the run, so new
+// required tags can be rolled out as warnings first.
+type RequiredTag struct {
+	Key           string   `yaml:"key"`
+	AllowedValues []string `yaml:"allowed_values,omitempty"`
+	ResourceTypes []string `yaml:"resource_types,omitempty"`
+	Severity      string   `yaml:"severity,omitempty"`
+}
+
+type RequiredTags struct {
+	Tags []RequiredTag `yaml:"required_tags"`
+}
+
+// Violation is a required tag which a resource is missing, or whose key or value doesn't comply with its rule
+type Violation struct {
+	TagKey   string
+	Reason   string
+	Severity string
+}
+
+// LoadRequiredTags reads the required tags rules file
+func LoadRequiredTags(rulesPath string) (*RequiredTags, error) {
+	// #nosec G304
+	rulesBytes, err := os.ReadFile(rulesPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the required tags file %s: %s", rulesPath, err)
+	}
+	requiredTags := &RequiredTags{}
+	if err = yaml.Unmarshal(rulesBytes, requiredTags); err != nil {
+		return nil, fmt.Errorf("failed to parse the required tags file %s: %s", rulesPath, err)
+	}
+	for _, rule := range requiredTags.Tags {
+		if rule.Key == "" {
+			return nil, fmt.Errorf("the required tags file %s has a rule without a key", rulesPath)
+		}
+		if !utils.InSlice(allowedSeverities, rule.GetSeverity()) {
+			return nil, fmt.Errorf("the required tags file %s has a rule of %s with an invalid severity %s, expected one of %s", rulesPath, rule.Key, rule.Severity, strings.Join(allowedSeverities, ", "))
+		}
+	}
+	return requiredTags, nil
+}
+
+// Write writes the rules to a YAML file, which can be passed to yor tag with --required-tags
+func (r *RequiredTags) Write(rulesPath string) error {
+	rulesBytes, err := yaml.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(rulesPath, rulesBytes, 0600); err != nil {
+		return fmt.Errorf("failed to write the required tags to %s: %s", rulesPath, err)
+	}
+	return nil
+}
+
+// Validate returns the violations of the rules by the tags of the block, including the tags yor adds to it. Tag keys
+// are matched case-insensitively, so a key with a different capitalization is reported as such rather than missing.
+func (r *RequiredTags) Validate(block structure.IBlock) []Violation {
+	if r == nil || !block.IsBlockTaggable() {
+		return nil
+	}
+	var violations []Violation
+	blockTags := block.MergeTags()
+	for _, rule := range r.Tags {
+		if !rule.appliesTo(block.GetResourceType()) {
+			continue
+		}
+		var blockTag tags.ITag
+		for _, tag := range blockTags {
+			if strings.EqualFold(tag.GetKey(), rule.Key) {
+				blockTag = tag
+				break
+			}
+		}
+		switch {
+		case blockTag == nil:
+			violations = append(violations, Violation{TagKey: rule.Key, Reason: "missing", Severity: rule.GetSeverity()})
+		case blockTag.GetKey() != rule.Key:
+			violations = append(violations, Violation{TagKey: rule.Key, Reason: fmt.Sprintf("key is capitalized as %s", blockTag.GetKey()), Severity: rule.GetSeverity()})
+		case !rule.isAllowedValue(blockTag.GetValue()):
+			violations = append(violations, Violation{TagKey: rule.Key, Reason: fmt.Sprintf("value %s is not one of %s", blockTag.GetValue(), strings.Join(rule.AllowedValues, ", ")), Severity: rule.GetSeverity()})
+		}
+	}
+	return violations
+}
+
+// GetSeverity returns the severity of the violations of the rule, which is error unless the rule sets another one
+func (rule *RequiredTag) GetSeverity() string {
+	if rule.Severity == "" {
+		return SeverityError
+	}
+	return strings.ToLower(rule.Severity)
+}
+
+func (rule *RequiredTag) appliesTo(resourceType string) bool {
+	if len(rule.ResourceTypes) == 0 {
+		return true
+	}
+	for _, pattern := range rule.ResourceTypes {
+		if isWildcardMatch(pattern, resourceType) {
+			return true
+		}
+	}
+	return false
+}
+
+func (rule *RequiredTag) isAllowedValue(value string) bool {
+	if len(rule.AllowedValues) == 0 {
+		return true
+	}
+	for _, pattern := range rule.AllowedValues {
+		if isWildcardMatch(pattern, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// isWildcardMatch matches the whole string to the pattern, in which * is the only wildcard, as in tag policies
+func isWildcardMatch(pattern string, str string) bool {
+	quotedParts := strings.Split(pattern, "*")
+	for i, part := range quotedParts {
+		quotedParts[i] = regexp.QuoteMeta(part)
+	}
+	return regexp.MustCompile("^" + strings.Join(quotedParts, ".*") + "$").MatchString(str)
+}
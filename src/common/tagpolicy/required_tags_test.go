@@ -0,0 +1,59 @@
+package tagpolicy
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/bridgecrewio/yor/src/common/structure"
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequiredTags(t *testing.T) {
+	requiredTags := &RequiredTags{Tags: []RequiredTag{
+		{Key: "CostCenter", AllowedValues: []string{"100", "300*"}, ResourceTypes: []string{"aws_instance", "AWS::EC2::*"}},
+		{Key: "Environment", Severity: SeverityWarning},
+	}}
+
+	t.Run("violations of the tags of the resource, including the new ones", func(t *testing.T) {
+		block := &structure.Block{
+			Type:        "aws_instance",
+			IsTaggable:  true,
+			ExitingTags: []tags.ITag{&tags.Tag{Key: "CostCenter", Value: "200"}},
+		}
+		assert.Equal(t, []Violation{
+			{TagKey: "CostCenter", Reason: "value 200 is not one of 100, 300*", Severity: SeverityError},
+			{TagKey: "Environment", Reason: "missing", Severity: SeverityWarning},
+		}, requiredTags.Validate(block))
+
+		block.ExitingTags = []tags.ITag{&tags.Tag{Key: "CostCenter", Value: "3001"}}
+		block.NewTags = []tags.ITag{&tags.Tag{Key: "environment", Value: "prod"}}
+		assert.Equal(t, []Violation{
+			{TagKey: "Environment", Reason: "key is capitalized as environment", Severity: SeverityWarning},
+		}, requiredTags.Validate(block))
+	})
+
+	t.Run("rules apply to their resource types", func(t *testing.T) {
+		block := &structure.Block{Type: "aws_s3_bucket", IsTaggable: true, ExitingTags: []tags.ITag{&tags.Tag{Key: "Environment", Value: "prod"}}}
+		assert.Empty(t, requiredTags.Validate(block))
+		block.Type = "AWS::EC2::Volume"
+		assert.Equal(t, []Violation{{TagKey: "CostCenter", Reason: "missing", Severity: SeverityError}}, requiredTags.Validate(block))
+		block.IsTaggable = false
+		assert.Empty(t, requiredTags.Validate(block))
+	})
+
+	t.Run("rules file round trip", func(t *testing.T) {
+		rulesPath := filepath.Join(t.TempDir(), "required-tags.yml")
+		assert.Nil(t, requiredTags.Write(rulesPath))
+		loadedTags, err := LoadRequiredTags(rulesPath)
+		assert.Nil(t, err)
+		assert.Equal(t, requiredTags, loadedTags)
+	})
+
+	t.Run("rules file with an invalid severity", func(t *testing.T) {
+		rulesPath := filepath.Join(t.TempDir(), "required-tags.yml")
+		assert.Nil(t, (&RequiredTags{Tags: []RequiredTag{{Key: "Owner", Severity: "critical"}}}).Write(rulesPath))
+		_, err := LoadRequiredTags(rulesPath)
+		assert.NotNil(t, err)
+	})
+}
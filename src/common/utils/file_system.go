@@ -0,0 +1,533 @@
+package utils
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileSystem reads and writes the IaC files which are parsed and tagged. It is the file system of the OS, unless
+// SetFileSystem replaces it, i.e. with a MemoryFileSystem where there is no file system (the browser), or with the
+// fs.FS of an archive or a git tree (see NewIOFileSystem).
+type FileSystem interface {
+	ReadFile(name string) ([]byte, error)
+	// WriteFile writes the file, fully or not at all
+	WriteFile(name string, data []byte) error
+	// CreateTemp creates an empty file in the directory, whose name is the pattern with its last * replaced by a random
+	// string, like os.CreateTemp
+	CreateTemp(dir string, pattern string) (string, error)
+	Remove(name string) error
+	Stat(name string) (fs.FileInfo, error)
+	ReadDir(name string) ([]fs.DirEntry, error)
+}
+
+// SymlinkFileSystem is implemented by file systems with symlinks, like the file system of the OS. The symlinks of
+// other file systems are files, if they have any.
+type SymlinkFileSystem interface {
+	Lstat(name string) (fs.FileInfo, error)
+	EvalSymlinks(name string) (string, error)
+}
+
+var fileSystem FileSystem = OSFileSystem{}
+
+func SetFileSystem(fileSys FileSystem) {
+	fileSystem = fileSys
+}
+
+func GetFileSystem() FileSystem {
+	return fileSystem
+}
+
+// mounts are the file systems mounted by Mount, by the directories they are mounted at
+var mounts = map[string]FileSystem{}
+var mountsLock sync.RWMutex
+
+// Mount mounts the file system at the directory, so the files under the directory are the files of the file system,
+// by their paths relative to the directory, until it is unmounted. Unlike SetFileSystem, the file systems mounted at
+// different directories are used concurrently, i.e. to tag the files of several in-memory file systems at once.
+func Mount(dir string, fileSys FileSystem) (unmount func()) {
+	dir = filepath.Clean(dir)
+	mountsLock.Lock()
+	defer mountsLock.Unlock()
+	mounts[dir] = &mountedFileSystem{dir: dir, fileSys: fileSys}
+	return func() {
+		mountsLock.Lock()
+		defer mountsLock.Unlock()
+		delete(mounts, dir)
+	}
+}
+
+// getFileSystem returns the file system the file is read from and written to, which is the file system mounted at a
+// directory of the file, if any
+func getFileSystem(name string) FileSystem {
+	mountsLock.RLock()
+	defer mountsLock.RUnlock()
+	if len(mounts) == 0 {
+		return fileSystem
+	}
+	name = filepath.Clean(name)
+	for dir, fileSys := range mounts {
+		if name == dir || isInDir(name, dir) {
+			return fileSys
+		}
+	}
+	return fileSystem
+}
+
+func ReadFile(name string) ([]byte, error) {
+	return getFileSystem(name).ReadFile(name)
+}
+
+func WriteFile(name string, data []byte) error {
+	return getFileSystem(name).WriteFile(name, data)
+}
+
+func CreateTemp(dir string, pattern string) (string, error) {
+	return getFileSystem(dir).CreateTemp(dir, pattern)
+}
+
+func Remove(name string) error {
+	return getFileSystem(name).Remove(name)
+}
+
+func Stat(name string) (fs.FileInfo, error) {
+	return getFileSystem(name).Stat(name)
+}
+
+func ReadDir(name string) ([]fs.DirEntry, error) {
+	return getFileSystem(name).ReadDir(name)
+}
+
+// Lstat returns the info of the file, which describes the symlink rather than the file it points to if it is one
+func Lstat(name string) (fs.FileInfo, error) {
+	fileSys := getFileSystem(name)
+	if symlinkFileSystem, ok := fileSys.(SymlinkFileSystem); ok {
+		return symlinkFileSystem.Lstat(name)
+	}
+	return fileSys.Stat(name)
+}
+
+// RealPath returns the absolute path of the file with all symlinks resolved, or an empty string if it can't be resolved
+func RealPath(name string) string {
+	resolved := name
+	fileSys := getFileSystem(name)
+	if symlinkFileSystem, ok := fileSys.(SymlinkFileSystem); ok {
+		var err error
+		if resolved, err = symlinkFileSystem.EvalSymlinks(name); err != nil {
+			return ""
+		}
+	} else if _, err := fileSys.Stat(name); err != nil {
+		return ""
+	}
+	absPath, err := filepath.Abs(resolved)
+	if err != nil {
+		return ""
+	}
+	return absPath
+}
+
+// WalkDir walks the file tree of the root like filepath.WalkDir, in the file system
+func WalkDir(root string, fn fs.WalkDirFunc) error {
+	info, err := Lstat(root)
+	if err != nil {
+		err = fn(root, nil, err)
+	} else {
+		err = walkDir(root, fs.FileInfoToDirEntry(info), fn)
+	}
+	if err == filepath.SkipDir {
+		return nil
+	}
+	return err
+}
+
+func walkDir(path string, entry fs.DirEntry, fn fs.WalkDirFunc) error {
+	if err := fn(path, entry, nil); err != nil || !entry.IsDir() {
+		if err == filepath.SkipDir && entry.IsDir() {
+			err = nil
+		}
+		return err
+	}
+	entries, err := ReadDir(path)
+	if err != nil {
+		// the directory is reported again with the error, so it can be skipped
+		if err = fn(path, entry, err); err != nil {
+			if err == filepath.SkipDir {
+				err = nil
+			}
+			return err
+		}
+	}
+	for _, child := range entries {
+		if err := walkDir(filepath.Join(path, child.Name()), child, fn); err != nil {
+			if err == filepath.SkipDir {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// OSFileSystem is the file system of the OS
+type OSFileSystem struct{}
+
+func (OSFileSystem) ReadFile(name string) ([]byte, error) {
+	// #nosec G304
+	return os.ReadFile(name)
+}
+
+func (OSFileSystem) WriteFile(name string, data []byte) error {
+	return WriteFileAtomically(name, data)
+}
+
+func (OSFileSystem) CreateTemp(dir string, pattern string) (string, error) {
+	tempFile, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return "", err
+	}
+	// can't delete files on windows if you dont close them
+	return tempFile.Name(), tempFile.Close()
+}
+
+func (OSFileSystem) Remove(name string) error {
+	return os.Remove(name)
+}
+
+func (OSFileSystem) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (OSFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+func (OSFileSystem) Lstat(name string) (fs.FileInfo, error) {
+	return os.Lstat(name)
+}
+
+func (OSFileSystem) EvalSymlinks(name string) (string, error) {
+	return filepath.EvalSymlinks(name)
+}
+
+// MemoryFileSystem keeps the files in memory. The directories are the ones of its files.
+type MemoryFileSystem struct {
+	files     map[string][]byte
+	lock      sync.RWMutex
+	tempFiles int
+}
+
+func NewMemoryFileSystem(files map[string][]byte) *MemoryFileSystem {
+	m := &MemoryFileSystem{files: make(map[string][]byte)}
+	for name, data := range files {
+		_ = m.WriteFile(name, data)
+	}
+	return m
+}
+
+func (m *MemoryFileSystem) ReadFile(name string) ([]byte, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	data, ok := m.files[filepath.Clean(name)]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (m *MemoryFileSystem) WriteFile(name string, data []byte) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.files[filepath.Clean(name)] = append([]byte(nil), data...)
+	return nil
+}
+
+func (m *MemoryFileSystem) CreateTemp(dir string, pattern string) (string, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	prefix, suffix := pattern, ""
+	if i := strings.LastIndex(pattern, "*"); i >= 0 {
+		prefix, suffix = pattern[:i], pattern[i+1:]
+	}
+	for {
+		m.tempFiles++
+		name := filepath.Join(dir, fmt.Sprintf("%s%d%s", prefix, m.tempFiles, suffix))
+		if _, ok := m.files[name]; !ok {
+			m.files[name] = []byte{}
+			return name, nil
+		}
+	}
+}
+
+func (m *MemoryFileSystem) Remove(name string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if _, ok := m.files[filepath.Clean(name)]; !ok {
+		return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrNotExist}
+	}
+	delete(m.files, filepath.Clean(name))
+	return nil
+}
+
+func (m *MemoryFileSystem) Stat(name string) (fs.FileInfo, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	name = filepath.Clean(name)
+	if data, ok := m.files[name]; ok {
+		return memoryFileInfo{name: filepath.Base(name), size: int64(len(data))}, nil
+	}
+	for file := range m.files {
+		if isInDir(file, name) {
+			return memoryFileInfo{name: filepath.Base(name), isDir: true}, nil
+		}
+	}
+	return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+}
+
+func (m *MemoryFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	name = filepath.Clean(name)
+	entries := make(map[string]memoryFileInfo)
+	for file, data := range m.files {
+		if !isInDir(file, name) {
+			continue
+		}
+		relativePath, _ := filepath.Rel(name, file)
+		parts := strings.SplitN(filepath.ToSlash(relativePath), "/", 2)
+		entries[parts[0]] = memoryFileInfo{name: parts[0], size: int64(len(data)), isDir: len(parts) > 1}
+	}
+	if len(entries) == 0 {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	dirEntries := make([]fs.DirEntry, 0, len(entries))
+	for _, info := range entries {
+		if info.isDir {
+			info.size = 0
+		}
+		dirEntries = append(dirEntries, fs.FileInfoToDirEntry(info))
+	}
+	sort.Slice(dirEntries, func(i, j int) bool {
+		return dirEntries[i].Name() < dirEntries[j].Name()
+	})
+	return dirEntries, nil
+}
+
+func isInDir(file string, dir string) bool {
+	relativePath, err := filepath.Rel(dir, file)
+	return err == nil && relativePath != "." && relativePath != ".." && !strings.HasPrefix(relativePath, ".."+string(filepath.Separator))
+}
+
+type memoryFileInfo struct {
+	name  string
+	size  int64
+	isDir bool
+}
+
+func (i memoryFileInfo) Name() string {
+	return i.name
+}
+
+func (i memoryFileInfo) Size() int64 {
+	return i.size
+}
+
+func (i memoryFileInfo) Mode() fs.FileMode {
+	if i.isDir {
+		return fs.ModeDir | 0700
+	}
+	return 0600
+}
+
+func (i memoryFileInfo) ModTime() time.Time {
+	return time.Time{}
+}
+
+func (i memoryFileInfo) IsDir() bool {
+	return i.isDir
+}
+
+func (i memoryFileInfo) Sys() interface{} {
+	return nil
+}
+
+// IOFileSystem is a read only FileSystem of an fs.FS, i.e. of a zip archive (zip.Reader) or of a git tree, so they can
+// be scanned without being extracted. Their files can't be written, so they are scanned with --dry-run.
+type IOFileSystem struct {
+	fsys fs.FS
+}
+
+func NewIOFileSystem(fsys fs.FS) *IOFileSystem {
+	return &IOFileSystem{fsys: fsys}
+}
+
+// ioPath returns the path of the file in the fs.FS, which is unrooted and slash separated. The root of the fs.FS is
+// both the current directory and the root directory of the file system.
+func ioPath(name string) string {
+	return strings.TrimPrefix(filepath.ToSlash(filepath.Clean(name)), "/")
+}
+
+func (i *IOFileSystem) ReadFile(name string) ([]byte, error) {
+	return fs.ReadFile(i.fsys, ioPath(name))
+}
+
+func (i *IOFileSystem) WriteFile(name string, _ []byte) error {
+	return &fs.PathError{Op: "write", Path: name, Err: fs.ErrPermission}
+}
+
+func (i *IOFileSystem) CreateTemp(dir string, _ string) (string, error) {
+	return "", &fs.PathError{Op: "createtemp", Path: dir, Err: fs.ErrPermission}
+}
+
+func (i *IOFileSystem) Remove(name string) error {
+	return &fs.PathError{Op: "remove", Path: name, Err: fs.ErrPermission}
+}
+
+func (i *IOFileSystem) Stat(name string) (fs.FileInfo, error) {
+	return fs.Stat(i.fsys, ioPath(name))
+}
+
+func (i *IOFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	return fs.ReadDir(i.fsys, ioPath(name))
+}
+
+// mountedFileSystem is a file system mounted at a directory, whose files are given their paths relative to it
+type mountedFileSystem struct {
+	dir     string
+	fileSys FileSystem
+}
+
+func (m *mountedFileSystem) relPath(name string) string {
+	relPath, err := filepath.Rel(m.dir, name)
+	if err != nil {
+		return name
+	}
+	return relPath
+}
+
+func (m *mountedFileSystem) ReadFile(name string) ([]byte, error) {
+	return m.fileSys.ReadFile(m.relPath(name))
+}
+
+func (m *mountedFileSystem) WriteFile(name string, data []byte) error {
+	return m.fileSys.WriteFile(m.relPath(name), data)
+}
+
+func (m *mountedFileSystem) CreateTemp(dir string, pattern string) (string, error) {
+	name, err := m.fileSys.CreateTemp(m.relPath(dir), pattern)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(m.dir, name), nil
+}
+
+func (m *mountedFileSystem) Remove(name string) error {
+	return m.fileSys.Remove(m.relPath(name))
+}
+
+func (m *mountedFileSystem) Stat(name string) (fs.FileInfo, error) {
+	return m.fileSys.Stat(m.relPath(name))
+}
+
+func (m *mountedFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	return m.fileSys.ReadDir(m.relPath(name))
+}
+
+// OverlayFileSystem reads the files of a base file system, while the files written to it, its temporary files and its
+// removals are kept in memory, so the base file system is never written. The written files are read back from memory.
+type OverlayFileSystem struct {
+	base    FileSystem
+	upper   *MemoryFileSystem
+	removed map[string]bool
+	lock    sync.RWMutex
+}
+
+func NewOverlayFileSystem(base FileSystem) *OverlayFileSystem {
+	return &OverlayFileSystem{base: base, upper: NewMemoryFileSystem(nil), removed: make(map[string]bool)}
+}
+
+func (o *OverlayFileSystem) isRemoved(name string) bool {
+	o.lock.RLock()
+	defer o.lock.RUnlock()
+	return o.removed[filepath.Clean(name)]
+}
+
+func (o *OverlayFileSystem) ReadFile(name string) ([]byte, error) {
+	if o.isRemoved(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	if data, err := o.upper.ReadFile(name); err == nil {
+		return data, nil
+	}
+	return o.base.ReadFile(name)
+}
+
+func (o *OverlayFileSystem) WriteFile(name string, data []byte) error {
+	o.lock.Lock()
+	delete(o.removed, filepath.Clean(name))
+	o.lock.Unlock()
+	return o.upper.WriteFile(name, data)
+}
+
+func (o *OverlayFileSystem) CreateTemp(dir string, pattern string) (string, error) {
+	name, err := o.upper.CreateTemp(dir, pattern)
+	if err != nil {
+		return "", err
+	}
+	o.lock.Lock()
+	delete(o.removed, filepath.Clean(name))
+	o.lock.Unlock()
+	return name, nil
+}
+
+func (o *OverlayFileSystem) Remove(name string) error {
+	upperErr := o.upper.Remove(name)
+	// the file of the base file system would show through once the written file is removed
+	if info, err := o.base.Stat(name); err == nil && !info.IsDir() && !o.isRemoved(name) {
+		o.lock.Lock()
+		o.removed[filepath.Clean(name)] = true
+		o.lock.Unlock()
+		return nil
+	}
+	return upperErr
+}
+
+func (o *OverlayFileSystem) Stat(name string) (fs.FileInfo, error) {
+	if o.isRemoved(name) {
+		return nil, &fs.PathError{Op: "stat", Path: name, Err: fs.ErrNotExist}
+	}
+	if info, err := o.upper.Stat(name); err == nil {
+		return info, nil
+	}
+	return o.base.Stat(name)
+}
+
+func (o *OverlayFileSystem) ReadDir(name string) ([]fs.DirEntry, error) {
+	baseEntries, baseErr := o.base.ReadDir(name)
+	upperEntries, upperErr := o.upper.ReadDir(name)
+	if baseErr != nil && upperErr != nil {
+		return nil, baseErr
+	}
+	entries := make(map[string]fs.DirEntry)
+	for _, entry := range baseEntries {
+		if !entry.IsDir() && o.isRemoved(filepath.Join(name, entry.Name())) {
+			continue
+		}
+		entries[entry.Name()] = entry
+	}
+	for _, entry := range upperEntries {
+		entries[entry.Name()] = entry
+	}
+	dirEntries := make([]fs.DirEntry, 0, len(entries))
+	for _, entry := range entries {
+		dirEntries = append(dirEntries, entry)
+	}
+	sort.Slice(dirEntries, func(i, j int) bool {
+		return dirEntries[i].Name() < dirEntries[j].Name()
+	})
+	return dirEntries, nil
+}
@@ -2,11 +2,13 @@ package utils
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
 	"unicode"
 
@@ -78,13 +80,12 @@ func convertToInterfaceSlice(origin interface{}) []interface{} {
 }
 
 func GetFileScanner(filePath string, nonFoundLines *structure.Lines) (*bufio.Scanner, *structure.Lines) {
-	//#nosec G304
-	file, err := os.Open(filePath)
+	src, err := ReadFile(filePath)
 	if err != nil {
 		logger.Warning(fmt.Sprintf("failed to read file %s", filePath))
 		return nil, nonFoundLines
 	}
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(bytes.NewReader(src))
 	return scanner, nonFoundLines
 }
 
@@ -94,9 +95,7 @@ func GetFileFormat(filePath string) string {
 		return ""
 	}
 	if strings.HasSuffix(filePath, common.CFTFileType.Extension) {
-		absFilePath, _ := filepath.Abs(filePath)
-		// #nosec G304 - file is from user
-		content, _ := os.ReadFile(absFilePath)
+		content, _ := ReadFile(filePath)
 		if strings.HasPrefix(string(content), "{") {
 			return common.JSONFileType.FileFormat
 		}
@@ -109,6 +108,46 @@ func GetLinesFromBytes(bytes []byte) []string {
 	return strings.Split(string(bytes), "\n")
 }
 
+// IsBinaryFile checks if the beginning of the file contains a NUL byte, which text files don't
+func IsBinaryFile(filePath string) bool {
+	src, err := ReadFile(filePath)
+	if err != nil {
+		return false
+	}
+	if len(src) > 8000 {
+		src = src[:8000]
+	}
+	return bytes.IndexByte(src, 0) != -1
+}
+
+// MatchLineEndings converts the LF line endings of the lines added to the original content to CRLF if the original
+// content used them, so rewriting a file created on Windows doesn't result in mixed line endings. Lines of the
+// original content which end with LF keep it.
+func MatchLineEndings(original []byte, updated []byte) []byte {
+	if !bytes.Contains(original, []byte("\r\n")) {
+		return updated
+	}
+	lfLines := map[string]int{}
+	for _, line := range bytes.SplitAfter(original, []byte("\n")) {
+		if bytes.HasSuffix(line, []byte("\n")) && !bytes.HasSuffix(line, []byte("\r\n")) {
+			lfLines[string(line)]++
+		}
+	}
+	result := make([]byte, 0, len(updated))
+	for _, line := range bytes.SplitAfter(updated, []byte("\n")) {
+		if !bytes.HasSuffix(line, []byte("\n")) || bytes.HasSuffix(line, []byte("\r\n")) {
+			result = append(result, line...)
+		} else if lfLines[string(line)] > 0 {
+			lfLines[string(line)]--
+			result = append(result, line...)
+		} else {
+			result = append(result, line[:len(line)-1]...)
+			result = append(result, "\r\n"...)
+		}
+	}
+	return result
+}
+
 func StructContainsProperty(s interface{}, property string) (bool, reflect.Value) {
 	var field reflect.Value
 	sValue := reflect.ValueOf(s)
@@ -182,3 +221,94 @@ func GetEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// TextEdit replaces the source between Start and End with Text
+type TextEdit struct {
+	Start int
+	End   int
+	Text  string
+}
+
+// ApplyTextEdits applies non overlapping edits to the source
+func ApplyTextEdits(src string, edits []TextEdit) string {
+	sorted := make([]TextEdit, len(edits))
+	copy(sorted, edits)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Start < sorted[j].Start
+	})
+	var builder strings.Builder
+	lastIndex := 0
+	for _, edit := range sorted {
+		builder.WriteString(src[lastIndex:edit.Start])
+		builder.WriteString(edit.Text)
+		lastIndex = edit.End
+	}
+	builder.WriteString(src[lastIndex:])
+	return builder.String()
+}
+
+// WriteFileAtomically writes the data to a temporary file next to the file, syncs it and renames it over the file, so
+// the file is either left as it was or fully written, even if yor is killed while writing it. The file keeps its
+// permissions, and a symlink is replaced through the file it points to.
+func WriteFileAtomically(filePath string, data []byte) error {
+	mode := os.FileMode(0600)
+	if realPath, err := filepath.EvalSymlinks(filePath); err == nil {
+		filePath = realPath
+	}
+	if info, err := os.Stat(filePath); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tempFile, err := os.CreateTemp(filepath.Dir(filePath), fmt.Sprintf(".%s.yor-*.tmp", filepath.Base(filePath)))
+	if err != nil {
+		return err
+	}
+	// the temporary file is removed unless it was renamed
+	defer func() {
+		_ = os.Remove(tempFile.Name())
+	}()
+	if _, err = tempFile.Write(data); err != nil {
+		_ = tempFile.Close()
+		return err
+	}
+	if err = tempFile.Sync(); err != nil {
+		_ = tempFile.Close()
+		return err
+	}
+	if err = tempFile.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tempFile.Name(), mode); err != nil {
+		return err
+	}
+	if err = os.Rename(tempFile.Name(), filePath); err != nil {
+		return err
+	}
+	syncDir(filepath.Dir(filePath))
+	return nil
+}
+
+// syncDir persists the rename of a file in the directory. Directories can't be synced on every OS (i.e. Windows), so
+// failures are ignored.
+func syncDir(dir string) {
+	// #nosec G304
+	d, err := os.Open(dir)
+	if err != nil {
+		return
+	}
+	_ = d.Sync()
+	_ = d.Close()
+}
+
+const BackupFileSuffix = ".orig"
+
+// BackupFile copies the file to <file>.orig, replacing the backup of a previous run
+func BackupFile(filePath string) error {
+	src, err := ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	if err = WriteFile(filePath+BackupFileSuffix, src); err != nil {
+		return fmt.Errorf("failed to back up %s: %s", filePath, err)
+	}
+	return nil
+}
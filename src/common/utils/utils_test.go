@@ -1,9 +1,13 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -183,6 +187,18 @@ func TestGetEnv(t *testing.T) {
 	})
 }
 
+func TestMatchLineEndings(t *testing.T) {
+	t.Run("keep LF line endings", func(t *testing.T) {
+		assert.Equal(t, "a\nb\n", string(MatchLineEndings([]byte("a\n"), []byte("a\nb\n"))))
+	})
+	t.Run("convert mixed line endings to CRLF", func(t *testing.T) {
+		assert.Equal(t, "a\r\nb\r\nc", string(MatchLineEndings([]byte("a\r\n"), []byte("a\r\nb\nc"))))
+	})
+	t.Run("keep the LF line endings of the original lines", func(t *testing.T) {
+		assert.Equal(t, "a\r\nb\nnew\r\nc\r\nb\n", string(MatchLineEndings([]byte("a\r\nb\nc\r\nb\n"), []byte("a\r\nb\nnew\nc\nb\n"))))
+	})
+}
+
 func TestAllNil(t *testing.T) {
 	t.Run("TestCheckForInterfaceWithString", func(t *testing.T) {
 		i := []string{"bla"}
@@ -197,3 +213,198 @@ func TestAllNil(t *testing.T) {
 		assert.Equal(t, true, AllNil(i))
 	})
 }
+
+func TestApplyTextEdits(t *testing.T) {
+	src := "{\"a\": 1}"
+	edited := ApplyTextEdits(src, []TextEdit{{Start: 7, End: 7, Text: ", \"b\": 2"}, {Start: 6, End: 7, Text: "3"}})
+	assert.Equal(t, "{\"a\": 3, \"b\": 2}", edited)
+}
+
+func TestWriteFileAtomically(t *testing.T) {
+	t.Run("replace the file and keep its permissions", func(t *testing.T) {
+		dir := t.TempDir()
+		file := filepath.Join(dir, "main.tf")
+		assert.Nil(t, os.WriteFile(file, []byte("original"), 0640))
+		assert.Nil(t, WriteFileAtomically(file, []byte("updated")))
+		content, err := os.ReadFile(file)
+		assert.Nil(t, err)
+		assert.Equal(t, "updated", string(content))
+		info, err := os.Stat(file)
+		assert.Nil(t, err)
+		assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+		entries, err := os.ReadDir(dir)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(entries))
+	})
+
+	t.Run("write through symlinks", func(t *testing.T) {
+		dir := t.TempDir()
+		file := filepath.Join(dir, "main.tf")
+		link := filepath.Join(dir, "link.tf")
+		assert.Nil(t, os.WriteFile(file, []byte("original"), 0600))
+		if err := os.Symlink(file, link); err != nil {
+			t.Skip("symlinks are not supported")
+		}
+		assert.Nil(t, WriteFileAtomically(link, []byte("updated")))
+		content, err := os.ReadFile(file)
+		assert.Nil(t, err)
+		assert.Equal(t, "updated", string(content))
+		info, err := os.Lstat(link)
+		assert.Nil(t, err)
+		assert.Equal(t, os.ModeSymlink, info.Mode()&os.ModeSymlink)
+	})
+
+	t.Run("back up the file", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "main.tf")
+		assert.Nil(t, os.WriteFile(file, []byte("original"), 0600))
+		assert.Nil(t, BackupFile(file))
+		assert.Nil(t, WriteFileAtomically(file, []byte("updated")))
+		backup, err := os.ReadFile(file + BackupFileSuffix)
+		assert.Nil(t, err)
+		assert.Equal(t, "original", string(backup))
+	})
+}
+
+func TestMemoryFileSystem(t *testing.T) {
+	fileSys := NewMemoryFileSystem(map[string][]byte{
+		"infra/main.tf":            []byte("resource \"aws_s3_bucket\" \"a\" {}\n"),
+		"infra/modules/vpc/vpc.tf": []byte("variable \"tags\" {}\n"),
+	})
+
+	t.Run("Read and write files", func(t *testing.T) {
+		assert.Nil(t, fileSys.WriteFile("infra/./outputs.tf", []byte("output \"a\" {}\n")))
+		data, err := fileSys.ReadFile("infra/outputs.tf")
+		assert.Nil(t, err)
+		assert.Equal(t, "output \"a\" {}\n", string(data))
+		_, err = fileSys.ReadFile("infra/variables.tf")
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("Stat files and directories", func(t *testing.T) {
+		info, err := fileSys.Stat("infra/main.tf")
+		assert.Nil(t, err)
+		assert.False(t, info.IsDir())
+		assert.Equal(t, int64(32), info.Size())
+		info, err = fileSys.Stat("infra/modules")
+		assert.Nil(t, err)
+		assert.True(t, info.IsDir())
+		_, err = fileSys.Stat("infra/mod")
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("Read directories", func(t *testing.T) {
+		entries, err := fileSys.ReadDir("infra")
+		assert.Nil(t, err)
+		var names []string
+		for _, entry := range entries {
+			names = append(names, fmt.Sprintf("%s %v", entry.Name(), entry.IsDir()))
+		}
+		assert.Equal(t, []string{"main.tf false", "modules true", "outputs.tf false"}, names)
+		_, err = fileSys.ReadDir("modules")
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("Create and remove temporary files", func(t *testing.T) {
+		tempFile, err := fileSys.CreateTemp("infra", "temp.*.tf")
+		assert.Nil(t, err)
+		assert.Regexp(t, `^infra/temp\.\d+\.tf$`, filepath.ToSlash(tempFile))
+		data, err := fileSys.ReadFile(tempFile)
+		assert.Nil(t, err)
+		assert.Empty(t, data)
+		assert.Nil(t, fileSys.Remove(tempFile))
+		_, err = fileSys.Stat(tempFile)
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("Walk the file system", func(t *testing.T) {
+		SetFileSystem(fileSys)
+		defer SetFileSystem(OSFileSystem{})
+		var paths []string
+		err := WalkDir("infra", func(path string, entry fs.DirEntry, err error) error {
+			assert.Nil(t, err)
+			if entry.Name() == "vpc" {
+				return filepath.SkipDir
+			}
+			paths = append(paths, filepath.ToSlash(path))
+			return nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"infra", "infra/main.tf", "infra/modules", "infra/outputs.tf"}, paths)
+		assert.Equal(t, filepath.Join(currentDir, "infra", "main.tf"), RealPath("infra/main.tf"))
+		assert.Equal(t, "", RealPath("infra/variables.tf"))
+	})
+}
+
+func TestIOFileSystem(t *testing.T) {
+	fileSys := NewIOFileSystem(fstest.MapFS{
+		"infra/main.tf": &fstest.MapFile{Data: []byte("resource \"aws_s3_bucket\" \"a\" {}\n")},
+	})
+
+	t.Run("Read the files of the fs.FS", func(t *testing.T) {
+		for _, name := range []string{"infra/main.tf", "./infra/main.tf", "/infra/main.tf"} {
+			data, err := fileSys.ReadFile(name)
+			assert.Nil(t, err, name)
+			assert.Equal(t, "resource \"aws_s3_bucket\" \"a\" {}\n", string(data))
+		}
+		entries, err := fileSys.ReadDir(".")
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(entries))
+		assert.True(t, entries[0].IsDir())
+	})
+
+	t.Run("Don't write the files of the fs.FS", func(t *testing.T) {
+		err := fileSys.WriteFile("infra/main.tf", []byte{})
+		assert.True(t, errors.Is(err, fs.ErrPermission))
+		_, err = fileSys.CreateTemp("infra", "temp.*.tf")
+		assert.True(t, errors.Is(err, fs.ErrPermission))
+	})
+}
+
+func TestOverlayFileSystem(t *testing.T) {
+	base := NewIOFileSystem(fstest.MapFS{
+		"infra/main.tf":    &fstest.MapFile{Data: []byte("resource \"aws_s3_bucket\" \"a\" {}\n")},
+		"infra/outputs.tf": &fstest.MapFile{Data: []byte("")},
+	})
+
+	t.Run("Keep the written files in memory", func(t *testing.T) {
+		fileSys := NewOverlayFileSystem(base)
+		assert.Nil(t, fileSys.WriteFile("infra/main.tf", []byte("tagged")))
+		data, err := fileSys.ReadFile("infra/main.tf")
+		assert.Nil(t, err)
+		assert.Equal(t, "tagged", string(data))
+		data, _ = base.ReadFile("infra/main.tf")
+		assert.Equal(t, "resource \"aws_s3_bucket\" \"a\" {}\n", string(data))
+
+		tempFile, err := fileSys.CreateTemp("infra", "temp.*.tf")
+		assert.Nil(t, err)
+		assert.Nil(t, fileSys.Remove("infra/outputs.tf"))
+		_, err = fileSys.Stat("infra/outputs.tf")
+		assert.True(t, errors.Is(err, fs.ErrNotExist))
+		entries, err := fileSys.ReadDir("infra")
+		assert.Nil(t, err)
+		var names []string
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		assert.Equal(t, []string{"main.tf", filepath.Base(tempFile)}, names)
+	})
+
+	t.Run("Mount file systems at directories", func(t *testing.T) {
+		mountDir := filepath.Join(t.TempDir(), "mount")
+		fileSys := NewOverlayFileSystem(base)
+		unmount := Mount(mountDir, fileSys)
+		data, err := ReadFile(filepath.Join(mountDir, "infra", "main.tf"))
+		assert.Nil(t, err)
+		assert.Equal(t, "resource \"aws_s3_bucket\" \"a\" {}\n", string(data))
+		assert.Nil(t, WriteFile(filepath.Join(mountDir, "infra", "main.tf"), []byte("tagged")))
+		data, _ = fileSys.ReadFile("infra/main.tf")
+		assert.Equal(t, "tagged", string(data))
+		tempFile, err := CreateTemp(filepath.Join(mountDir, "infra"), "temp.*.tf")
+		assert.Nil(t, err)
+		assert.Equal(t, filepath.Join(mountDir, "infra"), filepath.Dir(tempFile))
+
+		unmount()
+		_, err = ReadFile(filepath.Join(mountDir, "infra", "main.tf"))
+		assert.True(t, errors.Is(err, fs.ErrNotExist))
+	})
+}
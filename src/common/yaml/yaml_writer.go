@@ -3,7 +3,6 @@ package yaml
 import (
 	"fmt"
 	"math"
-	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
@@ -20,9 +19,8 @@ import (
 const SingleIndent = "  "
 
 func WriteYAMLFile(readFilePath string, blocks []structure.IBlock, writeFilePath string, tagsAttributeName string, resourcesStartToken string) error {
-	// #nosec G304
 	// read file bytes
-	originFileSrc, err := os.ReadFile(readFilePath)
+	originFileSrc, err := utils.ReadFile(readFilePath)
 	if err != nil {
 		return fmt.Errorf("failed to read file %s because %s", readFilePath, err)
 	}
@@ -38,7 +36,18 @@ func WriteYAMLFile(readFilePath string, blocks []structure.IBlock, writeFilePath
 	if isCfn {
 		linesPerTag = 2
 	}
+	// the last line written from the original resources, which is the functions line of serverless files as it is
+	// written separately
+	lastWrittenLine := oldResourcesLineRange.Start - 1
+	if !isCfn {
+		lastWrittenLine = oldResourcesLineRange.Start
+	}
 	for _, resourceBlock := range blocks {
+		// keep the blank lines between the resources
+		if resourceBlock.GetLines().Start > lastWrittenLine+1 {
+			resourcesLines = append(resourcesLines, originLines[lastWrittenLine+1:resourceBlock.GetLines().Start]...)
+		}
+		lastWrittenLine = resourceBlock.GetLines().End
 		rawBlock := resourceBlock.GetRawBlock()
 		newResourceLines := getYAMLLines(rawBlock, isCfn)
 		newResourceTagLineRange, _ := FindTagsLinesYAML(newResourceLines, tagsAttributeName)
@@ -122,7 +131,7 @@ func WriteYAMLFile(readFilePath string, blocks []structure.IBlock, writeFilePath
 	allLines = append(allLines, originLines[oldResourcesLineRange.End+1:]...)
 	linesText := strings.Join(allLines, "\n")
 
-	err = os.WriteFile(writeFilePath, []byte(linesText), 0600)
+	err = utils.WriteFile(writeFilePath, utils.MatchLineEndings(originFileSrc, []byte(linesText)))
 
 	return err
 }
@@ -252,7 +261,12 @@ func FindTagsLinesYAML(textLines []string, tagsAttributeName string) (structure.
 	var lineIndent string
 	var tagsExist bool
 	var tagsIndent = ""
+	inBlockScalar := FindBlockScalarLines(textLines)
 	for i, line := range textLines {
+		// blank lines and the content of block scalars neither start nor end the tags
+		if inBlockScalar[i] || strings.TrimSpace(line) == "" {
+			continue
+		}
 		lineIndent = ExtractIndentationOfLine(line)
 		switch {
 		case strings.HasPrefix(strings.TrimSpace(line), tagsAttributeName+":"):
@@ -274,14 +288,15 @@ func FindTagsLinesYAML(textLines []string, tagsAttributeName string) (structure.
 	return tagsLines, tagsExist
 }
 
+// MapResourcesLineYAML maps the resources under the resourcesStartToken key to their lines in the file. Only the first
+// document of the file is mapped, as it is the only one the parsers read, and the content of block scalars is ignored.
 func MapResourcesLineYAML(filePath string, resourceNames []string, resourcesStartToken string) map[string]*structure.Lines {
 	resourceToLines := make(map[string]*structure.Lines)
 	for _, resourceName := range resourceNames {
 		// initialize a map between resource name and its lines in file
 		resourceToLines[resourceName] = &structure.Lines{Start: -1, End: -1}
 	}
-	// #nosec G304
-	file, err := os.ReadFile(filePath)
+	file, err := utils.ReadFile(filePath)
 	if err != nil {
 		logger.Warning(fmt.Sprintf("failed to read file %s", filePath))
 		return nil
@@ -290,41 +305,59 @@ func MapResourcesLineYAML(filePath string, resourceNames []string, resourcesStar
 	readResources := false
 	latestResourceName := ""
 	fileLines := strings.Split(string(file), "\n")
+	inBlockScalar := FindBlockScalarLines(fileLines)
+	documentIndent := -1
 	resourcesIndent := 0
+	resourceIndent := -1
 	// iterate file line by line
 	for i, line := range fileLines {
 		cleanContent := strings.TrimSpace(line)
-		if strings.HasPrefix(cleanContent, resourcesStartToken+":") {
-			readResources = true
-			resourcesIndent = countLeadingSpaces(line)
+		if inBlockScalar[i] || cleanContent == "" || strings.HasPrefix(cleanContent, "#") {
 			continue
 		}
-
-		if readResources {
-			lineIndent := countLeadingSpaces(line)
-			if lineIndent <= resourcesIndent && strings.TrimSpace(line) != "" && !strings.Contains(line, "#") {
-				// No longer inside resources block, get the last line of the previous resource if exists
-				readResources = false
-				if latestResourceName != "" {
-					resourceToLines[latestResourceName].End = findLastNonEmptyLine(fileLines, i-1)
-				}
-				break
+		if documentIndent == -1 && (isDocumentSeparator(cleanContent) || strings.HasPrefix(cleanContent, "%")) {
+			// directives and the separator starting the first document
+			continue
+		}
+		if isDocumentSeparator(cleanContent) {
+			// The first document ended, complete the last resource
+			if latestResourceName != "" {
+				resourceToLines[latestResourceName].End = findLastNonEmptyLine(fileLines, i-1)
 			}
-			for _, resName := range resourceNames {
-				resNameRegex := regexp.MustCompile(fmt.Sprintf("^ {1,5}%v:", resName))
-				if resNameRegex.Match([]byte(line)) {
-					if latestResourceName != "" {
-						// Complete previous function block
-						resourceToLines[latestResourceName].End = findLastNonEmptyLine(fileLines, i-1)
-					}
-					latestResourceName = resName
-					resourceToLines[latestResourceName].Start = i
-					break
-				}
+			break
+		}
+		lineIndent := countLeadingSpaces(line)
+		if documentIndent == -1 {
+			documentIndent = lineIndent
+		}
+		if !readResources {
+			if lineIndent == documentIndent && strings.HasPrefix(cleanContent, resourcesStartToken+":") {
+				readResources = true
+				resourcesIndent = lineIndent
 			}
-			if !strings.HasPrefix(line, " ") && strings.TrimSpace(line) != "" && readResources && latestResourceName != "" && !strings.HasPrefix(line, "#") {
-				// This is no longer in the functions block, complete last function block
+			continue
+		}
+		if lineIndent <= resourcesIndent {
+			// No longer inside resources block, get the last line of the previous resource if exists
+			if latestResourceName != "" {
 				resourceToLines[latestResourceName].End = findLastNonEmptyLine(fileLines, i-1)
+			}
+			break
+		}
+		if resourceIndent == -1 {
+			resourceIndent = lineIndent
+		}
+		if lineIndent != resourceIndent {
+			continue
+		}
+		for _, resName := range resourceNames {
+			if strings.HasPrefix(cleanContent, resName+":") {
+				if latestResourceName != "" {
+					// Complete previous function block
+					resourceToLines[latestResourceName].End = findLastNonEmptyLine(fileLines, i-1)
+				}
+				latestResourceName = resName
+				resourceToLines[latestResourceName].Start = i
 				break
 			}
 		}
@@ -336,6 +369,79 @@ func MapResourcesLineYAML(filePath string, resourceNames []string, resourcesStar
 	return resourceToLines
 }
 
+func isDocumentSeparator(cleanLine string) bool {
+	return cleanLine == "---" || cleanLine == "..." || strings.HasPrefix(cleanLine, "--- ")
+}
+
+var blockScalarHeaderRegex = regexp.MustCompile(`(^\s*-|:)\s+([!&]\S*\s+)*[|>][1-9+-]*\s*(#.*)?$`)
+var sequenceBlockScalarHeaderRegex = regexp.MustCompile(`^\s*-\s+([!&]\S*\s+)*[|>]`)
+
+// FindBlockScalarLines returns whether each of the lines is the content of a block scalar (i.e. `script: |`), which
+// may look like YAML but must not be read as such
+func FindBlockScalarLines(textLines []string) []bool {
+	inBlockScalar := make([]bool, len(textLines))
+	scalarIndent := -1
+	for i, line := range textLines {
+		if scalarIndent >= 0 {
+			if strings.TrimSpace(line) == "" || countLeadingSpaces(line) > scalarIndent {
+				inBlockScalar[i] = true
+				continue
+			}
+			scalarIndent = -1
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "#") || !blockScalarHeaderRegex.MatchString(line) {
+			continue
+		}
+		// the content is indented more than the key, or than the dash of a scalar sequence item
+		if sequenceBlockScalarHeaderRegex.MatchString(line) {
+			scalarIndent = countLeadingSpaces(line)
+		} else {
+			scalarIndent = len(ExtractIndentationOfLine(line))
+		}
+	}
+	return inBlockScalar
+}
+
+// AreTagsEditable returns whether the tags of the resource can be rewritten line by line. The resource's tags can't
+// be rewritten if they, or the attributes containing them, are an alias, an anchor shared with other nodes or a flow
+// collection, as rewriting them would resolve the anchors or change the other nodes. attributeNames are the names of
+// the attributes on the path to the tags, including the tags attribute.
+func AreTagsEditable(resourceLines []string, attributeNames ...string) bool {
+	if len(resourceLines) == 0 {
+		return true
+	}
+	if getInlineValue(resourceLines[0]) != "" {
+		return false
+	}
+	inBlockScalar := FindBlockScalarLines(resourceLines)
+	for i, line := range resourceLines[1:] {
+		if inBlockScalar[i+1] {
+			continue
+		}
+		key := strings.TrimSpace(strings.SplitN(line, ":", 2)[0])
+		if !utils.InSlice(attributeNames, key) {
+			continue
+		}
+		if value := getInlineValue(line); value != "" && strings.ContainsAny(value[:1], "*&[{") {
+			return false
+		}
+	}
+	return true
+}
+
+// getInlineValue returns the value of a key which is on the key's line, without comments
+func getInlineValue(line string) string {
+	parts := strings.SplitN(line, ":", 2)
+	if len(parts) < 2 {
+		return ""
+	}
+	value := strings.TrimSpace(parts[1])
+	if strings.HasPrefix(value, "#") {
+		return ""
+	}
+	return value
+}
+
 func countLeadingSpaces(line string) int {
 	return len(line) - len(strings.TrimLeft(line, " "))
 }
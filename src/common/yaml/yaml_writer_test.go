@@ -214,6 +214,13 @@ func TestTagReplacement(t *testing.T) {
 		assert.Equal(t, *res["CloudFrontDistribution"], structure.Lines{Start: 18, End: 60})
 	})
 
+	t.Run("Test line computation of the first document", func(t *testing.T) {
+		res := MapResourcesLineYAML("../../../tests/cloudformation/resources/multi_document/template.yaml", []string{"Bucket", "Instance", "OtherVolume"}, "Resources")
+		assert.Equal(t, structure.Lines{Start: 4, End: 7}, *res["Bucket"])
+		assert.Equal(t, structure.Lines{Start: 9, End: 24}, *res["Instance"])
+		assert.Equal(t, structure.Lines{Start: 32, End: 36}, *res["OtherVolume"])
+	})
+
 	t.Run("Test line computation with duplicate - SLS", func(t *testing.T) {
 		res := MapResourcesLineYAML("../../../tests/cloudformation/resources/duplicate_entries/duplicate_sls.yaml", []string{"attribute", "zone", "customer", "apiVersion"}, "functions")
 		assert.Equal(t, *res["apiVersion"], structure.Lines{Start: 7, End: 12})
@@ -222,3 +229,56 @@ func TestTagReplacement(t *testing.T) {
 		assert.Equal(t, *res["attribute"], structure.Lines{Start: 40, End: 53})
 	})
 }
+
+func TestFindBlockScalarLines(t *testing.T) {
+	textLines := []string{
+		"Properties:",
+		"  UserData:",
+		"    Fn::Base64: !Sub |",
+		"      Tags:",
+		"",
+		"        - Key: Name",
+		"  Script: >-",
+		"    echo",
+		"  Commands:",
+		"    - |",
+		"      Tags: none",
+		"    - echo | cat",
+		"  Tags:",
+		"    - Key: Name",
+	}
+	expected := []bool{false, false, false, true, true, true, false, true, false, false, true, false, false, false}
+	assert.Equal(t, expected, FindBlockScalarLines(textLines))
+}
+
+func TestAreTagsEditable(t *testing.T) {
+	t.Run("Edit literal tags", func(t *testing.T) {
+		assert.True(t, AreTagsEditable([]string{"  Bucket:", "    Properties:", "      Tags: # tags", "        - Key: a", "          Value: b"}, "Properties", "Tags"))
+	})
+	t.Run("Don't edit anchors, aliases and flow collections", func(t *testing.T) {
+		assert.False(t, AreTagsEditable([]string{"  Bucket:", "    Properties:", "      Tags: &tags", "        - Key: a", "          Value: b"}, "Properties", "Tags"))
+		assert.False(t, AreTagsEditable([]string{"  Bucket:", "    Properties:", "      Tags: *tags"}, "Properties", "Tags"))
+		assert.False(t, AreTagsEditable([]string{"  Bucket:", "    Properties: *properties"}, "Properties", "Tags"))
+		assert.False(t, AreTagsEditable([]string{"  hello:", "    tags: {team: a}"}, "tags"))
+		assert.False(t, AreTagsEditable([]string{"  Bucket: *bucket"}, "Properties", "Tags"))
+	})
+	t.Run("Ignore block scalars", func(t *testing.T) {
+		assert.True(t, AreTagsEditable([]string{"  hello:", "    description: |", "      tags: *tags"}, "tags"))
+	})
+}
+
+func TestWriteYAMLFileKeepsBlankLines(t *testing.T) {
+	dir := t.TempDir()
+	readFilePath := filepath.Join(dir, "template.yaml")
+	content := "Resources:\n  A:\n    Type: AWS::S3::Bucket\n\n\n  B:\n    Type: AWS::S3::Bucket\n"
+	assert.Nil(t, os.WriteFile(readFilePath, []byte(content), 0600))
+	blocks := []structure.IBlock{
+		&structure.Block{FilePath: readFilePath, Lines: structure.Lines{Start: 1, End: 2}, TagLines: structure.Lines{Start: -1, End: -1}},
+		&structure.Block{FilePath: readFilePath, Lines: structure.Lines{Start: 5, End: 6}, TagLines: structure.Lines{Start: -1, End: -1}},
+	}
+	writeFilePath := filepath.Join(dir, "template_tagged.yaml")
+	assert.Nil(t, WriteYAMLFile(readFilePath, blocks, writeFilePath, "Tags", "Resources"))
+	written, err := os.ReadFile(writeFilePath)
+	assert.Nil(t, err)
+	assert.Equal(t, content, string(written))
+}
@@ -0,0 +1,33 @@
+package structure
+
+import (
+	"github.com/bridgecrewio/yor/src/common/structure"
+)
+
+type ComposeBlock struct {
+	structure.Block
+	// labelsAsList is set for services declaring their labels as a list of key=value strings rather than a map
+	labelsAsList bool
+}
+
+func (b *ComposeBlock) UpdateTags() {
+	if !b.IsTaggable {
+		return
+	}
+
+	mergedTags := make(map[string]string)
+	for _, t := range b.MergeTags() {
+		mergedTags[t.GetKey()] = t.GetValue()
+	}
+	if rawBlock, ok := b.RawBlock.(map[interface{}]interface{}); ok {
+		rawBlock[LabelsAttributeName] = mergedTags
+	}
+}
+
+func (b *ComposeBlock) GetTagsLines() structure.Lines {
+	return b.TagLines
+}
+
+func (b *ComposeBlock) GetSeparator() string {
+	return "/n"
+}
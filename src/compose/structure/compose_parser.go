@@ -0,0 +1,315 @@
+package structure
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bridgecrewio/yor/src/common"
+	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/bridgecrewio/yor/src/common/structure"
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+	"github.com/bridgecrewio/yor/src/common/utils"
+	yamlUtils "github.com/bridgecrewio/yor/src/common/yaml"
+	"gopkg.in/yaml.v2"
+)
+
+const LabelsAttributeName = "labels"
+const ServicesStartToken = "services"
+const ServiceResourceType = "compose_service"
+
+// composeFileNameRegex matches docker-compose.yml, compose.yaml and override files such as docker-compose.prod.yml
+var composeFileNameRegex = regexp.MustCompile(`^(docker-)?compose(\.[\w-]+)*\.ya?ml$`)
+
+// ComposeParser writes the tags as the labels of the services of Docker Compose files
+type ComposeParser struct {
+	rootDir string
+}
+
+type composeFile struct {
+	Services map[string]interface{} `yaml:"services"`
+}
+
+// serviceLines are the (0-based) line ranges of a service and of its labels in the file
+type serviceLines struct {
+	service        structure.Lines
+	propertyIndent string
+	labels         structure.Lines
+	// flowLabels is set for labels declared inline, i.e. `labels: []`
+	flowLabels bool
+}
+
+func (p *ComposeParser) Name() string {
+	return "DockerCompose"
+}
+
+func (p *ComposeParser) Init(rootDir string, _ map[string]string) {
+	p.rootDir = rootDir
+}
+
+func (p *ComposeParser) Close() {
+}
+
+func (p *ComposeParser) GetSkippedDirs() []string {
+	return []string{}
+}
+
+func (p *ComposeParser) GetSupportedFileExtensions() []string {
+	return []string{common.YamlFileType.Extension, common.YmlFileType.Extension}
+}
+
+// ValidFile Validate file is a compose file, by its name and its services section
+func (p *ComposeParser) ValidFile(filePath string) bool {
+	if !composeFileNameRegex.MatchString(filepath.Base(filePath)) {
+		return false
+	}
+	file, err := readComposeFile(filePath)
+	if err != nil {
+		logger.Warning(fmt.Sprintf("Error parsing compose file %s, skipping: %v", filePath, err))
+		return false
+	}
+	return len(file.Services) > 0
+}
+
+func readComposeFile(filePath string) (*composeFile, error) {
+	src, err := utils.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	file := &composeFile{}
+	if err = yaml.Unmarshal(src, file); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func (p *ComposeParser) ParseFile(filePath string) ([]structure.IBlock, error) {
+	file, err := readComposeFile(filePath)
+	if err != nil {
+		logger.Warning(fmt.Sprintf("There was an error processing the compose file %v: %s", filePath, err))
+		return nil, err
+	}
+	src, err := utils.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	fileLines := strings.Split(string(src), "\n")
+	serviceNames := make([]string, 0, len(file.Services))
+	for name := range file.Services {
+		serviceNames = append(serviceNames, name)
+	}
+	servicesToLines := yamlUtils.MapResourcesLineYAML(filePath, serviceNames, ServicesStartToken)
+
+	parsedBlocks := make([]structure.IBlock, 0)
+	for _, name := range serviceNames {
+		rawBlock, ok := file.Services[name].(map[interface{}]interface{})
+		if !ok || servicesToLines[name] == nil || servicesToLines[name].Start == -1 {
+			continue
+		}
+		lines := findServiceLines(fileLines, *servicesToLines[name])
+		existingTags, labelsAsList, isTaggable := getExistingTags(rawBlock[LabelsAttributeName])
+		if lines.propertyIndent == "" || (lines.flowLabels && len(existingTags) > 0) {
+			logger.Debug(fmt.Sprintf("Skipping service %s in %s, it is declared inline", name, filePath))
+			isTaggable = false
+		}
+		parsedBlocks = append(parsedBlocks, &ComposeBlock{
+			Block: structure.Block{
+				FilePath:          filePath,
+				ExitingTags:       existingTags,
+				RawBlock:          rawBlock,
+				IsTaggable:        isTaggable,
+				TagsAttributeName: LabelsAttributeName,
+				Lines:             lines.service,
+				TagLines:          lines.labels,
+				Name:              name,
+				Type:              ServiceResourceType,
+			},
+			labelsAsList: labelsAsList,
+		})
+	}
+	sort.Slice(parsedBlocks, func(i, j int) bool {
+		return parsedBlocks[i].GetLines().Start < parsedBlocks[j].GetLines().Start
+	})
+	return parsedBlocks, nil
+}
+
+// getExistingTags returns the labels of a service, which are either a map or a list of key=value strings
+func getExistingTags(labels interface{}) ([]tags.ITag, bool, bool) {
+	existingTags := make([]tags.ITag, 0)
+	switch labels := labels.(type) {
+	case nil:
+		return existingTags, false, true
+	case map[interface{}]interface{}:
+		for key, value := range labels {
+			if value == nil {
+				value = ""
+			}
+			existingTags = append(existingTags, &tags.Tag{Key: fmt.Sprint(key), Value: fmt.Sprint(value)})
+		}
+		sort.Slice(existingTags, func(i, j int) bool {
+			return existingTags[i].GetKey() < existingTags[j].GetKey()
+		})
+		return existingTags, false, true
+	case []interface{}:
+		for _, label := range labels {
+			key, value := splitListLabel(fmt.Sprint(label))
+			existingTags = append(existingTags, &tags.Tag{Key: key, Value: value})
+		}
+		return existingTags, true, true
+	default:
+		return existingTags, false, false
+	}
+}
+
+func splitListLabel(label string) (string, string) {
+	if index := strings.Index(label, "="); index >= 0 {
+		return label[:index], label[index+1:]
+	}
+	return label, ""
+}
+
+// findServiceLines finds the labels section among the properties of the service
+func findServiceLines(fileLines []string, service structure.Lines) serviceLines {
+	lines := serviceLines{service: service, labels: structure.Lines{Start: -1, End: -1}}
+	for i := service.Start + 1; i <= service.End && i < len(fileLines); i++ {
+		if isBlankOrComment(fileLines[i]) {
+			continue
+		}
+		indent := yamlUtils.ExtractIndentationOfLine(fileLines[i])
+		if lines.propertyIndent == "" {
+			lines.propertyIndent = indent
+		}
+		trimmed := strings.TrimSpace(fileLines[i])
+		if lines.labels.Start >= 0 {
+			// list items may be indented like the labels key itself
+			if len(indent) < len(lines.propertyIndent) || (len(indent) == len(lines.propertyIndent) && !strings.HasPrefix(trimmed, "-")) {
+				break
+			}
+			lines.labels.End = i
+			continue
+		}
+		if len(indent) == len(lines.propertyIndent) && strings.HasPrefix(trimmed, LabelsAttributeName+":") {
+			lines.labels = structure.Lines{Start: i, End: i}
+			value := strings.TrimSpace(strings.TrimPrefix(trimmed, LabelsAttributeName+":"))
+			lines.flowLabels = value != "" && !strings.HasPrefix(value, "#")
+		}
+	}
+	return lines
+}
+
+func isBlankOrComment(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || strings.HasPrefix(trimmed, "#")
+}
+
+func (p *ComposeParser) WriteFile(readFilePath string, blocks []structure.IBlock, writeFilePath string) error {
+	originFileSrc, err := utils.ReadFile(readFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s because %s", readFilePath, err)
+	}
+	fileLines := strings.Split(string(originFileSrc), "\n")
+	replacedLines := make(map[int]string)
+	insertedLines := make(map[int][]string)
+	for _, block := range blocks {
+		composeBlock, ok := block.(*ComposeBlock)
+		if !ok || !composeBlock.IsBlockTaggable() {
+			continue
+		}
+		diff := composeBlock.CalculateTagsDiff()
+		if len(diff.Added) == 0 && len(diff.Updated) == 0 {
+			continue
+		}
+		composeBlock.UpdateTags()
+		lines := findServiceLines(fileLines, composeBlock.GetLines())
+		updateLabels(fileLines, lines, composeBlock, diff, replacedLines, insertedLines)
+	}
+
+	newLines := make([]string, 0, len(fileLines))
+	for i, line := range fileLines {
+		if replaced, ok := replacedLines[i]; ok {
+			line = replaced
+		}
+		newLines = append(newLines, line)
+		newLines = append(newLines, insertedLines[i]...)
+	}
+	textToWrite := strings.Join(newLines, "\n")
+	if err = yaml.Unmarshal([]byte(textToWrite), &composeFile{}); err != nil {
+		return fmt.Errorf("editing file %v resulted in a malformed compose file, please open a github issue with the relevant details", readFilePath)
+	}
+	return utils.WriteFile(writeFilePath, utils.MatchLineEndings(originFileSrc, []byte(textToWrite)))
+}
+
+// updateLabels records the line changes which update the existing labels of the service and add the new ones
+func updateLabels(fileLines []string, lines serviceLines, block *ComposeBlock, diff *structure.TagDiff, replacedLines map[int]string, insertedLines map[int][]string) {
+	// the labels are added sorted by key, so the file doesn't change between runs computing the same tags
+	addedTags := make([]tags.ITag, len(diff.Added))
+	copy(addedTags, diff.Added)
+	sort.Slice(addedTags, func(i, j int) bool {
+		return addedTags[i].GetKey() < addedTags[j].GetKey()
+	})
+
+	serviceIndent := yamlUtils.ExtractIndentationOfLine(fileLines[lines.service.Start])
+	indentUnit := yamlUtils.SingleIndent
+	if len(lines.propertyIndent) > len(serviceIndent) {
+		indentUnit = lines.propertyIndent[len(serviceIndent):]
+	}
+
+	if lines.labels.Start == -1 || lines.flowLabels || lines.labels.End == lines.labels.Start {
+		// the service has no labels, add them as a map after its last property
+		newLines := []string{lines.propertyIndent + LabelsAttributeName + ":"}
+		for _, tag := range addedTags {
+			newLines = append(newLines, formatLabel(lines.propertyIndent+indentUnit, tag.GetKey(), tag.GetValue(), false))
+		}
+		if lines.labels.Start == -1 {
+			lastLine := lines.service.End
+			insertedLines[lastLine] = append(insertedLines[lastLine], newLines...)
+		} else {
+			replacedLines[lines.labels.Start] = newLines[0]
+			insertedLines[lines.labels.Start] = newLines[1:]
+		}
+		return
+	}
+
+	labelIndent := ""
+	for i := lines.labels.Start + 1; i <= lines.labels.End; i++ {
+		line := fileLines[i]
+		if isBlankOrComment(line) {
+			continue
+		}
+		if labelIndent == "" {
+			labelIndent = line[:len(line)-len(strings.TrimLeft(line, " "))]
+		}
+		key := getLabelKey(line, block.labelsAsList)
+		for _, tag := range diff.Updated {
+			if tag.Key == key {
+				replacedLines[i] = formatLabel(labelIndent, tag.Key, tag.NewValue, block.labelsAsList)
+			}
+		}
+	}
+	for _, tag := range addedTags {
+		insertedLines[lines.labels.End] = append(insertedLines[lines.labels.End], formatLabel(labelIndent, tag.GetKey(), tag.GetValue(), block.labelsAsList))
+	}
+}
+
+func getLabelKey(line string, asList bool) string {
+	trimmed := strings.TrimSpace(line)
+	if asList {
+		key, _ := splitListLabel(strings.Trim(strings.TrimSpace(strings.TrimPrefix(trimmed, "-")), `"'`))
+		return key
+	}
+	if index := strings.Index(trimmed, ":"); index >= 0 {
+		return strings.Trim(trimmed[:index], `"'`)
+	}
+	return ""
+}
+
+// formatLabel quotes the values, so labels such as "true" or "1" are kept as strings, which compose requires
+func formatLabel(indent string, key string, value string, asList bool) string {
+	if asList {
+		return indent + "- " + strconv.Quote(key+"="+value)
+	}
+	return indent + key + ": " + strconv.Quote(value)
+}
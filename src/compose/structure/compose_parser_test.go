@@ -0,0 +1,78 @@
+package structure
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bridgecrewio/yor/src/common/structure"
+	"github.com/bridgecrewio/yor/src/common/tagging/simple"
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComposeParser_ValidFile(t *testing.T) {
+	composeParser := ComposeParser{}
+	composeParser.Init("../../../tests/compose/resources", nil)
+	assert.True(t, composeParser.ValidFile("../../../tests/compose/resources/services/docker-compose.yml"))
+	assert.False(t, composeParser.ValidFile("../../../tests/cloudformation/resources/ebs/ebs.yaml"))
+
+	directory := t.TempDir()
+	for name, valid := range map[string]bool{"compose.yaml": true, "docker-compose.prod.yml": true, "compose-notes.yml": false} {
+		filePath := filepath.Join(directory, name)
+		assert.Nil(t, os.WriteFile(filePath, []byte("services:\n  web:\n    image: nginx\n"), 0600))
+		assert.Equal(t, valid, composeParser.ValidFile(filePath), name)
+	}
+}
+
+func TestComposeParser_ParseFile(t *testing.T) {
+	composeParser := ComposeParser{}
+	composeParser.Init("../../../tests/compose/resources/services", nil)
+	blocks, err := composeParser.ParseFile("../../../tests/compose/resources/services/docker-compose.yml")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(blocks))
+
+	expected := []struct {
+		name         string
+		lines        structure.Lines
+		labelsLines  structure.Lines
+		existingTags []tags.ITag
+	}{
+		{"web", structure.Lines{Start: 3, End: 12}, structure.Lines{Start: 7, End: 9}, []tags.ITag{&tags.Tag{Key: "com.example.team", Value: "frontend"}, &tags.Tag{Key: "new_tag", Value: "old_value"}}},
+		{"api", structure.Lines{Start: 14, End: 20}, structure.Lines{Start: 16, End: 18}, []tags.ITag{&tags.Tag{Key: "com.example.team", Value: "backend"}, &tags.Tag{Key: "com.example.tier", Value: "api"}}},
+		{"db", structure.Lines{Start: 22, End: 28}, structure.Lines{Start: -1, End: -1}, []tags.ITag{}},
+		{"cache", structure.Lines{Start: 30, End: 32}, structure.Lines{Start: 32, End: 32}, []tags.ITag{}},
+	}
+	for i, block := range blocks {
+		assert.Equal(t, expected[i].name, block.GetResourceID())
+		assert.Equal(t, ServiceResourceType, block.GetResourceType())
+		assert.True(t, block.IsBlockTaggable())
+		assert.Equal(t, expected[i].lines, block.GetLines())
+		assert.Equal(t, expected[i].labelsLines, block.GetTagsLines())
+		assert.Equal(t, expected[i].existingTags, block.GetExistingTags())
+	}
+}
+
+func TestComposeParser_WriteFile(t *testing.T) {
+	directory := "../../../tests/compose/resources/services"
+	composeParser := ComposeParser{}
+	composeParser.Init(directory, nil)
+	readFilePath := directory + "/docker-compose.yml"
+	tagGroup := simple.TagGroup{}
+	tagGroup.SetTags([]tags.ITag{
+		&tags.Tag{Key: "new_tag", Value: "new_value"},
+		&tags.Tag{Key: "yor_trace", Value: "123"},
+	})
+	tagGroup.InitTagGroup("", []string{}, []string{})
+	blocks, err := composeParser.ParseFile(readFilePath)
+	assert.Nil(t, err)
+	for _, block := range blocks {
+		assert.Nil(t, tagGroup.CreateTagsForBlock(block))
+	}
+	writeFilePath := filepath.Join(t.TempDir(), "docker-compose.yml")
+	assert.Nil(t, composeParser.WriteFile(readFilePath, blocks, writeFilePath))
+
+	expectedContent, _ := os.ReadFile(directory + "/docker-compose_tagged.yml")
+	actualContent, _ := os.ReadFile(writeFilePath)
+	assert.Equal(t, string(expectedContent), string(actualContent))
+}
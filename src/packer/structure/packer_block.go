@@ -0,0 +1,25 @@
+package structure
+
+import (
+	"strings"
+
+	"github.com/bridgecrewio/yor/src/common/structure"
+)
+
+type PackerBlock struct {
+	structure.Block
+	// labels are the builder type and the name of the source block
+	labels []string
+}
+
+func (b *PackerBlock) GetTagsLines() structure.Lines {
+	return b.TagLines
+}
+
+func (b *PackerBlock) GetSeparator() string {
+	return "="
+}
+
+func (b *PackerBlock) IsGCPBlock() bool {
+	return strings.HasPrefix(b.Type, "googlecompute")
+}
@@ -0,0 +1,335 @@
+package structure
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bridgecrewio/yor/src/common"
+	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/bridgecrewio/yor/src/common/structure"
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+	"github.com/bridgecrewio/yor/src/common/utils"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const SourceBlockType = "source"
+
+// BuilderToTagAttributes maps the builders which support tags to their tags attributes. The first attribute tags the
+// built image, the others the resources running during the build, and are only added the tags yor computes.
+var BuilderToTagAttributes = map[string][]string{
+	"amazon-ebs":          {"tags", "run_tags"},
+	"amazon-ebssurrogate": {"tags", "run_tags"},
+	"amazon-instance":     {"tags", "run_tags"},
+	"azure-arm":           {"azure_tags"},
+	"googlecompute":       {"image_labels", "labels"},
+}
+
+var hclWriteLock sync.Mutex
+
+// PackerParser tags the source blocks of Packer HCL2 templates, so the images they build can be traced back to them
+type PackerParser struct {
+	rootDir string
+}
+
+func (p *PackerParser) Name() string {
+	return "Packer"
+}
+
+func (p *PackerParser) Init(rootDir string, _ map[string]string) {
+	p.rootDir = rootDir
+}
+
+func (p *PackerParser) Close() {
+}
+
+func (p *PackerParser) GetSkippedDirs() []string {
+	return []string{}
+}
+
+func (p *PackerParser) GetSupportedFileExtensions() []string {
+	return []string{common.PackerFileType.Extension}
+}
+
+// ValidFile returns whether the file is a template with source blocks, as variable and build files share the extension
+func (p *PackerParser) ValidFile(filePath string) bool {
+	src, err := utils.ReadFile(filePath)
+	if err != nil {
+		logger.Warning(fmt.Sprintf("Error reading packer file %s, skipping: %v", filePath, err))
+		return false
+	}
+	body, err := parseHclFile(src, filePath)
+	if err != nil {
+		logger.Warning(fmt.Sprintf("Error parsing packer file %s, skipping: %v", filePath, err))
+		return false
+	}
+	for _, block := range body.Blocks {
+		if block.Type == SourceBlockType {
+			return true
+		}
+	}
+	return false
+}
+
+func parseHclFile(src []byte, filePath string) (*hclsyntax.Body, error) {
+	hclSyntaxFile, diagnostics := hclsyntax.ParseConfig(src, filePath, hcl.InitialPos)
+	if diagnostics != nil && diagnostics.HasErrors() {
+		return nil, fmt.Errorf("failed to parse hcl file %s because of errors %s", filePath, diagnostics.Errs())
+	}
+	if hclSyntaxFile == nil {
+		return nil, fmt.Errorf("failed to parse hcl file %s", filePath)
+	}
+	return hclSyntaxFile.Body.(*hclsyntax.Body), nil
+}
+
+func (p *PackerParser) ParseFile(filePath string) ([]structure.IBlock, error) {
+	src, err := utils.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s because %s", filePath, err)
+	}
+	body, err := parseHclFile(src, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedBlocks := make([]structure.IBlock, 0)
+	for _, block := range body.Blocks {
+		tagsAttributes, ok := getTagsAttributes(block)
+		if !ok {
+			continue
+		}
+		isTaggable := true
+		existingTags := make([]tags.ITag, 0)
+		tagsLines := structure.Lines{Start: -1, End: -1}
+		if attribute, exists := block.Body.Attributes[tagsAttributes[0]]; exists {
+			tagsLines = structure.Lines{Start: attribute.SrcRange.Start.Line, End: attribute.SrcRange.End.Line}
+			existingTags, isTaggable = getExistingTags(attribute)
+			if !isTaggable {
+				logger.Debug(fmt.Sprintf("Skipping source %s in %s, its %s are not a literal map", strings.Join(block.Labels, "."), filePath, tagsAttributes[0]))
+			}
+		}
+		parsedBlocks = append(parsedBlocks, &PackerBlock{
+			Block: structure.Block{
+				FilePath:          filePath,
+				ExitingTags:       existingTags,
+				RawBlock:          block,
+				IsTaggable:        isTaggable,
+				TagsAttributeName: tagsAttributes[0],
+				Lines:             structure.Lines{Start: block.Body.SrcRange.Start.Line, End: block.Body.SrcRange.End.Line},
+				TagLines:          tagsLines,
+				Name:              strings.Join(block.Labels, "."),
+				Type:              block.Labels[0],
+			},
+			labels: block.Labels,
+		})
+	}
+	return parsedBlocks, nil
+}
+
+func getTagsAttributes(block *hclsyntax.Block) ([]string, bool) {
+	if block.Type != SourceBlockType || len(block.Labels) != 2 {
+		return nil, false
+	}
+	tagsAttributes, ok := BuilderToTagAttributes[block.Labels[0]]
+	return tagsAttributes, ok
+}
+
+// getLiteralMap returns the items of a map attribute by key, and the keys in the order they are declared. The map can
+// only be tagged if its keys and values are literals.
+func getLiteralMap(attribute *hclsyntax.Attribute) (*hclsyntax.ObjectConsExpr, []string, map[string]hclsyntax.ObjectConsItem, bool) {
+	object, ok := attribute.Expr.(*hclsyntax.ObjectConsExpr)
+	if !ok {
+		return nil, nil, nil, false
+	}
+	keys := make([]string, 0, len(object.Items))
+	items := make(map[string]hclsyntax.ObjectConsItem)
+	for _, item := range object.Items {
+		key, diagnostics := item.KeyExpr.Value(nil)
+		if diagnostics.HasErrors() || !key.IsKnown() || key.Type() != cty.String {
+			return nil, nil, nil, false
+		}
+		value, diagnostics := item.ValueExpr.Value(nil)
+		if diagnostics.HasErrors() || !value.IsKnown() || value.Type() != cty.String {
+			return nil, nil, nil, false
+		}
+		keys = append(keys, key.AsString())
+		items[key.AsString()] = item
+	}
+	return object, keys, items, true
+}
+
+func getExistingTags(attribute *hclsyntax.Attribute) ([]tags.ITag, bool) {
+	existingTags := make([]tags.ITag, 0)
+	_, keys, items, ok := getLiteralMap(attribute)
+	if !ok {
+		return existingTags, false
+	}
+	for _, key := range keys {
+		value, _ := items[key].ValueExpr.Value(nil)
+		existingTags = append(existingTags, &tags.Tag{Key: key, Value: value.AsString()})
+	}
+	return existingTags, true
+}
+
+func (p *PackerParser) WriteFile(readFilePath string, blocks []structure.IBlock, writeFilePath string) error {
+	originFileSrc, err := utils.ReadFile(readFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s because %s", readFilePath, err)
+	}
+	body, err := parseHclFile(originFileSrc, readFilePath)
+	if err != nil {
+		return err
+	}
+	src := string(originFileSrc)
+
+	edits := make([]utils.TextEdit, 0)
+	for _, block := range body.Blocks {
+		tagsAttributes, ok := getTagsAttributes(block)
+		if !ok {
+			continue
+		}
+		for _, parsedBlock := range blocks {
+			packerBlock, ok := parsedBlock.(*PackerBlock)
+			if !ok || !packerBlock.IsBlockTaggable() || !reflect.DeepEqual(packerBlock.labels, block.Labels) {
+				continue
+			}
+			tagsToSet := getTagsToSet(packerBlock)
+			if len(tagsToSet) == 0 {
+				continue
+			}
+			for _, attributeName := range tagsAttributes {
+				edits = append(edits, getAttributeEdits(src, block, attributeName, tagsToSet)...)
+			}
+		}
+	}
+
+	textToWrite := utils.ApplyTextEdits(src, edits)
+	if _, err = parseHclFile([]byte(textToWrite), readFilePath); err != nil {
+		return fmt.Errorf("editing file %v resulted in a malformed template, please open a github issue with the relevant details", readFilePath)
+	}
+	return utils.WriteFile(writeFilePath, utils.MatchLineEndings(originFileSrc, []byte(textToWrite)))
+}
+
+// getTagsToSet returns the tags yor computed for the block, sorted by key so the file doesn't change between runs
+// computing the same tags
+func getTagsToSet(block *PackerBlock) []tags.ITag {
+	newTagKeys := make(map[string]bool)
+	for _, tag := range block.GetNewTags() {
+		newTagKeys[tag.GetKey()] = true
+	}
+	tagsToSet := make([]tags.ITag, 0, len(newTagKeys))
+	for _, tag := range block.MergeTags() {
+		if newTagKeys[tag.GetKey()] {
+			tagsToSet = append(tagsToSet, tag)
+		}
+	}
+	sort.Slice(tagsToSet, func(i, j int) bool {
+		return tagsToSet[i].GetKey() < tagsToSet[j].GetKey()
+	})
+	return tagsToSet
+}
+
+// getAttributeEdits returns the edits which set the tags in the map attribute, indented like the rest of the block
+func getAttributeEdits(src string, block *hclsyntax.Block, attributeName string, tagsToSet []tags.ITag) []utils.TextEdit {
+	edits := make([]utils.TextEdit, 0)
+	blockIndent := lineIndent(src, block.TypeRange.Start.Byte)
+	attributeIndent := blockIndent + "  "
+	if first := firstAttribute(block.Body); first != nil {
+		attributeIndent = lineIndent(src, first.SrcRange.Start.Byte)
+	}
+	indentUnit := "  "
+	if len(attributeIndent) > len(blockIndent) && strings.HasPrefix(attributeIndent, blockIndent) {
+		indentUnit = attributeIndent[len(blockIndent):]
+	}
+	itemIndent := attributeIndent + indentUnit
+
+	attribute, exists := block.Body.Attributes[attributeName]
+	if !exists {
+		text := attributeIndent + attributeName + " = " + formatMap(tagsToSet, attributeIndent, itemIndent)
+		closeBrace := block.CloseBraceRange.Start.Byte
+		lineStart := strings.LastIndex(src[:closeBrace], "\n") + 1
+		if strings.TrimSpace(src[lineStart:closeBrace]) == "" {
+			return append(edits, utils.TextEdit{Start: lineStart, End: lineStart, Text: text + "\n"})
+		}
+		return append(edits, utils.TextEdit{Start: closeBrace, End: closeBrace, Text: "\n" + text + "\n" + blockIndent})
+	}
+
+	object, _, items, ok := getLiteralMap(attribute)
+	if !ok {
+		logger.Debug(fmt.Sprintf("Skipping %s of source %s, they are not a literal map", attributeName, strings.Join(block.Labels, ".")))
+		return edits
+	}
+	addedTags := make([]tags.ITag, 0)
+	for _, tag := range tagsToSet {
+		item, exists := items[tag.GetKey()]
+		if !exists {
+			addedTags = append(addedTags, tag)
+			continue
+		}
+		if value, _ := item.ValueExpr.Value(nil); value.AsString() != tag.GetValue() {
+			valueRange := item.ValueExpr.Range()
+			edits = append(edits, utils.TextEdit{Start: valueRange.Start.Byte, End: valueRange.End.Byte, Text: formatString(tag.GetValue())})
+		}
+	}
+	if len(addedTags) == 0 {
+		return edits
+	}
+	if len(object.Items) == 0 {
+		indent := lineIndent(src, attribute.SrcRange.Start.Byte)
+		return append(edits, utils.TextEdit{Start: object.SrcRange.Start.Byte, End: object.SrcRange.End.Byte, Text: formatMap(addedTags, indent, indent+indentUnit)})
+	}
+	firstItem := object.Items[0].KeyExpr.Range()
+	separator := ", "
+	if firstItem.Start.Line != object.SrcRange.Start.Line {
+		separator = "\n" + lineIndent(src, firstItem.Start.Byte)
+	}
+	text := ""
+	for _, tag := range addedTags {
+		text += separator + formatItem(tag)
+	}
+	lastItemEnd := object.Items[len(object.Items)-1].ValueExpr.Range().End.Byte
+	return append(edits, utils.TextEdit{Start: lastItemEnd, End: lastItemEnd, Text: text})
+}
+
+func firstAttribute(body *hclsyntax.Body) *hclsyntax.Attribute {
+	var first *hclsyntax.Attribute
+	for _, attribute := range body.Attributes {
+		if first == nil || attribute.SrcRange.Start.Byte < first.SrcRange.Start.Byte {
+			first = attribute
+		}
+	}
+	return first
+}
+
+func formatMap(mapTags []tags.ITag, indent string, itemIndent string) string {
+	text := "{\n"
+	for _, tag := range mapTags {
+		text += itemIndent + formatItem(tag) + "\n"
+	}
+	return text + indent + "}"
+}
+
+func formatItem(tag tags.ITag) string {
+	key := tag.GetKey()
+	if !hclsyntax.ValidIdentifier(key) {
+		key = formatString(key)
+	}
+	return key + " = " + formatString(tag.GetValue())
+}
+
+// formatString quotes the value as an HCL string, escaping template sequences
+func formatString(value string) string {
+	hclWriteLock.Lock()
+	defer hclWriteLock.Unlock()
+	return string(hclwrite.TokensForValue(cty.StringVal(value)).Bytes())
+}
+
+func lineIndent(src string, index int) string {
+	line := src[strings.LastIndex(src[:index], "\n")+1 : index]
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
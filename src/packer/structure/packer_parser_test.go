@@ -0,0 +1,81 @@
+package structure
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bridgecrewio/yor/src/common/structure"
+	"github.com/bridgecrewio/yor/src/common/tagging/simple"
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackerParser_ValidFile(t *testing.T) {
+	packerParser := PackerParser{}
+	packerParser.Init("../../../tests/packer/resources/images", nil)
+	assert.True(t, packerParser.ValidFile("../../../tests/packer/resources/images/images.pkr.hcl"))
+
+	dir := t.TempDir()
+	variablesFile := filepath.Join(dir, "variables.pkr.hcl")
+	assert.Nil(t, os.WriteFile(variablesFile, []byte("variable \"region\" {\n  default = \"us-east-1\"\n}\n"), 0600))
+	assert.False(t, packerParser.ValidFile(variablesFile))
+	malformedFile := filepath.Join(dir, "malformed.pkr.hcl")
+	assert.Nil(t, os.WriteFile(malformedFile, []byte("source \"amazon-ebs\" \"ubuntu\" {\n"), 0600))
+	assert.False(t, packerParser.ValidFile(malformedFile))
+}
+
+func TestPackerParser_ParseFile(t *testing.T) {
+	packerParser := PackerParser{}
+	packerParser.Init("../../../tests/packer/resources/images", nil)
+	blocks, err := packerParser.ParseFile("../../../tests/packer/resources/images/images.pkr.hcl")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(blocks))
+
+	expected := []struct {
+		name         string
+		resourceType string
+		isTaggable   bool
+		lines        structure.Lines
+		tagsLines    structure.Lines
+		existingTags []tags.ITag
+	}{
+		{"amazon-ebs.ubuntu", "amazon-ebs", true, structure.Lines{Start: 15, End: 23}, structure.Lines{Start: 19, End: 22}, []tags.ITag{&tags.Tag{Key: "Name", Value: "ubuntu-base"}, &tags.Tag{Key: "new_tag", Value: "old_value"}}},
+		{"azure-arm.windows", "azure-arm", true, structure.Lines{Start: 25, End: 29}, structure.Lines{Start: -1, End: -1}, []tags.ITag{}},
+		{"googlecompute.debian", "googlecompute", true, structure.Lines{Start: 31, End: 37}, structure.Lines{Start: 35, End: 35}, []tags.ITag{&tags.Tag{Key: "team", Value: "platform"}}},
+		{"amazon-ebs.dynamic", "amazon-ebs", false, structure.Lines{Start: 39, End: 42}, structure.Lines{Start: 41, End: 41}, []tags.ITag{}},
+	}
+	for i, block := range blocks {
+		assert.Equal(t, expected[i].name, block.GetResourceID())
+		assert.Equal(t, expected[i].resourceType, block.GetResourceType())
+		assert.Equal(t, expected[i].isTaggable, block.IsBlockTaggable())
+		assert.Equal(t, expected[i].lines, block.GetLines())
+		assert.Equal(t, expected[i].tagsLines, block.GetTagsLines())
+		assert.Equal(t, expected[i].existingTags, block.GetExistingTags())
+	}
+	assert.True(t, blocks[2].IsGCPBlock())
+}
+
+func TestPackerParser_WriteFile(t *testing.T) {
+	directory := "../../../tests/packer/resources/images"
+	packerParser := PackerParser{}
+	packerParser.Init(directory, nil)
+	readFilePath := directory + "/images.pkr.hcl"
+	tagGroup := simple.TagGroup{}
+	tagGroup.SetTags([]tags.ITag{
+		&tags.Tag{Key: "new_tag", Value: "new_value"},
+		&tags.Tag{Key: "yor_trace", Value: "123"},
+	})
+	tagGroup.InitTagGroup("", []string{}, []string{})
+	blocks, err := packerParser.ParseFile(readFilePath)
+	assert.Nil(t, err)
+	for _, block := range blocks {
+		assert.Nil(t, tagGroup.CreateTagsForBlock(block))
+	}
+	writeFilePath := filepath.Join(t.TempDir(), "images.pkr.hcl")
+	assert.Nil(t, packerParser.WriteFile(readFilePath, blocks, writeFilePath))
+
+	expectedContent, _ := os.ReadFile(directory + "/images_tagged.pkr.hcl")
+	actualContent, _ := os.ReadFile(writeFilePath)
+	assert.Equal(t, string(expectedContent), string(actualContent))
+}
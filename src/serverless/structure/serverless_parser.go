@@ -1,12 +1,13 @@
 package structure
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
-	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/awslabs/goformation/v4/intrinsics"
 	"github.com/bridgecrewio/yor/src/common"
 	"github.com/bridgecrewio/yor/src/common/logger"
 	"github.com/bridgecrewio/yor/src/common/structure"
@@ -14,7 +15,6 @@ import (
 	"github.com/bridgecrewio/yor/src/common/types"
 	"github.com/bridgecrewio/yor/src/common/utils"
 	yamlUtils "github.com/bridgecrewio/yor/src/common/yaml"
-	"github.com/thepauleh/goserverless"
 	"github.com/thepauleh/goserverless/serverless"
 )
 
@@ -55,12 +55,23 @@ func goserverlessParse(file string) (*serverless.Template, error) {
 		}
 	}()
 
-	template, err = goserverless.Open(file)
+	data, err := utils.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	// like goserverless.Open, which only reads the file system of the OS
+	intrinsified, err := intrinsics.ProcessYAML(data, nil)
+	if err != nil {
+		return nil, err
+	}
+	template = &serverless.Template{}
+	err = json.Unmarshal(intrinsified, template)
 	return template, err
 }
 
-func (p *ServerlessParser) ValidFile(_ string) bool {
-	return true
+// ValidFile Validate file is a serverless framework configuration, which is always named serverless.<ext>
+func (p *ServerlessParser) ValidFile(filePath string) bool {
+	return filepath.Base(filePath) == fmt.Sprintf("serverless.%s", utils.GetFileFormat(filePath))
 }
 
 func (p *ServerlessParser) ParseFile(filePath string) ([]structure.IBlock, error) {
@@ -70,7 +81,6 @@ func (p *ServerlessParser) ParseFile(filePath string) ([]structure.IBlock, error
 	if !(fileName == fmt.Sprintf("serverless.%s", fileFormat)) {
 		return nil, nil
 	}
-	// #nosec G304 - file is from user
 	template, err := goserverlessParse(filePath)
 	if err != nil || template == nil || template.Functions == nil {
 		if err != nil {
@@ -88,12 +98,18 @@ func (p *ServerlessParser) ParseFile(filePath string) ([]structure.IBlock, error
 	// cfnStackTagsResource := p.template.Provider.CFNTags
 	resourceNames := make([]string, 0)
 	var resourceNamesToLines map[string]*structure.Lines
+	var fileLines []string
 	for funcName := range template.Functions {
 		resourceNames = append(resourceNames, funcName)
 	}
 	switch utils.GetFileFormat(filePath) {
 	case common.YmlFileType.FileFormat, common.YamlFileType.FileFormat:
 		resourceNamesToLines = yamlUtils.MapResourcesLineYAML(filePath, resourceNames, FunctionsSectionName)
+		src, err := utils.ReadFile(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file %s because %s", filePath, err)
+		}
+		fileLines = utils.GetLinesFromBytes(src)
 	default:
 		return nil, fmt.Errorf("unsupported file type %s", utils.GetFileFormat(filePath))
 	}
@@ -108,7 +124,12 @@ func (p *ServerlessParser) ParseFile(filePath string) ([]structure.IBlock, error
 		lines = resourceNamesToLines[funcName]
 		minResourceLine = int(math.Min(float64(minResourceLine), float64(lines.Start)))
 		maxResourceLine = int(math.Max(float64(maxResourceLine), float64(lines.End)))
-		if slsFunction.Tags != nil {
+		isTaggable := true
+		if lines.Start >= 0 && !yamlUtils.AreTagsEditable(fileLines[lines.Start:lines.End+1], FunctionTagsAttributeName) {
+			logger.Info(fmt.Sprintf("Skipping function %s in %s, its tags are set by a YAML alias, anchor or flow collection", funcName, filePath))
+			isTaggable = false
+		}
+		if isTaggable && slsFunction.Tags != nil {
 			tagsLines = p.getTagsLines(filePath, lines)
 			for tagKey, tagValue := range slsFunction.Tags {
 				existingTags = append(existingTags, &tags.Tag{Key: tagKey, Value: fmt.Sprintf("%v", tagValue)})
@@ -120,7 +141,7 @@ func (p *ServerlessParser) ParseFile(filePath string) ([]structure.IBlock, error
 				FilePath:          filePath,
 				ExitingTags:       existingTags,
 				RawBlock:          slsFunction,
-				IsTaggable:        true,
+				IsTaggable:        isTaggable,
 				TagsAttributeName: FunctionTagsAttributeName,
 				Lines:             *lines,
 				TagLines:          tagsLines,
@@ -140,18 +161,18 @@ func (p *ServerlessParser) WriteFile(readFilePath string, blocks []structure.IBl
 		block := block.(*ServerlessBlock)
 		block.UpdateTags()
 	}
-	tempFile, err := os.CreateTemp(filepath.Dir(readFilePath), "temp.*.yaml")
-	defer func() {
-		_ = os.Remove(tempFile.Name())
-	}()
+	tempFile, err := utils.CreateTemp(filepath.Dir(readFilePath), "temp.*.yaml")
 	if err != nil {
 		return err
 	}
-	err = yamlUtils.WriteYAMLFile(readFilePath, blocks, tempFile.Name(), FunctionTagsAttributeName, FunctionsSectionName)
+	defer func() {
+		_ = utils.Remove(tempFile)
+	}()
+	err = yamlUtils.WriteYAMLFile(readFilePath, blocks, tempFile, FunctionTagsAttributeName, FunctionsSectionName)
 	if err != nil {
 		return err
 	}
-	_, err = p.ParseFile(tempFile.Name())
+	_, err = p.ParseFile(tempFile)
 	if err != nil {
 		return fmt.Errorf("editing file %v resulted in a malformed template, please open a github issue with the relevant details", readFilePath)
 	}
@@ -77,6 +77,36 @@ func TestServerlessParser_ParseFile(t *testing.T) {
 	})
 }
 
+func TestServerlessParser_ParseFileWithAnchors(t *testing.T) {
+	dir := t.TempDir()
+	slsFilepath := filepath.Join(dir, "serverless.yml")
+	content := `service: anchors
+provider:
+  name: aws
+  runtime: nodejs14.x
+functions:
+  first:
+    handler: first.handler
+    tags: &shared
+      team: platform
+  second:
+    handler: second.handler
+    tags: *shared
+  third:
+    handler: third.handler
+`
+	assert.Nil(t, os.WriteFile(slsFilepath, []byte(content), 0600))
+	slsParser := ServerlessParser{}
+	slsParser.Init(dir, nil)
+	slsBlocks, err := slsParser.ParseFile(slsFilepath)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(slsBlocks))
+	for _, block := range slsBlocks {
+		// tagging the anchored tags would change the tags of the functions using the alias too
+		assert.Equal(t, block.GetResourceID() == "third", block.IsBlockTaggable(), block.GetResourceID())
+	}
+}
+
 func compareLines(t *testing.T, expected map[string]*structure.Lines, actual map[string]*structure.Lines) {
 	for resourceName := range expected {
 		actualLines := actual[resourceName]
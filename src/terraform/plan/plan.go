@@ -0,0 +1,199 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bridgecrewio/yor/src/common/i18n"
+	"github.com/bridgecrewio/yor/src/common/structure"
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+	"github.com/bridgecrewio/yor/src/common/tagpolicy"
+	"github.com/bridgecrewio/yor/src/common/utils"
+	tfStructure "github.com/bridgecrewio/yor/src/terraform/structure"
+	"github.com/olekukonko/tablewriter"
+)
+
+// StdinPath reads the plan from stdin, so the output of terraform show -json can be piped to yor
+const StdinPath = "-"
+
+const (
+	createAction    = "create"
+	managedMode     = "managed"
+	tagsAllSuffix   = "_all"
+	unknownTags     = "tags are unknown until apply"
+	unknownTagValue = "value is unknown until apply"
+)
+
+// Plan is the part of the JSON output of terraform show -json which holds the planned changes of the resources
+type Plan struct {
+	ResourceChanges []ResourceChange `json:"resource_changes"`
+}
+
+type ResourceChange struct {
+	Address      string `json:"address"`
+	Mode         string `json:"mode"`
+	Type         string `json:"type"`
+	ProviderName string `json:"provider_name"`
+	Change       Change `json:"change"`
+}
+
+// Change holds the attributes of the resource after apply. The attributes which are unknown until apply are set in
+// AfterUnknown, either to true, or to the same structure as the attribute for the values which are unknown in it.
+type Change struct {
+	Actions      []string               `json:"actions"`
+	After        map[string]interface{} `json:"after"`
+	AfterUnknown map[string]interface{} `json:"after_unknown"`
+}
+
+// Violation is a tag which a resource to create would lack at apply time, or whose key or value wouldn't comply with
+// its rule
+type Violation struct {
+	Address      string `json:"address"`
+	ResourceType string `json:"resourceType"`
+	TagKey       string `json:"key"`
+	Reason       string `json:"reason"`
+	Severity     string `json:"severity"`
+}
+
+type Result struct {
+	CreatedResources  int         `json:"createdResources"`
+	TaggableResources int         `json:"taggableResources"`
+	Violations        []Violation `json:"violations"`
+}
+
+// Load reads the JSON output of terraform show -json of a plan file, from stdin if planPath is StdinPath
+func Load(planPath string) (*Plan, error) {
+	var planBytes []byte
+	var err error
+	if planPath == StdinPath {
+		planBytes, err = io.ReadAll(os.Stdin)
+	} else {
+		// #nosec G304 - file is from user
+		planBytes, err = os.ReadFile(planPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the plan %s: %s", planPath, err)
+	}
+	plan := &Plan{}
+	if err = json.Unmarshal(planBytes, plan); err != nil {
+		return nil, fmt.Errorf("failed to parse the plan %s, expected the output of terraform show -json: %s", planPath, err)
+	}
+	return plan, nil
+}
+
+// Check validates the tags which the resources to create would have at apply time, i.e. with the provider's default
+// tags and the values of the expressions, against the yor_trace tag and the required tags rules. Values which are
+// unknown until apply can't be validated, so they are reported with the info severity. The key names rename yor_trace.
+func Check(plan *Plan, requiredTags *tagpolicy.RequiredTags, keyNames tags.KeyNames) *Result {
+	rules := &tagpolicy.RequiredTags{Tags: []tagpolicy.RequiredTag{{Key: keyNames.Get(tags.YorTraceTagKey)}}}
+	if requiredTags != nil {
+		rules.Tags = append(rules.Tags, requiredTags.Tags...)
+	}
+	result := &Result{Violations: []Violation{}}
+	for _, resourceChange := range plan.ResourceChanges {
+		if resourceChange.Mode != managedMode || !utils.InSlice(resourceChange.Change.Actions, createAction) {
+			continue
+		}
+		result.CreatedResources++
+		attribute := getTagsAttribute(resourceChange)
+		if attribute == "" {
+			continue
+		}
+		result.TaggableResources++
+		result.Violations = append(result.Violations, checkResource(resourceChange, attribute, rules)...)
+	}
+	sort.SliceStable(result.Violations, func(i, j int) bool {
+		return result.Violations[i].Address < result.Violations[j].Address
+	})
+	return result
+}
+
+// CountViolations returns the number of the violations of the severity
+func (r *Result) CountViolations(severity string) int {
+	count := 0
+	for _, violation := range r.Violations {
+		if violation.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+func (r *Result) Print(w io.Writer) {
+	_, _ = fmt.Fprintf(w, "%s: %d\n", i18n.T("Resources to Create"), r.CreatedResources)
+	_, _ = fmt.Fprintf(w, "%s: %d\n", i18n.T("Taggable Resources"), r.TaggableResources)
+	_, _ = fmt.Fprintf(w, "%s: %d\n", i18n.T("Required Tag Violations"), len(r.Violations))
+	if len(r.Violations) == 0 {
+		return
+	}
+	table := tablewriter.NewWriter(w)
+	table.SetHeader(i18n.Headers("Resource", "Tag Key", "Reason", "Severity"))
+	table.SetAutoWrapText(false)
+	for _, violation := range r.Violations {
+		table.Append([]string{violation.Address, violation.TagKey, violation.Reason, violation.Severity})
+	}
+	table.Render()
+}
+
+// getTagsAttribute returns the attribute which holds the tags of the resource at apply time, or an empty string if the
+// resource can't be tagged. The attributes of the resources are all set in the plan, so the attribute of a taggable
+// resource is set, if only to null. The tags_all attribute of the AWS resources also has the provider's default tags.
+func getTagsAttribute(resourceChange ResourceChange) string {
+	provider := resourceChange.ProviderName[strings.LastIndex(resourceChange.ProviderName, "/")+1:]
+	if provider == "" {
+		provider = strings.Split(resourceChange.Type, "_")[0]
+	}
+	attribute, ok := tfStructure.ProviderToTagAttribute[provider]
+	if !ok {
+		attribute = "tags"
+	}
+	for _, name := range []string{attribute + tagsAllSuffix, attribute} {
+		_, isSet := resourceChange.Change.After[name]
+		_, isUnknown := resourceChange.Change.AfterUnknown[name]
+		if isSet || isUnknown {
+			return name
+		}
+	}
+	return ""
+}
+
+func checkResource(resourceChange ResourceChange, attribute string, rules *tagpolicy.RequiredTags) []Violation {
+	var violations []Violation
+	newViolation := func(tagKey string, reason string, severity string) Violation {
+		return Violation{Address: resourceChange.Address, ResourceType: resourceChange.Type, TagKey: tagKey, Reason: reason, Severity: severity}
+	}
+	block := &structure.Block{Name: resourceChange.Address, Type: resourceChange.Type, IsTaggable: true}
+	unknownValues := resourceChange.Change.AfterUnknown[attribute]
+	if unknownValues == true {
+		// every rule which applies to the resource is missing from its empty tags
+		for _, violation := range rules.Validate(block) {
+			violations = append(violations, newViolation(violation.TagKey, unknownTags, tagpolicy.SeverityInfo))
+		}
+		return violations
+	}
+	unknownKeys, _ := unknownValues.(map[string]interface{})
+	values, _ := resourceChange.Change.After[attribute].(map[string]interface{})
+	for key, value := range values {
+		if value != nil {
+			block.ExitingTags = append(block.ExitingTags, tags.Init(key, fmt.Sprint(value)))
+		}
+	}
+	for key := range unknownKeys {
+		if values[key] == nil {
+			block.ExitingTags = append(block.ExitingTags, tags.Init(key, ""))
+		}
+	}
+	for _, violation := range rules.Validate(block) {
+		// a tag with the key of the rule violates it by its value, which can't be validated if it is unknown
+		if unknownKeys[violation.TagKey] == true {
+			violations = append(violations, newViolation(violation.TagKey, unknownTagValue, tagpolicy.SeverityInfo))
+			continue
+		}
+		violations = append(violations, newViolation(violation.TagKey, violation.Reason, violation.Severity))
+	}
+	return violations
+}
@@ -0,0 +1,53 @@
+package plan
+
+import (
+	"os"
+	"testing"
+
+	"github.com/bridgecrewio/yor/src/common/tagpolicy"
+	"github.com/bridgecrewio/yor/tests/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckPlan(t *testing.T) {
+	plan, err := Load("../../../tests/terraform/plan/plan.json")
+	assert.Nil(t, err)
+	requiredTags, err := tagpolicy.LoadRequiredTags("../../../tests/terraform/plan/required-tags.yml")
+	assert.Nil(t, err)
+
+	t.Run("Check the tags of the resources to create", func(t *testing.T) {
+		result := Check(plan, requiredTags, nil)
+		assert.Equal(t, 5, result.CreatedResources)
+		assert.Equal(t, 4, result.TaggableResources)
+		assert.Equal(t, []Violation{
+			{Address: "aws_iam_role.computed", ResourceType: "aws_iam_role", TagKey: "yor_trace", Reason: unknownTags, Severity: tagpolicy.SeverityInfo},
+			{Address: "aws_iam_role.computed", ResourceType: "aws_iam_role", TagKey: "env", Reason: unknownTags, Severity: tagpolicy.SeverityInfo},
+			{Address: "aws_iam_role.computed", ResourceType: "aws_iam_role", TagKey: "team", Reason: unknownTags, Severity: tagpolicy.SeverityInfo},
+			{Address: "aws_s3_bucket.untagged", ResourceType: "aws_s3_bucket", TagKey: "yor_trace", Reason: "missing", Severity: tagpolicy.SeverityError},
+			{Address: "aws_s3_bucket.untagged", ResourceType: "aws_s3_bucket", TagKey: "team", Reason: "missing", Severity: tagpolicy.SeverityWarning},
+			{Address: "module.network.aws_vpc.main", ResourceType: "aws_vpc", TagKey: "env", Reason: unknownTagValue, Severity: tagpolicy.SeverityInfo},
+		}, result.Violations)
+		assert.Equal(t, 1, result.CountViolations(tagpolicy.SeverityError))
+	})
+
+	t.Run("Check only the yor_trace tag without rules", func(t *testing.T) {
+		result := Check(plan, nil, nil)
+		assert.Equal(t, 2, len(result.Violations))
+		assert.Equal(t, "yor_trace", result.Violations[1].TagKey)
+		assert.Equal(t, "aws_s3_bucket.untagged", result.Violations[1].Address)
+	})
+
+	t.Run("Print the violations", func(t *testing.T) {
+		result := Check(plan, requiredTags, nil)
+		output := utils.CaptureOutput(func() {
+			result.Print(os.Stdout)
+		})
+		assert.Contains(t, output, "Resources to Create: 5")
+		assert.Contains(t, output, "module.network.aws_vpc.main")
+	})
+
+	t.Run("Fail on other files than the plan JSON", func(t *testing.T) {
+		_, err := Load("../../../tests/terraform/plan/required-tags.yml")
+		assert.NotNil(t, err)
+	})
+}
@@ -1,3 +1,5 @@
+//go:build !js
+
 package structure
 
 import "github.com/bridgecrewio/yor/src/common/logger"
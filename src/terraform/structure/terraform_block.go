@@ -1,6 +1,7 @@
 package structure
 
 import (
+	"path/filepath"
 	"strings"
 
 	"github.com/bridgecrewio/yor/src/common/structure"
@@ -11,6 +12,14 @@ import (
 type TerraformBlock struct {
 	structure.Block
 	HclSyntaxBlock *hclsyntax.Block
+	// Imported is set on resources which are the target of an import block of their module
+	Imported bool
+	// UneditableTagsReason is set on blocks which aren't tagged since tags can't be added to their tags expression
+	UneditableTagsReason string
+	// ModuleSource is the source of the module called by module blocks
+	ModuleSource string
+	// APIDefinitionFile is the local OpenAPI definition read by the body of an API Gateway API
+	APIDefinitionFile string
 }
 
 var ProviderToTagAttribute = map[string]string{"aws": "tags", "azurerm": "tags", "google": "labels", "oci": "freeform_tags", "alicloud": "tags"}
@@ -21,13 +30,40 @@ const DataBlockType = "data"
 const LocalBlockType = "local"
 const VarBlockType = "var"
 const VariableBlockType = "variable"
+const ImportBlockType = "import"
 
 var SupportedBlockTypes = []string{ResourceBlockType, ModuleBlockType, VariableBlockType}
 
+// APIResourceTypes are the resource types whose body can be an OpenAPI definition read from a file
+var APIResourceTypes = []string{"aws_api_gateway_rest_api", "aws_apigatewayv2_api"}
+
+const APIBodyAttributeName = "body"
+
 func (b *TerraformBlock) GetResourceID() string {
 	return strings.Join(b.HclSyntaxBlock.Labels, ".")
 }
 
+func (b *TerraformBlock) IsImported() bool {
+	return b.Imported
+}
+
+func (b *TerraformBlock) GetUneditableTagsReason() string {
+	return b.UneditableTagsReason
+}
+
+// GetModuleSource returns the directory of the module called by the block if it's a local module, or its source
+// otherwise
+func (b *TerraformBlock) GetModuleSource() string {
+	if strings.HasPrefix(b.ModuleSource, "./") || strings.HasPrefix(b.ModuleSource, "../") {
+		return filepath.Join(filepath.Dir(b.FilePath), b.ModuleSource)
+	}
+	return b.ModuleSource
+}
+
+func (b *TerraformBlock) GetAPIDefinitionFile() string {
+	return b.APIDefinitionFile
+}
+
 func (b *TerraformBlock) AddHclSyntaxBlock(hclSyntaxBlock *hclsyntax.Block) {
 	b.HclSyntaxBlock = hclSyntaxBlock
 }
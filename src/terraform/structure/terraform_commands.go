@@ -0,0 +1,102 @@
+//go:build !js
+
+package structure
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/bridgecrewio/yor/src/common/utils"
+	"github.com/hashicorp/go-hclog"
+	"github.com/hashicorp/terraform/command"
+	"github.com/minamijoyo/tfschema/tfschema"
+)
+
+// terraformCommands runs the provider plugins which return the schemas of the resource types, and terraform get, which
+// downloads the modules. They aren't built for the browser, which can't run them (see terraform_commands_js.go).
+type terraformCommands struct {
+	providerToClientMap sync.Map
+	moduleImporter      *command.GetCommand
+	tfClientLock        sync.Mutex
+}
+
+func (p *TerraformParser) initCommands() {
+	p.commands.moduleImporter = &command.GetCommand{Meta: command.Meta{Color: false, Ui: customTfLogger{}}}
+}
+
+func (p *TerraformParser) Close() {
+	logger.MuteOutputBlock(func() {
+		p.commands.providerToClientMap.Range(func(provider, iClient interface{}) bool {
+			client := iClient.(tfschema.Client)
+			client.Close()
+			return true
+		})
+	})
+}
+
+func (p *TerraformParser) downloadModules(dir string) {
+	_ = p.commands.moduleImporter.Run([]string{dir})
+}
+
+// hasSchemaAttribute returns whether the schema of the resource type has the attribute. Resource types whose provider
+// can't be loaded don't have it.
+func (p *TerraformParser) hasSchemaAttribute(providerName string, resourceType string, attribute string) (bool, error) {
+	client := p.getClient(providerName)
+	if client == nil {
+		return false, nil
+	}
+	var typeSchema *tfschema.Block
+	var err error
+	logger.MuteOutputBlock(func() {
+		typeSchema, err = client.GetResourceTypeSchema(resourceType)
+	})
+	if err != nil {
+		return false, err
+	}
+	_, ok := typeSchema.Attributes[attribute]
+	return ok, nil
+}
+
+func (p *TerraformParser) getClient(providerName string) tfschema.Client {
+	if utils.InSlice(SkippedProviders, providerName) {
+		return nil
+	}
+
+	p.commands.tfClientLock.Lock()
+	defer p.commands.tfClientLock.Unlock()
+
+	client, exists := p.commands.providerToClientMap.Load(providerName)
+	if exists {
+		return client.(tfschema.Client)
+	}
+
+	hclLogger := hclog.New(&hclog.LoggerOptions{
+		Name:   "plugin",
+		Level:  hclog.Error,
+		Output: hclog.DefaultOutput,
+	})
+	var err error
+	var newClient tfschema.Client
+	if p.terraformModule == nil {
+		logger.Warning(fmt.Sprintf("Failed to initialize terraform module, it might be due to a malformed file in the given root dir: [%s]", p.rootDir))
+		return nil
+	}
+	logger.MuteOutputBlock(func() {
+		newClient, err = tfschema.NewClient(providerName, tfschema.Option{
+			RootDir: p.terraformModule.ProvidersInstallDir,
+			Logger:  hclLogger,
+		})
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "Failed to find plugin") {
+			logger.Warning(fmt.Sprintf("Could not load provider %v, resources from this provider will not be tagged", providerName))
+			logger.Warning(fmt.Sprintf("Try to run `terraform init` in the given root dir: [%s] and try again.", p.rootDir))
+		}
+		return nil
+	}
+
+	p.commands.providerToClientMap.Store(providerName, newClient)
+	return newClient
+}
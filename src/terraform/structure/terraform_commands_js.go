@@ -0,0 +1,18 @@
+package structure
+
+// In the browser, there are no provider plugins to return the schemas of the resource types, so only the resource
+// types of TfTaggableResourceTypes are tagged, and no modules to download, so only the modules which are already
+// parsed are searched for tags attributes.
+type terraformCommands struct{}
+
+func (p *TerraformParser) initCommands() {}
+
+func (p *TerraformParser) Close() {}
+
+func (p *TerraformParser) downloadModules(_ string) {}
+
+func (p *TerraformParser) hasSchemaAttribute(_ string, _ string, _ string) (bool, error) {
+	return false, nil
+}
+
+func (t *TerraformModule) InitProvider() {}
@@ -0,0 +1,121 @@
+package structure
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/bridgecrewio/yor/src/common"
+	"github.com/bridgecrewio/yor/src/common/json"
+	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/bridgecrewio/yor/src/common/utils"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+const importTargetAttributeName = "to"
+
+// isImportedResource checks if an import block of the module of the file targets the resource. The import blocks of
+// the file itself are given by the caller, since its content may not be saved yet.
+func (p *TerraformParser) isImportedResource(filePath string, resourceID string, fileImportTargets []string) bool {
+	if utils.InSlice(fileImportTargets, resourceID) {
+		return true
+	}
+	return p.getImportedResources(filepath.Dir(filePath))[resourceID]
+}
+
+// getImportedResources returns the resources targeted by the import blocks of the files of the module in dir. Import
+// blocks usually live in a separate file from the resources they import, so the whole module is read once.
+func (p *TerraformParser) getImportedResources(dir string) map[string]bool {
+	p.importedResourcesLock.Lock()
+	defer p.importedResourcesLock.Unlock()
+	if p.importedResources == nil {
+		p.importedResources = make(map[string]map[string]bool)
+	}
+	if importedResources, ok := p.importedResources[dir]; ok {
+		return importedResources
+	}
+	importedResources := make(map[string]bool)
+	p.importedResources[dir] = importedResources
+
+	entries, err := utils.ReadDir(dir)
+	if err != nil {
+		logger.Debug(fmt.Sprintf("failed to read the import blocks of %s: %s", dir, err))
+		return importedResources
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		filePath := filepath.Join(dir, entry.Name())
+		var targets []string
+		switch {
+		case strings.HasSuffix(entry.Name(), common.TfFileType.Extension):
+			src, err := utils.ReadFile(filePath)
+			if err != nil {
+				continue
+			}
+			hclSyntaxFile, diagnostics := hclsyntax.ParseConfig(src, filePath, hcl.InitialPos)
+			if diagnostics != nil && diagnostics.HasErrors() {
+				continue
+			}
+			targets = getHclImportTargets(src, hclSyntaxFile.Body.(*hclsyntax.Body).Blocks)
+		case strings.HasSuffix(entry.Name(), common.TfJSONFileType.Extension):
+			src, err := utils.ReadFile(filePath)
+			if err != nil {
+				continue
+			}
+			root, err := json.ParseJSONNodes(string(src))
+			if err != nil {
+				continue
+			}
+			targets = getJSONImportTargets(root)
+		}
+		for _, target := range targets {
+			importedResources[target] = true
+		}
+	}
+	return importedResources
+}
+
+func getHclImportTargets(src []byte, blocks hclsyntax.Blocks) []string {
+	targets := make([]string, 0)
+	for _, block := range blocks {
+		if block.Type != ImportBlockType {
+			continue
+		}
+		if attribute, ok := block.Body.Attributes[importTargetAttributeName]; ok {
+			exprRange := attribute.Expr.Range()
+			if target := getImportedResourceID(string(src[exprRange.Start.Byte:exprRange.End.Byte])); target != "" {
+				targets = append(targets, target)
+			}
+		}
+	}
+	return targets
+}
+
+func getJSONImportTargets(root *json.Node) []string {
+	targets := make([]string, 0)
+	for _, body := range getJSONBlockBodies(root.Get(ImportBlockType)) {
+		if target := getImportedResourceID(body.Get(importTargetAttributeName).GetString()); target != "" {
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// getImportedResourceID returns the ID of the resource of the module an import block's target address refers to, i.e.
+// aws_s3_bucket.b for aws_s3_bucket.b["key"]. Resources of child modules are imported by their own module's address
+// and aren't tagged through it, so they are ignored.
+func getImportedResourceID(address string) string {
+	address = strings.TrimSpace(address)
+	address = strings.TrimSuffix(strings.TrimPrefix(address, "${"), "}")
+	if index := strings.Index(address, "["); index >= 0 {
+		address = address[:index]
+	}
+	parts := strings.Split(address, ".")
+	if len(parts) != 2 || parts[0] == ModuleBlockType {
+		return ""
+	}
+	return address
+}
@@ -0,0 +1,224 @@
+package structure
+
+import (
+	stdjson "encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bridgecrewio/yor/src/common/json"
+	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/bridgecrewio/yor/src/common/structure"
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+	"github.com/bridgecrewio/yor/src/common/utils"
+)
+
+// jsonCommentKey is the property name terraform ignores in JSON syntax files, to allow adding comments to them
+const jsonCommentKey = "//"
+
+// TerraformJSONBlock is a resource of a terraform file written in the JSON syntax (*.tf.json)
+type TerraformJSONBlock struct {
+	structure.Block
+	// Imported is set on resources which are the target of an import block of their module
+	Imported bool
+}
+
+type terraformJSONResource struct {
+	resourceType string
+	name         string
+	node         *json.Node
+}
+
+func (b *TerraformJSONBlock) IsImported() bool {
+	return b.Imported
+}
+
+func (b *TerraformJSONBlock) GetSeparator() string {
+	return ":"
+}
+
+func (b *TerraformJSONBlock) IsGCPBlock() bool {
+	return strings.HasPrefix(b.GetResourceType(), "google_") || b.GetTagsAttributeName() == ProviderToTagAttribute["google"]
+}
+
+// parseJSONBuffer parses src as a terraform file in the JSON syntax. Only resource blocks are tagged in these files.
+func (p *TerraformParser) parseJSONBuffer(filePath string, src []byte) ([]structure.IBlock, error) {
+	root, err := json.ParseJSONNodes(string(src))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse terraform json file %s because %s", filePath, err)
+	}
+
+	fileImportTargets := getJSONImportTargets(root)
+	parsedBlocks := make([]structure.IBlock, 0)
+	for _, resource := range collectJSONResources(root) {
+		blockID := resource.resourceType + "." + resource.name
+		providerName := getProviderFromResourceType(resource.resourceType)
+		if utils.InSlice(SkippedProviders, providerName) {
+			continue
+		}
+		tagsAttributeName, err := getTagAttributeByResourceType(resource.resourceType)
+		if err != nil {
+			logger.Warning(fmt.Sprintf("failed to parse terraform block because %s", err.Error()))
+			continue
+		}
+		isTaggable, err := p.isResourceTypeTaggable(resource.resourceType)
+		if err != nil {
+			if strings.HasPrefix(err.Error(), "could not find client") {
+				logger.Info(fmt.Sprintf("skipping block %s because the provider %s does not exist locally or does not support tags",
+					blockID, providerName))
+			} else {
+				logger.Warning(fmt.Sprintf("failed to parse terraform block because %s", err.Error()))
+			}
+			continue
+		}
+
+		var rawBlock map[string]interface{}
+		if err = stdjson.Unmarshal(src[resource.node.Start:resource.node.End], &rawBlock); err != nil {
+			return nil, err
+		}
+		existingTags := make([]tags.ITag, 0)
+		tagsLines := structure.Lines{Start: -1, End: -1}
+		if tagsNode := resource.node.Get(tagsAttributeName); tagsNode != nil {
+			tagsLines = structure.Lines{Start: json.LineAt(string(src), tagsNode.Start), End: json.LineAt(string(src), tagsNode.End-1)}
+			var literalTags bool
+			existingTags, literalTags = getJSONExistingTags(tagsNode)
+			if !literalTags {
+				logger.Debug(fmt.Sprintf("Skipping block %s in %s, its tags are set by an expression", blockID, filePath))
+				isTaggable = false
+			}
+		}
+		parsedBlocks = append(parsedBlocks, &TerraformJSONBlock{
+			Imported: p.isImportedResource(filePath, blockID, fileImportTargets),
+			Block: structure.Block{
+				FilePath:          filePath,
+				ExitingTags:       existingTags,
+				RawBlock:          rawBlock,
+				IsTaggable:        isTaggable,
+				TagsAttributeName: tagsAttributeName,
+				Lines:             structure.Lines{Start: json.LineAt(string(src), resource.node.Start), End: json.LineAt(string(src), resource.node.End-1)},
+				TagLines:          tagsLines,
+				Name:              blockID,
+				Type:              resource.resourceType,
+			},
+		})
+	}
+	return parsedBlocks, nil
+}
+
+// writeJSONFile edits the tags of the resources in place, so the formatting and the order of the keys of a generated
+// file are kept
+func (p *TerraformParser) writeJSONFile(readFilePath string, blocks []structure.IBlock, writeFilePath string) error {
+	originFileSrc, err := utils.ReadFile(readFilePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file %s because %s", readFilePath, err)
+	}
+	src := string(originFileSrc)
+	root, err := json.ParseJSONNodes(src)
+	if err != nil {
+		return fmt.Errorf("failed to parse terraform json file %s because %s", readFilePath, err)
+	}
+	resourcesByID := make(map[string]*json.Node)
+	for _, resource := range collectJSONResources(root) {
+		resourcesByID[resource.resourceType+"."+resource.name] = resource.node
+	}
+
+	edits := make([]utils.TextEdit, 0)
+	for _, block := range blocks {
+		jsonBlock, ok := block.(*TerraformJSONBlock)
+		if !ok || !jsonBlock.IsBlockTaggable() {
+			continue
+		}
+		diff := jsonBlock.CalculateTagsDiff()
+		if len(diff.Added) == 0 && len(diff.Updated) == 0 {
+			logger.Debug(fmt.Sprintf("Nothing to update for block %v (%v)", jsonBlock.GetResourceID(), jsonBlock.GetFilePath()))
+			continue
+		}
+		resourceNode, ok := resourcesByID[jsonBlock.GetResourceID()]
+		if !ok {
+			logger.Warning(fmt.Sprintf("failed to find resource %s in %s", jsonBlock.GetResourceID(), readFilePath))
+			continue
+		}
+		edits = append(edits, json.GetTagsEdits(src, resourceNode, jsonBlock.GetTagsAttributeName(), diff, escapeJSONTemplate)...)
+	}
+
+	textToWrite := utils.ApplyTextEdits(src, edits)
+	if !stdjson.Valid([]byte(strings.TrimPrefix(textToWrite, "\ufeff"))) {
+		return fmt.Errorf("editing file %v resulted in malformed terraform, please open a github issue with the relevant details", readFilePath)
+	}
+	err = utils.WriteFile(writeFilePath, utils.MatchLineEndings(originFileSrc, []byte(textToWrite)))
+	if err != nil {
+		return fmt.Errorf("failed to write terraform json file %s, %s", readFilePath, err.Error())
+	}
+	return nil
+}
+
+// collectJSONResources returns the resources of the file in the order they appear in it. Each level of the resource
+// blocks is either an object or an array of objects, which terraform merges.
+func collectJSONResources(root *json.Node) []terraformJSONResource {
+	resources := make([]terraformJSONResource, 0)
+	for _, typeMember := range getJSONBlockMembers(root.Get(ResourceBlockType)) {
+		for _, nameMember := range getJSONBlockMembers(typeMember.Value) {
+			for _, body := range getJSONBlockBodies(nameMember.Value) {
+				resources = append(resources, terraformJSONResource{resourceType: typeMember.Key, name: nameMember.Key, node: body})
+			}
+		}
+	}
+	return resources
+}
+
+func getJSONBlockMembers(node *json.Node) []*json.Member {
+	members := make([]*json.Member, 0)
+	for _, body := range getJSONBlockBodies(node) {
+		for _, member := range body.Members {
+			if member.Key != jsonCommentKey {
+				members = append(members, member)
+			}
+		}
+	}
+	return members
+}
+
+func getJSONBlockBodies(node *json.Node) []*json.Node {
+	if node == nil {
+		return nil
+	}
+	switch node.Kind {
+	case json.ObjectNode:
+		return []*json.Node{node}
+	case json.ArrayNode:
+		bodies := make([]*json.Node, 0, len(node.Elements))
+		for _, element := range node.Elements {
+			if element.Kind == json.ObjectNode {
+				bodies = append(bodies, element)
+			}
+		}
+		return bodies
+	}
+	return nil
+}
+
+// getJSONExistingTags returns the tags of a resource, which can only be tagged if they are a literal object
+func getJSONExistingTags(tagsNode *json.Node) ([]tags.ITag, bool) {
+	existingTags := make([]tags.ITag, 0)
+	if tagsNode.Kind != json.ObjectNode {
+		return existingTags, false
+	}
+	for _, member := range tagsNode.Members {
+		if member.Key == jsonCommentKey {
+			continue
+		}
+		if member.Value.Kind != json.StringNode {
+			return existingTags, false
+		}
+		existingTags = append(existingTags, &tags.Tag{Key: member.Key, Value: unescapeJSONTemplate(member.Value.GetString())})
+	}
+	return existingTags, true
+}
+
+// escapeJSONTemplate escapes the template sequences of a literal value, since strings in the JSON syntax are templates
+func escapeJSONTemplate(value string) string {
+	return strings.NewReplacer("${", "$${", "%{", "%%{").Replace(value)
+}
+
+func unescapeJSONTemplate(value string) string {
+	return strings.NewReplacer("$${", "${", "%%{", "%{").Replace(value)
+}
@@ -1,11 +1,14 @@
 package structure
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
@@ -15,12 +18,9 @@ import (
 	"github.com/bridgecrewio/yor/src/common/structure"
 	"github.com/bridgecrewio/yor/src/common/tagging/tags"
 	"github.com/bridgecrewio/yor/src/common/utils"
-	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/hcl/v2"
 	"github.com/hashicorp/hcl/v2/hclsyntax"
 	"github.com/hashicorp/hcl/v2/hclwrite"
-	"github.com/hashicorp/terraform/command"
-	"github.com/minamijoyo/tfschema/tfschema"
 	"github.com/zclconf/go-cty/cty"
 )
 
@@ -37,17 +37,21 @@ var unsupportedTerraformBlocks = []string{
 var taggableResourcesLock sync.RWMutex
 var hclWriteLock sync.Mutex
 
+// apiDefinitionFileRegex matches the path of the file read by file() or templatefile(), relative to the module
+var apiDefinitionFileRegex = regexp.MustCompile(`^\s*(?:file|templatefile)\(\s*"(?:\$\{path\.module\}/)?([^"$]+)"`)
+
 type TerraformParser struct {
 	rootDir                string
-	providerToClientMap    sync.Map
 	taggableResourcesCache map[string]bool
 	tagModules             bool
 	tagLocalModules        bool
 	terraformModule        *TerraformModule
-	moduleImporter         *command.GetCommand
+	commands               terraformCommands
 	moduleInstallDir       string
 	downloadedPaths        []string
-	tfClientLock           sync.Mutex
+	ctx                    context.Context
+	importedResources      map[string]map[string]bool
+	importedResourcesLock  sync.Mutex
 }
 
 func (p *TerraformParser) Name() string {
@@ -57,6 +61,7 @@ func (p *TerraformParser) Name() string {
 func (p *TerraformParser) Init(rootDir string, args map[string]string) {
 	p.rootDir = rootDir
 	p.taggableResourcesCache = make(map[string]bool)
+	p.importedResources = make(map[string]map[string]bool)
 	p.tagModules = true
 	p.tagLocalModules = false
 	p.terraformModule = NewTerraformModule(rootDir)
@@ -68,19 +73,21 @@ func (p *TerraformParser) Init(rootDir string, args map[string]string) {
 		p.tagLocalModules, _ = strconv.ParseBool(argTagLocalModule)
 	}
 
-	p.moduleImporter = &command.GetCommand{Meta: command.Meta{Color: false, Ui: customTfLogger{}}}
+	p.initCommands()
 	pwd, _ := os.Getwd()
 	p.moduleInstallDir = filepath.Join(pwd, ".terraform", "modules")
 }
 
-func (p *TerraformParser) Close() {
-	logger.MuteOutputBlock(func() {
-		p.providerToClientMap.Range(func(provider, iClient interface{}) bool {
-			client := iClient.(tfschema.Client)
-			client.Close()
-			return true
-		})
-	})
+// SetContext sets the context which stops the parser from parsing further files and downloading modules
+func (p *TerraformParser) SetContext(ctx context.Context) {
+	p.ctx = ctx
+}
+
+func (p *TerraformParser) context() context.Context {
+	if p.ctx == nil {
+		return context.Background()
+	}
+	return p.ctx
 }
 
 func (p *TerraformParser) GetSkippedDirs() []string {
@@ -88,7 +95,7 @@ func (p *TerraformParser) GetSkippedDirs() []string {
 }
 
 func (p *TerraformParser) GetSupportedFileExtensions() []string {
-	return []string{common.TfFileType.Extension}
+	return []string{common.TfFileType.Extension, common.TfJSONFileType.Extension}
 }
 
 func (p *TerraformParser) GetSourceFiles(directory string) ([]string, error) {
@@ -104,11 +111,11 @@ func (p *TerraformParser) GetSourceFiles(directory string) ([]string, error) {
 	var files []string
 	for _, dir := range modulesDirectories {
 
-		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		err := utils.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
 			if err != nil {
 				return err
 			}
-			if !info.IsDir() && strings.HasSuffix(info.Name(), ".tf") {
+			if !entry.IsDir() && (strings.HasSuffix(entry.Name(), common.TfFileType.Extension) || strings.HasSuffix(entry.Name(), common.TfJSONFileType.Extension)) {
 				files = append(files, path)
 			}
 			return nil
@@ -126,13 +133,23 @@ func (p *TerraformParser) ValidFile(_ string) bool {
 }
 
 func (p *TerraformParser) ParseFile(filePath string) ([]structure.IBlock, error) {
-	// #nosec G304
 	// read file bytes
-	src, err := os.ReadFile(filePath)
+	src, err := utils.ReadFile(filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file %s because %s", filePath, err)
 	}
+	return p.ParseBuffer(filePath, src)
+}
 
+// ParseBuffer parses src as the content of filePath, so modules are resolved relative to filePath even when src isn't
+// saved to it
+func (p *TerraformParser) ParseBuffer(filePath string, src []byte) ([]structure.IBlock, error) {
+	if err := p.context().Err(); err != nil {
+		return nil, fmt.Errorf("failed to parse hcl file %s because %w", filePath, err)
+	}
+	if strings.HasSuffix(filePath, common.TfJSONFileType.Extension) {
+		return p.parseJSONBuffer(filePath, src)
+	}
 	// parse the file into hclwrite.File and hclsyntax.File to allow getting existing tags and lines
 	hclFile, diagnostics := hclwrite.ParseConfig(src, filePath, hcl.InitialPos)
 	if diagnostics != nil && diagnostics.HasErrors() {
@@ -150,6 +167,7 @@ func (p *TerraformParser) ParseFile(filePath string) ([]structure.IBlock, error)
 	}
 
 	syntaxBlocks := hclSyntaxFile.Body.(*hclsyntax.Body).Blocks
+	fileImportTargets := getHclImportTargets(src, syntaxBlocks)
 	rawBlocks := hclFile.Body().Blocks()
 	parsedBlocks := make([]structure.IBlock, 0)
 	for i, block := range rawBlocks {
@@ -173,6 +191,9 @@ func (p *TerraformParser) ParseFile(filePath string) ([]structure.IBlock, error)
 		}
 		terraformBlock.Init(filePath, block)
 		terraformBlock.AddHclSyntaxBlock(syntaxBlocks[i])
+		if block.Type() == ResourceBlockType {
+			terraformBlock.Imported = p.isImportedResource(filePath, blockID, fileImportTargets)
+		}
 		parsedBlocks = append(parsedBlocks, terraformBlock)
 	}
 
@@ -180,9 +201,11 @@ func (p *TerraformParser) ParseFile(filePath string) ([]structure.IBlock, error)
 }
 
 func (p *TerraformParser) WriteFile(readFilePath string, blocks []structure.IBlock, writeFilePath string) error {
-	// #nosec G304
+	if strings.HasSuffix(readFilePath, common.TfJSONFileType.Extension) {
+		return p.writeJSONFile(readFilePath, blocks, writeFilePath)
+	}
 	// read file bytes
-	src, err := os.ReadFile(readFilePath)
+	src, err := utils.ReadFile(readFilePath)
 	if err != nil {
 		return fmt.Errorf("failed to read file %s because %s", readFilePath, err)
 	}
@@ -211,51 +234,19 @@ func (p *TerraformParser) WriteFile(readFilePath string, blocks []structure.IBlo
 		}
 	}
 
-	tempFile, err := os.CreateTemp(filepath.Dir(readFilePath), "temp.*.tf")
-	if err != nil {
-		return err
-	}
-	fd, err := os.OpenFile(tempFile.Name(), os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0600)
-	if err != nil {
-		return err
-	}
 	hclWriteLock.Lock()
 	defer hclWriteLock.Unlock()
-	_, err = hclFile.WriteTo(fd)
-	if err != nil {
-		return err
-	}
-
-	_, err = p.ParseFile(tempFile.Name())
+	hclSrc := hclFile.Bytes()
+	_, err = p.ParseBuffer(readFilePath, hclSrc)
 	if err != nil {
 		return fmt.Errorf("editing file %v resulted in malformed terraform, please open a github issue with the relevant details", readFilePath)
 	}
-	err = tempFile.Close()
-	if err != nil {
-		return err
-	}
 
-	// can't delete files on windows if you dont close them
-	if err = fd.Close(); err != nil {
-		return err
-	}
-	err = os.Remove(tempFile.Name())
-	if err != nil {
-		return err
-	}
-
-	// #nosec G304
-	f, err := os.OpenFile(writeFilePath, os.O_TRUNC|os.O_CREATE|os.O_WRONLY, 0600)
-	if err != nil {
-		return err
-	}
-	_, err = hclFile.WriteTo(f)
+	// hclwrite keeps the original line endings, but the lines it adds always end with LF
+	err = utils.WriteFile(writeFilePath, utils.MatchLineEndings(src, hclSrc))
 	if err != nil {
 		return fmt.Errorf("failed to write HCL file %s, %s", readFilePath, err.Error())
 	}
-	if err = f.Close(); err != nil {
-		return err
-	}
 
 	return nil
 }
@@ -271,20 +262,9 @@ func (p *TerraformParser) modifyBlockTags(rawBlock *hclwrite.Block, parsedBlock
 		}
 	} else {
 		rawTagsTokens := tagsAttribute.Expr().BuildTokens(hclwrite.Tokens{})
-		isMergeOpExists := false
-		isRenderedAttribute := false
+		isMergeOpExists := isMergeCall(rawTagsTokens)
+		isRenderedAttribute := isReference(rawTagsTokens)
 		existingParsedTags := p.parseTagAttribute(rawTagsTokens)
-		for i, rawTagsToken := range rawTagsTokens {
-			tokenStr := string(rawTagsToken.Bytes)
-			if tokenStr == "merge" {
-				isMergeOpExists = true
-				break
-			}
-			if i == 0 && utils.InSlice([]string{VarBlockType, LocalBlockType, ModuleBlockType, DataBlockType}, tokenStr) {
-				isRenderedAttribute = true
-				break
-			}
-		}
 
 		var replacedTags []tags.ITag
 		var newTags []tags.ITag
@@ -345,6 +325,12 @@ func (p *TerraformParser) modifyBlockTags(rawBlock *hclwrite.Block, parsedBlock
 		// These lines execute if there is either a `merge` operator at the start of the tags,
 		// or if it is rendered via a variable / local.
 		newTagsTokens := buildTagsTokens(newTags)
+		if isMergeOpExists {
+			if start, end, ok := getLastMergedMap(rawTagsTokens); ok {
+				rawBlock.Body().SetAttributeRaw(tagsAttributeName, insertIntoMap(rawTagsTokens, start, end, newTagsTokens))
+				return
+			}
+		}
 		if !isMergeOpExists && newTagsTokens != nil {
 			// Insert the merge token, opening and closing parenthesis tokens
 			rawTagsTokens = InsertToken(rawTagsTokens, 0, &hclwrite.Token{
@@ -461,6 +447,8 @@ func (p *TerraformParser) parseBlock(hclBlock *hclwrite.Block, filePath string)
 	isTaggable := false
 	var tagsAttributeName string
 	var resourceType string
+	var moduleSource string
+	var apiDefinitionFile string
 	var err error
 
 	switch hclBlock.Type() {
@@ -478,11 +466,14 @@ func (p *TerraformParser) parseBlock(hclBlock *hclwrite.Block, filePath string)
 		existingTags, isTaggable = p.getExistingTags(hclBlock, tagsAttributeName)
 
 		if !isTaggable {
-			isTaggable, err = p.isBlockTaggable(hclBlock)
+			isTaggable, err = p.isResourceTypeTaggable(hclBlock.Labels()[0])
 			if err != nil {
 				return nil, err
 			}
 		}
+		if utils.InSlice(APIResourceTypes, resourceType) {
+			apiDefinitionFile = getAPIDefinitionFile(hclBlock, filePath)
+		}
 	case ModuleBlockType:
 		resourceType = "module"
 		defer func() {
@@ -491,9 +482,19 @@ func (p *TerraformParser) parseBlock(hclBlock *hclwrite.Block, filePath string)
 				err = fmt.Errorf("failed to parse module.%v", strings.Join(hclBlock.Labels(), "."))
 			}
 		}()
+		moduleSource = getModuleSource(hclBlock)
 		isTaggable, existingTags, tagsAttributeName = p.extractTagsFromModule(hclBlock, filePath, isTaggable, existingTags, tagsAttributeName)
 	}
 
+	var uneditableTagsReason string
+	if tagsAttribute := hclBlock.Body().GetAttribute(tagsAttributeName); isTaggable && tagsAttribute != nil {
+		uneditableTagsReason = getUneditableTagsReason(tagsAttributeName, tagsAttribute.Expr().BuildTokens(hclwrite.Tokens{}))
+		if uneditableTagsReason != "" {
+			logger.Warning(fmt.Sprintf("Skipping %v (%v): %v", strings.Join(hclBlock.Labels(), "."), filePath, uneditableTagsReason))
+			isTaggable = false
+		}
+	}
+
 	terraformBlock := TerraformBlock{
 		Block: structure.Block{
 			ExitingTags:       existingTags,
@@ -501,15 +502,40 @@ func (p *TerraformParser) parseBlock(hclBlock *hclwrite.Block, filePath string)
 			TagsAttributeName: tagsAttributeName,
 			Type:              resourceType,
 		},
+		UneditableTagsReason: uneditableTagsReason,
+		ModuleSource:         moduleSource,
+		APIDefinitionFile:    apiDefinitionFile,
 	}
 
 	return &terraformBlock, err
 }
 
-func (p *TerraformParser) extractTagsFromModule(hclBlock *hclwrite.Block, filePath string, isTaggable bool, existingTags []tags.ITag, tagsAttributeName string) (bool, []tags.ITag, string) {
+func getModuleSource(hclBlock *hclwrite.Block) string {
 	moduleSource := string(hclBlock.Body().GetAttribute("source").Expr().BuildTokens(hclwrite.Tokens{}).Bytes())
 	// source is always wrapped in " front and back
-	moduleSource = strings.Trim(moduleSource, "\" ")
+	return strings.Trim(moduleSource, "\" ")
+}
+
+// getAPIDefinitionFile returns the path of the OpenAPI definition of an API when its body reads a file of the module,
+// e.g. file("${path.module}/openapi.yaml") or templatefile("openapi.yaml", {...})
+func getAPIDefinitionFile(hclBlock *hclwrite.Block, filePath string) string {
+	bodyAttribute := hclBlock.Body().GetAttribute(APIBodyAttributeName)
+	if bodyAttribute == nil {
+		return ""
+	}
+	match := apiDefinitionFileRegex.FindSubmatch(bodyAttribute.Expr().BuildTokens(hclwrite.Tokens{}).Bytes())
+	if match == nil {
+		return ""
+	}
+	definitionFile := filepath.Join(filepath.Dir(filePath), filepath.FromSlash(string(match[1])))
+	if info, err := utils.Stat(definitionFile); err != nil || info.IsDir() {
+		return ""
+	}
+	return definitionFile
+}
+
+func (p *TerraformParser) extractTagsFromModule(hclBlock *hclwrite.Block, filePath string, isTaggable bool, existingTags []tags.ITag, tagsAttributeName string) (bool, []tags.ITag, string) {
+	moduleSource := getModuleSource(hclBlock)
 
 	if !isRemoteModule(moduleSource) && !isTerraformRegistryModule(moduleSource) && !p.tagLocalModules {
 		// Don't use the tags label on local modules - the underlying resources will be tagged by themselves
@@ -562,19 +588,19 @@ func (p *TerraformParser) isModuleTaggable(fp string, moduleName string, tagAtts
 	actualPath, _ := filepath.Rel(p.rootDir, filepath.Dir(fp))
 	absRootPath, _ := filepath.Abs(p.rootDir)
 	actualPath, _ = filepath.Abs(filepath.Join(absRootPath, actualPath))
-	if !utils.InSlice(p.downloadedPaths, fp) && os.Getenv("YOR_DISABLE_TF_MODULE_DOWNLOAD") != "TRUE" {
+	if !utils.InSlice(p.downloadedPaths, fp) && os.Getenv("YOR_DISABLE_TF_MODULE_DOWNLOAD") != "TRUE" && p.context().Err() == nil {
 		logger.MuteOutputBlock(func() {
 			logger.Info(fmt.Sprintf("Downloading modules for dir %v\n", actualPath))
-			_ = p.moduleImporter.Run([]string{actualPath})
+			p.downloadModules(actualPath)
 			p.downloadedPaths = append(p.downloadedPaths, fp)
 		})
 	}
 	expectedModuleDir := filepath.Join(p.moduleInstallDir, moduleName)
-	if _, err := os.Stat(expectedModuleDir); os.IsNotExist(err) {
+	if _, err := utils.Stat(expectedModuleDir); os.IsNotExist(err) {
 		return false, ""
 	}
 
-	files, _ := os.ReadDir(expectedModuleDir)
+	files, _ := utils.ReadDir(expectedModuleDir)
 	for _, f := range files {
 		if strings.HasSuffix(f.Name(), ".tf") {
 			blocks, _ := p.ParseFile(filepath.Join(expectedModuleDir, f.Name()))
@@ -624,7 +650,7 @@ func (p *TerraformParser) getExistingTags(hclBlock *hclwrite.Block, tagsAttribut
 	tagsAttribute := hclBlock.Body().GetAttribute(tagsAttributeName)
 	if tagsAttribute != nil {
 		// if tags exists in resource
-		isTaggable, _ = p.isBlockTaggable(hclBlock)
+		isTaggable, _ = p.isResourceTypeTaggable(hclBlock.Labels()[0])
 		tagsTokens := tagsAttribute.Expr().BuildTokens(hclwrite.Tokens{})
 		parsedTags := p.parseTagAttribute(tagsTokens)
 		for key := range parsedTags {
@@ -636,8 +662,7 @@ func (p *TerraformParser) getExistingTags(hclBlock *hclwrite.Block, tagsAttribut
 	return existingTags, isTaggable
 }
 
-func (p *TerraformParser) isBlockTaggable(hclBlock *hclwrite.Block) (bool, error) {
-	resourceType := hclBlock.Labels()[0]
+func (p *TerraformParser) isResourceTypeTaggable(resourceType string) (bool, error) {
 	if utils.InSlice(unsupportedTerraformBlocks, resourceType) {
 		return false, nil
 	}
@@ -657,24 +682,13 @@ func (p *TerraformParser) isBlockTaggable(hclBlock *hclwrite.Block) (bool, error
 
 	providerName := getProviderFromResourceType(resourceType)
 
-	client := p.getClient(providerName)
-	taggable := false
-	if client != nil {
-		var typeSchema *tfschema.Block
-		logger.MuteOutputBlock(func() {
-			typeSchema, err = client.GetResourceTypeSchema(resourceType)
-		})
-		if err != nil {
-			if strings.Contains(err.Error(), "Failed to find resource type") {
-				// Resource Type doesn't have schema yet in the provider
-				return false, nil
-			}
-			return false, err
-		}
-
-		if _, ok := typeSchema.Attributes[tagAtt]; ok {
-			taggable = true
+	taggable, err := p.hasSchemaAttribute(providerName, resourceType, tagAtt)
+	if err != nil {
+		if strings.Contains(err.Error(), "Failed to find resource type") {
+			// Resource Type doesn't have schema yet in the provider
+			return false, nil
 		}
+		return false, err
 	}
 	taggableResourcesLock.Lock()
 	p.taggableResourcesCache[resourceType] = taggable
@@ -784,48 +798,6 @@ func (p *TerraformParser) parseTagAttribute(tokens hclwrite.Tokens) map[string]s
 	return parsedTags
 }
 
-func (p *TerraformParser) getClient(providerName string) tfschema.Client {
-	if utils.InSlice(SkippedProviders, providerName) {
-		return nil
-	}
-
-	p.tfClientLock.Lock()
-	defer p.tfClientLock.Unlock()
-
-	client, exists := p.providerToClientMap.Load(providerName)
-	if exists {
-		return client.(tfschema.Client)
-	}
-
-	hclLogger := hclog.New(&hclog.LoggerOptions{
-		Name:   "plugin",
-		Level:  hclog.Error,
-		Output: hclog.DefaultOutput,
-	})
-	var err error
-	var newClient tfschema.Client
-	if p.terraformModule == nil {
-		logger.Warning(fmt.Sprintf("Failed to initialize terraform module, it might be due to a malformed file in the given root dir: [%s]", p.rootDir))
-		return nil
-	}
-	logger.MuteOutputBlock(func() {
-		newClient, err = tfschema.NewClient(providerName, tfschema.Option{
-			RootDir: p.terraformModule.ProvidersInstallDir,
-			Logger:  hclLogger,
-		})
-	})
-	if err != nil {
-		if strings.Contains(err.Error(), "Failed to find plugin") {
-			logger.Warning(fmt.Sprintf("Could not load provider %v, resources from this provider will not be tagged", providerName))
-			logger.Warning(fmt.Sprintf("Try to run `terraform init` in the given root dir: [%s] and try again.", p.rootDir))
-		}
-		return nil
-	}
-
-	p.providerToClientMap.Store(providerName, newClient)
-	return newClient
-}
-
 func (p *TerraformParser) getModuleTags(hclBlock *hclwrite.Block, tagsAttributeName string) ([]tags.ITag, bool) {
 	isTaggable := false
 	existingTags := make([]tags.ITag, 0)
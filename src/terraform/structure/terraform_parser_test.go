@@ -1,6 +1,8 @@
 package structure
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -13,6 +15,7 @@ import (
 	"github.com/bridgecrewio/yor/src/common/structure"
 	"github.com/bridgecrewio/yor/src/common/tagging/code2cloud"
 	"github.com/bridgecrewio/yor/src/common/tagging/gittag"
+	"github.com/bridgecrewio/yor/src/common/tagging/simple"
 	"github.com/bridgecrewio/yor/src/common/tagging/tags"
 	"github.com/bridgecrewio/yor/src/common/utils"
 	"github.com/go-git/go-git/v5"
@@ -135,6 +138,72 @@ func TestTerraformParser_ParseFile(t *testing.T) {
 		assert.Nil(t, parsedBlocks)
 		assert.NotNil(t, err)
 	})
+
+	t.Run("parse terraform json file", func(t *testing.T) {
+		p := &TerraformParser{}
+		p.Init("../../../tests/terraform/resources/json", nil)
+		defer p.Close()
+		parsedBlocks, err := p.ParseFile("../../../tests/terraform/resources/json/main.tf.json")
+		assert.Nil(t, err)
+		expected := []struct {
+			resourceID   string
+			taggable     bool
+			lines        structure.Lines
+			tagsLines    structure.Lines
+			existingTags []tags.ITag
+		}{
+			{"aws_s3_bucket.data_bucket", true, structure.Lines{Start: 5, End: 11}, structure.Lines{Start: 7, End: 10},
+				[]tags.ITag{&tags.Tag{Key: "Name", Value: "data-bucket"}, &tags.Tag{Key: "template", Value: "${literal}"}}},
+			{"aws_s3_bucket.logs_bucket", false, structure.Lines{Start: 12, End: 15}, structure.Lines{Start: 14, End: 14}, []tags.ITag{}},
+			{"aws_instance.web", true, structure.Lines{Start: 18, End: 21}, structure.Lines{Start: -1, End: -1}, []tags.ITag{}},
+			{"google_storage_bucket.assets", true, structure.Lines{Start: 25, End: 25}, structure.Lines{Start: -1, End: -1}, []tags.ITag{}},
+		}
+		assert.Equal(t, len(expected), len(parsedBlocks))
+		for i, block := range parsedBlocks {
+			assert.Equal(t, expected[i].resourceID, block.GetResourceID())
+			assert.Equal(t, expected[i].taggable, block.IsBlockTaggable(), block.GetResourceID())
+			assert.Equal(t, expected[i].lines, block.GetLines())
+			assert.Equal(t, expected[i].tagsLines, block.GetTagsLines())
+			assert.Equal(t, expected[i].existingTags, block.GetExistingTags())
+		}
+		assert.True(t, parsedBlocks[3].IsGCPBlock())
+		assert.Equal(t, "labels", parsedBlocks[3].GetTagsAttributeName())
+	})
+
+	t.Run("parse resources targeted by import blocks", func(t *testing.T) {
+		p := &TerraformParser{}
+		p.Init("../../../tests/terraform/resources/import", nil)
+		defer p.Close()
+		parsedBlocks, err := p.ParseFile("../../../tests/terraform/resources/import/main.tf")
+		assert.Nil(t, err)
+		expectedImported := map[string]bool{
+			"aws_s3_bucket.imported": true,
+			"aws_s3_bucket.created":  false,
+			"aws_instance.web":       true,
+			"aws_s3_bucket.logs":     true,
+		}
+		assert.Equal(t, len(expectedImported), len(parsedBlocks))
+		c2cTagGroup := &code2cloud.TagGroup{}
+		c2cTagGroup.InitTagGroup("", nil, nil)
+		for _, block := range parsedBlocks {
+			assert.Equal(t, expectedImported[block.GetResourceID()], block.(*TerraformBlock).IsImported(), block.GetResourceID())
+			// imported resources are traced on their first scan, like the ones created by the code
+			assert.Nil(t, c2cTagGroup.CreateTagsForBlock(block))
+			assert.NotEqual(t, "", block.GetTraceID(), block.GetResourceID())
+		}
+	})
+
+	t.Run("Stop parsing when the context is cancelled", func(t *testing.T) {
+		p := &TerraformParser{}
+		p.Init("../../../tests/terraform/resources", nil)
+		defer p.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		p.SetContext(ctx)
+		parsedBlocks, err := p.ParseFile("../../../tests/terraform/resources/complex_tags.tf")
+		assert.Nil(t, parsedBlocks)
+		assert.True(t, errors.Is(err, context.Canceled))
+	})
 }
 
 func TestTerraformParser(t *testing.T) {
@@ -154,6 +223,52 @@ func TestTerraformParser(t *testing.T) {
 			t.Error(err)
 		}
 	})
+
+	t.Run("Preserve CRLF line endings", func(t *testing.T) {
+		directory := t.TempDir()
+		filePath := filepath.Join(directory, "main.tf")
+		src := "resource \"aws_s3_bucket\" \"b\" {\r\n  bucket = \"my-bucket\"\r\n}\r\n"
+		assert.Nil(t, os.WriteFile(filePath, []byte(src), 0600))
+		p := &TerraformParser{}
+		p.Init(directory, nil)
+		c2cTagGroup := &code2cloud.TagGroup{}
+		c2cTagGroup.InitTagGroup("", nil, nil)
+		parsedBlocks, err := p.ParseFile(filePath)
+		assert.Nil(t, err)
+		for _, block := range parsedBlocks {
+			_ = c2cTagGroup.CreateTagsForBlock(block)
+		}
+
+		assert.Nil(t, p.WriteFile(filePath, parsedBlocks, filePath))
+		written, _ := os.ReadFile(filePath)
+		assert.Contains(t, string(written), tags.YorTraceTagKey)
+		assert.Equal(t, strings.Count(string(written), "\n"), strings.Count(string(written), "\r\n"))
+	})
+
+	t.Run("Tag terraform json file keeping its formatting", func(t *testing.T) {
+		directory := "../../../tests/terraform/resources/json"
+		p := &TerraformParser{}
+		p.Init(directory, nil)
+		defer p.Close()
+		tagGroup := simple.TagGroup{}
+		tagGroup.SetTags([]tags.ITag{
+			&tags.Tag{Key: "new_tag", Value: "new_value"},
+			// strings are templates in the json syntax, so literal values are escaped
+			&tags.Tag{Key: "template", Value: "${literal}"},
+		})
+		tagGroup.InitTagGroup("", []string{}, []string{})
+		parsedBlocks, err := p.ParseFile(filepath.Join(directory, "main.tf.json"))
+		assert.Nil(t, err)
+		for _, block := range parsedBlocks {
+			assert.Nil(t, tagGroup.CreateTagsForBlock(block))
+		}
+
+		writeFilePath := filepath.Join(t.TempDir(), "main.tf.json")
+		assert.Nil(t, p.WriteFile(filepath.Join(directory, "main.tf.json"), parsedBlocks, writeFilePath))
+		expectedContent, _ := os.ReadFile(filepath.Join(directory, "main_tagged.tf.json"))
+		actualContent, _ := os.ReadFile(writeFilePath)
+		assert.Equal(t, string(expectedContent), string(actualContent))
+	})
 }
 
 func TestTerraformParser_Module(t *testing.T) {
@@ -464,6 +579,45 @@ func TestTerraformParser_Module(t *testing.T) {
 		assert.Equal(t, string(expected), string(result))
 	})
 
+	t.Run("TestTagsExpressions", func(t *testing.T) {
+		p := &TerraformParser{}
+		p.Init("../../../tests/terraform/resources/tagsexpressions", nil)
+		defer p.Close()
+		filePath := "../../../tests/terraform/resources/tagsexpressions/main.tf"
+		resultFilePath := "../../../tests/terraform/resources/tagsexpressions/main_result.tf"
+		expectedFilePath := "../../../tests/terraform/resources/tagsexpressions/expected.txt"
+		blocks, err := p.ParseFile(filePath)
+		assert.Nil(t, err)
+		uneditableBlocks := map[string]bool{
+			"aws_s3_bucket.conditional_tags":    true,
+			"aws_s3_bucket.tomap_tags":          true,
+			"aws_s3_bucket.for_expression_tags": true,
+		}
+		for _, block := range blocks {
+			if block.GetResourceType() != "aws_s3_bucket" {
+				continue
+			}
+			isUneditable := uneditableBlocks[block.GetResourceID()]
+			assert.Equal(t, !isUneditable, block.IsBlockTaggable(), block.GetResourceID())
+			assert.Equal(t, isUneditable, block.(*TerraformBlock).GetUneditableTagsReason() != "", block.GetResourceID())
+			if block.IsBlockTaggable() {
+				block.AddNewTags([]tags.ITag{
+					&tags.Tag{Key: "git_repo", Value: "yor"},
+					&tags.Tag{Key: "git_org", Value: "bridgecrewio"},
+				})
+			}
+		}
+
+		assert.Nil(t, p.WriteFile(filePath, blocks, resultFilePath))
+		defer func() {
+			_ = os.Remove(resultFilePath)
+		}()
+
+		result, _ := os.ReadFile(resultFilePath)
+		expected, _ := os.ReadFile(expectedFilePath)
+		assert.Equal(t, string(expected), string(result))
+	})
+
 	t.Run("Module isTaggable local/remote", func(t *testing.T) {
 		directory := "../../../tests/terraform/resources/local_module"
 		terraformParser := TerraformParser{}
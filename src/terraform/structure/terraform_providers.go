@@ -0,0 +1,121 @@
+//go:build !js
+
+package structure
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/bridgecrewio/yor/src/common/utils"
+	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+	"github.com/hashicorp/terraform/addrs"
+	"github.com/hashicorp/terraform/moduledeps"
+	"github.com/hashicorp/terraform/plugin/discovery"
+	"github.com/hashicorp/terraform/tfdiags"
+	"github.com/mitchellh/cli"
+)
+
+// InitProvider installs the providers of the module, which the browser can't download (see terraform_commands_js.go)
+func (t *TerraformModule) InitProvider() {
+	moduleDependencies := getProviderDependencies(t.tfModule)
+	providers := moduleDependencies.AllPluginRequirements()
+	providerInstaller := &discovery.ProviderInstaller{
+		Dir:                   t.ProvidersInstallDir,
+		PluginProtocolVersion: discovery.PluginInstallProtocolVersion,
+		SkipVerify:            false,
+		Ui:                    &cli.MockUi{},
+	}
+	for provider, constraints := range providers {
+		if utils.InSlice(SkippedProviders, provider) {
+			continue
+		}
+		if providerExists(t.ProvidersInstallDir, provider) {
+			return
+		}
+		pty := addrs.NewLegacyProvider(provider)
+		var err error
+		var diagnostics tfdiags.Diagnostics
+		logger.MuteOutputBlock(func() {
+			_, diagnostics, err = providerInstaller.Get(pty, constraints.Versions)
+		})
+		if (diagnostics != nil && diagnostics.HasErrors()) || err != nil {
+			errMsg := diagnostics.Err()
+			if errMsg == nil {
+				errMsg = err
+			}
+			logger.Warning(fmt.Sprintf("failed to install provider \"%v\" for directory %s because of errors %s", provider, t.rootDir, errMsg))
+		}
+	}
+}
+
+func providerExists(providersInstallDir string, provider string) bool {
+	fileInfo, err := os.ReadDir(providersInstallDir)
+	if err != nil {
+		return false
+	}
+	for _, file := range fileInfo {
+		if strings.Contains(file.Name(), provider) && strings.Contains(file.Name(), "provider") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func getProviderDependencies(tfModule *tfconfig.Module) *moduledeps.Module {
+	moduleDependencies := &moduledeps.Module{}
+	providers := make(moduledeps.Providers)
+
+	for name, requirement := range tfModule.RequiredProviders {
+		var constraints version.Constraints
+		for _, reqStr := range requirement.VersionConstraints {
+			if reqStr != "" {
+				constraint, err := version.NewConstraint(reqStr)
+				if err != nil {
+					logger.Warning(fmt.Sprintf("Invalid version constraint %q for provider %s.", reqStr, name))
+					continue
+				}
+				constraints = append(constraints, constraint...)
+			}
+		}
+
+		inst := moduledeps.ProviderInstance(name)
+		providers[inst] = moduledeps.ProviderDependency{
+			Constraints: discovery.NewConstraints(constraints),
+			Reason:      moduledeps.ProviderDependencyExplicit,
+		}
+	}
+
+	for name := range ProviderToTagAttribute {
+		inst := moduledeps.ProviderInstance(name)
+		if _, ok := providers[inst]; !ok {
+			providers[inst] = moduledeps.ProviderDependency{
+				Constraints: discovery.Constraints{},
+				Reason:      moduledeps.ProviderDependencyImplicit,
+			}
+		}
+	}
+	moduleDependencies.Providers = providers
+
+	for _, moduleCall := range tfModule.ModuleCalls {
+		if isRemoteModule(moduleCall.Source) || isTerraformRegistryModule(moduleCall.Source) {
+			logger.Info("Skipping remote git module", moduleCall.Source)
+			continue
+		}
+		childModulePath := path.Join(tfModule.Path, moduleCall.Source)
+		tfChildModule, diagnostics := tfconfig.LoadModule(childModulePath)
+		if diagnostics != nil && diagnostics.HasErrors() {
+			hclErrors := diagnostics.Error()
+			logger.Warning(fmt.Sprintf("failed to parse hcl module in directory %s because of errors %s", path.Join(childModulePath, moduleCall.Source), hclErrors))
+		} else {
+			child := getProviderDependencies(tfChildModule)
+			moduleDependencies.Children = append(moduleDependencies.Children, child)
+		}
+	}
+
+	return moduleDependencies
+}
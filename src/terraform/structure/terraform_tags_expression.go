@@ -0,0 +1,132 @@
+package structure
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+)
+
+// The tags attribute of a block can be set by any expression. Tags are added to its literal map if it has one, i.e.
+// { ... } or the last argument of merge(var.tags, { ... }), and are merged with it as a new map if it's a reference,
+// i.e. var.tags or local.tags. Any other expression, like a conditional or a function call, is left untouched and its
+// block isn't tagged, as adding a map to it may change what it evaluates to.
+
+// getUneditableTagsReason returns why tags can't be added to the given expression of the tags attribute, or an empty
+// string if they can
+func getUneditableTagsReason(tagsAttributeName string, tokens hclwrite.Tokens) string {
+	if isNullExpression(tokens) || isLiteralMap(tokens) || isMergeCall(tokens) || isReference(tokens) {
+		return ""
+	}
+	return fmt.Sprintf("the expression of %s has no literal map to add the tags to", tagsAttributeName)
+}
+
+func isNullExpression(tokens hclwrite.Tokens) bool {
+	return len(tokens) == 1 && string(tokens[0].Bytes) == "null"
+}
+
+// isLiteralMap checks if the tokens are a single map, i.e. { a = "b" }, rather than a for expression
+func isLiteralMap(tokens hclwrite.Tokens) bool {
+	if len(tokens) < 2 || tokens[0].Type != hclsyntax.TokenOBrace || getClosingTokenIndex(tokens, 0) != len(tokens)-1 {
+		return false
+	}
+	for _, token := range tokens[1:] {
+		if token.Type != hclsyntax.TokenNewline {
+			return token.Type != hclsyntax.TokenIdent || string(token.Bytes) != "for"
+		}
+	}
+	return true
+}
+
+// isMergeCall checks if the tokens are a single call of merge, i.e. merge(var.tags, { a = "b" })
+func isMergeCall(tokens hclwrite.Tokens) bool {
+	return len(tokens) > 2 && string(tokens[0].Bytes) == "merge" && tokens[1].Type == hclsyntax.TokenOParen &&
+		getClosingTokenIndex(tokens, 1) == len(tokens)-1
+}
+
+// isReference checks if the tokens are a single reference to a value, i.e. var.tags, local.tags["web"] or
+// each.value.tags
+func isReference(tokens hclwrite.Tokens) bool {
+	if len(tokens) < 3 || tokens[0].Type != hclsyntax.TokenIdent {
+		return false
+	}
+	for _, token := range tokens[1:] {
+		switch token.Type {
+		case hclsyntax.TokenDot, hclsyntax.TokenIdent, hclsyntax.TokenOBrack, hclsyntax.TokenCBrack,
+			hclsyntax.TokenNumberLit, hclsyntax.TokenOQuote, hclsyntax.TokenQuotedLit, hclsyntax.TokenCQuote:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// getClosingTokenIndex returns the index of the token which closes the bracket at the start index, or -1 if it isn't
+// closed
+func getClosingTokenIndex(tokens hclwrite.Tokens, start int) int {
+	depth := 0
+	for i := start; i < len(tokens); i++ {
+		switch tokens[i].Type {
+		case hclsyntax.TokenOBrace, hclsyntax.TokenOParen, hclsyntax.TokenOBrack, hclsyntax.TokenTemplateInterp,
+			hclsyntax.TokenTemplateControl:
+			depth++
+		case hclsyntax.TokenCBrace, hclsyntax.TokenCParen, hclsyntax.TokenCBrack, hclsyntax.TokenTemplateSeqEnd:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// getLastMergedMap returns the indexes of the braces of the last argument of the merge call, if it's a literal map.
+// Tags are added only to the last argument, since it takes precedence over the others like a new map would.
+func getLastMergedMap(tokens hclwrite.Tokens) (int, int, bool) {
+	closingIndex := len(tokens) - 1
+	lastArgStart := 2
+	for i := 2; i < closingIndex; i++ {
+		switch tokens[i].Type {
+		case hclsyntax.TokenComma:
+			// a trailing comma doesn't start another argument
+			if !isBlank(tokens[i+1 : closingIndex]) {
+				lastArgStart = i + 1
+			}
+		case hclsyntax.TokenOBrace, hclsyntax.TokenOParen, hclsyntax.TokenOBrack, hclsyntax.TokenTemplateInterp,
+			hclsyntax.TokenTemplateControl:
+			if i = getClosingTokenIndex(tokens, i); i == -1 {
+				return 0, 0, false
+			}
+		}
+	}
+	start, end := lastArgStart, closingIndex-1
+	for start <= end && tokens[start].Type == hclsyntax.TokenNewline {
+		start++
+	}
+	for end >= start && (tokens[end].Type == hclsyntax.TokenNewline || tokens[end].Type == hclsyntax.TokenComma) {
+		end--
+	}
+	if start > end || !isLiteralMap(tokens[start:end+1]) {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+func isBlank(tokens hclwrite.Tokens) bool {
+	for _, token := range tokens {
+		if token.Type != hclsyntax.TokenNewline {
+			return false
+		}
+	}
+	return true
+}
+
+// insertIntoMap inserts the tags of tagsTokens, as built by buildTagsTokens, to the map between the given braces
+func insertIntoMap(tokens hclwrite.Tokens, start int, end int, tagsTokens hclwrite.Tokens) hclwrite.Tokens {
+	var mapTokens hclwrite.Tokens
+	mapTokens = append(mapTokens, tokens[start:end+1]...)
+	var result hclwrite.Tokens
+	result = append(result, tokens[:start]...)
+	result = append(result, InsertTokens(mapTokens, tagsTokens[2:len(tagsTokens)-2])...)
+	return append(result, tokens[end+1:]...)
+}
@@ -0,0 +1,111 @@
+//go:build js && wasm
+
+// The wasm command builds the terraform parser and the tagging of yor for the browser, so editors can preview the tags
+// yor would add to a file before it is committed:
+//
+//	GOOS=js GOARCH=wasm go build -o yor.wasm ./src/wasm
+//
+// Once loaded (with the wasm_exec.js of the Go release), it sets the global function
+// yorPreviewTags(path, text, extraTags), which returns {text} with the text of the file tagged, or {error}. The files
+// only live in memory, so the git tags, which need the history of the file, aren't added.
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"syscall/js"
+
+	"github.com/bridgecrewio/yor/src/common/logger"
+	"github.com/bridgecrewio/yor/src/common/tagging"
+	"github.com/bridgecrewio/yor/src/common/tagging/code2cloud"
+	"github.com/bridgecrewio/yor/src/common/tagging/simple"
+	"github.com/bridgecrewio/yor/src/common/tagging/tags"
+	"github.com/bridgecrewio/yor/src/common/utils"
+	tfStructure "github.com/bridgecrewio/yor/src/terraform/structure"
+)
+
+// exitError ends the preview in which yor failed, instead of the Go program of the page
+type exitError int
+
+func main() {
+	logger.SetExitFunc(func(code int) {
+		panic(exitError(code))
+	})
+	js.Global().Set("yorPreviewTags", js.FuncOf(previewTagsFunc))
+	// the function is served until the page is closed
+	select {}
+}
+
+func previewTagsFunc(_ js.Value, args []js.Value) interface{} {
+	if len(args) < 2 || args[0].Type() != js.TypeString || args[1].Type() != js.TypeString {
+		return map[string]interface{}{"error": "expected the path and the text of a terraform file, and optionally an object of extra tags"}
+	}
+	extraTags := make(map[string]string)
+	if len(args) > 2 && args[2].Type() == js.TypeObject {
+		keys := js.Global().Get("Object").Call("keys", args[2])
+		for i := 0; i < keys.Length(); i++ {
+			key := keys.Index(i).String()
+			extraTags[key] = args[2].Get(key).String()
+		}
+	}
+	text, err := previewTags(args[0].String(), []byte(args[1].String()), extraTags)
+	if err != nil {
+		return map[string]interface{}{"error": err.Error()}
+	}
+	return map[string]interface{}{"text": string(text)}
+}
+
+// previewTags returns src, the text of the terraform file, with the code2cloud tags and the extra tags added to its
+// resources
+func previewTags(file string, src []byte, extraTags map[string]string) (tagged []byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			code, ok := r.(exitError)
+			if !ok {
+				panic(r)
+			}
+			err = fmt.Errorf("yor failed with exit code %d, the console has its errors", int(code))
+		}
+	}()
+	utils.SetFileSystem(utils.NewMemoryFileSystem(map[string][]byte{file: src}))
+	dir := filepath.Dir(file)
+	parser := &tfStructure.TerraformParser{}
+	parser.Init(dir, map[string]string{})
+	defer parser.Close()
+	blocks, err := parser.ParseFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	simpleTagGroup := &simple.TagGroup{}
+	tagGroups := []tagging.ITagGroup{&code2cloud.TagGroup{}, simpleTagGroup}
+	for _, tagGroup := range tagGroups {
+		tagGroup.InitTagGroup(dir, nil, nil)
+	}
+	keys := make([]string, 0, len(extraTags))
+	for key := range extraTags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	simpleTags := make([]tags.ITag, 0, len(keys))
+	for _, key := range keys {
+		simpleTags = append(simpleTags, tags.Init(key, extraTags[key]))
+	}
+	simpleTagGroup.SetTags(simpleTags)
+
+	for _, block := range blocks {
+		if !block.IsBlockTaggable() {
+			continue
+		}
+		for _, tagGroup := range tagGroups {
+			if err = tagGroup.CreateTagsForBlock(block); err != nil {
+				return nil, fmt.Errorf("failed to tag %v: %s", block.GetResourceID(), err)
+			}
+		}
+	}
+	if err = parser.WriteFile(file, blocks, file); err != nil {
+		return nil, err
+	}
+	return utils.ReadFile(file)
+}
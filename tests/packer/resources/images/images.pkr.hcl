@@ -0,0 +1,46 @@
+packer {
+  required_plugins {
+    amazon = {
+      version = ">= 1.2.0"
+      source  = "github.com/hashicorp/amazon"
+    }
+  }
+}
+
+variable "region" {
+  type    = string
+  default = "us-west-2"
+}
+
+source "amazon-ebs" "ubuntu" {
+  ami_name      = "ubuntu-base"
+  instance_type = "t3.micro"
+  region        = var.region
+  tags = {
+    Name    = "ubuntu-base"
+    new_tag = "old_value"
+  }
+}
+
+source "azure-arm" "windows" {
+  image_offer     = "WindowsServer"
+  image_publisher = "MicrosoftWindowsServer"
+  os_type         = "Windows"
+}
+
+source "googlecompute" "debian" {
+  project_id   = "my-project"
+  source_image = "debian-11"
+  zone         = "us-central1-a"
+  image_labels = { team = "platform" }
+  labels       = {}
+}
+
+source "amazon-ebs" "dynamic" {
+  ami_name = "dynamic"
+  tags     = local.tags
+}
+
+build {
+  sources = ["source.amazon-ebs.ubuntu", "source.azure-arm.windows", "source.googlecompute.debian"]
+}
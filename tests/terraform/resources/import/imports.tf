@@ -0,0 +1,14 @@
+import {
+  to = aws_s3_bucket.imported
+  id = "imported-bucket"
+}
+
+import {
+  to = aws_instance.web["a"]
+  id = "i-0123456789"
+}
+
+import {
+  to = module.storage.aws_s3_bucket.created
+  id = "created-bucket"
+}
@@ -0,0 +1,22 @@
+resource "aws_s3_bucket" "imported" {
+  bucket = "imported-bucket"
+}
+
+resource "aws_s3_bucket" "created" {
+  bucket = "created-bucket"
+}
+
+resource "aws_instance" "web" {
+  for_each      = toset(["a", "b"])
+  ami           = "ami-123"
+  instance_type = "t3.micro"
+}
+
+import {
+  to = aws_s3_bucket.logs
+  id = "logs-bucket"
+}
+
+resource "aws_s3_bucket" "logs" {
+  bucket = "logs-bucket"
+}
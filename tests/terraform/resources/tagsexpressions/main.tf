@@ -0,0 +1,58 @@
+locals {
+  common_tags = {
+    Team = "platform"
+  }
+}
+
+resource "aws_s3_bucket" "local_tags" {
+  bucket = "local-tags"
+  tags   = local.common_tags
+}
+
+resource "aws_s3_bucket" "merged_local_tags" {
+  bucket = "merged-local-tags"
+  tags = merge(local.common_tags, {
+    Name = "merged-local-tags"
+  })
+}
+
+resource "aws_s3_bucket" "merged_map_first" {
+  bucket = "merged-map-first"
+  tags   = merge({ Name = "merged-map-first" }, var.tags)
+}
+
+resource "aws_s3_bucket" "for_each_tags" {
+  for_each = var.buckets
+  bucket   = each.key
+  tags     = each.value.tags
+}
+
+resource "aws_s3_bucket" "conditional_tags" {
+  bucket = "conditional-tags"
+  tags   = var.env == "prod" ? var.tags : {}
+}
+
+resource "aws_s3_bucket" "tomap_tags" {
+  bucket = "tomap-tags"
+  tags   = tomap(var.tags)
+}
+
+resource "aws_s3_bucket" "for_expression_tags" {
+  bucket = "for-expression-tags"
+  tags   = { for key, value in var.tags : key => value if key != "Owner" }
+}
+
+variable "tags" {
+  default = {}
+  type    = map(string)
+}
+
+variable "buckets" {
+  default = {}
+  type    = map(object({ tags = map(string) }))
+}
+
+variable "env" {
+  default = "dev"
+  type    = string
+}